@@ -17,6 +17,7 @@
 package builder
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -28,6 +29,7 @@ import (
 
 	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/bake"
 	"github.com/containerd/nerdctl/v2/pkg/cmd/builder"
 )
 
@@ -43,6 +45,9 @@ func Command() *cobra.Command {
 	cmd.AddCommand(
 		BuildCommand(),
 		pruneCommand(),
+		diskUsageCommand(),
+		bakeCommand(),
+		historyCommand(),
 		debugCommand(),
 	)
 	return cmd
@@ -62,6 +67,8 @@ func pruneCommand() *cobra.Command {
 	cmd.Flags().String("buildkit-host", "", "BuildKit address")
 	cmd.Flags().BoolP("all", "a", false, "Remove all unused build cache, not just dangling ones")
 	cmd.Flags().BoolP("force", "f", false, "Do not prompt for confirmation")
+	cmd.Flags().StringArray("filter", nil, `Provide filter values (e.g., "until=24h", "type=regular")`)
+	cmd.Flags().String("keep-storage", "", "Amount of disk space to keep for cache (e.g., 10GB)")
 	return cmd
 }
 
@@ -122,15 +129,314 @@ func pruneOptions(cmd *cobra.Command) (types.BuilderPruneOptions, error) {
 		return types.BuilderPruneOptions{}, err
 	}
 
+	filters, err := cmd.Flags().GetStringArray("filter")
+	if err != nil {
+		return types.BuilderPruneOptions{}, err
+	}
+
+	keepStorageStr, err := cmd.Flags().GetString("keep-storage")
+	if err != nil {
+		return types.BuilderPruneOptions{}, err
+	}
+	var keepStorage int64
+	if keepStorageStr != "" {
+		keepStorage, err = units.RAMInBytes(keepStorageStr)
+		if err != nil {
+			return types.BuilderPruneOptions{}, fmt.Errorf("invalid keep-storage %q: %w", keepStorageStr, err)
+		}
+	}
+
 	return types.BuilderPruneOptions{
 		Stderr:       cmd.OutOrStderr(),
 		GOptions:     globalOptions,
 		BuildKitHost: buildkitHost,
 		All:          all,
 		Force:        force,
+		Filters:      filters,
+		KeepStorage:  keepStorage,
 	}, nil
 }
 
+func diskUsageCommand() *cobra.Command {
+	shortHelp := `Show disk usage of the BuildKit build cache`
+	var cmd = &cobra.Command{
+		Use:           "du",
+		Args:          cobra.NoArgs,
+		Short:         shortHelp,
+		RunE:          diskUsageAction,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+
+	cmd.Flags().String("buildkit-host", "", "BuildKit address")
+	cmd.Flags().StringArray("filter", nil, `Provide filter values (e.g., "until=24h", "type=regular")`)
+	cmd.Flags().BoolP("verbose", "v", false, "Provide a more verbose output")
+	cmd.Flags().String("format", "", "Format the output using the given Go template, e.g, '{{json .}}'")
+	cmd.RegisterFlagCompletionFunc("format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"json", "table"}, cobra.ShellCompDirectiveNoFileComp
+	})
+	return cmd
+}
+
+func diskUsageAction(cmd *cobra.Command, _ []string) error {
+	globalOptions, err := helpers.ProcessRootCmdFlags(cmd)
+	if err != nil {
+		return err
+	}
+
+	buildkitHost, err := GetBuildkitHost(cmd, globalOptions.Namespace)
+	if err != nil {
+		return err
+	}
+
+	filters, err := cmd.Flags().GetStringArray("filter")
+	if err != nil {
+		return err
+	}
+
+	verbose, err := cmd.Flags().GetBool("verbose")
+	if err != nil {
+		return err
+	}
+
+	format, err := cmd.Flags().GetString("format")
+	if err != nil {
+		return err
+	}
+
+	return builder.DiskUsage(cmd.Context(), types.BuilderDiskUsageOptions{
+		Stdout:       cmd.OutOrStdout(),
+		GOptions:     globalOptions,
+		BuildKitHost: buildkitHost,
+		Filters:      filters,
+		Verbose:      verbose,
+		Format:       format,
+	})
+}
+
+func bakeCommand() *cobra.Command {
+	shortHelp := `Build the targets defined in a bake or compose file`
+	longHelp := shortHelp + `
+
+The targets are read from docker-bake files (HCL or JSON) or from the "build" sections of compose files.
+When no file is specified, the following files are looked up in the current directory:
+` + strings.Join(bake.DefaultFiles, ", ") + `
+
+When no target is specified, the "default" group is built.
+The targets are built concurrently with the same BuildKit daemon.`
+	var cmd = &cobra.Command{
+		Use:           "bake [flags] [TARGET...]",
+		Short:         shortHelp,
+		Long:          longHelp,
+		RunE:          bakeAction,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+
+	cmd.Flags().String("buildkit-host", "", "BuildKit address")
+	cmd.Flags().StringArrayP("file", "f", nil, "Build definition file")
+	cmd.Flags().Bool("print", false, "Print the resolved targets in JSON without building them")
+	cmd.Flags().Bool("no-cache", false, "Do not use cache when building the images")
+	cmd.Flags().Bool("pull", false, "Always attempt to pull all the referenced images")
+	cmd.Flags().String("progress", "auto", "Set type of progress output (auto, plain, tty, rawjson, quiet). \"auto\" is \"plain\" when building several targets")
+	cmd.Flags().Bool("no-color", false, "Produce monochrome output")
+	cmd.Flags().Int("parallel", 0, "Maximum number of targets built concurrently (default: the number of CPUs)")
+	return cmd
+}
+
+func bakeAction(cmd *cobra.Command, args []string) error {
+	globalOptions, err := helpers.ProcessRootCmdFlags(cmd)
+	if err != nil {
+		return err
+	}
+
+	buildkitHost, err := GetBuildkitHost(cmd, globalOptions.Namespace)
+	if err != nil {
+		return err
+	}
+
+	files, err := cmd.Flags().GetStringArray("file")
+	if err != nil {
+		return err
+	}
+
+	printOnly, err := cmd.Flags().GetBool("print")
+	if err != nil {
+		return err
+	}
+
+	noCache, err := cmd.Flags().GetBool("no-cache")
+	if err != nil {
+		return err
+	}
+
+	pull, err := cmd.Flags().GetBool("pull")
+	if err != nil {
+		return err
+	}
+
+	progress, err := cmd.Flags().GetString("progress")
+	if err != nil {
+		return err
+	}
+
+	noColor, err := cmd.Flags().GetBool("no-color")
+	if err != nil {
+		return err
+	}
+
+	parallel, err := cmd.Flags().GetInt("parallel")
+	if err != nil {
+		return err
+	}
+	if parallel < 0 {
+		return fmt.Errorf("invalid --parallel value %d", parallel)
+	}
+
+	nerdctlCmd, nerdctlArgs := helpers.GlobalFlags(cmd)
+	return builder.Bake(cmd.Context(), types.BuilderBakeOptions{
+		Stdout:       cmd.OutOrStdout(),
+		Stderr:       cmd.ErrOrStderr(),
+		GOptions:     globalOptions,
+		BuildKitHost: buildkitHost,
+		NerdctlCmd:   nerdctlCmd,
+		NerdctlArgs:  nerdctlArgs,
+		Files:        files,
+		Targets:      args,
+		Print:        printOnly,
+		NoCache:      noCache,
+		Pull:         pull,
+		Progress:     progress,
+		NoColor:      noColor,
+		Parallel:     parallel,
+	})
+}
+
+func historyCommand() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:           "history",
+		Short:         "Manage the history of builds",
+		RunE:          helpers.UnknownSubcommandAction,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	cmd.AddCommand(
+		historyListCommand(),
+		historyInspectCommand(),
+		historyRemoveCommand(),
+	)
+	return cmd
+}
+
+func historyListCommand() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:           "ls",
+		Aliases:       []string{"list"},
+		Args:          cobra.NoArgs,
+		Short:         "List the recorded builds",
+		RunE:          historyListAction,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	cmd.Flags().BoolP("quiet", "q", false, "Only display build IDs")
+	cmd.Flags().String("format", "", "Format the output using the given Go template, e.g, '{{json .}}'")
+	cmd.RegisterFlagCompletionFunc("format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"json", "table"}, cobra.ShellCompDirectiveNoFileComp
+	})
+	return cmd
+}
+
+func historyListAction(cmd *cobra.Command, _ []string) error {
+	globalOptions, err := helpers.ProcessRootCmdFlags(cmd)
+	if err != nil {
+		return err
+	}
+	quiet, err := cmd.Flags().GetBool("quiet")
+	if err != nil {
+		return err
+	}
+	format, err := cmd.Flags().GetString("format")
+	if err != nil {
+		return err
+	}
+	return builder.HistoryList(cmd.Context(), types.BuilderHistoryListOptions{
+		Stdout:   cmd.OutOrStdout(),
+		GOptions: globalOptions,
+		Quiet:    quiet,
+		Format:   format,
+	})
+}
+
+func historyInspectCommand() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:           "inspect [flags] BUILD [BUILD...]",
+		Args:          cobra.MinimumNArgs(1),
+		Short:         "Display detailed information on recorded builds",
+		RunE:          historyInspectAction,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	cmd.Flags().String("format", "", "Format the output using the given Go template, e.g, '{{json .}}'")
+	cmd.RegisterFlagCompletionFunc("format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"json"}, cobra.ShellCompDirectiveNoFileComp
+	})
+	cmd.Flags().String("export", "", "Write the timings of the build steps to the file in the Trace Event Format (chrome://tracing, Perfetto)")
+	return cmd
+}
+
+func historyInspectAction(cmd *cobra.Command, args []string) error {
+	globalOptions, err := helpers.ProcessRootCmdFlags(cmd)
+	if err != nil {
+		return err
+	}
+	format, err := cmd.Flags().GetString("format")
+	if err != nil {
+		return err
+	}
+	export, err := cmd.Flags().GetString("export")
+	if err != nil {
+		return err
+	}
+	return builder.HistoryInspect(cmd.Context(), args, types.BuilderHistoryInspectOptions{
+		Stdout:   cmd.OutOrStdout(),
+		GOptions: globalOptions,
+		Format:   format,
+		Export:   export,
+	})
+}
+
+func historyRemoveCommand() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:           "rm [flags] [BUILD...]",
+		Aliases:       []string{"remove"},
+		Short:         "Remove recorded builds from the history",
+		RunE:          historyRemoveAction,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	cmd.Flags().BoolP("all", "a", false, "Remove all the recorded builds")
+	return cmd
+}
+
+func historyRemoveAction(cmd *cobra.Command, args []string) error {
+	globalOptions, err := helpers.ProcessRootCmdFlags(cmd)
+	if err != nil {
+		return err
+	}
+	all, err := cmd.Flags().GetBool("all")
+	if err != nil {
+		return err
+	}
+	if !all && len(args) == 0 {
+		return errors.New("requires at least 1 argument, or --all")
+	}
+	return builder.HistoryRemove(cmd.Context(), args, types.BuilderHistoryRemoveOptions{
+		Stdout:   cmd.OutOrStdout(),
+		GOptions: globalOptions,
+		All:      all,
+	})
+}
+
 func debugCommand() *cobra.Command {
 	shortHelp := `Debug Dockerfile`
 	var cmd = &cobra.Command{
@@ -53,7 +53,7 @@ If Dockerfile is not present and -f is not specified, it will look for Container
 	cmd.Flags().StringArray("build-arg", nil, "Set build-time variables")
 	cmd.Flags().Bool("no-cache", false, "Do not use cache when building the image")
 	cmd.Flags().StringP("output", "o", "", "Output destination (format: type=local,dest=path)")
-	cmd.Flags().String("progress", "auto", "Set type of progress output (auto, plain, tty). Use plain to show container output")
+	cmd.Flags().String("progress", "auto", "Set type of progress output (auto, plain, tty, rawjson, quiet). Use plain to show container output")
 	cmd.Flags().String("provenance", "", "Shorthand for \"--attest=type=provenance\"")
 	cmd.Flags().Bool("pull", false, "On true, always attempt to pull latest image version from remote. Default uses buildkit's default.")
 	cmd.Flags().StringArray("secret", nil, "Secret file to expose to the build: id=mysecret,src=/local/secret")
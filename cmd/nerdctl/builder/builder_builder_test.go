@@ -17,6 +17,7 @@
 package builder
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"path/filepath"
@@ -28,6 +29,7 @@ import (
 	"github.com/containerd/nerdctl/mod/tigron/expect"
 	"github.com/containerd/nerdctl/mod/tigron/require"
 	"github.com/containerd/nerdctl/mod/tigron/test"
+	"github.com/containerd/nerdctl/mod/tigron/tig"
 
 	"github.com/containerd/nerdctl/v2/pkg/buildkitutil"
 	"github.com/containerd/nerdctl/v2/pkg/referenceutil"
@@ -69,6 +71,170 @@ CMD ["echo", "nerdctl-test-builder-prune"]`, testutil.CommonImage)
 				Command:  test.Command("builder", "prune", "--force", "--all"),
 				Expected: test.Expects(0, nil, nil),
 			},
+			{
+				Description: "PruneFilterKeepStorage",
+				NoParallel:  true,
+				Setup: func(data test.Data, helpers test.Helpers) {
+					dockerfile := fmt.Sprintf(`FROM %s
+CMD ["echo", "nerdctl-test-builder-prune"]`, testutil.CommonImage)
+					data.Temp().Save(dockerfile, "Dockerfile")
+					helpers.Ensure("build", data.Temp().Path())
+				},
+				Command:  test.Command("builder", "prune", "--force", "--filter", "until=1h", "--filter", "type=regular", "--keep-storage", "1GB"),
+				Expected: test.Expects(0, nil, nil),
+			},
+			{
+				Description: "DiskUsage",
+				NoParallel:  true,
+				Require:     require.Not(nerdtest.Docker),
+				Setup: func(data test.Data, helpers test.Helpers) {
+					dockerfile := fmt.Sprintf(`FROM %s
+CMD ["echo", "nerdctl-test-builder-du"]`, testutil.CommonImage)
+					data.Temp().Save(dockerfile, "Dockerfile")
+					helpers.Ensure("build", data.Temp().Path())
+				},
+				Command:  test.Command("builder", "du", "--verbose"),
+				Expected: test.Expects(0, nil, expect.Contains("Reclaimable:", "Total:")),
+			},
+			{
+				Description: "DiskUsageJSON",
+				NoParallel:  true,
+				Require:     require.Not(nerdtest.Docker),
+				Setup: func(data test.Data, helpers test.Helpers) {
+					dockerfile := fmt.Sprintf(`FROM %s
+RUN echo nerdctl-test-builder-du > /du`, testutil.CommonImage)
+					data.Temp().Save(dockerfile, "Dockerfile")
+					helpers.Ensure("build", data.Temp().Path())
+				},
+				Command: test.Command("builder", "du", "--filter", "type=regular", "--format", "json"),
+				Expected: test.Expects(0, nil, func(stdout string, t tig.T) {
+					lines := strings.Split(strings.TrimSpace(stdout), "\n")
+					assert.Assert(t, len(lines) > 0 && lines[0] != "", "no build cache record")
+					var size int64
+					for _, line := range lines {
+						var record struct {
+							ID      string
+							RawSize int64
+							Type    string
+						}
+						assert.NilError(t, json.Unmarshal([]byte(line), &record), line)
+						assert.Assert(t, record.ID != "", line)
+						assert.Equal(t, record.Type, "regular", line)
+						size += record.RawSize
+					}
+					assert.Assert(t, size > 0, "the build cache records have no size")
+				}),
+			},
+			{
+				Description: "Bake",
+				NoParallel:  true,
+				Require:     require.Not(nerdtest.Docker),
+				Setup: func(data test.Data, helpers test.Helpers) {
+					dockerfile := fmt.Sprintf(`FROM %s
+ARG MESSAGE
+RUN echo "$MESSAGE" > /message`, testutil.CommonImage)
+					data.Temp().Save(dockerfile, "Dockerfile")
+					bakefile := fmt.Sprintf(`
+variable "MESSAGE" {
+  default = "nerdctl-test-builder-bake"
+}
+
+group "default" {
+  targets = ["foo", "bar"]
+}
+
+target "base" {
+  context = %q
+  args = {
+    MESSAGE = "${MESSAGE}"
+  }
+}
+
+target "foo" {
+  inherits = ["base"]
+  tags = ["%s"]
+}
+
+target "bar" {
+  inherits = ["base"]
+  tags = ["%s"]
+}
+`, data.Temp().Path(), data.Identifier("foo"), data.Identifier("bar"))
+					data.Temp().Save(bakefile, "docker-bake.hcl")
+				},
+				Cleanup: func(data test.Data, helpers test.Helpers) {
+					helpers.Anyhow("rmi", "-f", data.Identifier("foo"), data.Identifier("bar"))
+				},
+				Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+					return helpers.Command("builder", "bake", "-f", data.Temp().Path("docker-bake.hcl"))
+				},
+				Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+					return &test.Expected{
+						ExitCode: 0,
+						Output: func(stdout string, t tig.T) {
+							for _, name := range []string{"foo", "bar"} {
+								helpers.Command("run", "--rm", data.Identifier(name), "cat", "/message").
+									Run(&test.Expected{Output: expect.Equals("nerdctl-test-builder-bake\n")})
+							}
+						},
+					}
+				},
+			},
+			{
+				Description: "BakePrint",
+				Require:     require.Not(nerdtest.Docker),
+				Setup: func(data test.Data, helpers test.Helpers) {
+					data.Temp().Save(`
+target "app" {
+  name = "app-${replace(platform, "/", "-")}"
+  matrix = {
+    platform = ["linux/amd64", "linux/arm64"]
+  }
+  platforms = [platform]
+}
+`, "docker-bake.hcl")
+				},
+				Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+					return helpers.Command("builder", "bake", "--print", "-f", data.Temp().Path("docker-bake.hcl"), "app")
+				},
+				Expected: test.Expects(0, nil, expect.Contains(`"app-linux-amd64"`, `"app-linux-arm64"`)),
+			},
+			{
+				Description: "History",
+				NoParallel:  true,
+				Require:     require.Not(nerdtest.Docker),
+				Setup: func(data test.Data, helpers test.Helpers) {
+					dockerfile := fmt.Sprintf(`FROM %s
+CMD ["echo", "nerdctl-test-builder-history"]`, testutil.CommonImage)
+					data.Temp().Save(dockerfile, "Dockerfile")
+					helpers.Ensure("build", "-t", data.Identifier(), data.Temp().Path())
+					ref := helpers.Capture("image", "inspect", "--mode=native", "--format", `{{index .Image.Labels "nerdctl/build-ref"}}`, data.Identifier())
+					data.Labels().Set("ref", strings.TrimSpace(ref))
+				},
+				Cleanup: func(data test.Data, helpers test.Helpers) {
+					helpers.Anyhow("rmi", "-f", data.Identifier())
+					if ref := data.Labels().Get("ref"); ref != "" {
+						helpers.Anyhow("builder", "history", "rm", ref)
+					}
+				},
+				Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+					return helpers.Command("builder", "history", "inspect", "--format", "{{.Ref}} {{index .Tags 0}} {{.Error}}", data.Labels().Get("ref"))
+				},
+				Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+					return &test.Expected{
+						ExitCode: 0,
+						Output: func(stdout string, t tig.T) {
+							ref := data.Labels().Get("ref")
+							assert.Assert(t, ref != "")
+							assert.Equal(t, strings.TrimSpace(stdout), ref+" docker.io/library/"+data.Identifier()+":latest")
+							helpers.Command("builder", "history", "ls", "-q").Run(&test.Expected{Output: expect.Contains(ref)})
+							trace := data.Temp().Path("trace.json")
+							helpers.Ensure("builder", "history", "inspect", "--export", trace, ref)
+							data.Temp().Exists("trace.json")
+						},
+					}
+				},
+			},
 			{
 				Description: "builder with buildkit-host",
 				NoParallel:  true,
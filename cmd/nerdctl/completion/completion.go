@@ -18,6 +18,7 @@ package completion
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -157,6 +158,31 @@ func VolumeNames(cmd *cobra.Command) ([]string, cobra.ShellCompDirective) {
 	return candidates, cobra.ShellCompDirectiveNoFileComp
 }
 
+func PodNames(cmd *cobra.Command) ([]string, cobra.ShellCompDirective) {
+	globalOptions, err := helpers.ProcessRootCmdFlags(cmd)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	client, ctx, cancel, err := clientutil.NewClient(cmd.Context(), globalOptions.Namespace, globalOptions.Address)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	defer cancel()
+	infras, err := client.Containers(ctx, fmt.Sprintf("labels.%q==true", labels.PodInfra))
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	candidates := []string{}
+	for _, c := range infras {
+		lab, err := c.Labels(ctx)
+		if err != nil {
+			continue
+		}
+		candidates = append(candidates, lab[labels.Pod])
+	}
+	return candidates, cobra.ShellCompDirectiveNoFileComp
+}
+
 func Platforms(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 	candidates := []string{
 		"amd64",
@@ -36,6 +36,8 @@ func Command() *cobra.Command {
 		RunCommand(),
 		UpdateCommand(),
 		ExecCommand(),
+		execListCommand(),
+		execInspectCommand(),
 		listCommand(),
 		inspectCommand(),
 		LogsCommand(),
@@ -51,6 +53,8 @@ func Command() *cobra.Command {
 		UnpauseCommand(),
 		CommitCommand(),
 		RenameCommand(),
+		CloneCommand(),
+		RecreateCommand(),
 		pruneCommand(),
 		StatsCommand(),
 		AttachCommand(),
@@ -0,0 +1,134 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/completion"
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/container"
+)
+
+func CloneCommand() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:               "clone [flags] CONTAINER [NEW_NAME]",
+		Args:              cobra.RangeArgs(1, 2),
+		Short:             "Create a new container from the configuration of an existing one",
+		RunE:              cloneAction,
+		ValidArgsFunction: cloneShellComplete,
+		SilenceUsage:      true,
+		SilenceErrors:     true,
+	}
+	cmd.Flags().String("image", "", "Image to use instead of the one of the source container")
+	cmd.RegisterFlagCompletionFunc("image", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return completion.ImageNames(cmd)
+	})
+	cmd.Flags().StringArrayP("env", "e", nil, "Set additional environment variables")
+	cmd.Flags().StringArrayP("label", "l", nil, "Set additional meta data on the container")
+	cmd.Flags().StringArrayP("publish", "p", nil, "Publish container's port(s) to the host, replacing the ones of the source container")
+	cmd.Flags().Float64("cpus", 0.0, "Number of CPUs")
+	cmd.Flags().StringP("memory", "m", "", "Memory limit")
+	cmd.Flags().String("restart", "", `Restart policy to apply when a container exits (implemented values: "no"|"always|on-failure:n|unless-stopped")`)
+	cmd.Flags().Bool("start", false, "Start the new container")
+	cmd.Flags().Bool("destroy", false, "Remove the source container")
+	return cmd
+}
+
+func cloneOptions(cmd *cobra.Command) (types.ContainerCloneOptions, error) {
+	globalOptions, err := helpers.ProcessRootCmdFlags(cmd)
+	if err != nil {
+		return types.ContainerCloneOptions{}, err
+	}
+	image, err := cmd.Flags().GetString("image")
+	if err != nil {
+		return types.ContainerCloneOptions{}, err
+	}
+	env, err := cmd.Flags().GetStringArray("env")
+	if err != nil {
+		return types.ContainerCloneOptions{}, err
+	}
+	label, err := cmd.Flags().GetStringArray("label")
+	if err != nil {
+		return types.ContainerCloneOptions{}, err
+	}
+	publish, err := cmd.Flags().GetStringArray("publish")
+	if err != nil {
+		return types.ContainerCloneOptions{}, err
+	}
+	cpus, err := cmd.Flags().GetFloat64("cpus")
+	if err != nil {
+		return types.ContainerCloneOptions{}, err
+	}
+	memory, err := cmd.Flags().GetString("memory")
+	if err != nil {
+		return types.ContainerCloneOptions{}, err
+	}
+	restart, err := cmd.Flags().GetString("restart")
+	if err != nil {
+		return types.ContainerCloneOptions{}, err
+	}
+	start, err := cmd.Flags().GetBool("start")
+	if err != nil {
+		return types.ContainerCloneOptions{}, err
+	}
+	destroy, err := cmd.Flags().GetBool("destroy")
+	if err != nil {
+		return types.ContainerCloneOptions{}, err
+	}
+	return types.ContainerCloneOptions{
+		Stdout:   cmd.OutOrStdout(),
+		Stderr:   cmd.ErrOrStderr(),
+		GOptions: globalOptions,
+		Image:    image,
+		Env:      env,
+		Label:    label,
+		Publish:  publish,
+		CPUs:     cpus,
+		Memory:   memory,
+		Restart:  restart,
+		Start:    start,
+		Destroy:  destroy,
+	}, nil
+}
+
+func cloneAction(cmd *cobra.Command, args []string) error {
+	options, err := cloneOptions(cmd)
+	if err != nil {
+		return err
+	}
+	client, ctx, cancel, err := clientutil.NewClient(cmd.Context(), options.GOptions.Namespace, options.GOptions.Address)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	var newName string
+	if len(args) > 1 {
+		newName = args[1]
+	}
+	return container.Clone(ctx, client, args[0], newName, options)
+}
+
+func cloneShellComplete(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return completion.ContainerNames(cmd, nil)
+}
@@ -0,0 +1,127 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/containerd/nerdctl/mod/tigron/expect"
+	"github.com/containerd/nerdctl/mod/tigron/require"
+	"github.com/containerd/nerdctl/mod/tigron/test"
+
+	"github.com/containerd/nerdctl/v2/pkg/testutil"
+	"github.com/containerd/nerdctl/v2/pkg/testutil/nerdtest"
+)
+
+func TestClone(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Require = require.Not(nerdtest.Docker)
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		helpers.Ensure("run", "-d", "--name", data.Identifier("src"), "-e", "FOO=foo",
+			testutil.CommonImage, "sleep", nerdtest.Infinity)
+		helpers.Ensure("container", "clone", "--env", "BAR=bar", "--start",
+			data.Identifier("src"), data.Identifier("clone"))
+	}
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("rm", "-f", data.Identifier("src"), data.Identifier("clone"))
+	}
+
+	testCase.Command = func(data test.Data, helpers test.Helpers) test.TestableCommand {
+		return helpers.Command("exec", data.Identifier("clone"), "env")
+	}
+
+	testCase.Expected = test.Expects(expect.ExitCodeSuccess, nil, expect.Contains("FOO=foo", "BAR=bar"))
+
+	testCase.Run(t)
+}
+
+func TestRecreate(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Require = require.Not(nerdtest.Docker)
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		helpers.Ensure("run", "-d", "--name", data.Identifier(), "-v", "/data",
+			testutil.CommonImage, "sleep", nerdtest.Infinity)
+		helpers.Ensure("exec", data.Identifier(), "sh", "-c", "echo recreated > /data/file")
+		data.Labels().Set("name", data.Identifier())
+		data.Labels().Set("id", strings.TrimSpace(helpers.Capture("inspect", "--format", "{{.Id}}", data.Identifier())))
+		helpers.Ensure("container", "recreate", data.Identifier())
+	}
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("rm", "-f", "-v", data.Identifier())
+	}
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "the anonymous volume is reattached",
+			NoParallel:  true,
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("exec", data.Labels().Get("name"), "cat", "/data/file")
+			},
+			Expected: test.Expects(expect.ExitCodeSuccess, nil, expect.Equals("recreated\n")),
+		},
+		{
+			Description: "the container has a new ID",
+			NoParallel:  true,
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("inspect", "--format", "{{.Id}}", data.Labels().Get("name"))
+			},
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					Output: expect.DoesNotContain(data.Labels().Get("id")),
+				}
+			},
+		},
+	}
+
+	testCase.Run(t)
+}
+
+func TestRecreateWithCIDFile(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Require = require.Not(nerdtest.Docker)
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		helpers.Ensure("run", "-d", "--name", data.Identifier(), "--cidfile", data.Temp().Path("cid"),
+			testutil.CommonImage, "sleep", nerdtest.Infinity)
+		helpers.Ensure("container", "recreate", data.Identifier())
+		data.Labels().Set("id", strings.TrimSpace(helpers.Capture("inspect", "--format", "{{.Id}}", data.Identifier())))
+	}
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("rm", "-f", data.Identifier())
+	}
+
+	testCase.Command = func(data test.Data, helpers test.Helpers) test.TestableCommand {
+		return helpers.Custom("cat", data.Temp().Path("cid"))
+	}
+
+	testCase.Expected = func(data test.Data, helpers test.Helpers) *test.Expected {
+		return &test.Expected{
+			Output: expect.Equals(data.Labels().Get("id")),
+		}
+	}
+
+	testCase.Run(t)
+}
@@ -0,0 +1,156 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/nerdctl/mod/tigron/expect"
+	"github.com/containerd/nerdctl/mod/tigron/require"
+	"github.com/containerd/nerdctl/mod/tigron/test"
+	"github.com/containerd/nerdctl/mod/tigron/tig"
+
+	"github.com/containerd/nerdctl/v2/pkg/testutil"
+	"github.com/containerd/nerdctl/v2/pkg/testutil/nerdtest"
+)
+
+func TestCopyStream(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		helpers.Ensure("run", "-d", "--name", data.Identifier(), testutil.CommonImage, "sleep", nerdtest.Infinity)
+		helpers.Ensure("exec", data.Identifier(), "sh", "-euc", "mkdir -p /src/dir && echo -n from-container >/src/dir/file")
+	}
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("rm", "-f", data.Identifier())
+	}
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "tar archive read from stdin",
+			NoParallel:  true,
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				var b bytes.Buffer
+				tw := tar.NewWriter(&b)
+				assert.NilError(helpers.T(), tw.WriteHeader(&tar.Header{Name: "stdin/", Typeflag: tar.TypeDir, Mode: 0o755}))
+				assert.NilError(helpers.T(), tw.WriteHeader(&tar.Header{Name: "stdin/file", Typeflag: tar.TypeReg, Mode: 0o644, Size: 10}))
+				_, err := tw.Write([]byte("from-stdin"))
+				assert.NilError(helpers.T(), err)
+				assert.NilError(helpers.T(), tw.Close())
+
+				cmd := helpers.Command("cp", "-", data.Identifier()+":/tmp")
+				cmd.Feed(&b)
+				return cmd
+			},
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					Output: func(stdout string, t tig.T) {
+						out := helpers.Capture("exec", data.Identifier(), "cat", "/tmp/stdin/file")
+						assert.Equal(t, out, "from-stdin")
+					},
+				}
+			},
+		},
+		{
+			Description: "tar archive read from stdin requires an existing directory",
+			NoParallel:  true,
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				cmd := helpers.Command("cp", "-", data.Identifier()+":/src/dir/file")
+				cmd.Feed(strings.NewReader(""))
+				return cmd
+			},
+			Expected: test.Expects(1, []error{errors.New("destination is not a directory")}, nil),
+		},
+		{
+			Description: "tar archive written to stdout",
+			NoParallel:  true,
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("cp", data.Identifier()+":/src/dir", "-")
+			},
+			Expected: test.Expects(0, nil, func(stdout string, t tig.T) {
+				files := map[string]string{}
+				tr := tar.NewReader(strings.NewReader(stdout))
+				for {
+					hdr, err := tr.Next()
+					if errors.Is(err, io.EOF) {
+						break
+					}
+					assert.NilError(t, err)
+					content, err := io.ReadAll(tr)
+					assert.NilError(t, err)
+					files[hdr.Name] = string(content)
+				}
+				assert.DeepEqual(t, files, map[string]string{"dir/": "", "dir/file": "from-container"})
+			}),
+		},
+	}
+
+	testCase.Run(t)
+}
+
+func TestCopyArchive(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	// Changing the owner of the source file requires privileges
+	testCase.Require = require.Not(nerdtest.Rootless)
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		helpers.Ensure("run", "-d", "--name", data.Identifier(), testutil.CommonImage, "sleep", nerdtest.Infinity)
+		src := data.Temp().Save("content", "src")
+		assert.NilError(helpers.T(), os.Chown(src, 1234, 5678))
+	}
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("rm", "-f", data.Identifier())
+	}
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "files are owned by the user of the container by default",
+			NoParallel:  true,
+			Setup: func(data test.Data, helpers test.Helpers) {
+				helpers.Ensure("cp", data.Temp().Path("src"), data.Identifier()+":/default")
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("exec", data.Identifier(), "stat", "-c", "%u:%g", "/default")
+			},
+			Expected: test.Expects(0, nil, expect.Equals("0:0\n")),
+		},
+		{
+			Description: "archive mode preserves uid/gid",
+			NoParallel:  true,
+			Setup: func(data test.Data, helpers test.Helpers) {
+				helpers.Ensure("cp", "-a", data.Temp().Path("src"), data.Identifier()+":/archived")
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("exec", data.Identifier(), "stat", "-c", "%u:%g", "/archived")
+			},
+			Expected: test.Expects(0, nil, expect.Equals("1234:5678\n")),
+		},
+	}
+
+	testCase.Run(t)
+}
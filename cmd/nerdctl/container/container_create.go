@@ -17,12 +17,18 @@
 package container
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"runtime"
+	"slices"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	cdiparser "tags.cncf.io/container-device-interface/pkg/parser"
 
+	"github.com/containerd/errdefs"
+
 	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/clientutil"
@@ -89,6 +95,10 @@ func createOptions(cmd *cobra.Command) (types.ContainerCreateOptions, error) {
 	if err != nil {
 		return opt, err
 	}
+	opt.Pod, err = cmd.Flags().GetString("pod")
+	if err != nil {
+		return opt, err
+	}
 	opt.Pull, err = cmd.Flags().GetString("pull")
 	if err != nil {
 		return opt, err
@@ -525,28 +535,56 @@ func createOptions(cmd *cobra.Command) (types.ContainerCreateOptions, error) {
 }
 
 func createAction(cmd *cobra.Command, args []string) error {
-	createOpt, err := createOptions(cmd)
+	id, err := createContainer(cmd, args)
 	if err != nil {
 		return err
 	}
+	fmt.Fprintln(cmd.OutOrStdout(), id)
+	return nil
+}
+
+// CreateFromArgs creates a container from the flags and the arguments of `nerdctl create`, and returns its ID.
+// The global flags are parsed with the persistent flags of the root command.
+func CreateFromArgs(ctx context.Context, root *cobra.Command, args []string) (string, error) {
+	cmd := CreateCommand()
+	cmd.Flags().AddFlagSet(root.PersistentFlags())
+	cmd.SetContext(ctx)
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	if err := cmd.ParseFlags(args); err != nil {
+		return "", fmt.Errorf("%w: %w", err, errdefs.ErrInvalidArgument)
+	}
+	return createContainer(cmd, cmd.Flags().Args())
+}
+
+func createContainer(cmd *cobra.Command, args []string) (string, error) {
+	createOpt, err := createOptions(cmd)
+	if err != nil {
+		return "", err
+	}
+	createOpt.CreateArgs = createArgs(cmd, args)
 
 	if (createOpt.Platform == "windows" || createOpt.Platform == "freebsd") && !createOpt.GOptions.Experimental {
-		return fmt.Errorf("%s requires experimental mode to be enabled", createOpt.Platform)
+		return "", fmt.Errorf("%s requires experimental mode to be enabled", createOpt.Platform)
 	}
 	client, ctx, cancel, err := clientutil.NewClientWithPlatform(cmd.Context(), createOpt.GOptions.Namespace, createOpt.GOptions.Address, createOpt.Platform)
 	if err != nil {
-		return err
+		return "", err
 	}
 	defer cancel()
 
 	netFlags, err := loadNetworkFlags(cmd, createOpt.GOptions)
 	if err != nil {
-		return fmt.Errorf("failed to load networking flags: %w", err)
+		return "", fmt.Errorf("failed to load networking flags: %w", err)
+	}
+
+	if err := joinPod(ctx, cmd, client, &createOpt, &netFlags); err != nil {
+		return "", err
 	}
 
 	netManager, err := containerutil.NewNetworkingOptionsManager(createOpt.GOptions, netFlags, client)
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	c, gc, err := container.Create(ctx, client, args, netManager, createOpt)
@@ -554,7 +592,7 @@ func createAction(cmd *cobra.Command, args []string) error {
 		if gc != nil {
 			gc()
 		}
-		return err
+		return "", err
 	}
 	// defer setting `nerdctl/error` label in case of error
 	defer func() {
@@ -563,6 +601,28 @@ func createAction(cmd *cobra.Command, args []string) error {
 		}
 	}()
 
-	fmt.Fprintln(createOpt.Stdout, c.ID())
-	return nil
+	return c.ID(), nil
+}
+
+// createArgsSkippedFlags are the flags that are not recorded in the create args of a container,
+// as they are only meaningful for the command creating the container.
+var createArgsSkippedFlags = []string{"help", "name", "detach", "rm", "attach", "interactive", "sig-proxy", "detach-keys", "cidfile", "quiet"}
+
+// createArgs returns the flags and the arguments of the create or run command, without the global flags.
+// The flags are in the "--name=value" form, with one flag per value of the slice flags.
+func createArgs(cmd *cobra.Command, args []string) []string {
+	var res []string
+	cmd.LocalNonPersistentFlags().VisitAll(func(f *pflag.Flag) {
+		if !f.Changed || slices.Contains(createArgsSkippedFlags, f.Name) {
+			return
+		}
+		if sv, ok := f.Value.(pflag.SliceValue); ok {
+			for _, v := range sv.GetSlice() {
+				res = append(res, "--"+f.Name+"="+v)
+			}
+			return
+		}
+		res = append(res, "--"+f.Name+"="+f.Value.String())
+	})
+	return append(res, args...)
 }
@@ -17,30 +17,13 @@
 package container
 
 import (
-	"context"
-	"fmt"
-	"os"
-	"path/filepath"
-	"time"
-
-	"github.com/opencontainers/image-spec/identity"
 	"github.com/spf13/cobra"
 
-	containerd "github.com/containerd/containerd/v2/client"
-	"github.com/containerd/containerd/v2/core/leases"
-	"github.com/containerd/containerd/v2/core/mount"
-	"github.com/containerd/continuity/fs"
-	"github.com/containerd/log"
-	"github.com/containerd/platforms"
-
 	"github.com/containerd/nerdctl/v2/cmd/nerdctl/completion"
 	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/clientutil"
-	"github.com/containerd/nerdctl/v2/pkg/idgen"
-	"github.com/containerd/nerdctl/v2/pkg/idutil/containerwalker"
-	"github.com/containerd/nerdctl/v2/pkg/imgutil"
-	"github.com/containerd/nerdctl/v2/pkg/labels"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/container"
 )
 
 func DiffCommand() *cobra.Command {
@@ -53,6 +36,12 @@ func DiffCommand() *cobra.Command {
 		SilenceUsage:      true,
 		SilenceErrors:     true,
 	}
+	cmd.Flags().StringArray("filter", nil, "Only show the paths matching a glob pattern (e.g., '/var/log/*'), or their content")
+	cmd.Flags().StringArray("exclude", nil, "Do not show the paths matching a glob pattern (e.g., '/tmp'), or their content")
+	cmd.Flags().String("format", "", "Format the output using the given Go template, e.g, '{{json .}}'")
+	cmd.RegisterFlagCompletionFunc("format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"json"}, cobra.ShellCompDirectiveNoFileComp
+	})
 	return cmd
 }
 
@@ -61,10 +50,25 @@ func diffOptions(cmd *cobra.Command) (types.ContainerDiffOptions, error) {
 	if err != nil {
 		return types.ContainerDiffOptions{}, err
 	}
+	filter, err := cmd.Flags().GetStringArray("filter")
+	if err != nil {
+		return types.ContainerDiffOptions{}, err
+	}
+	exclude, err := cmd.Flags().GetStringArray("exclude")
+	if err != nil {
+		return types.ContainerDiffOptions{}, err
+	}
+	format, err := cmd.Flags().GetString("format")
+	if err != nil {
+		return types.ContainerDiffOptions{}, err
+	}
 
 	return types.ContainerDiffOptions{
 		Stdout:   cmd.OutOrStdout(),
 		GOptions: globalOptions,
+		Filter:   filter,
+		Exclude:  exclude,
+		Format:   format,
 	}, nil
 }
 
@@ -80,149 +84,7 @@ func diffAction(cmd *cobra.Command, args []string) error {
 	}
 	defer cancel()
 
-	walker := &containerwalker.ContainerWalker{
-		Client: client,
-		OnFound: func(ctx context.Context, found containerwalker.Found) error {
-			if found.MatchCount > 1 {
-				return fmt.Errorf("multiple IDs found with provided prefix: %s", found.Req)
-			}
-			changes, err := getChanges(ctx, client, found.Container)
-			if err != nil {
-				return err
-			}
-
-			for _, change := range changes {
-				switch change.Kind {
-				case fs.ChangeKindAdd:
-					fmt.Fprintln(options.Stdout, "A", change.Path)
-				case fs.ChangeKindModify:
-					fmt.Fprintln(options.Stdout, "C", change.Path)
-				case fs.ChangeKindDelete:
-					fmt.Fprintln(options.Stdout, "D", change.Path)
-				default:
-				}
-			}
-
-			return nil
-		},
-	}
-
-	container := args[0]
-
-	n, err := walker.Walk(ctx, container)
-	if err != nil {
-		return err
-	} else if n == 0 {
-		return fmt.Errorf("no such container %s", container)
-	}
-	return nil
-}
-
-func getChanges(ctx context.Context, client *containerd.Client, container containerd.Container) ([]fs.Change, error) {
-	id := container.ID()
-	info, err := container.Info(ctx)
-	if err != nil {
-		return nil, err
-	}
-
-	var (
-		snName = info.Snapshotter
-		sn     = client.SnapshotService(snName)
-	)
-
-	mounts, err := sn.Mounts(ctx, id)
-	if err != nil {
-		return nil, err
-	}
-
-	// NOTE: Moby uses provided rootfs to run container. It doesn't support
-	// to commit container created by moby.
-	baseImgWithoutPlatform, err := client.ImageService().Get(ctx, info.Image)
-	if err != nil {
-		return nil, fmt.Errorf("container %q lacks image (wasn't created by nerdctl?): %w", id, err)
-	}
-	platformLabel := info.Labels[labels.Platform]
-	if platformLabel == "" {
-		platformLabel = platforms.DefaultString()
-		log.G(ctx).Warnf("Image lacks label %q, assuming the platform to be %q", labels.Platform, platformLabel)
-	}
-	ocispecPlatform, err := platforms.Parse(platformLabel)
-	if err != nil {
-		return nil, err
-	}
-	log.G(ctx).Debugf("ocispecPlatform=%q", platforms.Format(ocispecPlatform))
-	platformMC := platforms.Only(ocispecPlatform)
-	baseImg := containerd.NewImageWithPlatform(client, baseImgWithoutPlatform, platformMC)
-
-	baseImgConfig, _, err := imgutil.ReadImageConfig(ctx, baseImg)
-	if err != nil {
-		return nil, err
-	}
-
-	// Don't gc me and clean the dirty data after 1 hour!
-	ctx, done, err := client.WithLease(ctx, leases.WithRandomID(), leases.WithExpiration(1*time.Hour))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create lease for diff: %w", err)
-	}
-	defer done(ctx)
-
-	rootfsID := identity.ChainID(baseImgConfig.RootFS.DiffIDs).String()
-
-	randomID := idgen.GenerateID()
-	parent, err := sn.View(ctx, randomID, rootfsID)
-	if err != nil {
-		return nil, err
-	}
-	defer sn.Remove(ctx, randomID)
-
-	var changes []fs.Change
-	err = mount.WithReadonlyTempMount(ctx, parent, func(lower string) error {
-		return mount.WithReadonlyTempMount(ctx, mounts, func(upper string) error {
-			return fs.Changes(ctx, lower, upper, func(ck fs.ChangeKind, s string, fi os.FileInfo, err error) error {
-				if err != nil {
-					return err
-				}
-				changes = appendChanges(changes, fs.Change{
-					Kind: ck,
-					Path: s,
-				})
-				return nil
-			})
-		})
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	return changes, err
-}
-
-func appendChanges(changes []fs.Change, fsChange fs.Change) []fs.Change {
-	newDir, _ := filepath.Split(fsChange.Path)
-	newDirPath := filepath.SplitList(newDir)
-
-	if len(changes) == 0 {
-		for i := 1; i < len(newDirPath); i++ {
-			changes = append(changes, fs.Change{
-				Kind: fs.ChangeKindModify,
-				Path: filepath.Join(newDirPath[:i+1]...),
-			})
-		}
-		return append(changes, fsChange)
-	}
-	last := changes[len(changes)-1]
-	lastDir, _ := filepath.Split(last.Path)
-	lastDirPath := filepath.SplitList(lastDir)
-	for i := range newDirPath {
-		if len(lastDirPath) > i && lastDirPath[i] == newDirPath[i] {
-			continue
-		}
-		changes = append(changes, fs.Change{
-			Kind: fs.ChangeKindModify,
-			Path: filepath.Join(newDirPath[:i+1]...),
-		})
-	}
-	return append(changes, fsChange)
+	return container.Diff(ctx, client, args[0], options)
 }
 
 func diffShellComplete(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
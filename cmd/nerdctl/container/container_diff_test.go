@@ -17,12 +17,18 @@
 package container
 
 import (
+	"encoding/json"
+	"strings"
 	"testing"
 
+	"gotest.tools/v3/assert"
+
 	"github.com/containerd/nerdctl/mod/tigron/expect"
 	"github.com/containerd/nerdctl/mod/tigron/require"
 	"github.com/containerd/nerdctl/mod/tigron/test"
+	"github.com/containerd/nerdctl/mod/tigron/tig"
 
+	"github.com/containerd/nerdctl/v2/pkg/cmd/container"
 	"github.com/containerd/nerdctl/v2/pkg/testutil"
 	"github.com/containerd/nerdctl/v2/pkg/testutil/nerdtest"
 )
@@ -63,3 +69,55 @@ func TestDiff(t *testing.T) {
 
 	testCase.Run(t)
 }
+
+func TestDiffFilter(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Require = require.All(
+		require.Not(require.Windows),
+		require.Not(nerdtest.Docker),
+	)
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		helpers.Ensure("run", "--name", data.Identifier(), testutil.CommonImage,
+			"sh", "-euxc", "mkdir -p /var/log/app /tmp/cache; echo -n hello >/var/log/app/out.log; touch /var/log/app/out.txt /tmp/cache/a")
+	}
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("rm", "-f", data.Identifier())
+	}
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "filter",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("diff", "--filter", "/var/log/*/*.log", data.Identifier())
+			},
+			Expected: test.Expects(0, nil, expect.Equals("C /var\nC /var/log\nA /var/log/app\nA /var/log/app/out.log\n")),
+		},
+		{
+			Description: "exclude",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("diff", "--exclude", "/var", "--exclude", "/tmp/*", data.Identifier())
+			},
+			Expected: test.Expects(0, nil, func(stdout string, t tig.T) {
+				assert.Assert(t, !strings.Contains(stdout, "/var"), stdout)
+				assert.Assert(t, !strings.Contains(stdout, "/tmp/cache"), stdout)
+			}),
+		},
+		{
+			Description: "json format",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("diff", "--filter", "/var/log/app/out.log", "--format", "json", data.Identifier())
+			},
+			Expected: test.Expects(0, nil, func(stdout string, t tig.T) {
+				lines := strings.Split(strings.TrimSpace(stdout), "\n")
+				var change container.DiffChange
+				assert.NilError(t, json.Unmarshal([]byte(lines[len(lines)-1]), &change))
+				assert.DeepEqual(t, change, container.DiffChange{Kind: "A", Path: "/var/log/app/out.log", Size: 5, Mode: "-rw-r--r--"})
+			}),
+		},
+	}
+
+	testCase.Run(t)
+}
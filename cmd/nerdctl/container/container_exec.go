@@ -28,6 +28,7 @@ import (
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/clientutil"
 	"github.com/containerd/nerdctl/v2/pkg/cmd/container"
+	"github.com/containerd/nerdctl/v2/pkg/consoleutil"
 )
 
 func ExecCommand() *cobra.Command {
@@ -52,6 +53,11 @@ func ExecCommand() *cobra.Command {
 	cmd.Flags().StringSlice("env-file", nil, "Set environment variables from file")
 	cmd.Flags().Bool("privileged", false, "Give extended privileges to the command")
 	cmd.Flags().StringP("user", "u", "", "Username or UID (format: <name|uid>[:<group|gid>])")
+	cmd.Flags().String("detach-keys", consoleutil.DefaultDetachKeys, "Override the default detach keys")
+	cmd.Flags().StringSlice("cap-add", nil, "Add Linux capabilities to the command")
+	cmd.RegisterFlagCompletionFunc("cap-add", capShellComplete)
+	cmd.Flags().StringSlice("cap-drop", nil, "Drop Linux capabilities from the command")
+	cmd.RegisterFlagCompletionFunc("cap-drop", capShellComplete)
 	return cmd
 }
 
@@ -108,6 +114,18 @@ func execOptions(cmd *cobra.Command) (types.ContainerExecOptions, error) {
 	if err != nil {
 		return types.ContainerExecOptions{}, err
 	}
+	detachKeys, err := cmd.Flags().GetString("detach-keys")
+	if err != nil {
+		return types.ContainerExecOptions{}, err
+	}
+	capAdd, err := cmd.Flags().GetStringSlice("cap-add")
+	if err != nil {
+		return types.ContainerExecOptions{}, err
+	}
+	capDrop, err := cmd.Flags().GetStringSlice("cap-drop")
+	if err != nil {
+		return types.ContainerExecOptions{}, err
+	}
 
 	return types.ContainerExecOptions{
 		GOptions:    globalOptions,
@@ -119,6 +137,9 @@ func execOptions(cmd *cobra.Command) (types.ContainerExecOptions, error) {
 		EnvFile:     envFile,
 		Privileged:  privileged,
 		User:        user,
+		DetachKeys:  detachKeys,
+		CapAdd:      capAdd,
+		CapDrop:     capDrop,
 	}, nil
 }
 
@@ -17,9 +17,11 @@
 package container
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/containerd/nerdctl/mod/tigron/expect"
+	"github.com/containerd/nerdctl/mod/tigron/require"
 	"github.com/containerd/nerdctl/mod/tigron/test"
 
 	"github.com/containerd/nerdctl/v2/pkg/testutil"
@@ -112,3 +114,74 @@ func TestExecTTY(t *testing.T) {
 
 	testCase.Run(t)
 }
+
+func TestExecCapabilities(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		helpers.Ensure("run", "-d", "--name", data.Identifier(), testutil.CommonImage, "sleep", nerdtest.Infinity)
+	}
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("rm", "-f", data.Identifier())
+	}
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "cap-drop ALL",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("exec", "--cap-drop=ALL", data.Identifier(), "grep", "^CapEff:", "/proc/self/status")
+			},
+			Expected: test.Expects(0, nil, expect.Equals("CapEff:\t0000000000000000\n")),
+		},
+		{
+			Description: "cap-drop ALL then cap-add CHOWN",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("exec", "--cap-drop=ALL", "--cap-add=CHOWN", data.Identifier(), "grep", "^CapEff:", "/proc/self/status")
+			},
+			Expected: test.Expects(0, nil, expect.Equals("CapEff:\t0000000000000001\n")),
+		},
+	}
+
+	testCase.Run(t)
+}
+
+func TestExecList(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Require = require.Not(nerdtest.Docker)
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		helpers.Ensure("run", "-d", "--name", data.Identifier(), testutil.CommonImage, "sleep", nerdtest.Infinity)
+		helpers.Ensure("exec", "-d", data.Identifier(), "sleep", "12345")
+	}
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("rm", "-f", data.Identifier())
+	}
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "exec-ls lists the detached exec session",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("container", "exec-ls", "--format", "{{.Command}} {{.Status}}", data.Identifier())
+			},
+			Expected: test.Expects(0, nil, expect.Equals("sleep 12345 running\n")),
+		},
+		{
+			Description: "exec-inspect shows the exec session",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				id := strings.TrimSpace(helpers.Capture("container", "exec-ls", "-q", data.Identifier()))
+				return helpers.Command("container", "exec-inspect", "--format", "{{json .Command}}", data.Identifier(), id[:8])
+			},
+			Expected: test.Expects(0, nil, expect.Equals("[\"sleep\",\"12345\"]\n")),
+		},
+		{
+			Description: "exec-inspect fails on unknown exec sessions",
+			Command:     test.Command("container", "exec-inspect", "nonexistent-container", "nonexistent"),
+			Expected:    test.Expects(1, nil, nil),
+		},
+	}
+
+	testCase.Run(t)
+}
@@ -42,6 +42,9 @@ func ExportCommand() *cobra.Command {
 		SilenceErrors:     true,
 	}
 	exportCommand.Flags().StringP("output", "o", "", "Write to a file, instead of STDOUT")
+	exportCommand.Flags().StringArray("filter", nil, "Only export the paths matching a glob pattern (e.g., '/var/log/*'), or their content")
+	exportCommand.Flags().StringArray("exclude", nil, "Do not export the paths matching a glob pattern (e.g., '/tmp'), or their content")
+	exportCommand.Flags().Bool("changes-only", false, "Only export the changes to the image filesystem, as an OCI layer tar")
 
 	return exportCommand
 }
@@ -60,6 +63,19 @@ func exportAction(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	filter, err := cmd.Flags().GetStringArray("filter")
+	if err != nil {
+		return err
+	}
+	exclude, err := cmd.Flags().GetStringArray("exclude")
+	if err != nil {
+		return err
+	}
+	changesOnly, err := cmd.Flags().GetBool("changes-only")
+	if err != nil {
+		return err
+	}
+
 	client, ctx, cancel, err := clientutil.NewClient(cmd.Context(), globalOptions.Namespace, globalOptions.Address)
 	if err != nil {
 		return err
@@ -81,8 +97,11 @@ func exportAction(cmd *cobra.Command, args []string) error {
 	}
 
 	options := types.ContainerExportOptions{
-		Stdout:   writer,
-		GOptions: globalOptions,
+		Stdout:      writer,
+		GOptions:    globalOptions,
+		Filter:      filter,
+		Exclude:     exclude,
+		ChangesOnly: changesOnly,
 	}
 
 	return container.Export(ctx, client, args[0], options)
@@ -26,6 +26,7 @@ import (
 
 	"gotest.tools/v3/assert"
 
+	"github.com/containerd/nerdctl/mod/tigron/require"
 	"github.com/containerd/nerdctl/mod/tigron/test"
 	"github.com/containerd/nerdctl/mod/tigron/tig"
 
@@ -168,3 +169,53 @@ func TestExportNonexistentContainer(t *testing.T) {
 
 	testCase.Run(t)
 }
+
+func TestExportChangesOnly(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Require = require.All(
+		require.Not(require.Windows),
+		require.Not(nerdtest.Docker),
+	)
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		helpers.Ensure("run", "--name", data.Identifier(), testutil.CommonImage,
+			"sh", "-euxc", "mkdir /out; echo -n result >/out/result; touch /out/tmp; rm /bin/base64")
+	}
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("rm", "-f", data.Identifier())
+	}
+
+	testCase.Command = func(data test.Data, helpers test.Helpers) test.TestableCommand {
+		return helpers.Command("export", "--changes-only", "--exclude", "/out/tmp", "-o", data.Temp().Path("changes.tar"), data.Identifier())
+	}
+
+	testCase.Expected = func(data test.Data, helpers test.Helpers) *test.Expected {
+		return &test.Expected{
+			Output: func(stdout string, t tig.T) {
+				file, err := os.Open(data.Temp().Path("changes.tar"))
+				assert.NilError(t, err)
+				defer file.Close()
+				names := map[string]bool{}
+				tr := tar.NewReader(file)
+				for {
+					hdr, err := tr.Next()
+					if err == io.EOF {
+						break
+					}
+					assert.NilError(t, err)
+					names[hdr.Name] = true
+				}
+				// The runtime may also create files, such as mount points
+				for _, name := range []string{"bin/", "bin/.wh.base64", "out/", "out/result"} {
+					assert.Assert(t, names[name], "%s is missing", name)
+				}
+				assert.Assert(t, !names["out/tmp"], "out/tmp is not excluded")
+				assert.Assert(t, !names["bin/busybox"], "unchanged files are exported")
+			},
+		}
+	}
+
+	testCase.Run(t)
+}
@@ -37,10 +37,17 @@ The following containers are supported:
 - Containers created with 'nerdctl run -d'. The log is currently empty for containers created without '-d'.
 - Containers created with 'nerdctl compose'.
 - Containers created with Kubernetes (EXPERIMENTAL).
+
+When several containers are specified, or selected with --filter, their logs are merged
+in timestamp order and prefixed with the container names.
+
+The log lines that are JSON objects or logfmt key=value pairs are parsed while decoding
+the json-file and local logs: --level filters them by level, and --format '{{.field}}'
+prints their fields. --grep filters all the lines. --tail selects the last lines that match --grep and --level.
 `
 	var cmd = &cobra.Command{
-		Use:               "logs [flags] CONTAINER",
-		Args:              helpers.IsExactArgs(1),
+		Use:               "logs [flags] [CONTAINER...]",
+		Args:              cobra.ArbitraryArgs,
 		Short:             shortUsage,
 		Long:              longUsage,
 		RunE:              logsAction,
@@ -54,6 +61,18 @@ The following containers are supported:
 	cmd.Flags().String("since", "", "Show logs since timestamp (e.g. 2013-01-02T13:23:37Z) or relative (e.g. 42m for 42 minutes)")
 	cmd.Flags().String("until", "", "Show logs before a timestamp (e.g. 2013-01-02T13:23:37Z) or relative (e.g. 42m for 42 minutes)")
 	cmd.Flags().Bool("details", false, "Show extra details provided to logs")
+	cmd.Flags().StringSlice("filter", nil, "Show the logs of the containers matching the filters (e.g. label=<key>=<value>, name=<name>)")
+	cmd.Flags().String("format", "", "Format the logs: '' (prefixed with the container names when there are several containers), 'json' (NDJSON with the container metadata), or a Go template of the fields of the structured log lines (e.g. '{{.msg}}')")
+	cmd.Flags().String("grep", "", "Show only the log lines matching a regular expression")
+	cmd.Flags().String("level", "", "Show only the structured (JSON or logfmt) log lines with a level at least as severe (trace|debug|info|warn|error|fatal)")
+	cmd.Flags().Bool("no-color", false, "Produce monochrome output for the container name prefixes")
+	cmd.Flags().Bool("no-log-prefix", false, "Don't print the container name prefixes")
+	cmd.RegisterFlagCompletionFunc("format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"json"}, cobra.ShellCompDirectiveNoFileComp
+	})
+	cmd.RegisterFlagCompletionFunc("level", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"trace", "debug", "info", "warn", "error", "fatal"}, cobra.ShellCompDirectiveNoFileComp
+	})
 	return cmd
 }
 
@@ -93,16 +112,46 @@ func logsOptions(cmd *cobra.Command) (types.ContainerLogsOptions, error) {
 	if err != nil {
 		return types.ContainerLogsOptions{}, err
 	}
+	filters, err := cmd.Flags().GetStringSlice("filter")
+	if err != nil {
+		return types.ContainerLogsOptions{}, err
+	}
+	format, err := cmd.Flags().GetString("format")
+	if err != nil {
+		return types.ContainerLogsOptions{}, err
+	}
+	grep, err := cmd.Flags().GetString("grep")
+	if err != nil {
+		return types.ContainerLogsOptions{}, err
+	}
+	level, err := cmd.Flags().GetString("level")
+	if err != nil {
+		return types.ContainerLogsOptions{}, err
+	}
+	noColor, err := cmd.Flags().GetBool("no-color")
+	if err != nil {
+		return types.ContainerLogsOptions{}, err
+	}
+	noLogPrefix, err := cmd.Flags().GetBool("no-log-prefix")
+	if err != nil {
+		return types.ContainerLogsOptions{}, err
+	}
 	return types.ContainerLogsOptions{
-		Stdout:     cmd.OutOrStdout(),
-		Stderr:     cmd.OutOrStderr(),
-		GOptions:   globalOptions,
-		Follow:     follow,
-		Timestamps: timestamps,
-		Tail:       tail,
-		Since:      since,
-		Until:      until,
-		Details:    details,
+		Stdout:      cmd.OutOrStdout(),
+		Stderr:      cmd.OutOrStderr(),
+		GOptions:    globalOptions,
+		Follow:      follow,
+		Timestamps:  timestamps,
+		Tail:        tail,
+		Since:       since,
+		Until:       until,
+		Details:     details,
+		Filters:     filters,
+		Format:      format,
+		Grep:        grep,
+		Level:       level,
+		NoColor:     noColor,
+		NoLogPrefix: noLogPrefix,
 	}, nil
 }
 
@@ -111,6 +160,9 @@ func logsAction(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	if len(args) == 0 && len(options.Filters) == 0 {
+		return fmt.Errorf("%q requires at least 1 container argument or --filter", cmd.CommandPath())
+	}
 
 	client, ctx, cancel, err := clientutil.NewClient(cmd.Context(), options.GOptions.Namespace, options.GOptions.Address)
 	if err != nil {
@@ -118,7 +170,10 @@ func logsAction(cmd *cobra.Command, args []string) error {
 	}
 	defer cancel()
 
-	return container.Logs(ctx, client, args[0], options)
+	if len(args) == 1 && len(options.Filters) == 0 && options.Format != "json" {
+		return container.Logs(ctx, client, args[0], options)
+	}
+	return container.MultiLogs(ctx, client, args, options)
 }
 
 func logsShellComplete(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
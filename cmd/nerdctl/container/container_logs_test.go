@@ -17,6 +17,7 @@
 package container
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"regexp"
@@ -491,6 +492,330 @@ func TestLogsNoneLoggerHasNoLogURI(t *testing.T) {
 	testCase.Run(t)
 }
 
+func TestLogsOfLocalDriver(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		helpers.Ensure("run", "--log-driver", "local",
+			"--log-opt", "max-size=1k",
+			"--log-opt", "max-file=3",
+			"--name", data.Identifier(), testutil.CommonImage,
+			"sh", "-euc", "for i in $(seq 1 100); do echo line$i; done")
+	}
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("rm", "-f", data.Identifier())
+	}
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "tail across rotated files",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("logs", "--tail", "3", data.Identifier())
+			},
+			Expected: test.Expects(0, nil, expect.Equals("line98\nline99\nline100\n")),
+		},
+		{
+			Description: "the oldest lines are rotated away",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("logs", data.Identifier())
+			},
+			Expected: test.Expects(0, nil, func(stdout string, t tig.T) {
+				assert.Assert(t, !strings.HasPrefix(stdout, "line1\n"), stdout)
+				assert.Assert(t, strings.HasSuffix(stdout, "line100\n"), stdout)
+			}),
+		},
+	}
+
+	testCase.Run(t)
+}
+
+func TestLogsWithLongLines(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	// A line of 40000 characters is split into 3 partial messages of at most 16KiB
+	const lineLength = 40000
+
+	testCase.Require = require.Not(nerdtest.Docker)
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("rm", "-f", data.Identifier("json-file"), data.Identifier("local"))
+	}
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		script := fmt.Sprintf("head -c %d /dev/zero | tr '\\0' a; echo; echo end", lineLength)
+		helpers.Ensure("run", "--log-driver", "json-file", "--name", data.Identifier("json-file"),
+			testutil.CommonImage, "sh", "-c", script)
+		helpers.Ensure("run", "--log-driver", "local", "--log-opt", "max-line-size=1k", "--name", data.Identifier("local"),
+			testutil.CommonImage, "sh", "-c", script)
+	}
+
+	expected := strings.Repeat("a", lineLength) + "\nend\n"
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "json-file reassembles the partial messages",
+			NoParallel:  true,
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("logs", data.Identifier("json-file"))
+			},
+			Expected: test.Expects(0, nil, expect.Equals(expected)),
+		},
+		{
+			Description: "local reassembles the partial messages",
+			NoParallel:  true,
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("logs", data.Identifier("local"))
+			},
+			Expected: test.Expects(0, nil, expect.Equals(expected)),
+		},
+	}
+
+	testCase.Run(t)
+}
+
+func TestLogsWithStructuredLines(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Require = require.Not(nerdtest.Docker)
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("rm", "-f", data.Identifier())
+	}
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		script := `echo '{"level":"info","msg":"started"}'; echo 'level=error msg="connection refused"'; echo plain`
+		helpers.Ensure("run", "--log-driver", "json-file", "--name", data.Identifier(),
+			testutil.CommonImage, "sh", "-c", script)
+	}
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "grep",
+			NoParallel:  true,
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("logs", "--grep", "start|plain", data.Identifier())
+			},
+			Expected: test.Expects(0, nil, expect.Equals("{\"level\":\"info\",\"msg\":\"started\"}\nplain\n")),
+		},
+		{
+			Description: "level and format",
+			NoParallel:  true,
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("logs", "--level", "warn", "--format", "{{.level}}: {{.msg}}", data.Identifier())
+			},
+			Expected: test.Expects(0, nil, expect.Equals("error: connection refused\n")),
+		},
+		{
+			Description: "tail applies after the filter",
+			NoParallel:  true,
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("logs", "--tail", "1", "--grep", "started", data.Identifier())
+			},
+			Expected: test.Expects(0, nil, expect.Equals("{\"level\":\"info\",\"msg\":\"started\"}\n")),
+		},
+		{
+			Description: "invalid level",
+			NoParallel:  true,
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("logs", "--level", "verbose", data.Identifier())
+			},
+			Expected: test.Expects(expect.ExitCodeGenericFail, []error{errors.New("invalid level")}, nil),
+		},
+		{
+			Description: "NDJSON with the fields",
+			NoParallel:  true,
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("logs", "--format", "json", "--level", "info", data.Identifier())
+			},
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					Output: func(stdout string, t tig.T) {
+						lines := strings.Split(strings.TrimSpace(stdout), "\n")
+						assert.Equal(t, len(lines), 2, stdout)
+						var entry struct {
+							Stream string         `json:"stream"`
+							Fields map[string]any `json:"fields"`
+						}
+						assert.NilError(t, json.Unmarshal([]byte(lines[1]), &entry))
+						assert.Equal(t, entry.Stream, "stdout")
+						assert.Equal(t, entry.Fields["msg"], "connection refused")
+					},
+				}
+			},
+		},
+	}
+
+	testCase.Run(t)
+}
+
+func TestLogsWithFileLogURI(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Require = require.All(
+		require.Not(nerdtest.Docker),
+		require.Not(require.Windows),
+	)
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("rm", "-f", data.Identifier("detached"), data.Identifier("attached"))
+	}
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		helpers.Ensure("run", "-d", "--log-driver", "file://"+data.Temp().Path("detached.log"),
+			"--name", data.Identifier("detached"), testutil.CommonImage, "echo", "foo")
+		helpers.Ensure("run", "--log-driver", "file://"+data.Temp().Path("attached.log"),
+			"--name", data.Identifier("attached"), testutil.CommonImage, "echo", "bar")
+		helpers.Ensure("wait", data.Identifier("detached"))
+	}
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "the shim writes the logs of a detached container to the file",
+			NoParallel:  true,
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Custom("cat", data.Temp().Path("detached.log"))
+			},
+			Expected: test.Expects(0, nil, expect.Contains("foo")),
+		},
+		{
+			Description: "nerdctl writes the logs of an attached container to the file",
+			NoParallel:  true,
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Custom("cat", data.Temp().Path("attached.log"))
+			},
+			Expected: test.Expects(0, nil, expect.Equals("bar\n")),
+		},
+		{
+			Description: "the log viewer is unavailable",
+			NoParallel:  true,
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("logs", data.Identifier("detached"))
+			},
+			Expected: test.Expects(expect.ExitCodeGenericFail, []error{errors.New("the log viewer is unavailable")}, nil),
+		},
+		{
+			Description: "log URIs with an unsupported scheme are rejected",
+			NoParallel:  true,
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("create", "--log-driver", "fifo:///run/container.fifo", testutil.CommonImage)
+			},
+			Expected: test.Expects(expect.ExitCodeGenericFail, []error{errors.New("unsupported log URI scheme")}, nil),
+		},
+	}
+
+	testCase.Run(t)
+}
+
+func TestLogsOfSeveralContainers(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Require = require.Not(nerdtest.Docker)
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		data.Labels().Set("label", "com.example.test="+data.Identifier())
+		helpers.Ensure("run", "--label", data.Labels().Get("label"), "--name", data.Identifier("first"),
+			testutil.CommonImage, "echo", "foo")
+		helpers.Ensure("run", "--label", data.Labels().Get("label"), "--name", data.Identifier("second"),
+			testutil.CommonImage, "echo", "bar")
+		helpers.Ensure("run", "--name", data.Identifier("other"), testutil.CommonImage, "echo", "baz")
+	}
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("rm", "-f", data.Identifier("first"), data.Identifier("second"), data.Identifier("other"))
+	}
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "the logs are merged in timestamp order with the container names",
+			NoParallel:  true,
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("logs", "--no-color", data.Identifier("second"), data.Identifier("first"))
+			},
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				width := len(data.Identifier("second")) + 1
+				return &test.Expected{
+					Output: expect.Equals(
+						fmt.Sprintf("%-*s|foo\n", width, data.Identifier("first")) +
+							fmt.Sprintf("%-*s|bar\n", width, data.Identifier("second")),
+					),
+				}
+			},
+		},
+		{
+			Description: "the containers are selected with a label filter",
+			NoParallel:  true,
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("logs", "--no-log-prefix", "--filter", "label="+data.Labels().Get("label"))
+			},
+			Expected: test.Expects(0, nil, expect.Equals("foo\nbar\n")),
+		},
+		{
+			Description: "the logs are printed as JSON lines",
+			NoParallel:  true,
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("logs", "--format", "json", "--filter", "name="+data.Identifier("other"))
+			},
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					Output: func(stdout string, t tig.T) {
+						var entry map[string]any
+						assert.NilError(t, json.Unmarshal([]byte(stdout), &entry), stdout)
+						assert.Equal(t, entry["container"], data.Identifier("other"))
+						assert.Equal(t, entry["stream"], "stdout")
+						assert.Equal(t, entry["log"], "baz")
+					},
+				}
+			},
+		},
+		{
+			Description: "at least one container or filter is required",
+			NoParallel:  true,
+			Command:     test.Command("logs"),
+			Expected:    test.Expects(expect.ExitCodeGenericFail, nil, nil),
+		},
+	}
+
+	testCase.Run(t)
+}
+
+func TestLogsOfRemoteDriverFromCache(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Require = require.Not(require.Windows)
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("rm", "-f", data.Identifier("cached"), data.Identifier("disabled"))
+	}
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		// Nothing needs to listen: the UDP datagrams are just lost
+		helpers.Ensure("run", "--log-driver", "syslog", "--log-opt", "syslog-address=udp://127.0.0.1:5514",
+			"--name", data.Identifier("cached"), testutil.CommonImage, "echo", "foo")
+		helpers.Ensure("run", "--log-driver", "syslog", "--log-opt", "syslog-address=udp://127.0.0.1:5514",
+			"--log-opt", "cache-disabled=true",
+			"--name", data.Identifier("disabled"), testutil.CommonImage, "echo", "foo")
+	}
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "logs are read from the local cache",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("logs", data.Identifier("cached"))
+			},
+			Expected: test.Expects(0, nil, expect.Equals("foo\n")),
+		},
+		{
+			Description: "logs cannot be read when the cache is disabled",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("logs", data.Identifier("disabled"))
+			},
+			Expected: test.Expects(expect.ExitCodeGenericFail, nil, nil),
+		},
+	}
+
+	testCase.Run(t)
+}
+
 func TestLogsWithDetails(t *testing.T) {
 	testCase := nerdtest.Setup()
 
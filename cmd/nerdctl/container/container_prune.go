@@ -35,6 +35,7 @@ func pruneCommand() *cobra.Command {
 		SilenceErrors: true,
 	}
 	cmd.Flags().BoolP("force", "f", false, "Do not prompt for confirmation")
+	cmd.Flags().StringSlice("filter", []string{}, "Provide filter values (e.g., 'until=24h', 'label=foo=bar')")
 	return cmd
 }
 
@@ -44,9 +45,15 @@ func pruneOptions(cmd *cobra.Command) (types.ContainerPruneOptions, error) {
 		return types.ContainerPruneOptions{}, err
 	}
 
+	filters, err := cmd.Flags().GetStringSlice("filter")
+	if err != nil {
+		return types.ContainerPruneOptions{}, err
+	}
+
 	return types.ContainerPruneOptions{
 		GOptions: globalOptions,
 		Stdout:   cmd.OutOrStdout(),
+		Filters:  filters,
 	}, nil
 }
 
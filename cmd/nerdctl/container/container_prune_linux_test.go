@@ -54,3 +54,31 @@ func TestPruneContainer(t *testing.T) {
 
 	testCase.Expected = test.Expects(1, nil, nil)
 }
+
+func TestPruneContainerFilter(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("rm", "-f", data.Identifier("1"))
+		helpers.Anyhow("rm", "-f", data.Identifier("2"))
+	}
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		helpers.Ensure("create", "--name", data.Identifier("1"), "--label", data.Identifier()+"=1", testutil.CommonImage)
+		helpers.Ensure("create", "--name", data.Identifier("2"), "--label", data.Identifier()+"=2", testutil.CommonImage)
+	}
+
+	testCase.Command = func(data test.Data, helpers test.Helpers) test.TestableCommand {
+		// Both containers were created less than an hour ago
+		helpers.Ensure("container", "prune", "-f", "--filter", "label="+data.Identifier(), "--filter", "until=1h")
+		helpers.Ensure("inspect", data.Identifier("1"))
+		helpers.Ensure("inspect", data.Identifier("2"))
+		helpers.Ensure("container", "prune", "-f", "--filter", "label="+data.Identifier()+"=1")
+		helpers.Fail("inspect", data.Identifier("1"))
+		return helpers.Command("inspect", data.Identifier("2"))
+	}
+
+	testCase.Expected = test.Expects(0, nil, nil)
+
+	testCase.Run(t)
+}
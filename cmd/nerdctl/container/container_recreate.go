@@ -0,0 +1,89 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/completion"
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/container"
+)
+
+func RecreateCommand() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:               "recreate [flags] CONTAINER [CONTAINER, ...]",
+		Args:              cobra.MinimumNArgs(1),
+		Short:             "Re-create one or more containers with the configuration they were created with",
+		RunE:              recreateAction,
+		ValidArgsFunction: recreateShellComplete,
+		SilenceUsage:      true,
+		SilenceErrors:     true,
+	}
+	cmd.Flags().Bool("pull", false, "Pull the image before re-creating the container")
+	cmd.Flags().IntP("time", "t", 10, "Seconds to wait before sending a SIGKILL")
+	return cmd
+}
+
+func recreateOptions(cmd *cobra.Command) (types.ContainerRecreateOptions, error) {
+	globalOptions, err := helpers.ProcessRootCmdFlags(cmd)
+	if err != nil {
+		return types.ContainerRecreateOptions{}, err
+	}
+	pull, err := cmd.Flags().GetBool("pull")
+	if err != nil {
+		return types.ContainerRecreateOptions{}, err
+	}
+	var timeout *time.Duration
+	if cmd.Flags().Changed("time") {
+		timeValue, err := cmd.Flags().GetInt("time")
+		if err != nil {
+			return types.ContainerRecreateOptions{}, err
+		}
+		t := time.Duration(timeValue) * time.Second
+		timeout = &t
+	}
+	return types.ContainerRecreateOptions{
+		Stdout:   cmd.OutOrStdout(),
+		Stderr:   cmd.ErrOrStderr(),
+		GOptions: globalOptions,
+		Pull:     pull,
+		Timeout:  timeout,
+	}, nil
+}
+
+func recreateAction(cmd *cobra.Command, args []string) error {
+	options, err := recreateOptions(cmd)
+	if err != nil {
+		return err
+	}
+	client, ctx, cancel, err := clientutil.NewClient(cmd.Context(), options.GOptions.Namespace, options.GOptions.Address)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	return container.Recreate(ctx, client, args, options)
+}
+
+func recreateShellComplete(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return completion.ContainerNames(cmd, nil)
+}
@@ -92,6 +92,10 @@ func setCreateFlags(cmd *cobra.Command) {
 		return []string{"no", "always", "on-failure", "unless-stopped"}, cobra.ShellCompDirectiveNoFileComp
 	})
 	cmd.Flags().Bool("rm", false, "Automatically remove the container when it exits")
+	cmd.Flags().String("pod", "", "Run the container in an existing pod, sharing its namespaces")
+	cmd.RegisterFlagCompletionFunc("pod", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return completion.PodNames(cmd)
+	})
 	cmd.Flags().String("pull", "missing", `Pull image before running ("always"|"missing"|"never")`)
 	cmd.Flags().BoolP("quiet", "q", false, "Suppress the pull output")
 	cmd.RegisterFlagCompletionFunc("pull", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
@@ -273,7 +277,7 @@ func setCreateFlags(cmd *cobra.Command) {
 
 	// #region logging flags
 	// log-opt needs to be StringArray, not StringSlice, to prevent "env=os,customer" from being split to {"env=os", "customer"}
-	cmd.Flags().String("log-driver", "json-file", "Logging driver for the container. Default is json-file. It also supports logURI (eg: --log-driver binary://<path>)")
+	cmd.Flags().String("log-driver", "json-file", "Logging driver for the container. Default is json-file. It also supports log URIs (eg: --log-driver binary:///<path>?<key>=<value>, --log-driver file:///<path>)")
 	cmd.RegisterFlagCompletionFunc("log-driver", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		return logging.Drivers(), cobra.ShellCompDirectiveNoFileComp
 	})
@@ -361,6 +365,7 @@ func runAction(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	createOpt.CreateArgs = createArgs(cmd, args)
 
 	client, ctx, cancel, err := clientutil.NewClientWithPlatform(cmd.Context(), createOpt.GOptions.Namespace, createOpt.GOptions.Address, createOpt.Platform)
 	if err != nil {
@@ -381,6 +386,10 @@ func runAction(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to load networking flags: %w", err)
 	}
 
+	if err := joinPod(ctx, cmd, client, &createOpt, &netFlags); err != nil {
+		return err
+	}
+
 	netManager, err := containerutil.NewNetworkingOptionsManager(createOpt.GOptions, netFlags, client)
 	if err != nil {
 		return err
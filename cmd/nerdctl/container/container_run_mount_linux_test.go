@@ -28,6 +28,7 @@ import (
 
 	"github.com/containerd/containerd/v2/core/mount"
 	"github.com/containerd/nerdctl/mod/tigron/expect"
+	"github.com/containerd/nerdctl/mod/tigron/require"
 	"github.com/containerd/nerdctl/mod/tigron/test"
 	"github.com/containerd/nerdctl/mod/tigron/tig"
 
@@ -736,6 +737,49 @@ func TestRunVolumesFrom(t *testing.T) {
 	).AssertOutExactly("str1str3")
 }
 
+func TestRmAnonymousVolumes(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	// Docker removes the container asynchronously after it exits
+	testCase.Require = require.Not(nerdtest.Docker)
+
+	anonymousVolume := func(helpers test.Helpers, container, destination string) string {
+		var name string
+		for _, m := range nerdtest.InspectContainer(helpers, container).Mounts {
+			if m.Destination == destination {
+				name = m.Name
+			}
+		}
+		assert.Assert(helpers.T(), name != "", "no volume mounted on %s in container %s", destination, container)
+		return name
+	}
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		helpers.Ensure("run", "-d", "--name", data.Identifier("from"), "-v", "/from", testutil.CommonImage, "sleep", nerdtest.Infinity)
+		helpers.Ensure("create", "--rm", "--name", data.Identifier("rm"), "-v", "/rm",
+			"--volumes-from", data.Identifier("from"), testutil.CommonImage, "true")
+		data.Labels().Set("fromVolume", anonymousVolume(helpers, data.Identifier("from"), "/from"))
+		data.Labels().Set("rmVolume", anonymousVolume(helpers, data.Identifier("rm"), "/rm"))
+	}
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("rm", "-f", "-v", data.Identifier("from"))
+		helpers.Anyhow("rm", "-f", "-v", data.Identifier("rm"))
+	}
+
+	testCase.Command = func(data test.Data, helpers test.Helpers) test.TestableCommand {
+		helpers.Ensure("start", "-a", data.Identifier("rm"))
+		helpers.Fail("container", "inspect", data.Identifier("rm"))
+		// The volumes of the container mounted with --volumes-from are still in use
+		helpers.Ensure("volume", "inspect", data.Labels().Get("fromVolume"))
+		return helpers.Command("volume", "inspect", data.Labels().Get("rmVolume"))
+	}
+
+	testCase.Expected = test.Expects(expect.ExitCodeGenericFail, nil, nil)
+
+	testCase.Run(t)
+}
+
 func TestBindMountWhenHostFolderDoesNotExist(t *testing.T) {
 	t.Parallel()
 	base := testutil.NewBase(t)
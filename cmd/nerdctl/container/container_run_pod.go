@@ -0,0 +1,59 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	containerd "github.com/containerd/containerd/v2/client"
+
+	"github.com/spf13/cobra"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/pod"
+)
+
+// podConflictingFlags are the flags configuring namespaces owned by the infra container of a pod.
+var podConflictingFlags = []string{"network", "net", "publish", "hostname", "domainname", "ip", "ip6", "mac-address", "ipc", "pid"}
+
+// joinPod makes the container join the namespaces of the pod set with --pod, if any.
+func joinPod(ctx context.Context, cmd *cobra.Command, client *containerd.Client, createOpt *types.ContainerCreateOptions, netFlags *types.NetworkOptions) error {
+	if createOpt.Pod == "" {
+		return nil
+	}
+	for _, name := range podConflictingFlags {
+		if f := cmd.Flags().Lookup(name); f != nil && f.Changed {
+			return fmt.Errorf("flag --%s conflicts with --pod, set it on the pod instead", name)
+		}
+	}
+	infra, name, share, err := pod.Join(ctx, client, createOpt.Pod)
+	if err != nil {
+		return err
+	}
+	mode := "container:" + infra.ID()
+	netFlags.NetworkSlice = []string{mode}
+	if slices.Contains(share, pod.ShareIPC) {
+		createOpt.IPC = mode
+	}
+	if slices.Contains(share, pod.SharePID) {
+		createOpt.Pid = mode
+	}
+	createOpt.Pod = name
+	return nil
+}
@@ -45,7 +45,10 @@ func StatsCommand() *cobra.Command {
 
 func addStatsFlags(cmd *cobra.Command) {
 	cmd.Flags().BoolP("all", "a", false, "Show all containers (default shows just running)")
-	cmd.Flags().String("format", "", "Pretty-print images using a Go template, e.g, '{{json .}}'")
+	cmd.Flags().String("format", "", "Format the output using the given Go template, e.g, '{{json .}}', or 'json' for a stream of JSON lines with the raw counters")
+	cmd.RegisterFlagCompletionFunc("format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"json", "table"}, cobra.ShellCompDirectiveNoFileComp
+	})
 	cmd.Flags().Bool("no-stream", false, "Disable streaming stats and only pull the first result")
 	cmd.Flags().Bool("no-trunc", false, "Do not truncate output")
 }
@@ -20,10 +20,14 @@ import (
 	"runtime"
 	"testing"
 
+	"gotest.tools/v3/assert"
+
 	"github.com/containerd/nerdctl/mod/tigron/expect"
 	"github.com/containerd/nerdctl/mod/tigron/require"
 	"github.com/containerd/nerdctl/mod/tigron/test"
+	"github.com/containerd/nerdctl/mod/tigron/tig"
 
+	"github.com/containerd/nerdctl/v2/pkg/statsutil"
 	"github.com/containerd/nerdctl/v2/pkg/testutil"
 	"github.com/containerd/nerdctl/v2/pkg/testutil/nerdtest"
 )
@@ -105,6 +109,23 @@ func TestStats(t *testing.T) {
 			},
 			Expected: test.Expects(0, nil, expect.Contains("1GiB")),
 		},
+		{
+			Description: "json format with raw counters",
+			Require:     require.Not(nerdtest.Docker),
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("stats", "--no-stream", "--format", "json", data.Identifier("memlimited"))
+			},
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					Output: expect.JSON(&statsutil.JSONStatsEntry{}, func(entry *statsutil.JSONStatsEntry, t tig.T) {
+						assert.Equal(t, entry.MemUsage[len(entry.MemUsage)-4:], "1GiB")
+						assert.Equal(t, entry.MemoryLimit, float64(1<<30))
+						assert.Assert(t, entry.Memory > 0)
+						assert.Assert(t, !entry.Read.IsZero())
+					}),
+				}
+			},
+		},
 	}
 
 	testCase.Run(t)
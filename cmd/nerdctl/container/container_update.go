@@ -21,7 +21,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
 	"runtime"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/docker/go-units"
@@ -42,6 +45,9 @@ import (
 	"github.com/containerd/nerdctl/v2/pkg/formatter"
 	"github.com/containerd/nerdctl/v2/pkg/idutil/containerwalker"
 	"github.com/containerd/nerdctl/v2/pkg/infoutil"
+	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/dockercompat"
+	"github.com/containerd/nerdctl/v2/pkg/labels"
+	"github.com/containerd/nerdctl/v2/pkg/strutil"
 )
 
 type updateResourceOptions struct {
@@ -55,6 +61,10 @@ type updateResourceOptions struct {
 	CpusetMems         string
 	PidsLimit          int64
 	BlkioWeight        uint16
+	CPURealtimePeriod  uint64
+	CPURealtimeRuntime uint64
+	LabelsAdd          map[string]string
+	LabelsRm           []string
 }
 
 func UpdateCommand() *cobra.Command {
@@ -77,6 +87,8 @@ func setUpdateFlags(cmd *cobra.Command) {
 	cmd.Flags().Uint64("cpu-period", 0, "Limit CPU CFS (Completely Fair Scheduler) period")
 	cmd.Flags().Int64("cpu-quota", -1, "Limit CPU CFS (Completely Fair Scheduler) quota")
 	cmd.Flags().Uint64("cpu-shares", 0, "CPU shares (relative weight)")
+	cmd.Flags().Uint64("cpu-rt-period", 0, "Limit CPU real-time period in microseconds")
+	cmd.Flags().Uint64("cpu-rt-runtime", 0, "Limit CPU real-time runtime in microseconds")
 	cmd.Flags().StringP("memory", "m", "", "Memory limit")
 	cmd.Flags().String("memory-reservation", "", "Memory soft limit")
 	cmd.Flags().String("memory-swap", "", "Swap limit equal to memory plus swap: '-1' to enable unlimited swap")
@@ -89,6 +101,8 @@ func setUpdateFlags(cmd *cobra.Command) {
 	cmd.RegisterFlagCompletionFunc("restart", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		return []string{"no", "always", "on-failure", "unless-stopped"}, cobra.ShellCompDirectiveNoFileComp
 	})
+	cmd.Flags().StringArray("label-add", nil, "Add or overwrite a label (key=value)")
+	cmd.Flags().StringArray("label-rm", nil, "Remove a label (key)")
 }
 
 func updateAction(cmd *cobra.Command, args []string) error {
@@ -224,6 +238,39 @@ func getUpdateOption(cmd *cobra.Command, globalOptions types.GlobalCommandOption
 	if blkioWeight > 0 && blkioWeight < 10 || blkioWeight > 1000 {
 		return options, errors.New("range of blkio weight is from 10 to 1000")
 	}
+	cpuRtPeriod, err := cmd.Flags().GetUint64("cpu-rt-period")
+	if err != nil {
+		return options, err
+	}
+	cpuRtRuntime, err := cmd.Flags().GetUint64("cpu-rt-runtime")
+	if err != nil {
+		return options, err
+	}
+	if cmd.Flags().Changed("cpu-rt-period") || cmd.Flags().Changed("cpu-rt-runtime") {
+		if !infoutil.CPURealtime(globalOptions.CgroupManager) {
+			// CPU realtime scheduling is not supported in cgroup V2
+			return options, errors.New("kernel does not support CPU real-time scheduler")
+		}
+		if cpuRtPeriod != 0 && cpuRtRuntime > cpuRtPeriod {
+			return options, errors.New("cpu real-time runtime cannot be higher than cpu real-time period")
+		}
+	}
+	labelsAdd, err := cmd.Flags().GetStringArray("label-add")
+	if err != nil {
+		return options, err
+	}
+	labelsRm, err := cmd.Flags().GetStringArray("label-rm")
+	if err != nil {
+		return options, err
+	}
+	for _, key := range append(slices.Collect(maps.Keys(strutil.ConvertKVStringsToMap(labelsAdd))), labelsRm...) {
+		if key == "" {
+			return options, errors.New("label key must not be empty")
+		}
+		if isReservedLabel(key) {
+			return options, fmt.Errorf("label %q is reserved and cannot be updated", key)
+		}
+	}
 
 	if runtime.GOOS == "linux" {
 		options = updateResourceOptions{
@@ -237,8 +284,12 @@ func getUpdateOption(cmd *cobra.Command, globalOptions types.GlobalCommandOption
 			MemorySwapInBytes:  memSwap64,
 			PidsLimit:          pidsLimit,
 			BlkioWeight:        blkioWeight,
+			CPURealtimePeriod:  cpuRtPeriod,
+			CPURealtimeRuntime: cpuRtRuntime,
 		}
 	}
+	options.LabelsAdd = strutil.ConvertKVStringsToMap(labelsAdd)
+	options.LabelsRm = labelsRm
 	return options, nil
 }
 
@@ -275,7 +326,8 @@ func updateContainer(ctx context.Context, client *containerd.Client, id string,
 				spec.Linux.Resources.BlockIO.Weight = &opts.BlkioWeight
 			}
 		}
-		if cmd.Flags().Changed("cpu-shares") || cmd.Flags().Changed("cpu-quota") || cmd.Flags().Changed("cpu-period") || cmd.Flags().Changed("cpus") || cmd.Flags().Changed("cpuset-mems") || cmd.Flags().Changed("cpuset-cpus") {
+		if cmd.Flags().Changed("cpu-shares") || cmd.Flags().Changed("cpu-quota") || cmd.Flags().Changed("cpu-period") || cmd.Flags().Changed("cpus") || cmd.Flags().Changed("cpuset-mems") || cmd.Flags().Changed("cpuset-cpus") ||
+			cmd.Flags().Changed("cpu-rt-period") || cmd.Flags().Changed("cpu-rt-runtime") {
 			if spec.Linux.Resources.CPU == nil {
 				spec.Linux.Resources.CPU = &runtimespec.LinuxCPU{}
 			}
@@ -296,9 +348,15 @@ func updateContainer(ctx context.Context, client *containerd.Client, id string,
 			}
 		}
 		if cmd.Flags().Changed("cpus") {
-			if spec.Linux.Resources.CPU.Cpus != opts.CpusetCpus {
-				spec.Linux.Resources.CPU.Cpus = opts.CpusetCpus
-			}
+			spec.Linux.Resources.CPU.Quota = &opts.CPUQuota
+			spec.Linux.Resources.CPU.Period = &opts.CPUPeriod
+		}
+		if cmd.Flags().Changed("cpu-rt-period") {
+			spec.Linux.Resources.CPU.RealtimePeriod = &opts.CPURealtimePeriod
+		}
+		if cmd.Flags().Changed("cpu-rt-runtime") {
+			rtRuntime := int64(opts.CPURealtimeRuntime)
+			spec.Linux.Resources.CPU.RealtimeRuntime = &rtRuntime
 		}
 		if cmd.Flags().Changed("cpuset-mems") {
 			if spec.Linux.Resources.CPU.Mems != opts.CpusetMems {
@@ -339,15 +397,25 @@ func updateContainer(ctx context.Context, client *containerd.Client, id string,
 		}
 	}
 
-	if err := updateContainerSpec(ctx, container, spec); err != nil {
-		return fmt.Errorf("failed to update spec %+v for container %q", spec, id)
+	oldLabels, err := container.Labels(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := updateContainerSpec(ctx, container, spec, func(lbls map[string]string) error {
+		return updateLabels(lbls, opts, cmd)
+	}); err != nil {
+		return fmt.Errorf("failed to update spec %+v for container %q: %w", spec, id, err)
 	}
 	defer func() {
 		if retErr != nil {
 			deferCtx, deferCancel := context.WithTimeout(ctx, 1*time.Minute)
 			defer deferCancel()
-			// Reset spec on error.
-			if err := updateContainerSpec(deferCtx, container, oldSpec); err != nil {
+			// Reset spec and labels on error.
+			if err := updateContainerSpec(deferCtx, container, oldSpec, func(lbls map[string]string) error {
+				restoreLabels(lbls, oldLabels, opts)
+				return nil
+			}); err != nil {
 				log.G(ctx).WithError(err).Errorf("Failed to update spec %+v for container %q", oldSpec, id)
 			}
 		}
@@ -379,14 +447,63 @@ func updateContainer(ctx context.Context, client *containerd.Client, id string,
 	return task.Update(ctx, containerd.WithResources(spec.Linux.Resources))
 }
 
-func updateContainerSpec(ctx context.Context, container containerd.Container, spec *runtimespec.Spec) error {
+// updateLabels adds and removes the user labels, and updates the host config label.
+// The restart labels are updated separately by UpdateContainerRestartPolicyLabel.
+func updateLabels(lbls map[string]string, opts updateResourceOptions, cmd *cobra.Command) error {
+	for _, key := range opts.LabelsRm {
+		delete(lbls, key)
+	}
+	maps.Copy(lbls, opts.LabelsAdd)
+
+	if cmd.Flags().Changed("blkio-weight") {
+		var hostConfigLabel dockercompat.HostConfigLabel
+		if v, ok := lbls[labels.HostConfigLabel]; ok {
+			if err := json.Unmarshal([]byte(v), &hostConfigLabel); err != nil {
+				return fmt.Errorf("failed to parse host config label: %w", err)
+			}
+		}
+		hostConfigLabel.BlkioWeight = opts.BlkioWeight
+		hostConfigJSON, err := json.Marshal(hostConfigLabel)
+		if err != nil {
+			return err
+		}
+		lbls[labels.HostConfigLabel] = string(hostConfigJSON)
+	}
+	return nil
+}
+
+// restoreLabels reverts the labels changed by updateLabels to their old values.
+func restoreLabels(lbls, oldLabels map[string]string, opts updateResourceOptions) {
+	keys := append([]string{labels.HostConfigLabel}, opts.LabelsRm...)
+	for key := range opts.LabelsAdd {
+		keys = append(keys, key)
+	}
+	for _, key := range keys {
+		if v, ok := oldLabels[key]; ok {
+			lbls[key] = v
+		} else {
+			delete(lbls, key)
+		}
+	}
+}
+
+// isReservedLabel returns whether the label is managed by nerdctl or containerd, rather than by the user.
+func isReservedLabel(key string) bool {
+	return strings.HasPrefix(key, labels.Prefix) || strings.HasPrefix(key, "containerd.io/")
+}
+
+// updateContainerSpec replaces the spec of the container, and updates its labels with updateLabels in the same transaction.
+func updateContainerSpec(ctx context.Context, container containerd.Container, spec *runtimespec.Spec, updateLabels func(map[string]string) error) error {
 	if err := container.Update(ctx, func(ctx context.Context, client *containerd.Client, c *containers.Container) error {
 		a, err := typeurl.MarshalAny(spec)
 		if err != nil {
 			return fmt.Errorf("failed to marshal spec %+v:%w", spec, err)
 		}
 		c.Spec = a
-		return nil
+		if c.Labels == nil {
+			c.Labels = map[string]string{}
+		}
+		return updateLabels(c.Labels)
 	}); err != nil {
 		return fmt.Errorf("failed to update container spec:%w", err)
 	}
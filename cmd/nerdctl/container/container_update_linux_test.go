@@ -17,9 +17,15 @@
 package container
 
 import (
+	"errors"
 	"testing"
 
+	"github.com/containerd/nerdctl/mod/tigron/expect"
+	"github.com/containerd/nerdctl/mod/tigron/require"
+	"github.com/containerd/nerdctl/mod/tigron/test"
+
 	"github.com/containerd/nerdctl/v2/pkg/testutil"
+	"github.com/containerd/nerdctl/v2/pkg/testutil/nerdtest"
 )
 
 func TestUpdateContainer(t *testing.T) {
@@ -31,3 +37,60 @@ func TestUpdateContainer(t *testing.T) {
 	base.Cmd("update", "--memory", "999999999", "--restart", "123", testContainerName).AssertFail()
 	base.Cmd("inspect", "--mode=native", testContainerName).AssertOutNotContains(`"limit": 999999999,`)
 }
+
+func TestUpdateInspect(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		helpers.Ensure("run", "-d", "--name", data.Identifier(), testutil.CommonImage, "sleep", nerdtest.Infinity)
+	}
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("rm", "-f", data.Identifier())
+	}
+
+	testCase.Command = func(data test.Data, helpers test.Helpers) test.TestableCommand {
+		helpers.Ensure("update", "--restart", "always", "--pids-limit", "42", data.Identifier())
+		return helpers.Command("inspect", "--format", "{{.HostConfig.RestartPolicy.Name}} {{.HostConfig.RestartPolicy.MaximumRetryCount}} {{.HostConfig.PidsLimit}}", data.Identifier())
+	}
+
+	testCase.Expected = test.Expects(0, nil, expect.Equals("always 0 42\n"))
+
+	testCase.Run(t)
+}
+
+func TestUpdateLabels(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Require = require.Not(nerdtest.Docker)
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		helpers.Ensure("run", "-d", "--name", data.Identifier(), "--label", "foo=bar", "--label", "baz=qux", testutil.CommonImage, "sleep", nerdtest.Infinity)
+	}
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("rm", "-f", data.Identifier())
+	}
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "add and remove labels",
+			NoParallel:  true,
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				helpers.Ensure("update", "--label-add", "foo=updated", "--label-add", "new=label", "--label-rm", "baz", data.Identifier())
+				return helpers.Command("inspect", "--format", "{{.Config.Labels.foo}} {{.Config.Labels.new}} {{index .Config.Labels \"baz\"}}", data.Identifier())
+			},
+			Expected: test.Expects(0, nil, expect.Equals("updated label <no value>\n")),
+		},
+		{
+			Description: "reserved labels cannot be updated",
+			NoParallel:  true,
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("update", "--label-rm", "nerdctl/name", data.Identifier())
+			},
+			Expected: test.Expects(1, []error{errors.New("reserved")}, nil),
+		},
+	}
+
+	testCase.Run(t)
+}
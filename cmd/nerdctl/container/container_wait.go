@@ -17,6 +17,8 @@
 package container
 
 import (
+	"strings"
+
 	"github.com/spf13/cobra"
 
 	containerd "github.com/containerd/containerd/v2/client"
@@ -32,12 +34,18 @@ func WaitCommand() *cobra.Command {
 	var cmd = &cobra.Command{
 		Use:               "wait [flags] CONTAINER [CONTAINER, ...]",
 		Args:              cobra.MinimumNArgs(1),
-		Short:             "Block until one or more containers stop, then print their exit codes.",
+		Short:             "Block until one or more containers meet a condition (default: stop), then print their exit codes.",
 		RunE:              waitAction,
 		ValidArgsFunction: waitShellComplete,
 		SilenceUsage:      true,
 		SilenceErrors:     true,
 	}
+	cmd.Flags().String("condition", container.WaitConditionNotRunning, "Condition to wait for: "+strings.Join(container.WaitConditions, ", "))
+	cmd.RegisterFlagCompletionFunc("condition", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return container.WaitConditions, cobra.ShellCompDirectiveNoFileComp
+	})
+	cmd.Flags().Duration("timeout", 0, "Maximum time to wait (e.g. 30s, default: no timeout)")
+	cmd.Flags().Bool("any", false, "Return as soon as one of the containers meets the condition, exiting with its exit code")
 	return cmd
 }
 
@@ -46,9 +54,24 @@ func waitOptions(cmd *cobra.Command) (types.ContainerWaitOptions, error) {
 	if err != nil {
 		return types.ContainerWaitOptions{}, err
 	}
+	condition, err := cmd.Flags().GetString("condition")
+	if err != nil {
+		return types.ContainerWaitOptions{}, err
+	}
+	timeout, err := cmd.Flags().GetDuration("timeout")
+	if err != nil {
+		return types.ContainerWaitOptions{}, err
+	}
+	anyContainer, err := cmd.Flags().GetBool("any")
+	if err != nil {
+		return types.ContainerWaitOptions{}, err
+	}
 	return types.ContainerWaitOptions{
-		Stdout:   cmd.OutOrStdout(),
-		GOptions: globalOptions,
+		Stdout:    cmd.OutOrStdout(),
+		GOptions:  globalOptions,
+		Condition: condition,
+		Timeout:   timeout,
+		Any:       anyContainer,
 	}, nil
 }
 
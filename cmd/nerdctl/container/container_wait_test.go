@@ -17,9 +17,11 @@
 package container
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/containerd/nerdctl/mod/tigron/expect"
+	"github.com/containerd/nerdctl/mod/tigron/require"
 	"github.com/containerd/nerdctl/mod/tigron/test"
 
 	"github.com/containerd/nerdctl/v2/pkg/testutil"
@@ -50,3 +52,79 @@ func TestWait(t *testing.T) {
 
 	testCase.Run(t)
 }
+
+func TestWaitCondition(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Require = require.Not(nerdtest.Docker)
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "next-exit",
+			Setup: func(data test.Data, helpers test.Helpers) {
+				helpers.Ensure("run", "-d", "--name", data.Identifier(), testutil.CommonImage, "sh", "-c", "sleep 2; exit 7")
+			},
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("rm", "-f", data.Identifier())
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("wait", "--condition=next-exit", data.Identifier())
+			},
+			Expected: test.Expects(0, nil, expect.Equals("7\n")),
+		},
+		{
+			Description: "removed",
+			Setup: func(data test.Data, helpers test.Helpers) {
+				helpers.Ensure("run", "-d", "--rm", "--name", data.Identifier(), testutil.CommonImage, "sh", "-c", "sleep 2; exit 5")
+			},
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("rm", "-f", data.Identifier())
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("wait", "--condition=removed", data.Identifier())
+			},
+			Expected: test.Expects(0, nil, expect.Equals("5\n")),
+		},
+		{
+			Description: "invalid condition",
+			Command:     test.Command("wait", "--condition=paused", "foo"),
+			Expected:    test.Expects(1, []error{errors.New("invalid condition")}, nil),
+		},
+	}
+
+	testCase.Run(t)
+}
+
+func TestWaitAny(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Require = require.Not(nerdtest.Docker)
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		helpers.Ensure("run", "-d", "--name", data.Identifier("sidecar"), testutil.CommonImage, "sleep", nerdtest.Infinity)
+		helpers.Ensure("run", "-d", "--name", data.Identifier("job"), testutil.CommonImage, "sh", "-c", "sleep 2; exit 42")
+	}
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("rm", "-f", data.Identifier("sidecar"), data.Identifier("job"))
+	}
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "returns the exit code of the first container to exit",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("wait", "--any", data.Identifier("sidecar"), data.Identifier("job"))
+			},
+			Expected: test.Expects(42, nil, expect.Equals("42\n")),
+		},
+		{
+			Description: "times out",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("wait", "--timeout=1s", data.Identifier("sidecar"))
+			},
+			Expected: test.Expects(1, []error{errors.New("timed out after 1s")}, nil),
+		},
+	}
+
+	testCase.Run(t)
+}
@@ -0,0 +1,125 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package generate
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/completion"
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/generate"
+)
+
+func systemdCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "systemd [flags] CONTAINER|PROJECT [CONTAINER|PROJECT...]",
+		Short: "Generate systemd units running containers or compose projects",
+		Long: `Generate systemd units running containers or compose projects.
+
+The units of containers start the existing containers, or create them with the flags they were created with (--new).
+The units of compose projects run "nerdctl compose up".
+In rootless mode, the units are user units.`,
+		Args:              cobra.MinimumNArgs(1),
+		RunE:              systemdAction,
+		ValidArgsFunction: systemdShellComplete,
+		SilenceUsage:      true,
+		SilenceErrors:     true,
+	}
+	cmd.Flags().Bool("new", false, "Create the containers when the units start, and remove them when the units stop")
+	cmd.Flags().Bool("quadlet", false, "Generate Quadlet .container files (implies --new)")
+	cmd.Flags().Bool("files", false, "Write the units to files in the current directory, instead of printing them")
+	cmd.Flags().String("restart-policy", "on-failure", `Restart policy of the units ("no"|"on-success"|"on-failure"|"on-abnormal"|"on-watchdog"|"on-abort"|"always")`)
+	cmd.RegisterFlagCompletionFunc("restart-policy", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"no", "on-success", "on-failure", "on-abnormal", "on-watchdog", "on-abort", "always"}, cobra.ShellCompDirectiveNoFileComp
+	})
+	cmd.Flags().Uint("restart-sec", 0, "Seconds to sleep before restarting the units")
+	cmd.Flags().UintP("time", "t", generate.DefaultStopTimeout, "Seconds to wait before killing the containers when stopping the units (default: the stop timeout of the containers)")
+	cmd.Flags().StringArray("wants", nil, "Add a Wants= dependency to the units")
+	cmd.Flags().StringArray("after", nil, "Add an After= dependency to the units")
+	cmd.Flags().StringArray("requires", nil, "Add a Requires= dependency to the units")
+	return cmd
+}
+
+func systemdOptions(cmd *cobra.Command) (types.GenerateSystemdOptions, error) {
+	globalOptions, err := helpers.ProcessRootCmdFlags(cmd)
+	if err != nil {
+		return types.GenerateSystemdOptions{}, err
+	}
+	options := types.GenerateSystemdOptions{
+		Stdout:   cmd.OutOrStdout(),
+		GOptions: globalOptions,
+	}
+	options.NerdctlCmd, options.NerdctlArgs = helpers.GlobalFlags(cmd)
+	for _, f := range []struct {
+		name  string
+		value *bool
+	}{
+		{"new", &options.New},
+		{"quadlet", &options.Quadlet},
+		{"files", &options.Files},
+	} {
+		if *f.value, err = cmd.Flags().GetBool(f.name); err != nil {
+			return types.GenerateSystemdOptions{}, err
+		}
+	}
+	if options.RestartPolicy, err = cmd.Flags().GetString("restart-policy"); err != nil {
+		return types.GenerateSystemdOptions{}, err
+	}
+	if options.RestartSec, err = cmd.Flags().GetUint("restart-sec"); err != nil {
+		return types.GenerateSystemdOptions{}, err
+	}
+	if cmd.Flags().Changed("time") {
+		t, err := cmd.Flags().GetUint("time")
+		if err != nil {
+			return types.GenerateSystemdOptions{}, err
+		}
+		options.StopTimeout = &t
+	}
+	for _, f := range []struct {
+		name  string
+		value *[]string
+	}{
+		{"wants", &options.Wants},
+		{"after", &options.After},
+		{"requires", &options.Requires},
+	} {
+		if *f.value, err = cmd.Flags().GetStringArray(f.name); err != nil {
+			return types.GenerateSystemdOptions{}, err
+		}
+	}
+	return options, nil
+}
+
+func systemdAction(cmd *cobra.Command, args []string) error {
+	options, err := systemdOptions(cmd)
+	if err != nil {
+		return err
+	}
+	client, ctx, cancel, err := clientutil.NewClient(cmd.Context(), options.GOptions.Namespace, options.GOptions.Address)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	return generate.Systemd(ctx, client, args, options)
+}
+
+func systemdShellComplete(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return completion.ContainerNames(cmd, nil)
+}
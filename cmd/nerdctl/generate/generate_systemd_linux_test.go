@@ -0,0 +1,106 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package generate
+
+import (
+	"testing"
+
+	"github.com/containerd/nerdctl/mod/tigron/expect"
+	"github.com/containerd/nerdctl/mod/tigron/require"
+	"github.com/containerd/nerdctl/mod/tigron/test"
+
+	"github.com/containerd/nerdctl/v2/pkg/testutil"
+	"github.com/containerd/nerdctl/v2/pkg/testutil/nerdtest"
+)
+
+func TestGenerateSystemd(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Require = require.Not(nerdtest.Docker)
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		helpers.Ensure("create", "--name", data.Identifier(), "--restart=always", "-p", "8080:80", "-e", "FOO=bar baz",
+			testutil.CommonImage, "sleep", nerdtest.Infinity)
+	}
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("rm", "-f", data.Identifier())
+	}
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "existing container",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("generate", "systemd", data.Identifier())
+			},
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					Output: expect.Contains(
+						"# nerdctl-"+data.Identifier()+".service",
+						"start --attach "+data.Identifier(),
+						"stop --time=10 "+data.Identifier(),
+						"Restart=on-failure",
+					),
+				}
+			},
+		},
+		{
+			Description: "new container",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("generate", "systemd", "--new", "--restart-policy=always", data.Identifier())
+			},
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					Output: expect.All(
+						expect.Contains(
+							"run --rm --name="+data.Identifier()+" ",
+							`"--env=FOO=bar baz"`,
+							"--publish=8080:80",
+							testutil.CommonImage+" sleep "+nerdtest.Infinity,
+							"ExecStopPost=-",
+							"Restart=always",
+						),
+						expect.DoesNotContain("--restart="),
+					),
+				}
+			},
+		},
+		{
+			Description: "quadlet",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("generate", "systemd", "--quadlet", data.Identifier())
+			},
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					Output: expect.Contains(
+						"# "+data.Identifier()+".container",
+						"ContainerName="+data.Identifier(),
+						"Image="+testutil.CommonImage,
+						"Exec=sleep "+nerdtest.Infinity,
+					),
+				}
+			},
+		},
+		{
+			Description: "unknown container or project",
+			Command:     test.Command("generate", "systemd", "does-not-exist"),
+			Expected:    test.Expects(expect.ExitCodeGenericFail, nil, nil),
+		},
+	}
+
+	testCase.Run(t)
+}
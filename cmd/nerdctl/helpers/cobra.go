@@ -154,11 +154,17 @@ func GlobalFlags(cmd *cobra.Command) (string, []string) {
 	flagSet := rootCmd.Flags()
 	args := []string{}
 	flagSet.VisitAll(func(f *pflag.Flag) {
-		key := f.Name
-		val := f.Value.String()
-		if f.Changed {
-			args = append(args, "--"+key+"="+val)
+		if !f.Changed {
+			return
 		}
+		// The values of the slice flags may contain commas (e.g., --default-address-pool), so they are passed one by one
+		if sv, ok := f.Value.(pflag.SliceValue); ok {
+			for _, v := range sv.GetSlice() {
+				args = append(args, "--"+f.Name+"="+v)
+			}
+			return
+		}
+		args = append(args, "--"+f.Name+"="+f.Value.String())
 	})
 	return args0, args
 }
@@ -22,6 +22,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/config"
 	"github.com/containerd/nerdctl/v2/pkg/fs"
 )
 
@@ -157,6 +158,18 @@ func ProcessRootCmdFlags(cmd *cobra.Command) (types.GlobalCommandOptions, error)
 	if err != nil {
 		return types.GlobalCommandOptions{}, err
 	}
+	defaultAddressPoolStrs, err := cmd.Flags().GetStringArray("default-address-pool")
+	if err != nil {
+		return types.GlobalCommandOptions{}, err
+	}
+	var defaultAddressPools []config.AddressPool
+	for _, s := range defaultAddressPoolStrs {
+		p, err := config.ParseAddressPool(s)
+		if err != nil {
+			return types.GlobalCommandOptions{}, err
+		}
+		defaultAddressPools = append(defaultAddressPools, p)
+	}
 
 	// Point to dataRoot for filesystem-helpers implementing rollback / backups.
 	err = fs.InitFS(dataRoot)
@@ -165,25 +178,26 @@ func ProcessRootCmdFlags(cmd *cobra.Command) (types.GlobalCommandOptions, error)
 	}
 
 	return types.GlobalCommandOptions{
-		Debug:            debug,
-		DebugFull:        debugFull,
-		Address:          address,
-		Namespace:        namespace,
-		Snapshotter:      snapshotter,
-		CNIPath:          cniPath,
-		CNINetConfPath:   cniConfigPath,
-		DataRoot:         dataRoot,
-		CgroupManager:    cgroupManager,
-		InsecureRegistry: insecureRegistry,
-		HostsDir:         hostsDir,
-		Experimental:     experimental,
-		HostGatewayIP:    hostGatewayIP,
-		BridgeIP:         bridgeIP,
-		KubeHideDupe:     kubeHideDupe,
-		CDISpecDirs:      cdiSpecDirs,
-		DNS:              dns,
-		DNSOpts:          dnsOpts,
-		DNSSearch:        dnsSearch,
+		Debug:               debug,
+		DebugFull:           debugFull,
+		Address:             address,
+		Namespace:           namespace,
+		Snapshotter:         snapshotter,
+		CNIPath:             cniPath,
+		CNINetConfPath:      cniConfigPath,
+		DataRoot:            dataRoot,
+		CgroupManager:       cgroupManager,
+		InsecureRegistry:    insecureRegistry,
+		HostsDir:            hostsDir,
+		Experimental:        experimental,
+		HostGatewayIP:       hostGatewayIP,
+		BridgeIP:            bridgeIP,
+		KubeHideDupe:        kubeHideDupe,
+		CDISpecDirs:         cdiSpecDirs,
+		DNS:                 dns,
+		DNSOpts:             dnsOpts,
+		DNSSearch:           dnsSearch,
+		DefaultAddressPools: defaultAddressPools,
 	}, nil
 }
 
@@ -32,6 +32,7 @@ func Command() *cobra.Command {
 	cmd.AddCommand(
 		newInternalOCIHookCommandCommand(),
 	)
+	addInternalCpCommand(cmd)
 
 	return cmd
 }
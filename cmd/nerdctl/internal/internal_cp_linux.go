@@ -0,0 +1,102 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package internal
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/containerd/nerdctl/v2/pkg/errutil"
+	"github.com/containerd/nerdctl/v2/pkg/tarutil"
+)
+
+// addInternalCpCommand adds the commands executed by `nerdctl cp` in the user namespace of a rootless container.
+func addInternalCpCommand(cmd *cobra.Command) {
+	var cpCmd = &cobra.Command{
+		Use:           "cp",
+		Short:         "Archive and extract files for nerdctl cp",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+
+	var archiveCmd = &cobra.Command{
+		Use:           "archive PATH",
+		Args:          cobra.ExactArgs(1),
+		RunE:          internalCpArchiveAction,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	archiveCmd.Flags().String("name", "", "Name of the top-level entry of the archive")
+	archiveCmd.Flags().Bool("follow-link", false, "Follow symbolic links")
+
+	var extractCmd = &cobra.Command{
+		Use:           "extract DIR",
+		Args:          cobra.ExactArgs(1),
+		RunE:          internalCpExtractAction,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	extractCmd.Flags().Bool("same-owner", false, "Restore the owners recorded in the archive")
+	extractCmd.Flags().String("chown", "", "UID:GID owning the extracted files")
+
+	cpCmd.AddCommand(archiveCmd, extractCmd)
+	cmd.AddCommand(cpCmd)
+}
+
+func internalCpArchiveAction(cmd *cobra.Command, args []string) error {
+	name, err := cmd.Flags().GetString("name")
+	if err != nil {
+		return err
+	}
+	followLink, err := cmd.Flags().GetBool("follow-link")
+	if err != nil {
+		return err
+	}
+	err = tarutil.Archive(cmd.OutOrStdout(), args[0], tarutil.ArchiveOptions{Name: name, FollowSymlinks: followLink})
+	return internalCpResult(cmd, err)
+}
+
+func internalCpExtractAction(cmd *cobra.Command, args []string) error {
+	var opts tarutil.ExtractOptions
+	var err error
+	opts.SameOwner, err = cmd.Flags().GetBool("same-owner")
+	if err != nil {
+		return err
+	}
+	chown, err := cmd.Flags().GetString("chown")
+	if err != nil {
+		return err
+	}
+	if chown != "" {
+		opts.Chown = &tarutil.Owner{}
+		if _, err := fmt.Sscanf(chown, "%d:%d", &opts.Chown.UID, &opts.Chown.GID); err != nil {
+			return fmt.Errorf("invalid --chown %q: %w", chown, err)
+		}
+	}
+	err = tarutil.Extract(cmd.InOrStdin(), args[0], opts)
+	return internalCpResult(cmd, err)
+}
+
+// internalCpResult prints the error as is on stderr, for `nerdctl cp` to report it.
+func internalCpResult(cmd *cobra.Command, err error) error {
+	if err == nil {
+		return nil
+	}
+	fmt.Fprintln(cmd.ErrOrStderr(), err)
+	return errutil.NewExitCoderErr(1)
+}
@@ -0,0 +1,65 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package kube
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/kube"
+)
+
+func downCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "down [flags] FILE",
+		Short: "Remove the pods created from a Kubernetes manifest",
+		Long: `Remove the pods created from a Kubernetes manifest, with their containers and their emptyDir and configMap volumes.
+
+The volumes of the persistent volume claims are kept. Use "-" to read the manifest from the standard input.`,
+		Args:          helpers.IsExactArgs(1),
+		RunE:          downAction,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	return cmd
+}
+
+func downAction(cmd *cobra.Command, args []string) error {
+	globalOptions, err := helpers.ProcessRootCmdFlags(cmd)
+	if err != nil {
+		return err
+	}
+	options := types.KubeDownOptions{
+		Stdout:   cmd.OutOrStdout(),
+		GOptions: globalOptions,
+	}
+	f, err := openManifest(cmd, args[0])
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	client, ctx, cancel, err := clientutil.NewClient(cmd.Context(), options.GOptions.Namespace, options.GOptions.Address)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	return kube.Down(ctx, client, f, options)
+}
@@ -0,0 +1,95 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package kube
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/completion"
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/kube"
+)
+
+func generateCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "generate [flags] CONTAINER|POD [CONTAINER|POD...]",
+		Short: "Generate a Kubernetes pod or deployment manifest from containers",
+		Long: `Generate a Kubernetes pod or deployment manifest from containers.
+
+All the containers are generated in a single pod. The containers of a nerdctl pod can be generated by passing the name of the pod.`,
+		Args:              cobra.MinimumNArgs(1),
+		RunE:              generateAction,
+		ValidArgsFunction: generateShellComplete,
+		SilenceUsage:      true,
+		SilenceErrors:     true,
+	}
+	cmd.Flags().StringP("type", "t", kube.TypePod, `Kind of the generated object ("pod"|"deployment")`)
+	cmd.RegisterFlagCompletionFunc("type", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{kube.TypePod, kube.TypeDeployment}, cobra.ShellCompDirectiveNoFileComp
+	})
+	cmd.Flags().String("name", "", "Name of the generated object (default: the name of the pod, or of the first container suffixed with \"-pod\")")
+	cmd.Flags().Int32("replicas", 1, "Number of replicas of the generated deployment")
+	return cmd
+}
+
+func generateOptions(cmd *cobra.Command) (types.KubeGenerateOptions, error) {
+	globalOptions, err := helpers.ProcessRootCmdFlags(cmd)
+	if err != nil {
+		return types.KubeGenerateOptions{}, err
+	}
+	typ, err := cmd.Flags().GetString("type")
+	if err != nil {
+		return types.KubeGenerateOptions{}, err
+	}
+	name, err := cmd.Flags().GetString("name")
+	if err != nil {
+		return types.KubeGenerateOptions{}, err
+	}
+	replicas, err := cmd.Flags().GetInt32("replicas")
+	if err != nil {
+		return types.KubeGenerateOptions{}, err
+	}
+	return types.KubeGenerateOptions{
+		Stdout:   cmd.OutOrStdout(),
+		GOptions: globalOptions,
+		Type:     typ,
+		Name:     name,
+		Replicas: replicas,
+	}, nil
+}
+
+func generateAction(cmd *cobra.Command, args []string) error {
+	options, err := generateOptions(cmd)
+	if err != nil {
+		return err
+	}
+	client, ctx, cancel, err := clientutil.NewClient(cmd.Context(), options.GOptions.Namespace, options.GOptions.Address)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	return kube.Generate(ctx, client, args, options)
+}
+
+func generateShellComplete(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	containers, _ := completion.ContainerNames(cmd, nil)
+	pods, _ := completion.PodNames(cmd)
+	return append(containers, pods...), cobra.ShellCompDirectiveNoFileComp
+}
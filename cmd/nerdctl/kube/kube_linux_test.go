@@ -0,0 +1,171 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package kube
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/containerd/nerdctl/mod/tigron/expect"
+	"github.com/containerd/nerdctl/mod/tigron/require"
+	"github.com/containerd/nerdctl/mod/tigron/test"
+	"github.com/containerd/nerdctl/mod/tigron/tig"
+
+	"github.com/containerd/nerdctl/v2/pkg/testutil"
+	"github.com/containerd/nerdctl/v2/pkg/testutil/nerdtest"
+)
+
+func TestKubeGenerate(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Require = require.Not(nerdtest.Docker)
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		helpers.Ensure("volume", "create", data.Identifier())
+		helpers.Ensure("create", "--name", data.Identifier(), "-p", "8080:80", "-e", "FOO=bar",
+			"-v", data.Identifier()+":/data", "--memory", "64m", testutil.CommonImage, "sleep", nerdtest.Infinity)
+	}
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("rm", "-f", data.Identifier())
+		helpers.Anyhow("volume", "rm", "-f", data.Identifier())
+	}
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "pod",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("kube", "generate", data.Identifier())
+			},
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					Output: expect.Contains(
+						"kind: Pod",
+						"name: "+data.Identifier()+"-pod",
+						"containerPort: 80",
+						"hostPort: 8080",
+						"value: bar",
+						"claimName: "+data.Identifier(),
+						"memory: 64Mi",
+					),
+				}
+			},
+		},
+		{
+			Description: "deployment",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("kube", "generate", "--type", "deployment", "--name", "foo", "--replicas", "2", data.Identifier())
+			},
+			Expected: test.Expects(expect.ExitCodeSuccess, nil, expect.Contains("kind: Deployment", "name: foo", "replicas: 2")),
+		},
+	}
+
+	testCase.Run(t)
+}
+
+const playManifest = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: %[1]s
+data:
+  greeting: hello
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: %[1]s
+spec:
+  volumes:
+  - name: config
+    configMap:
+      name: %[1]s
+  - name: shared
+    emptyDir: {}
+  containers:
+  - name: main
+    image: %[2]s
+    command: ["sleep", "%[3]s"]
+    env:
+    - name: GREETING
+      valueFrom:
+        configMapKeyRef:
+          name: %[1]s
+          key: greeting
+    volumeMounts:
+    - name: config
+      mountPath: /config
+    - name: shared
+      mountPath: /shared
+`
+
+func TestKubePlayDown(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Require = require.Not(nerdtest.Docker)
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		manifest := fmt.Sprintf(playManifest, data.Identifier(), testutil.CommonImage, nerdtest.Infinity)
+		data.Labels().Set("manifest", data.Temp().Save(manifest, "pod.yaml"))
+		helpers.Ensure("kube", "play", data.Labels().Get("manifest"))
+	}
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("kube", "down", data.Labels().Get("manifest"))
+	}
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "configmap is exposed as environment variables and files",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("exec", data.Identifier()+"-main", "sh", "-c", "echo $GREETING; cat /config/greeting")
+			},
+			Expected: test.Expects(expect.ExitCodeSuccess, nil, expect.Equals("hello\nhello")),
+		},
+		{
+			Description: "emptyDir is a named volume",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("volume", "inspect", data.Identifier()+"-shared")
+			},
+			Expected: test.Expects(expect.ExitCodeSuccess, nil, nil),
+		},
+		{
+			Description: "play fails on existing pods",
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("kube", "play", data.Labels().Get("manifest"))
+			},
+			Expected: test.Expects(expect.ExitCodeGenericFail, nil, nil),
+		},
+		{
+			Description: "down removes the pod and its volumes",
+			NoParallel:  true,
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return helpers.Command("kube", "down", data.Labels().Get("manifest"))
+			},
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					Output: func(stdout string, t tig.T) {
+						helpers.Fail("pod", "inspect", data.Identifier())
+						helpers.Fail("inspect", data.Identifier()+"-main")
+						helpers.Fail("volume", "inspect", data.Identifier()+"-shared")
+					},
+				}
+			},
+		},
+	}
+
+	testCase.Run(t)
+}
@@ -0,0 +1,97 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package kube
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/completion"
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/kube"
+)
+
+func playCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "play [flags] FILE",
+		Short: "Create pods, volumes and containers from a Kubernetes manifest",
+		Long: `Create pods, volumes and containers from a Kubernetes manifest.
+
+Pods, deployments (with a single replica) and configmaps are supported. Use "-" to read the manifest from the standard input.`,
+		Args:          helpers.IsExactArgs(1),
+		RunE:          playAction,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	cmd.Flags().Bool("replace", false, "Remove the existing pods of the manifest before creating them")
+	cmd.Flags().Bool("start", true, "Start the containers")
+	cmd.Flags().StringSlice("network", nil, "Connect the pods to a network")
+	cmd.RegisterFlagCompletionFunc("network", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return completion.NetworkNames(cmd, []string{})
+	})
+	return cmd
+}
+
+func playOptions(cmd *cobra.Command) (types.KubePlayOptions, error) {
+	globalOptions, err := helpers.ProcessRootCmdFlags(cmd)
+	if err != nil {
+		return types.KubePlayOptions{}, err
+	}
+	replace, err := cmd.Flags().GetBool("replace")
+	if err != nil {
+		return types.KubePlayOptions{}, err
+	}
+	start, err := cmd.Flags().GetBool("start")
+	if err != nil {
+		return types.KubePlayOptions{}, err
+	}
+	networks, err := cmd.Flags().GetStringSlice("network")
+	if err != nil {
+		return types.KubePlayOptions{}, err
+	}
+	options := types.KubePlayOptions{
+		Stdout:   cmd.OutOrStdout(),
+		Stderr:   cmd.ErrOrStderr(),
+		GOptions: globalOptions,
+		Replace:  replace,
+		Start:    start,
+		Networks: networks,
+	}
+	options.NerdctlCmd, options.NerdctlArgs = helpers.GlobalFlags(cmd)
+	return options, nil
+}
+
+func playAction(cmd *cobra.Command, args []string) error {
+	options, err := playOptions(cmd)
+	if err != nil {
+		return err
+	}
+	f, err := openManifest(cmd, args[0])
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	client, ctx, cancel, err := clientutil.NewClient(cmd.Context(), options.GOptions.Namespace, options.GOptions.Address)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	return kube.Play(ctx, client, f, options)
+}
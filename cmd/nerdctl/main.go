@@ -34,15 +34,18 @@ import (
 	"github.com/containerd/nerdctl/v2/cmd/nerdctl/completion"
 	"github.com/containerd/nerdctl/v2/cmd/nerdctl/compose"
 	"github.com/containerd/nerdctl/v2/cmd/nerdctl/container"
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/generate"
 	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
 	"github.com/containerd/nerdctl/v2/cmd/nerdctl/image"
 	"github.com/containerd/nerdctl/v2/cmd/nerdctl/inspect"
 	"github.com/containerd/nerdctl/v2/cmd/nerdctl/internal"
 	"github.com/containerd/nerdctl/v2/cmd/nerdctl/ipfs"
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/kube"
 	"github.com/containerd/nerdctl/v2/cmd/nerdctl/login"
 	"github.com/containerd/nerdctl/v2/cmd/nerdctl/manifest"
 	"github.com/containerd/nerdctl/v2/cmd/nerdctl/namespace"
 	"github.com/containerd/nerdctl/v2/cmd/nerdctl/network"
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/pod"
 	"github.com/containerd/nerdctl/v2/cmd/nerdctl/system"
 	"github.com/containerd/nerdctl/v2/cmd/nerdctl/volume"
 	"github.com/containerd/nerdctl/v2/pkg/config"
@@ -192,6 +195,11 @@ func initRootCmdFlags(rootCmd *cobra.Command, tomlPath string) (*pflag.FlagSet,
 	helpers.HiddenPersistentStringArrayFlag(rootCmd, "global-dns", cfg.DNS, "Global DNS servers for containers")
 	helpers.HiddenPersistentStringArrayFlag(rootCmd, "global-dns-opts", cfg.DNSOpts, "Global DNS options for containers")
 	helpers.HiddenPersistentStringArrayFlag(rootCmd, "global-dns-search", cfg.DNSSearch, "Global DNS search domains for containers")
+	defaultAddressPools := make([]string, len(cfg.DefaultAddressPools))
+	for i, p := range cfg.DefaultAddressPools {
+		defaultAddressPools[i] = p.String()
+	}
+	rootCmd.PersistentFlags().StringArray("default-address-pool", defaultAddressPools, `Default address pool for allocating network subnets, e.g., "base=10.10.0.0/16,size=24" (can be specified multiple times)`)
 	return aliasToBeInherited, nil
 }
 
@@ -328,6 +336,9 @@ Config file ($NERDCTL_TOML): %s
 		image.Command(),
 		network.Command(),
 		volume.Command(),
+		pod.Command(),
+		kube.Command(),
+		generate.Command(),
 		system.Command(),
 		namespace.Command(),
 		builder.Command(),
@@ -0,0 +1,117 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package pod
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/completion"
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/pod"
+)
+
+func createCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:           "create [flags] NAME",
+		Short:         "Create and start a pod, with an infra container owning the namespaces shared by its containers",
+		Args:          helpers.IsExactArgs(1),
+		RunE:          createAction,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	cmd.Flags().String("infra-image", pod.DefaultInfraImage, "Image of the infra container")
+	cmd.Flags().StringSlice("share", pod.DefaultShare, `Namespaces shared by the containers of the pod ("net", "ipc", "pid")`)
+	cmd.Flags().StringArrayP("label", "l", nil, "Set metadata on the pod")
+	cmd.Flags().StringSliceP("publish", "p", nil, "Publish a port of the pod to the host")
+	cmd.Flags().StringSlice("network", nil, "Connect the pod to a network")
+	cmd.RegisterFlagCompletionFunc("network", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return completion.NetworkNames(cmd, []string{})
+	})
+	cmd.Flags().String("hostname", "", "Hostname of the pod (default: the name of the pod)")
+	cmd.Flags().StringSlice("dns", nil, "Set custom DNS servers")
+	cmd.Flags().StringSlice("dns-search", nil, "Set custom DNS search domains")
+	cmd.Flags().StringSlice("dns-option", nil, "Set DNS options")
+	cmd.Flags().StringSlice("add-host", nil, "Add a custom host-to-IP mapping (host:ip)")
+	cmd.Flags().String("ip", "", "IPv4 address of the pod")
+	cmd.Flags().String("ip6", "", "IPv6 address of the pod")
+	cmd.Flags().String("mac-address", "", "MAC address of the pod")
+	return cmd
+}
+
+func createOptions(cmd *cobra.Command, args []string) (types.PodCreateOptions, error) {
+	globalOptions, err := helpers.ProcessRootCmdFlags(cmd)
+	if err != nil {
+		return types.PodCreateOptions{}, err
+	}
+	options := types.PodCreateOptions{
+		Stdout:   cmd.OutOrStdout(),
+		Stderr:   cmd.ErrOrStderr(),
+		GOptions: globalOptions,
+		Name:     args[0],
+	}
+	options.NerdctlCmd, options.NerdctlArgs = helpers.GlobalFlags(cmd)
+	for _, f := range []struct {
+		name  string
+		value *string
+	}{
+		{"infra-image", &options.InfraImage},
+		{"hostname", &options.Hostname},
+		{"ip", &options.IP},
+		{"ip6", &options.IP6},
+		{"mac-address", &options.MACAddress},
+	} {
+		if *f.value, err = cmd.Flags().GetString(f.name); err != nil {
+			return types.PodCreateOptions{}, err
+		}
+	}
+	for _, f := range []struct {
+		name  string
+		value *[]string
+	}{
+		{"share", &options.Share},
+		{"publish", &options.Publish},
+		{"network", &options.Networks},
+		{"dns", &options.DNS},
+		{"dns-search", &options.DNSSearch},
+		{"dns-option", &options.DNSOptions},
+		{"add-host", &options.AddHost},
+	} {
+		if *f.value, err = cmd.Flags().GetStringSlice(f.name); err != nil {
+			return types.PodCreateOptions{}, err
+		}
+	}
+	if options.Labels, err = cmd.Flags().GetStringArray("label"); err != nil {
+		return types.PodCreateOptions{}, err
+	}
+	return options, nil
+}
+
+func createAction(cmd *cobra.Command, args []string) error {
+	options, err := createOptions(cmd, args)
+	if err != nil {
+		return err
+	}
+	client, ctx, cancel, err := clientutil.NewClient(cmd.Context(), options.GOptions.Namespace, options.GOptions.Address)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	return pod.Create(ctx, client, options)
+}
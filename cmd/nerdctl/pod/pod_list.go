@@ -0,0 +1,85 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package pod
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/pod"
+)
+
+func listCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:           "ps [flags]",
+		Aliases:       []string{"ls", "list"},
+		Short:         "List pods",
+		Args:          cobra.NoArgs,
+		RunE:          listAction,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	cmd.Flags().BoolP("quiet", "q", false, "Only display pod IDs")
+	cmd.Flags().Bool("no-trunc", false, "Don't truncate output")
+	cmd.Flags().String("format", "", "Format the output using the given Go template, e.g, '{{json .}}'")
+	cmd.RegisterFlagCompletionFunc("format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"json", "table", "wide"}, cobra.ShellCompDirectiveNoFileComp
+	})
+	return cmd
+}
+
+func listOptions(cmd *cobra.Command) (types.PodListOptions, error) {
+	globalOptions, err := helpers.ProcessRootCmdFlags(cmd)
+	if err != nil {
+		return types.PodListOptions{}, err
+	}
+	quiet, err := cmd.Flags().GetBool("quiet")
+	if err != nil {
+		return types.PodListOptions{}, err
+	}
+	noTrunc, err := cmd.Flags().GetBool("no-trunc")
+	if err != nil {
+		return types.PodListOptions{}, err
+	}
+	format, err := cmd.Flags().GetString("format")
+	if err != nil {
+		return types.PodListOptions{}, err
+	}
+	return types.PodListOptions{
+		Stdout:   cmd.OutOrStdout(),
+		GOptions: globalOptions,
+		Quiet:    quiet,
+		NoTrunc:  noTrunc,
+		Format:   format,
+	}, nil
+}
+
+func listAction(cmd *cobra.Command, args []string) error {
+	options, err := listOptions(cmd)
+	if err != nil {
+		return err
+	}
+	client, ctx, cancel, err := clientutil.NewClient(cmd.Context(), options.GOptions.Namespace, options.GOptions.Address)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	return pod.List(ctx, client, options)
+}
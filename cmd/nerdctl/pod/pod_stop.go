@@ -0,0 +1,77 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package pod
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/pod"
+)
+
+func stopCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "stop [flags] POD [POD...]",
+		Short:             "Stop one or more pods",
+		Args:              cobra.MinimumNArgs(1),
+		RunE:              stopAction,
+		ValidArgsFunction: podShellComplete,
+		SilenceUsage:      true,
+		SilenceErrors:     true,
+	}
+	cmd.Flags().IntP("time", "t", 10, "Seconds to wait before sending a SIGKILL to each container")
+	return cmd
+}
+
+func stopOptions(cmd *cobra.Command) (types.PodStopOptions, error) {
+	globalOptions, err := helpers.ProcessRootCmdFlags(cmd)
+	if err != nil {
+		return types.PodStopOptions{}, err
+	}
+	var timeout *time.Duration
+	if cmd.Flags().Changed("time") {
+		timeValue, err := cmd.Flags().GetInt("time")
+		if err != nil {
+			return types.PodStopOptions{}, err
+		}
+		t := time.Duration(timeValue) * time.Second
+		timeout = &t
+	}
+	return types.PodStopOptions{
+		Stdout:   cmd.OutOrStdout(),
+		GOptions: globalOptions,
+		Timeout:  timeout,
+	}, nil
+}
+
+func stopAction(cmd *cobra.Command, args []string) error {
+	options, err := stopOptions(cmd)
+	if err != nil {
+		return err
+	}
+	client, ctx, cancel, err := clientutil.NewClient(cmd.Context(), options.GOptions.Namespace, options.GOptions.Address)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	return pod.Stop(ctx, client, args, options)
+}
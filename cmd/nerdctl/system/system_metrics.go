@@ -0,0 +1,87 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package system
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/system"
+)
+
+func metricsCommand() *cobra.Command {
+	shortHelp := "Serve the metrics of the containers, images, volumes and networks in the Prometheus format"
+	longHelp := shortHelp + `
+
+The metrics are served on the "/metrics" path, in the Prometheus text format or in the OpenMetrics format.
+The statistics of the containers are sampled on each scrape.`
+	var cmd = &cobra.Command{
+		Use:           "metrics",
+		Args:          cobra.NoArgs,
+		Short:         shortHelp,
+		Long:          longHelp,
+		RunE:          metricsAction,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	cmd.Flags().String("listen", ":9323", "TCP address to serve the metrics on")
+	cmd.Flags().Bool("all-namespaces", false, "Serve the metrics of all the namespaces")
+	return cmd
+}
+
+func metricsOptions(cmd *cobra.Command) (types.SystemMetricsOptions, error) {
+	globalOptions, err := helpers.ProcessRootCmdFlags(cmd)
+	if err != nil {
+		return types.SystemMetricsOptions{}, err
+	}
+	listen, err := cmd.Flags().GetString("listen")
+	if err != nil {
+		return types.SystemMetricsOptions{}, err
+	}
+	allNamespaces, err := cmd.Flags().GetBool("all-namespaces")
+	if err != nil {
+		return types.SystemMetricsOptions{}, err
+	}
+	return types.SystemMetricsOptions{
+		Stdout:        cmd.OutOrStdout(),
+		GOptions:      globalOptions,
+		Listen:        listen,
+		AllNamespaces: allNamespaces,
+	}, nil
+}
+
+func metricsAction(cmd *cobra.Command, args []string) error {
+	options, err := metricsOptions(cmd)
+	if err != nil {
+		return err
+	}
+	client, ctx, cancel, err := clientutil.NewClient(cmd.Context(), options.GOptions.Namespace, options.GOptions.Address)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return system.Metrics(ctx, client, options)
+}
@@ -0,0 +1,98 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package system
+
+import (
+	"os"
+	"regexp"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/nerdctl/mod/tigron/expect"
+	"github.com/containerd/nerdctl/mod/tigron/require"
+	"github.com/containerd/nerdctl/mod/tigron/test"
+
+	"github.com/containerd/nerdctl/v2/pkg/testutil"
+	"github.com/containerd/nerdctl/v2/pkg/testutil/nerdtest"
+)
+
+func TestSystemMetrics(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	var server test.TestableCommand
+
+	testCase.Require = require.All(
+		require.Linux,
+		require.Not(nerdtest.Docker),
+		require.Binary("curl"),
+	)
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		helpers.Ensure("run", "-d", "--name", data.Identifier(), "--label", "com.docker.compose.project=proj",
+			"--health-cmd", "true", "--health-interval", "1s", testutil.CommonImage, "sleep", nerdtest.Infinity)
+		// listen on a free port, and read it from the address printed by the server
+		out := data.Temp().Path("metrics.out")
+		server = helpers.Command("system", "metrics", "--listen", "127.0.0.1:0")
+		server.WithWrapper("sh", "-c", `exec "$@" >"$0"`, out)
+		server.WithTimeout(time.Minute)
+		server.Background()
+		servingRegexp := regexp.MustCompile(`Serving the metrics on (http://\S+)`)
+		for i := 0; i < 50 && data.Labels().Get("url") == ""; i++ {
+			time.Sleep(100 * time.Millisecond)
+			if b, err := os.ReadFile(out); err == nil {
+				if m := servingRegexp.FindSubmatch(b); m != nil {
+					data.Labels().Set("url", string(m[1]))
+				}
+			}
+		}
+		assert.Assert(helpers.T(), data.Labels().Get("url") != "", "the metrics server did not start")
+		// let the health check run
+		time.Sleep(2 * time.Second)
+	}
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		if server != nil {
+			server.Signal(os.Interrupt)
+		}
+		helpers.Anyhow("rm", "-f", data.Identifier())
+	}
+
+	testCase.Command = func(data test.Data, helpers test.Helpers) test.TestableCommand {
+		return helpers.Custom("curl", "-sSf", data.Labels().Get("url"))
+	}
+
+	testCase.Expected = func(data test.Data, helpers test.Helpers) *test.Expected {
+		return &test.Expected{
+			Output: expect.Contains(
+				"# TYPE nerdctl_container_running gauge",
+				`compose_project="proj"`,
+				`name="`+data.Identifier()+`"`,
+				"nerdctl_container_memory_usage_bytes{",
+				"# TYPE nerdctl_container_cpu_usage_seconds_total counter",
+				"# TYPE nerdctl_container_network_receive_bytes_total counter",
+				`nerdctl_container_health_status{`,
+				`status="healthy"} 1`,
+				`nerdctl_containers{namespace="`,
+				"nerdctl_images{",
+			),
+		}
+	}
+
+	testCase.Run(t)
+}
@@ -44,6 +44,7 @@ func pruneCommand() *cobra.Command {
 	cmd.Flags().BoolP("all", "a", false, "Remove all unused images, not just dangling ones")
 	cmd.Flags().BoolP("force", "f", false, "Do not prompt for confirmation")
 	cmd.Flags().Bool("volumes", false, "Prune volumes")
+	cmd.Flags().StringSlice("filter", []string{}, "Provide filter values (e.g., 'until=24h', 'label=foo=bar')")
 	return cmd
 }
 
@@ -63,6 +64,11 @@ func pruneOptions(cmd *cobra.Command) (types.SystemPruneOptions, error) {
 		return types.SystemPruneOptions{}, err
 	}
 
+	filters, err := cmd.Flags().GetStringSlice("filter")
+	if err != nil {
+		return types.SystemPruneOptions{}, err
+	}
+
 	buildkitHost, err := builder.GetBuildkitHost(cmd, globalOptions.Namespace)
 	if err != nil {
 		log.L.WithError(err).Warn("BuildKit is not running. Build caches will not be pruned.")
@@ -77,6 +83,7 @@ func pruneOptions(cmd *cobra.Command) (types.SystemPruneOptions, error) {
 		Volumes:              vFlag,
 		BuildKitHost:         buildkitHost,
 		NetworkDriversToKeep: network.NetworkDriversToKeep,
+		Filters:              filters,
 	}, nil
 }
 
@@ -0,0 +1,109 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package system
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/container"
+	"github.com/containerd/nerdctl/v2/cmd/nerdctl/helpers"
+	"github.com/containerd/nerdctl/v2/pkg/apiserver"
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
+)
+
+func serviceCommand() *cobra.Command {
+	shortHelp := "Serve a subset of the Docker Engine API"
+	longHelp := shortHelp + `
+
+The API is served on a unix socket, or on a tcp address.
+A tcp address is served with TLS, and the clients must present a certificate signed by --tlscacert,
+unless --insecure-tcp is set.
+The versions ` + apiserver.MinAPIVersion + ` to ` + apiserver.APIVersion + ` of the API are supported.
+NOTE: Only the endpoints of containers, images, networks, volumes, events and system information are implemented.`
+	var cmd = &cobra.Command{
+		Use:           "service",
+		Args:          cobra.NoArgs,
+		Short:         shortHelp,
+		Long:          longHelp,
+		RunE:          serviceAction,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	cmd.Flags().String("host", apiserver.DefaultHost(), "Address to listen on (unix:///path or tcp://host:port)")
+	cmd.Flags().String("tlscacert", "", "CA certificate that the client certificates are verified with (tcp only)")
+	cmd.Flags().String("tlscert", "", "TLS certificate of the server (tcp only)")
+	cmd.Flags().String("tlskey", "", "TLS key of the server (tcp only)")
+	cmd.Flags().Bool("insecure-tcp", false, "Serve the API on a tcp address without TLS. Anyone reaching the address can run containers")
+	return cmd
+}
+
+func serviceAction(cmd *cobra.Command, args []string) error {
+	globalOptions, err := helpers.ProcessRootCmdFlags(cmd)
+	if err != nil {
+		return err
+	}
+	host, err := cmd.Flags().GetString("host")
+	if err != nil {
+		return err
+	}
+	var listenOptions apiserver.ListenOptions
+	listenOptions.TLSCACert, err = cmd.Flags().GetString("tlscacert")
+	if err != nil {
+		return err
+	}
+	listenOptions.TLSCert, err = cmd.Flags().GetString("tlscert")
+	if err != nil {
+		return err
+	}
+	listenOptions.TLSKey, err = cmd.Flags().GetString("tlskey")
+	if err != nil {
+		return err
+	}
+	listenOptions.InsecureTCP, err = cmd.Flags().GetBool("insecure-tcp")
+	if err != nil {
+		return err
+	}
+	client, ctx, cancel, err := clientutil.NewClient(cmd.Context(), globalOptions.Namespace, globalOptions.Address)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	root := cmd.Root()
+	srv := apiserver.New(client, apiserver.Options{
+		GOptions: globalOptions,
+		CreateContainer: func(ctx context.Context, args []string) (string, error) {
+			return container.CreateFromArgs(ctx, root, args)
+		},
+	})
+	l, err := apiserver.Listen(host, listenOptions)
+	if err != nil {
+		return err
+	}
+	log.G(ctx).Infof("API listening on %s", host)
+	return srv.Serve(ctx, l)
+}
@@ -0,0 +1,282 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package system
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/containerd/nerdctl/mod/tigron/expect"
+	"github.com/containerd/nerdctl/mod/tigron/require"
+	"github.com/containerd/nerdctl/mod/tigron/test"
+	"github.com/containerd/nerdctl/mod/tigron/tig"
+
+	"github.com/containerd/nerdctl/v2/pkg/testutil"
+	"github.com/containerd/nerdctl/v2/pkg/testutil/nerdtest"
+)
+
+func TestSystemService(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	var (
+		service test.TestableCommand
+		sock    string
+	)
+
+	curl := func(helpers test.Helpers, args ...string) test.TestableCommand {
+		return helpers.Custom("curl", append([]string{"-sS", "--unix-socket", sock}, args...)...)
+	}
+
+	testCase.Require = require.All(
+		require.Linux,
+		require.Not(nerdtest.Docker),
+		require.Binary("curl"),
+	)
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		helpers.Ensure("pull", "--quiet", testutil.CommonImage)
+		sock = filepath.Join(data.Temp().Path(), "api.sock")
+		service = helpers.Command("system", "service", "--host", "unix://"+sock)
+		service.WithTimeout(5 * time.Minute)
+		service.Background()
+		// let the server listen
+		for range 50 {
+			if _, err := os.Stat(sock); err == nil {
+				break
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+	}
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		if service != nil {
+			service.Signal(os.Interrupt)
+		}
+	}
+
+	testCase.SubTests = []*test.Case{
+		{
+			Description: "ping with the supported versions",
+			NoParallel:  true,
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return curl(helpers,
+					"http://localhost/_ping", "-w", "\n",
+					"http://localhost/v1.24/_ping", "-w", "\n",
+					"http://localhost/v1.41/_ping", "-w", "\n",
+					"http://localhost/v1.44/_ping", "-w", "\n",
+				)
+			},
+			Expected: test.Expects(0, nil, expect.Equals("OK\nOK\nOK\nOK\n")),
+		},
+		{
+			Description: "unsupported versions",
+			NoParallel:  true,
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return curl(helpers, "http://localhost/v1.23/version", "-w", "\n", "http://localhost/v1.99/version")
+			},
+			Expected: test.Expects(0, nil, expect.Contains("is too old", "is too new")),
+		},
+		{
+			Description: "version",
+			NoParallel:  true,
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return curl(helpers, "http://localhost/v1.41/version")
+			},
+			Expected: test.Expects(0, nil, expect.Contains(`"ApiVersion":"1.44"`, `"MinAPIVersion":"1.24"`)),
+		},
+		{
+			Description: "unknown container",
+			NoParallel:  true,
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return curl(helpers, "-o", "/dev/null", "-w", "%{http_code}", "http://localhost/v1.41/containers/"+data.Identifier()+"/json")
+			},
+			Expected: test.Expects(0, nil, expect.Equals("404")),
+		},
+		{
+			Description: "container lifecycle",
+			NoParallel:  true,
+			Setup: func(data test.Data, helpers test.Helpers) {
+				body := `{"Image":"` + testutil.CommonImage + `","Cmd":["echo","hello-api"],"Labels":{"foo":"bar"}}`
+				curl(helpers, "-f", "-X", "POST", "-H", "Content-Type: application/json", "-d", body,
+					"http://localhost/v1.41/containers/create?name="+data.Identifier()).Run(&test.Expected{
+					Output: expect.Contains(`"Id":"`),
+				})
+				curl(helpers, "-f", "-X", "POST", "http://localhost/v1.41/containers/"+data.Identifier()+"/start").Run(&test.Expected{})
+				curl(helpers, "-f", "-X", "POST", "http://localhost/v1.41/containers/"+data.Identifier()+"/wait").Run(&test.Expected{
+					Output: expect.Contains(`"StatusCode":0`),
+				})
+			},
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("rm", "-f", data.Identifier())
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return curl(helpers, "-f", "http://localhost/v1.41/containers/"+data.Identifier()+"/logs?stdout=1")
+			},
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					Output: expect.All(
+						expect.Contains("hello-api"),
+						func(stdout string, t tig.T) {
+							cmd := curl(helpers, "-f", "http://localhost/v1.41/containers/"+data.Identifier()+"/json")
+							cmd.Run(&test.Expected{
+								Output: expect.Contains(`"Status":"exited"`, `"foo":"bar"`),
+							})
+							cmd = curl(helpers, "-f", "http://localhost/v1.41/containers/json?all=1&filters=%7B%22label%22%3A%5B%22foo%3Dbar%22%5D%7D")
+							cmd.Run(&test.Expected{
+								Output: expect.Contains(`"/` + data.Identifier() + `"`),
+							})
+							cmd = curl(helpers, "-o", "/dev/null", "-w", "%{http_code}", "-X", "DELETE", "http://localhost/v1.41/containers/"+data.Identifier())
+							cmd.Run(&test.Expected{
+								Output: expect.Equals("204"),
+							})
+						},
+					),
+				}
+			},
+		},
+		{
+			Description: "volumes",
+			NoParallel:  true,
+			Setup: func(data test.Data, helpers test.Helpers) {
+				curl(helpers, "-f", "-X", "POST", "-H", "Content-Type: application/json", "-d", `{"Name":"`+data.Identifier()+`"}`,
+					"http://localhost/v1.41/volumes/create").Run(&test.Expected{})
+			},
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("volume", "rm", "-f", data.Identifier())
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return curl(helpers, "-f", "http://localhost/v1.41/volumes")
+			},
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					Output: expect.Contains(`"Name":"`+data.Identifier()+`"`, `"Driver":"local"`),
+				}
+			},
+		},
+		{
+			Description: "images",
+			NoParallel:  true,
+			Setup: func(data test.Data, helpers test.Helpers) {
+				curl(helpers, "-f", "-X", "POST", "http://localhost/v1.41/images/create?fromImage="+url.QueryEscape(testutil.CommonImage)).Run(&test.Expected{
+					Output: expect.Contains("Status: Downloaded newer image for " + testutil.CommonImage),
+				})
+				helpers.Ensure("tag", testutil.CommonImage, data.Identifier()+":latest")
+			},
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("rmi", "-f", data.Identifier()+":latest")
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return curl(helpers, "-f", "http://localhost/v1.41/images/json")
+			},
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					Output: expect.All(
+						expect.Contains(`"`+data.Identifier()+`:latest"`),
+						func(stdout string, t tig.T) {
+							cmd := curl(helpers, "-f", "http://localhost/v1.41/images/"+data.Identifier()+":latest/json")
+							cmd.Run(&test.Expected{
+								Output: expect.Contains(`"`+data.Identifier()+`:latest"`, `"Os":"linux"`),
+							})
+							cmd = curl(helpers, "-o", "/dev/null", "-w", "%{http_code}", "http://localhost/v1.41/images/"+data.Identifier()+"-unknown/json")
+							cmd.Run(&test.Expected{
+								Output: expect.Equals("404"),
+							})
+						},
+					),
+				}
+			},
+		},
+		{
+			Description: "networks",
+			NoParallel:  true,
+			Setup: func(data test.Data, helpers test.Helpers) {
+				curl(helpers, "-f", "-X", "POST", "-H", "Content-Type: application/json", "-d", `{"Name":"`+data.Identifier()+`"}`,
+					"http://localhost/v1.41/networks/create").Run(&test.Expected{
+					Output: expect.Contains(`"Id":"`),
+				})
+			},
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("network", "rm", data.Identifier())
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				return curl(helpers, "-f", "http://localhost/v1.41/networks")
+			},
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					Output: expect.All(
+						expect.Contains(`"Name":"`+data.Identifier()+`"`),
+						func(stdout string, t tig.T) {
+							cmd := curl(helpers, "-f", "http://localhost/v1.41/networks/"+data.Identifier())
+							cmd.Run(&test.Expected{
+								Output: expect.Contains(`"Name":"` + data.Identifier() + `"`),
+							})
+							cmd = curl(helpers, "-o", "/dev/null", "-w", "%{http_code}", "-X", "DELETE", "http://localhost/v1.41/networks/"+data.Identifier())
+							cmd.Run(&test.Expected{
+								Output: expect.Equals("204"),
+							})
+						},
+					),
+				}
+			},
+		},
+		{
+			Description: "events",
+			NoParallel:  true,
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("rm", "-f", data.Identifier())
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				filters := url.QueryEscape(`{"container":{"` + data.Identifier() + `":true}}`)
+				cmd := curl(helpers, "-N", "http://localhost/v1.41/events?filters="+filters)
+				cmd.WithTimeout(10 * time.Second)
+				cmd.Background()
+				// let the client subscribe
+				time.Sleep(time.Second)
+				helpers.Ensure("run", "--name", data.Identifier(), testutil.CommonImage, "true")
+				return cmd
+			},
+			Expected: func(data test.Data, helpers test.Helpers) *test.Expected {
+				return &test.Expected{
+					ExitCode: expect.ExitCodeTimeout,
+					Output:   expect.Contains(`"Action":"start"`, `"Action":"die"`, `"name":"`+data.Identifier()+`"`),
+				}
+			},
+		},
+		{
+			Description: "attach",
+			NoParallel:  true,
+			Setup: func(data test.Data, helpers test.Helpers) {
+				helpers.Ensure("run", "-d", "--name", data.Identifier(), testutil.CommonImage,
+					"sh", "-c", "echo hello-attach; sleep 3; echo bye-attach")
+			},
+			Cleanup: func(data test.Data, helpers test.Helpers) {
+				helpers.Anyhow("rm", "-f", data.Identifier())
+			},
+			Command: func(data test.Data, helpers test.Helpers) test.TestableCommand {
+				// the stream ends when the container exits
+				return curl(helpers, "-N", "-X", "POST",
+					"http://localhost/v1.41/containers/"+data.Identifier()+"/attach?logs=1&stream=1&stdout=1")
+			},
+			Expected: test.Expects(0, nil, expect.Contains("hello-attach", "bye-attach")),
+		},
+	}
+
+	testCase.Run(t)
+}
@@ -39,6 +39,7 @@ func pruneCommand() *cobra.Command {
 	}
 	cmd.Flags().BoolP("all", "a", false, "Remove all unused volumes, not just anonymous ones")
 	cmd.Flags().BoolP("force", "f", false, "Do not prompt for confirmation")
+	cmd.Flags().StringSlice("filter", []string{}, "Provide filter values (e.g., 'until=24h', 'label=foo=bar')")
 	return cmd
 }
 
@@ -58,11 +59,17 @@ func pruneOptions(cmd *cobra.Command) (types.VolumePruneOptions, error) {
 		return types.VolumePruneOptions{}, err
 	}
 
+	filters, err := cmd.Flags().GetStringSlice("filter")
+	if err != nil {
+		return types.VolumePruneOptions{}, err
+	}
+
 	options := types.VolumePruneOptions{
 		GOptions: globalOptions,
 		All:      all,
 		Force:    force,
 		Stdout:   cmd.OutOrStdout(),
+		Filters:  filters,
 	}
 	return options, nil
 }
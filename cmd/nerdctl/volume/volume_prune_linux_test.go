@@ -111,3 +111,36 @@ func TestVolumePrune(t *testing.T) {
 
 	testCase.Run(t)
 }
+
+func TestVolumePruneFilter(t *testing.T) {
+	testCase := nerdtest.Setup()
+
+	testCase.Setup = func(data test.Data, helpers test.Helpers) {
+		helpers.Ensure("volume", "create", "--label", data.Identifier()+"=1", data.Identifier("1"))
+		helpers.Ensure("volume", "create", "--label", data.Identifier()+"=2", data.Identifier("2"))
+	}
+
+	testCase.Cleanup = func(data test.Data, helpers test.Helpers) {
+		helpers.Anyhow("volume", "rm", "-f", data.Identifier("1"))
+		helpers.Anyhow("volume", "rm", "-f", data.Identifier("2"))
+	}
+
+	testCase.Command = func(data test.Data, helpers test.Helpers) test.TestableCommand {
+		return helpers.Command("volume", "prune", "-f", "--all", "--filter", "label="+data.Identifier()+"=1")
+	}
+
+	testCase.Expected = func(data test.Data, helpers test.Helpers) *test.Expected {
+		return &test.Expected{
+			Output: expect.All(
+				expect.Contains(data.Identifier("1")),
+				expect.DoesNotContain(data.Identifier("2")),
+				func(stdout string, t tig.T) {
+					helpers.Fail("volume", "inspect", data.Identifier("1"))
+					helpers.Ensure("volume", "inspect", data.Identifier("2"))
+				},
+			),
+		}
+	}
+
+	testCase.Run(t)
+}
@@ -39,7 +39,7 @@ type BuilderBuildOptions struct {
 	NoCache bool
 	// Output is the output destination
 	Output string
-	// Progress Set type of progress output (auto, plain, tty). Use plain to show container output
+	// Progress Set type of progress output (auto, plain, tty, rawjson, quiet). Use plain to show container output
 	Progress string
 	// Secret file to expose to the build: id=mysecret,src=/local/secret
 	Secret []string
@@ -86,4 +86,84 @@ type BuilderPruneOptions struct {
 	All bool
 	// Force will not prompt for confirmation.
 	Force bool
+	// Filters are the filters for selecting the build cache to prune (e.g., "until=24h", "type=regular")
+	Filters []string
+	// KeepStorage is the amount of disk space (in bytes) to keep for the build cache
+	KeepStorage int64
+}
+
+// BuilderDiskUsageOptions specifies options for `nerdctl builder du`.
+type BuilderDiskUsageOptions struct {
+	Stdout io.Writer
+	// GOptions is the global options
+	GOptions GlobalCommandOptions
+	// BuildKitHost is the buildkit host
+	BuildKitHost string
+	// Filters are the filters for selecting the build cache records (e.g., "until=24h", "type=regular")
+	Filters []string
+	// Verbose prints all the fields of each record
+	Verbose bool
+	// Format the output using the given Go template, e.g, '{{json .}}'
+	Format string
+}
+
+// BuilderBakeOptions specifies options for `nerdctl builder bake`.
+type BuilderBakeOptions struct {
+	Stdout io.Writer
+	Stderr io.Writer
+	// GOptions is the global options
+	GOptions GlobalCommandOptions
+	// BuildKitHost is the buildkit host
+	BuildKitHost string
+	// NerdctlCmd is the path of the nerdctl executable that runs each build
+	NerdctlCmd string
+	// NerdctlArgs are the global flags passed to each build
+	NerdctlArgs []string
+	// Files are the bake or compose files. The default files in the current directory are used when empty.
+	Files []string
+	// Targets are the targets or groups to build. The "default" group is built when empty.
+	Targets []string
+	// Print prints the resolved targets in JSON instead of building them
+	Print bool
+	// NoCache disables cache for all the targets
+	NoCache bool
+	// Pull always attempts to pull all the referenced images
+	Pull bool
+	// Progress Set type of progress output (auto, plain, tty, rawjson, quiet)
+	Progress string
+	// NoColor disables the colors of the target prefixes
+	NoColor bool
+	// Parallel is the maximum number of targets built concurrently, the number of CPUs when 0
+	Parallel int
+}
+
+// BuilderHistoryListOptions specifies options for `nerdctl builder history ls`.
+type BuilderHistoryListOptions struct {
+	Stdout io.Writer
+	// GOptions is the global options
+	GOptions GlobalCommandOptions
+	// Quiet only shows the build refs
+	Quiet bool
+	// Format the output using the given Go template, e.g, '{{json .}}'
+	Format string
+}
+
+// BuilderHistoryInspectOptions specifies options for `nerdctl builder history inspect`.
+type BuilderHistoryInspectOptions struct {
+	Stdout io.Writer
+	// GOptions is the global options
+	GOptions GlobalCommandOptions
+	// Format the output using the given Go template, e.g, '{{json .}}'
+	Format string
+	// Export writes the timings of the build to the file in the Trace Event Format instead of printing the record
+	Export string
+}
+
+// BuilderHistoryRemoveOptions specifies options for `nerdctl builder history rm`.
+type BuilderHistoryRemoveOptions struct {
+	Stdout io.Writer
+	// GOptions is the global options
+	GOptions GlobalCommandOptions
+	// All removes all the records
+	All bool
 }
@@ -49,14 +49,20 @@ type ContainerExportOptions struct {
 	Stdout io.Writer
 	// GOptions is the global options
 	GOptions GlobalCommandOptions
+	// Filter only exports the paths matching these glob patterns
+	Filter []string
+	// Exclude does not export the paths matching these glob patterns
+	Exclude []string
+	// ChangesOnly only exports the changes to the image layers, as an OCI layer tar
+	ChangesOnly bool
 }
 
 // ContainerCreateOptions specifies options for `nerdctl (container) create` and `nerdctl (container) run`.
 type ContainerCreateOptions struct {
-	Stdout io.Writer
-	Stderr io.Writer
+	Stdout io.Writer `json:"-"`
+	Stderr io.Writer `json:"-"`
 	// GOptions is the global options
-	GOptions GlobalCommandOptions
+	GOptions GlobalCommandOptions `json:"-"`
 
 	// NerdctlCmd is the command name of nerdctl
 	NerdctlCmd string
@@ -83,6 +89,10 @@ type ContainerCreateOptions struct {
 	Restart string
 	// Rm specifies whether to remove the container automatically when it exits
 	Rm bool
+	// Pod is the name of the pod (`nerdctl pod`) whose namespaces the container joins
+	Pod string
+	// CreateArgs are the flags and the arguments of the command creating the container, recorded to recreate it
+	CreateArgs []string
 	// Pull image before running, default is missing
 	Pull string
 	// Pid namespace to use
@@ -340,6 +350,8 @@ type ContainerPruneOptions struct {
 	Stdout io.Writer
 	// GOptions is the global options
 	GOptions GlobalCommandOptions
+	// Filters are the filters for selecting the containers to prune (e.g., "until=24h", "label=foo")
+	Filters []string
 }
 
 // ContainerUnpauseOptions specifies options for `nerdctl (container) unpause`.
@@ -363,6 +375,44 @@ type ContainerRenameOptions struct {
 	GOptions GlobalCommandOptions
 }
 
+// ContainerCloneOptions specifies options for `nerdctl container clone`.
+type ContainerCloneOptions struct {
+	Stdout io.Writer
+	Stderr io.Writer
+	// GOptions is the global options
+	GOptions GlobalCommandOptions
+	// Image overrides the image of the source container
+	Image string
+	// Env appends environment variables to the ones of the source container
+	Env []string
+	// Label appends labels to the ones of the source container
+	Label []string
+	// Publish replaces the published ports of the source container
+	Publish []string
+	// CPUs overrides the number of CPUs (0 keeps the source value)
+	CPUs float64
+	// Memory overrides the memory limit
+	Memory string
+	// Restart overrides the restart policy
+	Restart string
+	// Start starts the clone after creating it
+	Start bool
+	// Destroy removes the source container after cloning it
+	Destroy bool
+}
+
+// ContainerRecreateOptions specifies options for `nerdctl container recreate`.
+type ContainerRecreateOptions struct {
+	Stdout io.Writer
+	Stderr io.Writer
+	// GOptions is the global options
+	GOptions GlobalCommandOptions
+	// Pull always pulls the image before re-creating the container
+	Pull bool
+	// Timeout is the time to wait for the container to stop before killing it
+	Timeout *time.Duration
+}
+
 // ContainerTopOptions specifies options for `nerdctl top`.
 type ContainerTopOptions struct {
 	Stdout io.Writer
@@ -430,6 +480,12 @@ type ContainerDiffOptions struct {
 	Stdout io.Writer
 	// GOptions is the global options
 	GOptions GlobalCommandOptions
+	// Filter only shows the paths matching these glob patterns
+	Filter []string
+	// Exclude does not show the paths matching these glob patterns
+	Exclude []string
+	// Format the output using the given Go template (e.g., '{{json .}}')
+	Format string
 }
 
 // ContainerLogsOptions specifies options for `nerdctl (container) logs`.
@@ -451,6 +507,19 @@ type ContainerLogsOptions struct {
 	Until string
 	// Details specifies whether to show extra details provided to logs
 	Details bool
+	// Filters selects the containers whose logs are shown, e.g. "label=com.example.app", "name=web"
+	Filters []string
+	// Format is the output format of the logs: "" (prefixed with the container names when there are several containers),
+	// "json" (NDJSON with the container metadata and the parsed fields), or a Go template of the fields of the structured log lines
+	Format string
+	// Grep shows only the log lines matching a regular expression
+	Grep string
+	// Level shows only the structured log lines with a level at least as severe (e.g. "warn")
+	Level string
+	// NoColor disables the colors of the container name prefixes
+	NoColor bool
+	// NoLogPrefix disables the container name prefixes
+	NoLogPrefix bool
 }
 
 // ContainerWaitOptions specifies options for `nerdctl (container) wait`.
@@ -458,6 +527,12 @@ type ContainerWaitOptions struct {
 	Stdout io.Writer
 	// GOptions is the global options.
 	GOptions GlobalCommandOptions
+	// Condition is the state to wait for: "not-running" (default), "next-exit", "removed" or "healthy".
+	Condition string
+	// Timeout is the maximum duration to wait for. Zero means no timeout.
+	Timeout time.Duration
+	// Any returns as soon as one of the containers meets the condition, exiting with its exit code.
+	Any bool
 }
 
 // ContainerAttachOptions specifies options for `nerdctl (container) attach`.
@@ -491,6 +566,32 @@ type ContainerExecOptions struct {
 	Privileged bool
 	// Username or UID (format: <name|uid>[:<group|gid>])
 	User string
+	// DetachKeys is the key sequences to detach from the command.
+	DetachKeys string
+	// CapAdd are the capabilities to add to the command
+	CapAdd []string
+	// CapDrop are the capabilities to drop from the command
+	CapDrop []string
+}
+
+// ContainerExecListOptions specifies options for `nerdctl container exec-ls`.
+type ContainerExecListOptions struct {
+	Stdout io.Writer
+	// GOptions is the global options.
+	GOptions GlobalCommandOptions
+	// Only display the IDs of the exec sessions
+	Quiet bool
+	// Format the output using the given Go template (e.g., '{{json .}}')
+	Format string
+}
+
+// ContainerExecInspectOptions specifies options for `nerdctl container exec-inspect`.
+type ContainerExecInspectOptions struct {
+	Stdout io.Writer
+	// GOptions is the global options.
+	GOptions GlobalCommandOptions
+	// Format the output using the given Go template (e.g., '{{json .}}')
+	Format string
 }
 
 // ContainerListOptions specifies options for `nerdctl (container) list`.
@@ -512,6 +613,10 @@ type ContainerListOptions struct {
 
 // ContainerCpOptions specifies options for `nerdctl (container) cp`
 type ContainerCpOptions struct {
+	// Stdin is the tar archive read when SrcPath is "-".
+	Stdin io.Reader
+	// Stdout is where the tar archive is written when DestPath is "-".
+	Stdout io.Writer
 	// GOptions is the global options.
 	GOptions GlobalCommandOptions
 	// ContainerReq is name, short ID, or long ID of container to copy to/from.
@@ -523,6 +628,8 @@ type ContainerCpOptions struct {
 	SrcPath string
 	// Follow symbolic links in SRC_PATH
 	FollowSymLink bool
+	// Archive preserves the uid/gid of the copied files.
+	Archive bool
 }
 
 // ContainerStatsOptions specifies options for `nerdctl stats`.
@@ -533,7 +640,8 @@ type ContainerStatsOptions struct {
 	GOptions GlobalCommandOptions
 	// Show all containers (default shows just running).
 	All bool
-	// Pretty-print images using a Go template, e.g., {{json .}}.
+	// Format the output using the given Go template, e.g., {{json .}}.
+	// "json" (or "raw") prints a stream of JSON lines, with the raw counters along with the formatted fields.
 	Format string
 	// Disable streaming stats and only pull the first result.
 	NoStream bool
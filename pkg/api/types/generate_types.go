@@ -0,0 +1,47 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package types
+
+import "io"
+
+// GenerateSystemdOptions specifies options for `nerdctl generate systemd`.
+type GenerateSystemdOptions struct {
+	Stdout io.Writer
+	// GOptions is the global options
+	GOptions GlobalCommandOptions
+	// NerdctlCmd is the command name of nerdctl, run by the units
+	NerdctlCmd string
+	// NerdctlArgs is the global arguments of nerdctl, passed by the units
+	NerdctlArgs []string
+	// New creates the container when the unit starts, and removes it when the unit stops
+	New bool
+	// Quadlet generates Quadlet .container files instead of service units
+	Quadlet bool
+	// Files writes the units to files in the current directory, instead of printing them
+	Files bool
+	// RestartPolicy is the systemd restart policy of the units
+	RestartPolicy string
+	// RestartSec is the time to sleep before restarting the units, in seconds
+	RestartSec uint
+	// StopTimeout is the time to wait before killing the containers when stopping the units, in seconds.
+	// The stop timeout of the containers is used when nil.
+	StopTimeout *uint
+	// Wants, After and Requires are the additional dependencies of the units
+	Wants    []string
+	After    []string
+	Requires []string
+}
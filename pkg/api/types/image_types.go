@@ -210,12 +210,12 @@ type RemoteSnapshotterFlags struct {
 
 // ImagePullOptions specifies options for `nerdctl (image) pull`.
 type ImagePullOptions struct {
-	Stdout io.Writer
-	Stderr io.Writer
+	Stdout io.Writer `json:"-"`
+	Stderr io.Writer `json:"-"`
 	// ProgressOutputToStdout directs progress output to stdout instead of stderr
 	ProgressOutputToStdout bool
 
-	GOptions      GlobalCommandOptions
+	GOptions      GlobalCommandOptions `json:"-"`
 	VerifyOptions ImageVerifyOptions
 	// Unpack the image for the current single platform.
 	// If nil, it will unpack automatically if only 1 platform is specified.
@@ -0,0 +1,57 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package types
+
+import "io"
+
+// KubeGenerateOptions specifies options for `nerdctl kube generate`.
+type KubeGenerateOptions struct {
+	Stdout io.Writer
+	// GOptions is the global options
+	GOptions GlobalCommandOptions
+	// Type is the kind of the generated object ("pod" or "deployment")
+	Type string
+	// Name is the name of the generated object
+	Name string
+	// Replicas is the number of replicas of the generated deployment
+	Replicas int32
+}
+
+// KubePlayOptions specifies options for `nerdctl kube play`.
+type KubePlayOptions struct {
+	Stdout io.Writer
+	Stderr io.Writer
+	// GOptions is the global options
+	GOptions GlobalCommandOptions
+	// NerdctlCmd is the command name of nerdctl, used to create the containers
+	NerdctlCmd string
+	// NerdctlArgs is the global arguments of nerdctl, used to create the containers
+	NerdctlArgs []string
+	// Replace removes the existing pods of the manifest before creating them
+	Replace bool
+	// Networks are the networks the pods are connected to
+	Networks []string
+	// Start starts the containers after creating them
+	Start bool
+}
+
+// KubeDownOptions specifies options for `nerdctl kube down`.
+type KubeDownOptions struct {
+	Stdout io.Writer
+	// GOptions is the global options
+	GOptions GlobalCommandOptions
+}
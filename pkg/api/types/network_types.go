@@ -71,6 +71,8 @@ type NetworkPruneOptions struct {
 	GOptions GlobalCommandOptions
 	// Network drivers to keep while pruning
 	NetworkDriversToKeep []string
+	// Filters are the filters for selecting the networks to prune (e.g., "until=24h", "label=foo")
+	Filters []string
 }
 
 // NetworkRemoveOptions specifies options for `nerdctl network rm`.
@@ -0,0 +1,111 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package types
+
+import (
+	"io"
+	"time"
+)
+
+// PodCreateOptions specifies options for `nerdctl pod create`.
+type PodCreateOptions struct {
+	Stdout io.Writer
+	Stderr io.Writer
+	// GOptions is the global options
+	GOptions GlobalCommandOptions
+	// NerdctlCmd is the command name of nerdctl, used to create the infra container
+	NerdctlCmd string
+	// NerdctlArgs is the global arguments of nerdctl, used to create the infra container
+	NerdctlArgs []string
+	// Name is the name of the pod
+	Name string
+	// InfraImage is the image of the infra container, which owns the namespaces shared by the pod
+	InfraImage string
+	// Share is the list of the namespaces shared by the containers of the pod ("net", "ipc", "pid")
+	Share []string
+	// Labels are the labels of the pod
+	Labels []string
+	// The options below are passed to the infra container, as the members of the pod share its network namespace
+	// Publish are the ports published by the pod (e.g., "8080:80")
+	Publish []string
+	// Networks are the networks the pod is connected to
+	Networks []string
+	// Hostname is the hostname of the pod, the name of the pod by default
+	Hostname string
+	// DNS are the custom DNS servers
+	DNS []string
+	// DNSSearch are the custom DNS search domains
+	DNSSearch []string
+	// DNSOptions are the DNS options
+	DNSOptions []string
+	// AddHost are the custom host-to-IP mappings (host:ip)
+	AddHost []string
+	// IP is the static IP address of the pod
+	IP string
+	// IP6 is the static IPv6 address of the pod
+	IP6 string
+	// MACAddress is the MAC address of the pod
+	MACAddress string
+}
+
+// PodStartOptions specifies options for `nerdctl pod start`.
+type PodStartOptions struct {
+	Stdout io.Writer
+	// GOptions is the global options
+	GOptions GlobalCommandOptions
+}
+
+// PodStopOptions specifies options for `nerdctl pod stop`.
+type PodStopOptions struct {
+	Stdout io.Writer
+	// GOptions is the global options
+	GOptions GlobalCommandOptions
+	// Timeout specifies how long to wait after sending a SIGTERM and before sending a SIGKILL to each container.
+	// If it's nil, the default is 10 seconds.
+	Timeout *time.Duration
+}
+
+// PodRemoveOptions specifies options for `nerdctl pod rm`.
+type PodRemoveOptions struct {
+	Stdout io.Writer
+	// GOptions is the global options
+	GOptions GlobalCommandOptions
+	// Force removes running pods
+	Force bool
+}
+
+// PodListOptions specifies options for `nerdctl pod ps`.
+type PodListOptions struct {
+	Stdout io.Writer
+	// GOptions is the global options
+	GOptions GlobalCommandOptions
+	// Quiet only displays the pod IDs
+	Quiet bool
+	// NoTrunc does not truncate the pod IDs
+	NoTrunc bool
+	// Format the output using the given Go template (e.g., '{{json .}}')
+	Format string
+}
+
+// PodInspectOptions specifies options for `nerdctl pod inspect`.
+type PodInspectOptions struct {
+	Stdout io.Writer
+	// GOptions is the global options
+	GOptions GlobalCommandOptions
+	// Format the output using the given Go template (e.g., '{{json .}}')
+	Format string
+}
@@ -55,4 +55,17 @@ type SystemPruneOptions struct {
 	BuildKitHost string
 	// NetworkDriversToKeep the network drivers which need to keep
 	NetworkDriversToKeep []string
+	// Filters are the filters for selecting the containers, images, networks and volumes to prune
+	Filters []string
+}
+
+// SystemMetricsOptions specifies options for `nerdctl system metrics`.
+type SystemMetricsOptions struct {
+	Stdout io.Writer
+	// GOptions is the global options
+	GOptions GlobalCommandOptions
+	// Listen is the TCP address serving the metrics, e.g. ":9323"
+	Listen string
+	// AllNamespaces collects the metrics of all the namespaces, instead of the one of GOptions
+	AllNamespaces bool
 }
@@ -58,6 +58,8 @@ type VolumePruneOptions struct {
 	All bool
 	// Do not prompt for confirmation
 	Force bool
+	// Filters are the filters for selecting the volumes to prune (e.g., "until=24h", "label=foo")
+	Filters []string
 }
 
 // VolumeRemoveOptions specifies options for `nerdctl volume rm`.
@@ -0,0 +1,338 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package apiserver serves a subset of the Docker Engine API for `nerdctl system service`,
+// so that the tools talking to the Docker socket can use nerdctl.
+package apiserver
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/versions"
+	"github.com/docker/go-connections/tlsconfig"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/pkg/namespaces"
+	"github.com/containerd/errdefs"
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/rootlessutil"
+	"github.com/containerd/nerdctl/v2/pkg/version"
+)
+
+const (
+	// APIVersion is the most recent version of the Docker Engine API that is served.
+	APIVersion = "1.44"
+	// MinAPIVersion is the oldest version of the Docker Engine API that is served.
+	MinAPIVersion = "1.24"
+)
+
+// DefaultHost returns the default address of the API socket.
+func DefaultHost() string {
+	if rootlessutil.IsRootless() {
+		if xdr, err := rootlessutil.XDGRuntimeDir(); err == nil {
+			return "unix://" + filepath.Join(xdr, "nerdctl.sock")
+		}
+	}
+	return "unix:///run/nerdctl.sock"
+}
+
+// Options are the options of the API server.
+type Options struct {
+	// GOptions is the global options.
+	GOptions types.GlobalCommandOptions
+	// CreateContainer creates a container with the flags and the arguments of `nerdctl create`,
+	// and returns its ID.
+	CreateContainer func(ctx context.Context, args []string) (string, error)
+}
+
+// Server serves the Docker Engine API with a containerd client.
+type Server struct {
+	client  *containerd.Client
+	options Options
+	mux     *http.ServeMux
+}
+
+// handlerFunc is an API handler. The returned error is written as the response,
+// unless the handler already started writing it.
+type handlerFunc func(w http.ResponseWriter, r *http.Request) error
+
+// New returns a Server for a client and options.
+func New(client *containerd.Client, options Options) *Server {
+	s := &Server{
+		client:  client,
+		options: options,
+		mux:     http.NewServeMux(),
+	}
+	s.registerSystemRoutes()
+	s.registerContainerRoutes()
+	s.registerImageRoutes()
+	s.registerNetworkRoutes()
+	s.registerVolumeRoutes()
+	s.handle("/", func(w http.ResponseWriter, r *http.Request) error {
+		return fmt.Errorf("page not found: %w", errdefs.ErrNotFound)
+	})
+	return s
+}
+
+func (s *Server) handle(pattern string, fn handlerFunc) {
+	s.mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		if err := fn(w, r); err != nil {
+			log.G(r.Context()).WithError(err).Debugf("%s %s", r.Method, r.URL.Path)
+			writeError(w, err)
+		}
+	})
+}
+
+var versionedPath = regexp.MustCompile(`^/v([0-9]+\.[0-9]+)(/.*)$`)
+
+// ServeHTTP serves a request, whose path may be prefixed with the API version (e.g. "/v1.41/containers/json").
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Api-Version", APIVersion)
+	w.Header().Set("Docker-Experimental", "false")
+	w.Header().Set("Ostype", runtime.GOOS)
+	w.Header().Set("Server", "nerdctl/"+version.GetVersion()+" ("+runtime.GOOS+")")
+	if m := versionedPath.FindStringSubmatch(r.URL.Path); m != nil {
+		if err := checkAPIVersion(m[1]); err != nil {
+			writeError(w, err)
+			return
+		}
+		r.URL.Path = m[2]
+		r.URL.RawPath = ""
+	}
+	ctx := namespaces.WithNamespace(r.Context(), s.options.GOptions.Namespace)
+	s.mux.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// checkAPIVersion returns an error if the requested version of the API is not served.
+func checkAPIVersion(v string) error {
+	if versions.GreaterThan(v, APIVersion) {
+		return fmt.Errorf("client version %s is too new. Maximum supported API version is %s: %w", v, APIVersion, errdefs.ErrInvalidArgument)
+	}
+	if versions.LessThan(v, MinAPIVersion) {
+		return fmt.Errorf("client version %s is too old. Minimum supported API version is %s: %w", v, MinAPIVersion, errdefs.ErrInvalidArgument)
+	}
+	return nil
+}
+
+// ListenOptions are the options of Listen.
+type ListenOptions struct {
+	// TLSCACert is the CA certificate that the client certificates are verified with
+	TLSCACert string
+	// TLSCert is the certificate of the server
+	TLSCert string
+	// TLSKey is the private key of the server
+	TLSKey string
+	// InsecureTCP allows serving the API on a tcp host without TLS
+	InsecureTCP bool
+}
+
+func (o ListenOptions) tls() bool {
+	return o.TLSCACert != "" || o.TLSCert != "" || o.TLSKey != ""
+}
+
+// Listen listens on a "unix://" or "tcp://" host.
+// A tcp host is served with TLS, the clients being authenticated by their certificate,
+// unless options.InsecureTCP is set.
+func Listen(host string, options ListenOptions) (net.Listener, error) {
+	u, err := url.Parse(host)
+	if err != nil {
+		return nil, err
+	}
+	switch u.Scheme {
+	case "unix":
+		if options.tls() {
+			return nil, fmt.Errorf("TLS is only supported with tcp hosts: %w", errdefs.ErrInvalidArgument)
+		}
+		path := u.Path
+		if path == "" {
+			path = u.Opaque
+		}
+		if st, err := os.Lstat(path); err == nil {
+			if st.Mode()&os.ModeSocket == 0 {
+				return nil, fmt.Errorf("%s exists and is not a socket", path)
+			}
+			if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+				conn.Close()
+				return nil, fmt.Errorf("%s is already in use", path)
+			}
+			// the socket of a previous server
+			if err := os.Remove(path); err != nil {
+				return nil, err
+			}
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return nil, err
+		}
+		l, err := net.Listen("unix", path)
+		if err != nil {
+			return nil, err
+		}
+		if err := os.Chmod(path, 0o660); err != nil {
+			l.Close()
+			return nil, err
+		}
+		return l, nil
+	case "tcp":
+		if !options.tls() {
+			if !options.InsecureTCP {
+				return nil, fmt.Errorf("serving the API on %s requires TLS (--tlscacert, --tlscert and --tlskey), or --insecure-tcp: %w", host, errdefs.ErrInvalidArgument)
+			}
+			log.L.Warnf("the API listening on %s is not protected, anyone reaching it can run containers", host)
+			return net.Listen("tcp", u.Host)
+		}
+		if options.TLSCACert == "" || options.TLSCert == "" || options.TLSKey == "" {
+			return nil, fmt.Errorf("--tlscacert, --tlscert and --tlskey must be set together: %w", errdefs.ErrInvalidArgument)
+		}
+		tlsConfig, err := tlsconfig.Server(tlsconfig.Options{
+			CAFile:     options.TLSCACert,
+			CertFile:   options.TLSCert,
+			KeyFile:    options.TLSKey,
+			ClientAuth: tls.RequireAndVerifyClientCert,
+		})
+		if err != nil {
+			return nil, err
+		}
+		l, err := net.Listen("tcp", u.Host)
+		if err != nil {
+			return nil, err
+		}
+		return tls.NewListener(l, tlsConfig), nil
+	default:
+		return nil, fmt.Errorf("unsupported host %q (supported: unix://<path>, tcp://<address>)", host)
+	}
+}
+
+// Serve serves the API on a listener until ctx is done.
+func (s *Server) Serve(ctx context.Context, l net.Listener) error {
+	srv := &http.Server{
+		Handler: s,
+		BaseContext: func(net.Listener) context.Context {
+			return ctx
+		},
+	}
+	go func() {
+		<-ctx.Done()
+		srv.Close()
+	}()
+	if err := srv.Serve(l); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// apiError is the body of the error responses.
+type apiError struct {
+	Message string `json:"message"`
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	writeJSON(w, errorStatus(err), apiError{Message: err.Error()})
+}
+
+// errorStatus returns the HTTP status of an error.
+func errorStatus(err error) int {
+	switch {
+	case errdefs.IsNotFound(err):
+		return http.StatusNotFound
+	case errdefs.IsInvalidArgument(err):
+		return http.StatusBadRequest
+	case errdefs.IsAlreadyExists(err), errdefs.IsConflict(err), errdefs.IsFailedPrecondition(err):
+		return http.StatusConflict
+	case errdefs.IsNotImplemented(err):
+		return http.StatusNotImplemented
+	}
+	// most of the errors of the commands are not typed
+	msg := strings.ToLower(err.Error())
+	if strings.Contains(msg, "no such") || strings.Contains(msg, "not found") {
+		return http.StatusNotFound
+	}
+	if strings.Contains(msg, "already exists") || strings.Contains(msg, "already in use") {
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.L.WithError(err).Debug("failed to write the response")
+	}
+}
+
+// decodeJSON decodes the JSON body of a request, if any.
+func decodeJSON(r *http.Request, v any) error {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("invalid JSON body: %v: %w", err, errdefs.ErrInvalidArgument)
+	}
+	return nil
+}
+
+// boolValue returns the boolean value of a query parameter, like "1" or "true".
+func boolValue(r *http.Request, key string) bool {
+	b, _ := strconv.ParseBool(r.URL.Query().Get(key))
+	return b
+}
+
+// filtersValue returns the "filters" query parameter, a JSON map of lists, as the `--filter` values of nerdctl.
+func filtersValue(r *http.Request) ([]string, error) {
+	args, err := filters.FromJSON(r.URL.Query().Get("filters"))
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", err, errdefs.ErrInvalidArgument)
+	}
+	var res []string
+	for _, key := range args.Keys() {
+		for _, value := range args.Get(key) {
+			res = append(res, key+"="+value)
+		}
+	}
+	return res, nil
+}
+
+// flushWriter writes the responses streamed by several goroutines, and flushes them.
+type flushWriter struct {
+	mu sync.Mutex
+	w  http.ResponseWriter
+}
+
+func (fw *flushWriter) Write(p []byte) (int, error) {
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+	n, err := fw.w.Write(p)
+	if f, ok := fw.w.(http.Flusher); ok {
+		f.Flush()
+	}
+	return n, err
+}
@@ -0,0 +1,283 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package apiserver
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	dockercontainer "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/go-connections/nat"
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/errdefs"
+
+	"github.com/containerd/nerdctl/v2/pkg/testutil/testca"
+)
+
+// TestAPIVersions is the compatibility matrix of the versioned paths of the API.
+func TestAPIVersions(t *testing.T) {
+	s := New(nil, Options{})
+	testCases := []struct {
+		method  string
+		path    string
+		status  int
+		message string
+	}{
+		{method: http.MethodGet, path: "/_ping", status: http.StatusOK},
+		{method: http.MethodHead, path: "/_ping", status: http.StatusOK},
+		{method: http.MethodGet, path: "/v1.24/_ping", status: http.StatusOK},
+		{method: http.MethodGet, path: "/v1.41/_ping", status: http.StatusOK},
+		{method: http.MethodGet, path: "/v" + APIVersion + "/_ping", status: http.StatusOK},
+		{method: http.MethodGet, path: "/v1.23/_ping", status: http.StatusBadRequest, message: "client version 1.23 is too old. Minimum supported API version is 1.24"},
+		{method: http.MethodGet, path: "/v1.45/_ping", status: http.StatusBadRequest, message: "client version 1.45 is too new. Maximum supported API version is 1.44"},
+		{method: http.MethodGet, path: "/v1.100/containers/json", status: http.StatusBadRequest, message: "client version 1.100 is too new. Maximum supported API version is 1.44"},
+		{method: http.MethodGet, path: "/v1.41/unknown", status: http.StatusNotFound, message: "page not found"},
+		{method: http.MethodPost, path: "/v1.41/_ping", status: http.StatusNotFound, message: "page not found"},
+		{method: http.MethodGet, path: "/images/nginx", status: http.StatusNotFound, message: "page not found"},
+		{method: http.MethodPost, path: "/v1.41/containers/create", status: http.StatusBadRequest, message: "no image specified"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.method+" "+tc.path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			s.ServeHTTP(rec, httptest.NewRequest(tc.method, tc.path, nil))
+			assert.Equal(t, rec.Code, tc.status)
+			assert.Equal(t, rec.Header().Get("Api-Version"), APIVersion)
+			if tc.message == "" {
+				if tc.method == http.MethodGet {
+					assert.Equal(t, rec.Body.String(), "OK")
+				}
+				return
+			}
+			var apiErr apiError
+			assert.NilError(t, json.Unmarshal(rec.Body.Bytes(), &apiErr))
+			assert.ErrorContains(t, errors.New(apiErr.Message), tc.message)
+		})
+	}
+}
+
+func TestErrorStatus(t *testing.T) {
+	testCases := []struct {
+		err    error
+		status int
+	}{
+		{err: fmt.Errorf("foo: %w", errdefs.ErrNotFound), status: http.StatusNotFound},
+		{err: fmt.Errorf("foo: %w", errdefs.ErrInvalidArgument), status: http.StatusBadRequest},
+		{err: fmt.Errorf("foo: %w", errdefs.ErrAlreadyExists), status: http.StatusConflict},
+		{err: fmt.Errorf("foo: %w", errdefs.ErrNotImplemented), status: http.StatusNotImplemented},
+		{err: errors.New("no such container: foo"), status: http.StatusNotFound},
+		{err: errors.New("network foo already exists"), status: http.StatusConflict},
+		{err: errors.New("failed"), status: http.StatusInternalServerError},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.err.Error(), func(t *testing.T) {
+			assert.Equal(t, errorStatus(tc.err), tc.status)
+		})
+	}
+}
+
+func TestListen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nerdctl.sock")
+	l, err := Listen("unix://"+path, ListenOptions{})
+	assert.NilError(t, err)
+
+	// the socket of a running server is not replaced
+	_, err = Listen("unix://"+path, ListenOptions{})
+	assert.ErrorContains(t, err, "already in use")
+
+	// the socket of a previous server is replaced
+	l.(*net.UnixListener).SetUnlinkOnClose(false)
+	assert.NilError(t, l.Close())
+	l, err = Listen("unix://"+path, ListenOptions{})
+	assert.NilError(t, err)
+	assert.NilError(t, l.Close())
+
+	_, err = Listen("unix://"+path, ListenOptions{TLSCert: "cert.pem"})
+	assert.Assert(t, errdefs.IsInvalidArgument(err))
+}
+
+func TestListenTCP(t *testing.T) {
+	// an unprotected tcp host must be opted in
+	_, err := Listen("tcp://127.0.0.1:0", ListenOptions{})
+	assert.Assert(t, errdefs.IsInvalidArgument(err))
+	l, err := Listen("tcp://127.0.0.1:0", ListenOptions{InsecureTCP: true})
+	assert.NilError(t, err)
+	assert.NilError(t, l.Close())
+
+	ca := testca.New(t)
+	cert := ca.NewCert("127.0.0.1")
+	_, err = Listen("tcp://127.0.0.1:0", ListenOptions{TLSCert: cert.CertPath, TLSKey: cert.KeyPath})
+	assert.Assert(t, errdefs.IsInvalidArgument(err))
+
+	l, err = Listen("tcp://127.0.0.1:0", ListenOptions{TLSCACert: ca.CertPath, TLSCert: cert.CertPath, TLSKey: cert.KeyPath})
+	assert.NilError(t, err)
+	defer l.Close()
+	go http.Serve(l, New(nil, Options{}))
+
+	caPool := x509.NewCertPool()
+	caPEM, err := os.ReadFile(ca.CertPath)
+	assert.NilError(t, err)
+	assert.Assert(t, caPool.AppendCertsFromPEM(caPEM))
+	get := func(certs ...tls.Certificate) (*http.Response, error) {
+		client := &http.Client{Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{RootCAs: caPool, Certificates: certs},
+		}}
+		return client.Get("https://" + l.Addr().String() + "/_ping")
+	}
+
+	// the clients without a certificate are rejected
+	_, err = get()
+	assert.Assert(t, err != nil)
+
+	// the CA certificate is valid for client authentication
+	clientCert, err := tls.LoadX509KeyPair(ca.CertPath, ca.KeyPath)
+	assert.NilError(t, err)
+	resp, err := get(clientCert)
+	assert.NilError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, resp.StatusCode, http.StatusOK)
+}
+
+func TestFiltersValue(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/containers/json?filters="+url.QueryEscape(`{"label":{"foo=bar":true},"status":{"running":true}}`), nil)
+	filters, err := filtersValue(r)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, filters, []string{"label=foo=bar", "status=running"})
+
+	r = httptest.NewRequest(http.MethodGet, "/containers/json?filters=invalid", nil)
+	_, err = filtersValue(r)
+	assert.Assert(t, errdefs.IsInvalidArgument(err))
+}
+
+func TestCreateArgs(t *testing.T) {
+	stopTimeout := 5
+	initEnabled := true
+	testCases := []struct {
+		name     string
+		ctrName  string
+		req      dockercontainer.CreateRequest
+		expected []string
+	}{
+		{
+			name: "minimal",
+			req: dockercontainer.CreateRequest{
+				Config: &dockercontainer.Config{Image: "alpine", Cmd: []string{"echo", "foo"}},
+			},
+			expected: []string{"--", "alpine", "echo", "foo"},
+		},
+		{
+			name: "config",
+			req: dockercontainer.CreateRequest{
+				Config: &dockercontainer.Config{
+					Image:       "alpine",
+					Hostname:    "host",
+					User:        "1000",
+					Tty:         true,
+					OpenStdin:   true,
+					Env:         []string{"A=1", "B=2"},
+					Labels:      map[string]string{"b": "2", "a": "1"},
+					WorkingDir:  "/work",
+					StopSignal:  "SIGINT",
+					StopTimeout: &stopTimeout,
+					Volumes:     map[string]struct{}{"/data": {}},
+					Healthcheck: &dockercontainer.HealthConfig{
+						Test:     []string{"CMD-SHELL", "true"},
+						Interval: 10 * time.Second,
+						Retries:  3,
+					},
+				},
+			},
+			expected: []string{
+				"--hostname=host", "--user=1000", "--tty=true", "--interactive=true",
+				"--env=A=1", "--env=B=2", "--label=a=1", "--label=b=2", "--workdir=/work",
+				"--stop-signal=SIGINT", "--stop-timeout=5", "--volume=/data",
+				"--health-cmd=true", "--health-interval=10s", "--health-retries=3",
+				"--", "alpine",
+			},
+		},
+		{
+			name: "empty entrypoint",
+			req: dockercontainer.CreateRequest{
+				Config: &dockercontainer.Config{Image: "alpine", Entrypoint: []string{}, Cmd: []string{"ls", "-l"}},
+			},
+			expected: []string{"--entrypoint=ls", "--", "alpine", "-l"},
+		},
+		{
+			name:    "host config",
+			ctrName: "/web",
+			req: dockercontainer.CreateRequest{
+				Config: &dockercontainer.Config{Image: "nginx", Entrypoint: []string{"nginx", "-g"}},
+				HostConfig: &dockercontainer.HostConfig{
+					Binds: []string{"/src:/dst:ro"},
+					PortBindings: nat.PortMap{
+						"80/tcp": []nat.PortBinding{{HostIP: "127.0.0.1", HostPort: "8080"}},
+						"53/udp": []nat.PortBinding{{}},
+					},
+					NetworkMode:   "default",
+					AutoRemove:    true,
+					RestartPolicy: dockercontainer.RestartPolicy{Name: dockercontainer.RestartPolicyOnFailure, MaximumRetryCount: 3},
+					LogConfig:     dockercontainer.LogConfig{Type: "json-file", Config: map[string]string{"max-size": "1m"}},
+					Init:          &initEnabled,
+					Mounts:        []mount.Mount{{Type: mount.TypeVolume, Source: "data", Target: "/data", ReadOnly: true}},
+					Resources: dockercontainer.Resources{
+						Memory:   64 * 1024 * 1024,
+						NanoCPUs: 1500000000,
+						Ulimits:  []*dockercontainer.Ulimit{{Name: "nofile", Soft: 1024, Hard: 2048}},
+					},
+				},
+				NetworkingConfig: &network.NetworkingConfig{
+					EndpointsConfig: map[string]*network.EndpointSettings{
+						"foo": {IPAMConfig: &network.EndpointIPAMConfig{IPv4Address: "10.4.0.2"}},
+					},
+				},
+			},
+			expected: []string{
+				"--name=web", "--entrypoint=nginx", "--entrypoint=-g", "--volume=/src:/dst:ro",
+				"--publish=53/udp", "--publish=127.0.0.1:8080:80/tcp",
+				"--rm=true", "--init=true", "--restart=on-failure:3",
+				"--log-driver=json-file", "--log-opt=max-size=1m",
+				"--mount=type=volume,source=data,target=/data,readonly",
+				"--memory=67108864", "--cpus=1.5", "--ulimit=nofile=1024:2048",
+				"--network=foo", "--ip=10.4.0.2",
+				"--", "nginx",
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			args, err := createArgs(tc.ctrName, "", &tc.req)
+			assert.NilError(t, err)
+			assert.DeepEqual(t, args, tc.expected)
+		})
+	}
+
+	_, err := createArgs("", "", &dockercontainer.CreateRequest{Config: &dockercontainer.Config{}})
+	assert.Assert(t, errdefs.IsInvalidArgument(err))
+}
@@ -0,0 +1,804 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package apiserver
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	dockercontainer "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/pkg/stdcopy"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/pkg/cio"
+	"github.com/containerd/errdefs"
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/container"
+	"github.com/containerd/nerdctl/v2/pkg/idutil/containerwalker"
+	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/dockercompat"
+)
+
+func (s *Server) registerContainerRoutes() {
+	s.handle("GET /containers/json", s.getContainersJSON)
+	s.handle("POST /containers/create", s.postContainersCreate)
+	s.handle("GET /containers/{id}/json", s.getContainerJSON)
+	s.handle("POST /containers/{id}/start", s.postContainerStart)
+	s.handle("POST /containers/{id}/stop", s.postContainerStop)
+	s.handle("POST /containers/{id}/restart", s.postContainerRestart)
+	s.handle("POST /containers/{id}/kill", s.postContainerKill)
+	s.handle("POST /containers/{id}/wait", s.postContainerWait)
+	s.handle("GET /containers/{id}/logs", s.getContainerLogs)
+	s.handle("POST /containers/{id}/attach", s.postContainerAttach)
+	s.handle("DELETE /containers/{id}", s.deleteContainer)
+}
+
+func (s *Server) getContainersJSON(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+	filters, err := filtersValue(r)
+	if err != nil {
+		return err
+	}
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	items, err := container.List(ctx, s.client, types.ContainerListOptions{
+		GOptions: s.options.GOptions,
+		All:      boolValue(r, "all"),
+		LastN:    limit,
+		Size:     boolValue(r, "size"),
+		Filters:  filters,
+	})
+	if err != nil {
+		return err
+	}
+	summaries := make([]dockercontainer.Summary, 0, len(items))
+	for _, item := range items {
+		c, err := s.inspectContainer(ctx, item.ID, boolValue(r, "size"))
+		if err != nil {
+			// the container was removed in the meantime
+			log.G(ctx).WithError(err).Debugf("failed to inspect container %s", item.ID)
+			continue
+		}
+		summaries = append(summaries, containerSummary(c, item.Status))
+	}
+	writeJSON(w, http.StatusOK, summaries)
+	return nil
+}
+
+// inspectContainer returns the Docker-compatible inspection of a container.
+func (s *Server) inspectContainer(ctx context.Context, id string, size bool) (*dockercompat.Container, error) {
+	entries, err := container.Inspect(ctx, s.client, []string{id}, types.ContainerInspectOptions{
+		GOptions: s.options.GOptions,
+		Mode:     "dockercompat",
+		Size:     size,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("no such container: %s: %w", id, errdefs.ErrNotFound)
+	}
+	c, ok := entries[0].(*dockercompat.Container)
+	if !ok {
+		return nil, fmt.Errorf("unexpected inspection of container %s: %T", id, entries[0])
+	}
+	return c, nil
+}
+
+// containerSummary returns the summary of a container in the list of containers.
+func containerSummary(c *dockercompat.Container, status string) dockercontainer.Summary {
+	summary := dockercontainer.Summary{
+		ID:      c.ID,
+		Names:   []string{"/" + strings.TrimPrefix(c.Name, "/")},
+		Image:   c.Image,
+		ImageID: c.Image,
+		Command: strings.Join(append([]string{c.Path}, c.Args...), " "),
+		Status:  status,
+		Ports:   []dockercontainer.Port{},
+		Mounts:  []dockercontainer.MountPoint{},
+	}
+	if t, err := time.Parse(time.RFC3339Nano, c.Created); err == nil {
+		summary.Created = t.Unix()
+	}
+	if c.Config != nil {
+		summary.Labels = c.Config.Labels
+		if c.Config.Image != "" {
+			summary.Image = c.Config.Image
+		}
+	}
+	if c.State != nil {
+		summary.State = c.State.Status
+	}
+	if c.SizeRw != nil {
+		summary.SizeRw = *c.SizeRw
+	}
+	if c.SizeRootFs != nil {
+		summary.SizeRootFs = *c.SizeRootFs
+	}
+	if c.NetworkSettings != nil && c.NetworkSettings.Ports != nil {
+		for port, bindings := range *c.NetworkSettings.Ports {
+			for _, b := range bindings {
+				hostPort, _ := strconv.ParseUint(b.HostPort, 10, 16)
+				summary.Ports = append(summary.Ports, dockercontainer.Port{
+					IP:          b.HostIP,
+					PrivatePort: uint16(port.Int()),
+					PublicPort:  uint16(hostPort),
+					Type:        port.Proto(),
+				})
+			}
+		}
+	}
+	for _, m := range c.Mounts {
+		summary.Mounts = append(summary.Mounts, dockercontainer.MountPoint{
+			Type:        mount.Type(m.Type),
+			Name:        m.Name,
+			Source:      m.Source,
+			Destination: m.Destination,
+			Driver:      m.Driver,
+			Mode:        m.Mode,
+			RW:          m.RW,
+			Propagation: mount.Propagation(m.Propagation),
+		})
+	}
+	return summary
+}
+
+func (s *Server) postContainersCreate(w http.ResponseWriter, r *http.Request) error {
+	var req dockercontainer.CreateRequest
+	if err := decodeJSON(r, &req); err != nil {
+		return err
+	}
+	args, err := createArgs(r.URL.Query().Get("name"), r.URL.Query().Get("platform"), &req)
+	if err != nil {
+		return err
+	}
+	id, err := s.options.CreateContainer(r.Context(), args)
+	if err != nil {
+		return err
+	}
+	writeJSON(w, http.StatusCreated, dockercontainer.CreateResponse{ID: id, Warnings: []string{}})
+	return nil
+}
+
+// createArgs returns the flags and the arguments of `nerdctl create` for a request of the API.
+// The fields that have no equivalent flag are ignored.
+func createArgs(name, platform string, req *dockercontainer.CreateRequest) ([]string, error) {
+	cfg := req.Config
+	if cfg == nil || cfg.Image == "" {
+		return nil, fmt.Errorf("no image specified: %w", errdefs.ErrInvalidArgument)
+	}
+	var args []string
+	add := func(flag string, values ...string) {
+		for _, v := range values {
+			args = append(args, "--"+flag+"="+v)
+		}
+	}
+	addKV := func(flag string, m map[string]string) {
+		for _, k := range slices.Sorted(maps.Keys(m)) {
+			add(flag, k+"="+m[k])
+		}
+	}
+	if name != "" {
+		add("name", strings.TrimPrefix(name, "/"))
+	}
+	if platform != "" {
+		add("platform", platform)
+	}
+
+	if cfg.Hostname != "" {
+		add("hostname", cfg.Hostname)
+	}
+	if cfg.Domainname != "" {
+		add("domainname", cfg.Domainname)
+	}
+	if cfg.User != "" {
+		add("user", cfg.User)
+	}
+	if cfg.Tty {
+		add("tty", "true")
+	}
+	if cfg.OpenStdin {
+		add("interactive", "true")
+	}
+	add("env", cfg.Env...)
+	addKV("label", cfg.Labels)
+	if cfg.WorkingDir != "" {
+		add("workdir", cfg.WorkingDir)
+	}
+	cmd := cfg.Cmd
+	if cfg.Entrypoint != nil && len(cfg.Entrypoint) == 0 && len(cmd) > 0 {
+		// an empty entrypoint resets the one of the image
+		add("entrypoint", cmd[0])
+		cmd = cmd[1:]
+	}
+	add("entrypoint", cfg.Entrypoint...)
+	if cfg.StopSignal != "" {
+		add("stop-signal", cfg.StopSignal)
+	}
+	if cfg.StopTimeout != nil {
+		add("stop-timeout", strconv.Itoa(*cfg.StopTimeout))
+	}
+	//nolint:staticcheck // MacAddress is deprecated in favor of the endpoint settings, but still sent by old clients
+	if cfg.MacAddress != "" {
+		add("mac-address", cfg.MacAddress)
+	}
+	for _, v := range slices.Sorted(maps.Keys(cfg.Volumes)) {
+		// anonymous volumes
+		add("volume", v)
+	}
+	if hc := cfg.Healthcheck; hc != nil && len(hc.Test) > 0 {
+		switch hc.Test[0] {
+		case "NONE":
+			add("no-healthcheck", "true")
+		case "CMD-SHELL":
+			add("health-cmd", strings.Join(hc.Test[1:], " "))
+		case "CMD":
+			add("health-cmd", strings.Join(hc.Test[1:], " "))
+		}
+		if hc.Interval > 0 {
+			add("health-interval", hc.Interval.String())
+		}
+		if hc.Timeout > 0 {
+			add("health-timeout", hc.Timeout.String())
+		}
+		if hc.StartPeriod > 0 {
+			add("health-start-period", hc.StartPeriod.String())
+		}
+		if hc.StartInterval > 0 {
+			add("health-start-interval", hc.StartInterval.String())
+		}
+		if hc.Retries > 0 {
+			add("health-retries", strconv.Itoa(hc.Retries))
+		}
+	}
+
+	if hc := req.HostConfig; hc != nil {
+		hostConfigArgs(hc, add, addKV)
+	}
+	networks, ip := networkArgs(req)
+	add("network", networks...)
+	if ip != "" {
+		add("ip", ip)
+	}
+
+	args = append(args, "--", cfg.Image)
+	return append(args, cmd...), nil
+}
+
+func hostConfigArgs(hc *dockercontainer.HostConfig, add func(string, ...string), addKV func(string, map[string]string)) {
+	add("volume", hc.Binds...)
+	for _, port := range slices.Sorted(maps.Keys(hc.PortBindings)) {
+		for _, b := range hc.PortBindings[port] {
+			publish := port.Port() + "/" + port.Proto()
+			if b.HostIP != "" || b.HostPort != "" {
+				publish = b.HostPort + ":" + publish
+			}
+			if b.HostIP != "" {
+				publish = b.HostIP + ":" + publish
+			}
+			add("publish", publish)
+		}
+	}
+	if hc.AutoRemove {
+		add("rm", "true")
+	}
+	if hc.Privileged {
+		add("privileged", "true")
+	}
+	if hc.ReadonlyRootfs {
+		add("read-only", "true")
+	}
+	if hc.Init != nil && *hc.Init {
+		add("init", "true")
+	}
+	if policy := string(hc.RestartPolicy.Name); policy != "" && policy != "no" {
+		if hc.RestartPolicy.MaximumRetryCount > 0 {
+			policy += ":" + strconv.Itoa(hc.RestartPolicy.MaximumRetryCount)
+		}
+		add("restart", policy)
+	}
+	if hc.LogConfig.Type != "" {
+		add("log-driver", hc.LogConfig.Type)
+	}
+	addKV("log-opt", hc.LogConfig.Config)
+	add("cap-add", hc.CapAdd...)
+	add("cap-drop", hc.CapDrop...)
+	add("security-opt", hc.SecurityOpt...)
+	add("dns", hc.DNS...)
+	add("dns-search", hc.DNSSearch...)
+	add("dns-option", hc.DNSOptions...)
+	add("add-host", hc.ExtraHosts...)
+	add("group-add", hc.GroupAdd...)
+	add("volumes-from", hc.VolumesFrom...)
+	addKV("sysctl", hc.Sysctls)
+	for _, path := range slices.Sorted(maps.Keys(hc.Tmpfs)) {
+		tmpfs := path
+		if opts := hc.Tmpfs[path]; opts != "" {
+			tmpfs += ":" + opts
+		}
+		add("tmpfs", tmpfs)
+	}
+	for _, m := range hc.Mounts {
+		add("mount", mountArg(m))
+	}
+	for flag, mode := range map[string]string{"pid": string(hc.PidMode), "ipc": string(hc.IpcMode), "uts": string(hc.UTSMode), "cgroupns": string(hc.CgroupnsMode)} {
+		if mode != "" {
+			add(flag, mode)
+		}
+	}
+	if hc.ShmSize > 0 {
+		add("shm-size", strconv.FormatInt(hc.ShmSize, 10))
+	}
+	if hc.Runtime != "" {
+		add("runtime", hc.Runtime)
+	}
+
+	res := hc.Resources
+	if res.Memory > 0 {
+		add("memory", strconv.FormatInt(res.Memory, 10))
+	}
+	if res.MemorySwap != 0 {
+		add("memory-swap", strconv.FormatInt(res.MemorySwap, 10))
+	}
+	if res.MemoryReservation > 0 {
+		add("memory-reservation", strconv.FormatInt(res.MemoryReservation, 10))
+	}
+	if res.NanoCPUs > 0 {
+		add("cpus", strconv.FormatFloat(float64(res.NanoCPUs)/1e9, 'f', -1, 64))
+	}
+	if res.CPUShares > 0 {
+		add("cpu-shares", strconv.FormatInt(res.CPUShares, 10))
+	}
+	if res.CPUQuota > 0 {
+		add("cpu-quota", strconv.FormatInt(res.CPUQuota, 10))
+	}
+	if res.CPUPeriod > 0 {
+		add("cpu-period", strconv.FormatInt(res.CPUPeriod, 10))
+	}
+	if res.CpusetCpus != "" {
+		add("cpuset-cpus", res.CpusetCpus)
+	}
+	if res.CpusetMems != "" {
+		add("cpuset-mems", res.CpusetMems)
+	}
+	if res.PidsLimit != nil && *res.PidsLimit > 0 {
+		add("pids-limit", strconv.FormatInt(*res.PidsLimit, 10))
+	}
+	if res.OomKillDisable != nil && *res.OomKillDisable {
+		add("oom-kill-disable", "true")
+	}
+	for _, d := range res.Devices {
+		device := d.PathOnHost
+		if d.PathInContainer != "" {
+			device += ":" + d.PathInContainer
+		}
+		if d.CgroupPermissions != "" {
+			device += ":" + d.CgroupPermissions
+		}
+		add("device", device)
+	}
+	for _, u := range res.Ulimits {
+		add("ulimit", fmt.Sprintf("%s=%d:%d", u.Name, u.Soft, u.Hard))
+	}
+}
+
+// networkArgs returns the networks of a request, and the IPv4 address requested on one of them.
+func networkArgs(req *dockercontainer.CreateRequest) ([]string, string) {
+	var networks []string
+	if req.HostConfig != nil {
+		if mode := string(req.HostConfig.NetworkMode); mode != "" && mode != "default" {
+			networks = append(networks, mode)
+		}
+	}
+	var ip string
+	if req.NetworkingConfig != nil {
+		for _, name := range slices.Sorted(maps.Keys(req.NetworkingConfig.EndpointsConfig)) {
+			if !slices.Contains(networks, name) {
+				networks = append(networks, name)
+			}
+			if ep := req.NetworkingConfig.EndpointsConfig[name]; ep != nil && ep.IPAMConfig != nil && ep.IPAMConfig.IPv4Address != "" {
+				ip = ep.IPAMConfig.IPv4Address
+			}
+		}
+	}
+	return networks, ip
+}
+
+// mountArg returns the `--mount` value of a mount.
+func mountArg(m mount.Mount) string {
+	opts := []string{"type=" + string(m.Type)}
+	if m.Source != "" {
+		opts = append(opts, "source="+m.Source)
+	}
+	opts = append(opts, "target="+m.Target)
+	if m.ReadOnly {
+		opts = append(opts, "readonly")
+	}
+	if m.BindOptions != nil && m.BindOptions.Propagation != "" {
+		opts = append(opts, "bind-propagation="+string(m.BindOptions.Propagation))
+	}
+	if m.TmpfsOptions != nil {
+		if m.TmpfsOptions.SizeBytes > 0 {
+			opts = append(opts, "tmpfs-size="+strconv.FormatInt(m.TmpfsOptions.SizeBytes, 10))
+		}
+		if m.TmpfsOptions.Mode != 0 {
+			opts = append(opts, fmt.Sprintf("tmpfs-mode=%o", m.TmpfsOptions.Mode))
+		}
+	}
+	return strings.Join(opts, ",")
+}
+
+func (s *Server) getContainerJSON(w http.ResponseWriter, r *http.Request) error {
+	c, err := s.inspectContainer(r.Context(), r.PathValue("id"), boolValue(r, "size"))
+	if err != nil {
+		return err
+	}
+	writeJSON(w, http.StatusOK, c)
+	return nil
+}
+
+func (s *Server) postContainerStart(w http.ResponseWriter, r *http.Request) error {
+	err := container.Start(r.Context(), s.client, []string{r.PathValue("id")}, types.ContainerStartOptions{
+		Stdout:   io.Discard,
+		GOptions: s.options.GOptions,
+	})
+	if err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// stopTimeout returns the "t" query parameter, in seconds.
+func stopTimeout(r *http.Request) (*time.Duration, error) {
+	t := r.URL.Query().Get("t")
+	if t == "" {
+		return nil, nil
+	}
+	sec, err := strconv.Atoi(t)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timeout %q: %w", t, errdefs.ErrInvalidArgument)
+	}
+	d := time.Duration(sec) * time.Second
+	return &d, nil
+}
+
+func (s *Server) postContainerStop(w http.ResponseWriter, r *http.Request) error {
+	timeout, err := stopTimeout(r)
+	if err != nil {
+		return err
+	}
+	err = container.Stop(r.Context(), s.client, []string{r.PathValue("id")}, types.ContainerStopOptions{
+		Stdout:   io.Discard,
+		Stderr:   io.Discard,
+		GOptions: s.options.GOptions,
+		Timeout:  timeout,
+		Signal:   r.URL.Query().Get("signal"),
+	})
+	if err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func (s *Server) postContainerRestart(w http.ResponseWriter, r *http.Request) error {
+	timeout, err := stopTimeout(r)
+	if err != nil {
+		return err
+	}
+	err = container.Restart(r.Context(), s.client, []string{r.PathValue("id")}, types.ContainerRestartOptions{
+		Stdout:  io.Discard,
+		GOption: s.options.GOptions,
+		Timeout: timeout,
+		Signal:  r.URL.Query().Get("signal"),
+	})
+	if err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func (s *Server) postContainerKill(w http.ResponseWriter, r *http.Request) error {
+	signal := r.URL.Query().Get("signal")
+	if signal == "" {
+		signal = "SIGKILL"
+	}
+	err := container.Kill(r.Context(), s.client, []string{r.PathValue("id")}, types.ContainerKillOptions{
+		Stdout:     io.Discard,
+		Stderr:     io.Discard,
+		GOptions:   s.options.GOptions,
+		KillSignal: signal,
+	})
+	if err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func (s *Server) postContainerWait(w http.ResponseWriter, r *http.Request) error {
+	var out bytes.Buffer
+	err := container.Wait(r.Context(), s.client, []string{r.PathValue("id")}, types.ContainerWaitOptions{
+		Stdout:    &out,
+		GOptions:  s.options.GOptions,
+		Condition: r.URL.Query().Get("condition"),
+	})
+	if err != nil {
+		return err
+	}
+	code, err := strconv.ParseInt(strings.TrimSpace(out.String()), 10, 64)
+	if err != nil {
+		return fmt.Errorf("failed to parse the exit code %q: %w", out.String(), err)
+	}
+	writeJSON(w, http.StatusOK, dockercontainer.WaitResponse{StatusCode: code})
+	return nil
+}
+
+func (s *Server) deleteContainer(w http.ResponseWriter, r *http.Request) error {
+	err := container.Remove(r.Context(), s.client, []string{r.PathValue("id")}, types.ContainerRemoveOptions{
+		Stdout:   io.Discard,
+		GOptions: s.options.GOptions,
+		Force:    boolValue(r, "force"),
+		Volumes:  boolValue(r, "v"),
+	})
+	if err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// findContainer returns the container matching an ID, an ID prefix or a name.
+func (s *Server) findContainer(ctx context.Context, req string) (containerd.Container, error) {
+	var found containerd.Container
+	walker := &containerwalker.ContainerWalker{
+		Client: s.client,
+		OnFound: func(ctx context.Context, f containerwalker.Found) error {
+			if f.MatchCount > 1 {
+				return fmt.Errorf("multiple IDs found with provided prefix: %s: %w", f.Req, errdefs.ErrInvalidArgument)
+			}
+			found = f.Container
+			return nil
+		},
+	}
+	n, err := walker.Walk(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if n == 0 {
+		return nil, fmt.Errorf("no such container: %s: %w", req, errdefs.ErrNotFound)
+	}
+	return found, nil
+}
+
+// streamWriters returns the writers of the stdout and stderr of a container.
+// The streams of the containers without a TTY are multiplexed, as documented by the Docker API.
+func streamWriters(w io.Writer, tty, stdout, stderr bool) (io.Writer, io.Writer) {
+	outW, errW := io.Discard, io.Discard
+	if tty {
+		if stdout || stderr {
+			outW = w
+		}
+		return outW, outW
+	}
+	if stdout {
+		outW = stdcopy.NewStdWriter(w, stdcopy.Stdout)
+	}
+	if stderr {
+		errW = stdcopy.NewStdWriter(w, stdcopy.Stderr)
+	}
+	return outW, errW
+}
+
+func streamContentType(tty bool) string {
+	if tty {
+		return "application/vnd.docker.raw-stream"
+	}
+	return "application/vnd.docker.multiplexed-stream"
+}
+
+// isTTY returns whether a container has a TTY.
+func isTTY(ctx context.Context, c containerd.Container) (bool, error) {
+	spec, err := c.Spec(ctx)
+	if err != nil {
+		return false, err
+	}
+	return spec.Process != nil && spec.Process.Terminal, nil
+}
+
+func (s *Server) getContainerLogs(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+	stdout, stderr := boolValue(r, "stdout"), boolValue(r, "stderr")
+	if !stdout && !stderr {
+		return fmt.Errorf("you must choose at least one stream: %w", errdefs.ErrInvalidArgument)
+	}
+	c, err := s.findContainer(ctx, r.PathValue("id"))
+	if err != nil {
+		return err
+	}
+	tty, err := isTTY(ctx, c)
+	if err != nil {
+		return err
+	}
+	options, err := s.logsOptions(r)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", streamContentType(tty))
+	w.WriteHeader(http.StatusOK)
+	fw := &flushWriter{w: w}
+	options.Stdout, options.Stderr = streamWriters(fw, tty, stdout, stderr)
+	if err := container.Logs(ctx, s.client, c.ID(), options); err != nil {
+		log.G(ctx).WithError(err).Warnf("failed to print the logs of container %s", c.ID())
+	}
+	return nil
+}
+
+// logsOptions returns the options of the logs requested with the "follow", "timestamps", "tail", "since" and "until" parameters.
+func (s *Server) logsOptions(r *http.Request) (types.ContainerLogsOptions, error) {
+	q := r.URL.Query()
+	options := types.ContainerLogsOptions{
+		GOptions:   s.options.GOptions,
+		Follow:     boolValue(r, "follow"),
+		Timestamps: boolValue(r, "timestamps"),
+		Since:      q.Get("since"),
+		Until:      q.Get("until"),
+	}
+	// "0" means no "since" or "until"
+	if options.Since == "0" {
+		options.Since = ""
+	}
+	if options.Until == "0" {
+		options.Until = ""
+	}
+	if tail := q.Get("tail"); tail != "" && tail != "all" {
+		n, err := strconv.ParseUint(tail, 10, 64)
+		if err != nil {
+			return options, fmt.Errorf("invalid tail %q: %w", tail, errdefs.ErrInvalidArgument)
+		}
+		options.Tail = uint(n)
+	}
+	return options, nil
+}
+
+// hijackedConn is a hijacked connection that cancels the attachment of a client when it disconnects.
+type hijackedConn struct {
+	net.Conn
+	cancel context.CancelFunc
+	// halfClose is set when the client sends stdin: it closes its side of the connection at the end of
+	// stdin, which is not a disconnection
+	halfClose bool
+}
+
+func (c *hijackedConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	if err != nil && (!errors.Is(err, io.EOF) || !c.halfClose) {
+		c.cancel()
+	}
+	return n, err
+}
+
+func (c *hijackedConn) Write(p []byte) (int, error) {
+	n, err := c.Conn.Write(p)
+	if err != nil {
+		c.cancel()
+	}
+	return n, err
+}
+
+// postContainerAttach attaches the connection of the client to the streams of a running container.
+// Like `nerdctl attach`, it requires a container whose streams can be attached.
+func (s *Server) postContainerAttach(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+	c, err := s.findContainer(ctx, r.PathValue("id"))
+	if err != nil {
+		return err
+	}
+	tty, err := isTTY(ctx, c)
+	if err != nil {
+		return err
+	}
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		return errors.New("the connection cannot be hijacked")
+	}
+	conn, buf, err := hj.Hijack()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	// The context of the request is not canceled when the client of a hijacked connection
+	// disconnects, so the disconnection is detected on the connection itself.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	hc := &hijackedConn{Conn: conn, cancel: cancel}
+
+	status := "200 OK"
+	if r.Header.Get("Upgrade") != "" {
+		status = "101 UPGRADED\r\nConnection: Upgrade\r\nUpgrade: tcp"
+	}
+	if _, err := fmt.Fprintf(conn, "HTTP/1.1 %s\r\nContent-Type: %s\r\n\r\n", status, streamContentType(tty)); err != nil {
+		return nil
+	}
+	stdout, stderr := streamWriters(hc, tty, boolValue(r, "stdout"), boolValue(r, "stderr"))
+
+	if boolValue(r, "logs") {
+		options, err := s.logsOptions(r)
+		if err != nil {
+			log.G(ctx).WithError(err).Warn("invalid logs options")
+		} else {
+			options.Follow = false
+			options.Stdout, options.Stderr = stdout, stderr
+			if err := container.Logs(ctx, s.client, c.ID(), options); err != nil {
+				log.G(ctx).WithError(err).Warnf("failed to print the logs of container %s", c.ID())
+			}
+		}
+	}
+	if !boolValue(r, "stream") {
+		return nil
+	}
+
+	var stdin io.Reader
+	if boolValue(r, "stdin") {
+		hc.halfClose = true
+		stdin = bufio.NewReader(io.MultiReader(buf.Reader, hc))
+	} else {
+		go func() {
+			// Nothing is expected from the client, until it disconnects
+			io.Copy(io.Discard, io.MultiReader(buf.Reader, hc))
+			cancel()
+		}()
+	}
+	task, err := c.Task(ctx, cio.NewAttach(cio.WithStreams(stdin, stdout, stderr)))
+	if err != nil {
+		log.G(ctx).WithError(err).Warnf("failed to attach to container %s", c.ID())
+		return nil
+	}
+	defer func() {
+		// Stop copying the streams, without closing the FIFOs that belong to the task
+		if tio := task.IO(); tio != nil {
+			tio.Cancel()
+		}
+	}()
+	statusC, err := task.Wait(ctx)
+	if err != nil {
+		log.G(ctx).WithError(err).Warnf("failed to wait for container %s", c.ID())
+		return nil
+	}
+	select {
+	case <-ctx.Done():
+	case <-statusC:
+		if tio := task.IO(); tio != nil {
+			tio.Wait()
+		}
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package apiserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	dockerimage "github.com/docker/docker/api/types/image"
+
+	"github.com/containerd/errdefs"
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/image"
+	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/dockercompat"
+	"github.com/containerd/nerdctl/v2/pkg/platformutil"
+)
+
+func (s *Server) registerImageRoutes() {
+	s.handle("POST /images/create", s.postImagesCreate)
+	s.handle("GET /images/json", s.getImagesJSON)
+	// image names may contain slashes
+	s.handle("GET /images/{name...}", s.getImageJSON)
+}
+
+// postImagesCreate pulls an image.
+// The credentials of the X-Registry-Auth header are ignored, the ones of `nerdctl login` are used instead.
+func (s *Server) postImagesCreate(w http.ResponseWriter, r *http.Request) error {
+	q := r.URL.Query()
+	ref := q.Get("fromImage")
+	if ref == "" {
+		return fmt.Errorf("importing images is not supported, fromImage must be set: %w", errdefs.ErrNotImplemented)
+	}
+	if tag := q.Get("tag"); tag != "" {
+		if strings.Contains(tag, ":") {
+			// digest
+			ref += "@" + tag
+		} else {
+			ref += ":" + tag
+		}
+	}
+	var platforms []string
+	if p := q.Get("platform"); p != "" {
+		platforms = append(platforms, p)
+	}
+	ociPlatforms, err := platformutil.NewOCISpecPlatformSlice(false, platforms)
+	if err != nil {
+		return fmt.Errorf("%w: %w", err, errdefs.ErrInvalidArgument)
+	}
+	// The image is pulled before writing the response, so that the errors are reported with their status code.
+	err = image.Pull(r.Context(), s.client, ref, types.ImagePullOptions{
+		Stdout:          io.Discard,
+		Stderr:          io.Discard,
+		GOptions:        s.options.GOptions,
+		VerifyOptions:   types.ImageVerifyOptions{Provider: "none"},
+		OCISpecPlatform: ociPlatforms,
+		Mode:            "always",
+		Quiet:           true,
+	})
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	enc := json.NewEncoder(&flushWriter{w: w})
+	for _, status := range []string{"Pulling from " + ref, "Status: Downloaded newer image for " + ref} {
+		if err := enc.Encode(map[string]string{"status": status}); err != nil {
+			log.G(r.Context()).WithError(err).Debug("failed to write the pull status")
+			break
+		}
+	}
+	return nil
+}
+
+func (s *Server) getImagesJSON(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+	filters, err := filtersValue(r)
+	if err != nil {
+		return err
+	}
+	imageList, err := image.List(ctx, s.client, filters, nil)
+	if err != nil {
+		return err
+	}
+	// Docker lists an image once for all its names.
+	var names []string
+	seen := make(map[string]bool)
+	for _, img := range imageList {
+		if seen[img.Target.Digest.String()] {
+			continue
+		}
+		seen[img.Target.Digest.String()] = true
+		names = append(names, img.Name)
+	}
+	summaries := make([]dockerimage.Summary, 0, len(names))
+	for _, name := range names {
+		img, err := s.inspectImage(r, name)
+		if err != nil {
+			log.G(ctx).WithError(err).Debugf("failed to inspect image %s", name)
+			continue
+		}
+		summaries = append(summaries, imageSummary(img))
+	}
+	writeJSON(w, http.StatusOK, summaries)
+	return nil
+}
+
+// inspectImage returns the Docker-compatible inspection of an image.
+func (s *Server) inspectImage(r *http.Request, name string) (*dockercompat.Image, error) {
+	entries, err := image.Inspect(r.Context(), s.client, []string{name}, types.ImageInspectOptions{
+		GOptions: s.options.GOptions,
+		Mode:     "dockercompat",
+		Platform: r.URL.Query().Get("platform"),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("no such image: %s: %w", name, errdefs.ErrNotFound)
+	}
+	img, ok := entries[0].(*dockercompat.Image)
+	if !ok {
+		return nil, fmt.Errorf("unexpected inspection of image %s: %T", name, entries[0])
+	}
+	return img, nil
+}
+
+// imageSummary returns the summary of an image in the list of images.
+func imageSummary(img *dockercompat.Image) dockerimage.Summary {
+	summary := dockerimage.Summary{
+		ID:          img.ID,
+		ParentID:    img.Parent,
+		RepoTags:    img.RepoTags,
+		RepoDigests: img.RepoDigests,
+		Size:        img.Size,
+		// not computed
+		Containers: -1,
+		SharedSize: -1,
+		Labels:     map[string]string{},
+	}
+	if summary.RepoTags == nil {
+		summary.RepoTags = []string{}
+	}
+	if summary.RepoDigests == nil {
+		summary.RepoDigests = []string{}
+	}
+	if img.Config != nil && img.Config.Labels != nil {
+		summary.Labels = img.Config.Labels
+	}
+	if t, err := time.Parse(time.RFC3339Nano, img.Created); err == nil {
+		summary.Created = t.Unix()
+	}
+	return summary
+}
+
+func (s *Server) getImageJSON(w http.ResponseWriter, r *http.Request) error {
+	name, ok := strings.CutSuffix(r.PathValue("name"), "/json")
+	if !ok || name == "" {
+		return fmt.Errorf("page not found: %w", errdefs.ErrNotFound)
+	}
+	img, err := s.inspectImage(r, name)
+	if err != nil {
+		return err
+	}
+	writeJSON(w, http.StatusOK, img)
+	return nil
+}
@@ -0,0 +1,205 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package apiserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+
+	dockernetwork "github.com/docker/docker/api/types/network"
+
+	"github.com/containerd/errdefs"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/network"
+	"github.com/containerd/nerdctl/v2/pkg/netutil"
+)
+
+func (s *Server) registerNetworkRoutes() {
+	s.handle("GET /networks", s.getNetworks)
+	s.handle("POST /networks/create", s.postNetworksCreate)
+	s.handle("GET /networks/{id}", s.getNetwork)
+	s.handle("DELETE /networks/{id}", s.deleteNetwork)
+}
+
+// inspectNetworks returns the Docker-compatible inspection of networks, as a JSON array.
+func (s *Server) inspectNetworks(r *http.Request, names []string) (json.RawMessage, error) {
+	var out bytes.Buffer
+	err := network.Inspect(r.Context(), s.client, types.NetworkInspectOptions{
+		Stdout:   &out,
+		GOptions: s.options.GOptions,
+		Mode:     "dockercompat",
+		Networks: names,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// checkNetwork returns an error if a name or an ID does not match exactly one network.
+func (s *Server) checkNetwork(req string) error {
+	e, err := netutil.NewCNIEnv(s.options.GOptions.CNIPath, s.options.GOptions.CNINetConfPath, netutil.WithNamespace(s.options.GOptions.Namespace))
+	if err != nil {
+		return err
+	}
+	netLists, errs := e.ListNetworksMatch([]string{req}, true)
+	if len(errs) > 0 {
+		return errs[0]
+	}
+	switch len(netLists[req]) {
+	case 0:
+		return fmt.Errorf("network %s not found: %w", req, errdefs.ErrNotFound)
+	case 1:
+		return nil
+	default:
+		return fmt.Errorf("multiple IDs found with provided prefix: %s: %w", req, errdefs.ErrInvalidArgument)
+	}
+}
+
+func (s *Server) getNetworks(w http.ResponseWriter, r *http.Request) error {
+	filters, err := filtersValue(r)
+	if err != nil {
+		return err
+	}
+	var out bytes.Buffer
+	err = network.List(r.Context(), types.NetworkListOptions{
+		Stdout:   &out,
+		GOptions: s.options.GOptions,
+		Format:   "{{.Name}}",
+		Filters:  filters,
+	})
+	if err != nil {
+		return err
+	}
+	var names []string
+	for _, name := range strings.Fields(out.String()) {
+		// the "host" and "none" pseudo networks have no CNI configuration to inspect
+		if name != "host" && name != "none" && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		writeJSON(w, http.StatusOK, []any{})
+		return nil
+	}
+	networks, err := s.inspectNetworks(r, names)
+	if err != nil {
+		return err
+	}
+	writeJSON(w, http.StatusOK, networks)
+	return nil
+}
+
+func (s *Server) getNetwork(w http.ResponseWriter, r *http.Request) error {
+	id := r.PathValue("id")
+	if err := s.checkNetwork(id); err != nil {
+		return err
+	}
+	networks, err := s.inspectNetworks(r, []string{id})
+	if err != nil {
+		return err
+	}
+	var entries []json.RawMessage
+	if err := json.Unmarshal(networks, &entries); err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return fmt.Errorf("network %s not found: %w", id, errdefs.ErrNotFound)
+	}
+	writeJSON(w, http.StatusOK, entries[0])
+	return nil
+}
+
+func (s *Server) postNetworksCreate(w http.ResponseWriter, r *http.Request) error {
+	var req dockernetwork.CreateRequest
+	if err := decodeJSON(r, &req); err != nil {
+		return err
+	}
+	if req.Name == "" {
+		return fmt.Errorf("network name must be specified: %w", errdefs.ErrInvalidArgument)
+	}
+	options := types.NetworkCreateOptions{
+		GOptions:   s.options.GOptions,
+		Name:       req.Name,
+		Driver:     req.Driver,
+		Options:    req.Options,
+		IPAMDriver: "default",
+		Internal:   req.Internal,
+	}
+	if options.Driver == "" {
+		options.Driver = "bridge"
+	}
+	if options.Options == nil {
+		options.Options = map[string]string{}
+	}
+	if req.EnableIPv6 != nil {
+		options.IPv6 = *req.EnableIPv6
+	}
+	for _, k := range slices.Sorted(maps.Keys(req.Labels)) {
+		options.Labels = append(options.Labels, k+"="+req.Labels[k])
+	}
+	if ipam := req.IPAM; ipam != nil {
+		if ipam.Driver != "" {
+			options.IPAMDriver = ipam.Driver
+		}
+		options.IPAMOptions = ipam.Options
+		for _, c := range ipam.Config {
+			if c.Subnet != "" {
+				options.Subnets = append(options.Subnets, c.Subnet)
+			}
+			if c.Gateway != "" {
+				options.Gateway = c.Gateway
+			}
+			if c.IPRange != "" {
+				options.IPRange = c.IPRange
+			}
+		}
+	}
+	if options.IPAMOptions == nil {
+		options.IPAMOptions = map[string]string{}
+	}
+	var out bytes.Buffer
+	if err := network.Create(options, &out); err != nil {
+		return err
+	}
+	writeJSON(w, http.StatusCreated, dockernetwork.CreateResponse{ID: strings.TrimSpace(out.String())})
+	return nil
+}
+
+func (s *Server) deleteNetwork(w http.ResponseWriter, r *http.Request) error {
+	id := r.PathValue("id")
+	if err := s.checkNetwork(id); err != nil {
+		return err
+	}
+	err := network.Remove(r.Context(), s.client, types.NetworkRemoveOptions{
+		Stdout:   io.Discard,
+		GOptions: s.options.GOptions,
+		Networks: []string{id},
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %w", err, errdefs.ErrConflict)
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
@@ -0,0 +1,254 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package apiserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	dockertypes "github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
+	timetypes "github.com/docker/docker/api/types/time"
+
+	eventstypes "github.com/containerd/containerd/api/events"
+	"github.com/containerd/errdefs"
+	"github.com/containerd/log"
+	"github.com/containerd/typeurl/v2"
+
+	"github.com/containerd/nerdctl/v2/pkg/infoutil"
+	"github.com/containerd/nerdctl/v2/pkg/labels"
+	"github.com/containerd/nerdctl/v2/pkg/logging"
+	"github.com/containerd/nerdctl/v2/pkg/version"
+)
+
+func (s *Server) registerSystemRoutes() {
+	s.handle("GET /_ping", s.ping)
+	s.handle("HEAD /_ping", s.ping)
+	s.handle("GET /version", s.getVersion)
+	s.handle("GET /info", s.getInfo)
+	s.handle("GET /events", s.getEvents)
+}
+
+func (s *Server) ping(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+	w.Header().Set("Pragma", "no-cache")
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	if r.Method == http.MethodHead {
+		w.Header().Set("Content-Length", "0")
+		return nil
+	}
+	_, err := w.Write([]byte("OK"))
+	return err
+}
+
+func (s *Server) getVersion(w http.ResponseWriter, r *http.Request) error {
+	sv, err := infoutil.ServerVersion(r.Context(), s.client)
+	if err != nil {
+		return err
+	}
+	v := dockertypes.Version{
+		Version:       version.GetVersion(),
+		APIVersion:    APIVersion,
+		MinAPIVersion: MinAPIVersion,
+		GitCommit:     version.GetRevision(),
+		GoVersion:     runtime.Version(),
+		Os:            runtime.GOOS,
+		Arch:          runtime.GOARCH,
+		KernelVersion: infoutil.UnameR(),
+	}
+	v.Platform.Name = "nerdctl"
+	for _, c := range sv.Components {
+		v.Components = append(v.Components, dockertypes.ComponentVersion{
+			Name:    c.Name,
+			Version: c.Version,
+			Details: c.Details,
+		})
+	}
+	writeJSON(w, http.StatusOK, v)
+	return nil
+}
+
+func (s *Server) getInfo(w http.ResponseWriter, r *http.Request) error {
+	info, err := infoutil.Info(r.Context(), s.client, s.options.GOptions.Snapshotter, s.options.GOptions.CgroupManager)
+	if err != nil {
+		return err
+	}
+	info.Plugins.Log = logging.Drivers()
+	writeJSON(w, http.StatusOK, info)
+	return nil
+}
+
+// getEvents streams the events of the containers and the images.
+// The past events are not recorded, so "since" is ignored.
+func (s *Server) getEvents(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+	args, err := filters.FromJSON(r.URL.Query().Get("filters"))
+	if err != nil {
+		return fmt.Errorf("%v: %w", err, errdefs.ErrInvalidArgument)
+	}
+	if until := r.URL.Query().Get("until"); until != "" {
+		t, err := parseTimestamp(until)
+		if err != nil {
+			return err
+		}
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, t)
+		defer cancel()
+	}
+
+	eventsCh, errCh := s.client.EventService().Subscribe(ctx, `topic~="^/(tasks|containers|images)/"`)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	fw := &flushWriter{w: w}
+	// the headers are flushed right away, like Docker
+	fw.Write(nil)
+	enc := json.NewEncoder(fw)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-errCh:
+			if err != nil && ctx.Err() == nil {
+				log.G(ctx).WithError(err).Warn("failed to receive the events")
+			}
+			return nil
+		case e := <-eventsCh:
+			if e.Namespace != s.options.GOptions.Namespace || e.Event == nil {
+				continue
+			}
+			v, err := typeurl.UnmarshalAny(e.Event)
+			if err != nil {
+				log.G(ctx).WithError(err).Debug("failed to unmarshal an event")
+				continue
+			}
+			m := s.dockerEvent(ctx, v)
+			if m == nil {
+				continue
+			}
+			m.Time = e.Timestamp.Unix()
+			m.TimeNano = e.Timestamp.UnixNano()
+			if !eventMatches(args, m) {
+				continue
+			}
+			if err := enc.Encode(m); err != nil {
+				return nil
+			}
+		}
+	}
+}
+
+// dockerEvent returns the Docker event of a containerd event, or nil.
+func (s *Server) dockerEvent(ctx context.Context, v any) *events.Message {
+	var (
+		typ        = events.ContainerEventType
+		action     events.Action
+		id         string
+		attributes = make(map[string]string)
+	)
+	switch e := v.(type) {
+	case *eventstypes.ContainerCreate:
+		action, id = events.ActionCreate, e.ID
+	case *eventstypes.ContainerDelete:
+		action, id = events.ActionDestroy, e.ID
+	case *eventstypes.TaskStart:
+		action, id = events.ActionStart, e.ContainerID
+	case *eventstypes.TaskExit:
+		if e.ID != e.ContainerID {
+			// the exit of an exec process
+			return nil
+		}
+		action, id = events.ActionDie, e.ContainerID
+		attributes["exitCode"] = strconv.FormatUint(uint64(e.ExitStatus), 10)
+	case *eventstypes.TaskOOM:
+		action, id = events.ActionOOM, e.ContainerID
+	case *eventstypes.TaskPaused:
+		action, id = events.ActionPause, e.ContainerID
+	case *eventstypes.TaskResumed:
+		action, id = events.ActionUnPause, e.ContainerID
+	case *eventstypes.ImageCreate:
+		typ, action, id = events.ImageEventType, events.ActionPull, e.Name
+		attributes["name"] = e.Name
+	case *eventstypes.ImageDelete:
+		typ, action, id = events.ImageEventType, events.ActionDelete, e.Name
+		attributes["name"] = e.Name
+	default:
+		return nil
+	}
+	if typ == events.ContainerEventType {
+		// the attributes of Docker are the labels, the name and the image of the container
+		if c, err := s.client.LoadContainer(ctx, id); err == nil {
+			if info, err := c.Info(ctx); err == nil {
+				for k, v := range info.Labels {
+					if !strings.HasPrefix(k, labels.Prefix) {
+						attributes[k] = v
+					}
+				}
+				attributes["name"] = info.Labels[labels.Name]
+				attributes["image"] = info.Image
+			}
+		}
+	}
+	return &events.Message{
+		Status: string(action),
+		ID:     id,
+		From:   attributes["image"],
+		Type:   typ,
+		Action: action,
+		Actor: events.Actor{
+			ID:         id,
+			Attributes: attributes,
+		},
+		Scope: "local",
+	}
+}
+
+// eventMatches returns whether an event matches the "type", "event", "container", "image" and "label" filters.
+func eventMatches(args filters.Args, m *events.Message) bool {
+	if !args.ExactMatch("type", string(m.Type)) || !args.ExactMatch("event", string(m.Action)) {
+		return false
+	}
+	if args.Contains("container") {
+		if m.Type != events.ContainerEventType ||
+			!args.ExactMatch("container", m.Actor.ID) && !args.ExactMatch("container", m.Actor.Attributes["name"]) {
+			return false
+		}
+	}
+	if args.Contains("image") && !args.ExactMatch("image", m.Actor.Attributes["image"]) && !args.ExactMatch("image", m.Actor.Attributes["name"]) {
+		return false
+	}
+	return args.MatchKVList("label", m.Actor.Attributes)
+}
+
+// parseTimestamp parses a timestamp of the API, in seconds since the epoch (e.g. "1700000000.5") or RFC 3339.
+func parseTimestamp(value string) (time.Time, error) {
+	ts, err := timetypes.GetTimestamp(value, time.Now())
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%v: %w", err, errdefs.ErrInvalidArgument)
+	}
+	sec, nsec, err := timetypes.ParseTimestamps(ts, 0)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%v: %w", err, errdefs.ErrInvalidArgument)
+	}
+	return time.Unix(sec, nsec), nil
+}
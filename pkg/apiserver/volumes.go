@@ -0,0 +1,147 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package apiserver
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"time"
+
+	dockervolume "github.com/docker/docker/api/types/volume"
+
+	"github.com/containerd/errdefs"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/volume"
+	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/native"
+)
+
+func (s *Server) registerVolumeRoutes() {
+	s.handle("GET /volumes", s.getVolumes)
+	s.handle("POST /volumes/create", s.postVolumesCreate)
+	s.handle("GET /volumes/{name}", s.getVolume)
+	s.handle("DELETE /volumes/{name}", s.deleteVolume)
+}
+
+// dockerVolume returns the Docker representation of a volume.
+// Only the "local" driver is supported.
+func dockerVolume(vol *native.Volume) *dockervolume.Volume {
+	v := &dockervolume.Volume{
+		Name:       vol.Name,
+		Driver:     "local",
+		Mountpoint: vol.Mountpoint,
+		Scope:      "local",
+		Labels:     map[string]string{},
+		Options:    map[string]string{},
+	}
+	if vol.Labels != nil {
+		v.Labels = *vol.Labels
+	}
+	if !vol.CreatedAt.IsZero() {
+		v.CreatedAt = vol.CreatedAt.Format(time.RFC3339)
+	}
+	return v
+}
+
+func (s *Server) getVolumes(w http.ResponseWriter, r *http.Request) error {
+	filters, err := filtersValue(r)
+	if err != nil {
+		return err
+	}
+	g := s.options.GOptions
+	vols, err := volume.Volumes(g.Namespace, g.DataRoot, g.Address, false, filters)
+	if err != nil {
+		return err
+	}
+	resp := dockervolume.ListResponse{Volumes: []*dockervolume.Volume{}, Warnings: []string{}}
+	for _, name := range slices.Sorted(maps.Keys(vols)) {
+		vol := vols[name]
+		resp.Volumes = append(resp.Volumes, dockerVolume(&vol))
+	}
+	writeJSON(w, http.StatusOK, resp)
+	return nil
+}
+
+func (s *Server) getVolume(w http.ResponseWriter, r *http.Request) error {
+	g := s.options.GOptions
+	store, err := volume.Store(g.Namespace, g.DataRoot, g.Address)
+	if err != nil {
+		return err
+	}
+	vol, err := store.Get(r.PathValue("name"), false)
+	if err != nil {
+		return err
+	}
+	writeJSON(w, http.StatusOK, dockerVolume(vol))
+	return nil
+}
+
+func (s *Server) postVolumesCreate(w http.ResponseWriter, r *http.Request) error {
+	var req dockervolume.CreateOptions
+	if err := decodeJSON(r, &req); err != nil {
+		return err
+	}
+	if req.Driver != "" && req.Driver != "local" {
+		return fmt.Errorf("volume driver %q is not supported: %w", req.Driver, errdefs.ErrNotImplemented)
+	}
+	if len(req.DriverOpts) > 0 {
+		return fmt.Errorf("volume driver options are not supported: %w", errdefs.ErrNotImplemented)
+	}
+	var labels []string
+	for _, k := range slices.Sorted(maps.Keys(req.Labels)) {
+		labels = append(labels, k+"="+req.Labels[k])
+	}
+	vol, err := volume.Create(req.Name, types.VolumeCreateOptions{
+		Stdout:   io.Discard,
+		GOptions: s.options.GOptions,
+		Labels:   labels,
+	})
+	if err != nil {
+		return err
+	}
+	writeJSON(w, http.StatusCreated, dockerVolume(vol))
+	return nil
+}
+
+func (s *Server) deleteVolume(w http.ResponseWriter, r *http.Request) error {
+	name := r.PathValue("name")
+	g := s.options.GOptions
+	store, err := volume.Store(g.Namespace, g.DataRoot, g.Address)
+	if err != nil {
+		return err
+	}
+	if _, err := store.Get(name, false); err != nil {
+		if errdefs.IsNotFound(err) && boolValue(r, "force") {
+			w.WriteHeader(http.StatusNoContent)
+			return nil
+		}
+		return err
+	}
+	err = volume.Remove(r.Context(), s.client, []string{name}, types.VolumeRemoveOptions{
+		Stdout:   io.Discard,
+		GOptions: g,
+		Force:    boolValue(r, "force"),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to remove volume %s: %v: %w", name, err, errdefs.ErrConflict)
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
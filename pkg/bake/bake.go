@@ -0,0 +1,306 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package bake parses the build definitions consumed by `nerdctl builder bake`:
+// docker-bake files written in HCL or JSON, and the `build:` sections of compose files.
+package bake
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/containerd/nerdctl/v2/pkg/internal/filesystem"
+)
+
+// DefaultGroup is the group built when no target is specified.
+const DefaultGroup = "default"
+
+// DefaultFiles are the files looked up in the current directory when no file is specified.
+// Later files override the targets defined in earlier ones.
+var DefaultFiles = []string{
+	"compose.yaml",
+	"compose.yml",
+	"docker-compose.yml",
+	"docker-compose.yaml",
+	"docker-bake.json",
+	"docker-bake.hcl",
+	"docker-bake.override.json",
+	"docker-bake.override.hcl",
+}
+
+// File is a bake definition file.
+type File struct {
+	Name string
+	Data []byte
+}
+
+// Config is the set of groups and targets defined by one or more files.
+type Config struct {
+	Groups  map[string]*Group  `json:"group,omitempty"`
+	Targets map[string]*Target `json:"target"`
+}
+
+// Group is a named set of targets and groups.
+type Group struct {
+	Targets []string `json:"targets" hcl:"targets"`
+}
+
+// Target describes a single image build.
+// Empty fields are unset and are inherited from the targets listed in Inherits.
+type Target struct {
+	Name             string             `json:"-"`
+	Inherits         []string           `json:"inherits,omitempty" hcl:"inherits,optional"`
+	Context          string             `json:"context,omitempty" hcl:"context,optional"`
+	Contexts         map[string]string  `json:"contexts,omitempty" hcl:"contexts,optional"`
+	Dockerfile       string             `json:"dockerfile,omitempty" hcl:"dockerfile,optional"`
+	DockerfileInline string             `json:"dockerfile-inline,omitempty" hcl:"dockerfile-inline,optional"`
+	Args             map[string]*string `json:"args,omitempty" hcl:"args,optional"`
+	Labels           map[string]string  `json:"labels,omitempty" hcl:"labels,optional"`
+	Tags             []string           `json:"tags,omitempty" hcl:"tags,optional"`
+	Target           string             `json:"target,omitempty" hcl:"target,optional"`
+	Platforms        []string           `json:"platforms,omitempty" hcl:"platforms,optional"`
+	CacheFrom        []string           `json:"cache-from,omitempty" hcl:"cache-from,optional"`
+	CacheTo          []string           `json:"cache-to,omitempty" hcl:"cache-to,optional"`
+	Secret           []string           `json:"secret,omitempty" hcl:"secret,optional"`
+	SSH              []string           `json:"ssh,omitempty" hcl:"ssh,optional"`
+	Attest           []string           `json:"attest,omitempty" hcl:"attest,optional"`
+	Output           []string           `json:"output,omitempty" hcl:"output,optional"`
+	Network          string             `json:"network,omitempty" hcl:"network,optional"`
+	NoCache          *bool              `json:"no-cache,omitempty" hcl:"no-cache,optional"`
+	Pull             *bool              `json:"pull,omitempty" hcl:"pull,optional"`
+}
+
+// ReadFiles reads the named files. When names is empty, the existing DefaultFiles are read.
+func ReadFiles(names []string) ([]File, error) {
+	explicit := len(names) > 0
+	if !explicit {
+		names = DefaultFiles
+	}
+	var files []File
+	for _, name := range names {
+		dt, err := filesystem.ReadFile(name)
+		if err != nil {
+			if !explicit && errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		files = append(files, File{Name: name, Data: dt})
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no bake file found (looked up %s)", strings.Join(DefaultFiles, ", "))
+	}
+	return files, nil
+}
+
+// Parse parses the files and merges their definitions in order.
+// Compose files are recognized by their .yml/.yaml extension, other files are parsed as HCL or JSON.
+// env is used for the interpolation of variables.
+func Parse(ctx context.Context, files []File, env map[string]string) (*Config, error) {
+	c := &Config{
+		Groups:  map[string]*Group{},
+		Targets: map[string]*Target{},
+	}
+	var hclFiles []File
+	for _, f := range files {
+		switch filepath.Ext(f.Name) {
+		case ".yml", ".yaml":
+			cc, err := parseCompose(ctx, f, env)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse %s: %w", f.Name, err)
+			}
+			c.merge(cc)
+		default:
+			hclFiles = append(hclFiles, f)
+		}
+	}
+	if len(hclFiles) > 0 {
+		// Variables are shared across all the HCL files, so they are parsed together.
+		hc, err := parseHCL(hclFiles, env)
+		if err != nil {
+			return nil, err
+		}
+		c.merge(hc)
+	}
+	return c, nil
+}
+
+func (c *Config) merge(other *Config) {
+	for name, g := range other.Groups {
+		if existing, ok := c.Groups[name]; ok {
+			for _, t := range g.Targets {
+				if !slices.Contains(existing.Targets, t) {
+					existing.Targets = append(existing.Targets, t)
+				}
+			}
+			continue
+		}
+		c.Groups[name] = g
+	}
+	for name, t := range other.Targets {
+		if existing, ok := c.Targets[name]; ok {
+			existing.override(t)
+			continue
+		}
+		c.Targets[name] = t
+	}
+}
+
+// ResolveTargets expands the named groups and returns the targets with their inheritance resolved.
+// When names is empty, the default group is resolved.
+func (c *Config) ResolveTargets(names []string) ([]*Target, error) {
+	if len(names) == 0 {
+		names = []string{DefaultGroup}
+	}
+	var (
+		ordered []string
+		seen    = map[string]struct{}{}
+	)
+	var expand func(name string, visiting []string) error
+	expand = func(name string, visiting []string) error {
+		if g, ok := c.Groups[name]; ok {
+			if slices.Contains(visiting, name) {
+				return fmt.Errorf("group %q includes itself", name)
+			}
+			for _, n := range g.Targets {
+				if err := expand(n, append(visiting, name)); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		if _, ok := c.Targets[name]; !ok {
+			return fmt.Errorf("failed to find target %q", name)
+		}
+		if _, ok := seen[name]; !ok {
+			seen[name] = struct{}{}
+			ordered = append(ordered, name)
+		}
+		return nil
+	}
+	for _, name := range names {
+		if err := expand(name, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	res := make([]*Target, 0, len(ordered))
+	for _, name := range ordered {
+		t, err := c.resolve(name, nil)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, t)
+	}
+	return res, nil
+}
+
+// resolve returns a copy of the target with the targets it inherits from applied.
+func (c *Config) resolve(name string, visiting []string) (*Target, error) {
+	if slices.Contains(visiting, name) {
+		return nil, fmt.Errorf("target %q inherits from itself", name)
+	}
+	t, ok := c.Targets[name]
+	if !ok {
+		return nil, fmt.Errorf("failed to find target %q", name)
+	}
+	res := &Target{Name: name}
+	for _, parent := range t.Inherits {
+		p, err := c.resolve(parent, append(visiting, name))
+		if err != nil {
+			return nil, err
+		}
+		res.override(p)
+	}
+	res.override(t)
+	res.Name = name
+	res.Inherits = nil
+	return res, nil
+}
+
+// override sets the fields of t that are set in o.
+func (t *Target) override(o *Target) {
+	if o.Inherits != nil {
+		t.Inherits = o.Inherits
+	}
+	if o.Context != "" {
+		t.Context = o.Context
+	}
+	t.Contexts = mergeMap(t.Contexts, o.Contexts)
+	if o.Dockerfile != "" {
+		t.Dockerfile = o.Dockerfile
+	}
+	if o.DockerfileInline != "" {
+		t.DockerfileInline = o.DockerfileInline
+	}
+	t.Args = mergeMap(t.Args, o.Args)
+	t.Labels = mergeMap(t.Labels, o.Labels)
+	if o.Tags != nil {
+		t.Tags = o.Tags
+	}
+	if o.Target != "" {
+		t.Target = o.Target
+	}
+	if o.Platforms != nil {
+		t.Platforms = o.Platforms
+	}
+	if o.CacheFrom != nil {
+		t.CacheFrom = o.CacheFrom
+	}
+	if o.CacheTo != nil {
+		t.CacheTo = o.CacheTo
+	}
+	if o.Secret != nil {
+		t.Secret = o.Secret
+	}
+	if o.SSH != nil {
+		t.SSH = o.SSH
+	}
+	if o.Attest != nil {
+		t.Attest = o.Attest
+	}
+	if o.Output != nil {
+		t.Output = o.Output
+	}
+	if o.Network != "" {
+		t.Network = o.Network
+	}
+	if o.NoCache != nil {
+		t.NoCache = o.NoCache
+	}
+	if o.Pull != nil {
+		t.Pull = o.Pull
+	}
+}
+
+func mergeMap[V any](dst, src map[string]V) map[string]V {
+	if len(src) == 0 {
+		return dst
+	}
+	res := make(map[string]V, len(dst)+len(src))
+	for k, v := range dst {
+		res[k] = v
+	}
+	for k, v := range src {
+		res[k] = v
+	}
+	return res
+}
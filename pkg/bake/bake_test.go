@@ -0,0 +1,207 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package bake
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func targetNames(targets []*Target) []string {
+	names := make([]string, 0, len(targets))
+	for _, t := range targets {
+		names = append(names, t.Name)
+	}
+	return names
+}
+
+func TestParseHCL(t *testing.T) {
+	const dt = `
+variable "REGISTRY" {
+  default = "example.com"
+}
+
+variable "TAG" {
+  default = "latest"
+}
+
+variable "IMAGE" {
+  default = "${REGISTRY}/app"
+}
+
+group "default" {
+  targets = ["app", "tools"]
+}
+
+target "base" {
+  context = "."
+  args = {
+    GO_VERSION = "1.24"
+  }
+  labels = {
+    "org.opencontainers.image.vendor" = "example"
+  }
+}
+
+target "app" {
+  inherits = ["base"]
+  dockerfile = "app.Dockerfile"
+  tags = ["${IMAGE}:${TAG}", "${IMAGE}:${upper("edge")}"]
+  args = {
+    MODE = "release"
+  }
+}
+
+target "tools" {
+  inherits = ["base"]
+  name = "tools-${replace(platform, "/", "-")}"
+  matrix = {
+    platform = ["linux/amd64", "linux/arm64"]
+  }
+  platforms = [platform]
+  no-cache = true
+}
+`
+	c, err := Parse(context.Background(), []File{{Name: "docker-bake.hcl", Data: []byte(dt)}}, map[string]string{"TAG": "v1"})
+	assert.NilError(t, err)
+
+	targets, err := c.ResolveTargets(nil)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, targetNames(targets), []string{"app", "tools-linux-amd64", "tools-linux-arm64"})
+
+	app := targets[0]
+	assert.Equal(t, app.Context, ".")
+	assert.Equal(t, app.Dockerfile, "app.Dockerfile")
+	assert.DeepEqual(t, app.Tags, []string{"example.com/app:v1", "example.com/app:EDGE"})
+	assert.Equal(t, len(app.Args), 2)
+	assert.Equal(t, *app.Args["GO_VERSION"], "1.24")
+	assert.Equal(t, *app.Args["MODE"], "release")
+	assert.Equal(t, app.Labels["org.opencontainers.image.vendor"], "example")
+	assert.Assert(t, app.Inherits == nil)
+
+	arm := targets[2]
+	assert.DeepEqual(t, arm.Platforms, []string{"linux/arm64"})
+	assert.Equal(t, *arm.Args["GO_VERSION"], "1.24")
+	assert.Assert(t, arm.NoCache != nil && *arm.NoCache)
+
+	// the name of a matrix target refers to all its combinations
+	targets, err = c.ResolveTargets([]string{"tools"})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, targetNames(targets), []string{"tools-linux-amd64", "tools-linux-arm64"})
+}
+
+func TestParseJSON(t *testing.T) {
+	const dt = `{
+  "variable": {
+    "TAG": {"default": "latest"}
+  },
+  "target": {
+    "app": {
+      "context": "app",
+      "tags": ["app:${TAG}"],
+      "platforms": ["linux/amd64", "linux/arm64"]
+    }
+  }
+}`
+	c, err := Parse(context.Background(), []File{{Name: "docker-bake.json", Data: []byte(dt)}}, nil)
+	assert.NilError(t, err)
+
+	targets, err := c.ResolveTargets([]string{"app"})
+	assert.NilError(t, err)
+	assert.Equal(t, len(targets), 1)
+	assert.Equal(t, targets[0].Context, "app")
+	assert.DeepEqual(t, targets[0].Tags, []string{"app:latest"})
+	assert.DeepEqual(t, targets[0].Platforms, []string{"linux/amd64", "linux/arm64"})
+
+	_, err = c.ResolveTargets(nil)
+	assert.ErrorContains(t, err, `failed to find target "default"`)
+}
+
+func TestParseCompose(t *testing.T) {
+	dir := t.TempDir()
+	const composeYAML = `
+services:
+  web:
+    build:
+      context: ./web
+      dockerfile: web.Dockerfile
+      args:
+        FOO: bar
+      target: prod
+    platform: linux/arm64
+  db:
+    image: postgres
+`
+	const overrideHCL = `
+target "web" {
+  tags = ["example.com/web:latest"]
+}
+`
+	c, err := Parse(context.Background(), []File{
+		{Name: filepath.Join(dir, "compose.yaml"), Data: []byte(composeYAML)},
+		{Name: filepath.Join(dir, "docker-bake.override.hcl"), Data: []byte(overrideHCL)},
+	}, nil)
+	assert.NilError(t, err)
+
+	targets, err := c.ResolveTargets(nil)
+	assert.NilError(t, err)
+	assert.Equal(t, len(targets), 1)
+	web := targets[0]
+	assert.Equal(t, web.Name, "web")
+	assert.Equal(t, web.Context, filepath.Join(dir, "web"))
+	assert.Equal(t, web.Dockerfile, filepath.Join(dir, "web", "web.Dockerfile"))
+	assert.Equal(t, *web.Args["FOO"], "bar")
+	assert.Equal(t, web.Target, "prod")
+	assert.DeepEqual(t, web.Platforms, []string{"linux/arm64"})
+	assert.DeepEqual(t, web.Tags, []string{"example.com/web:latest"})
+}
+
+func TestResolveTargetsErrors(t *testing.T) {
+	c := &Config{
+		Groups: map[string]*Group{
+			"loop": {Targets: []string{"loop"}},
+		},
+		Targets: map[string]*Target{
+			"a": {Inherits: []string{"b"}},
+			"b": {Inherits: []string{"a"}},
+		},
+	}
+	_, err := c.ResolveTargets([]string{"loop"})
+	assert.ErrorContains(t, err, "includes itself")
+
+	_, err = c.ResolveTargets([]string{"a"})
+	assert.ErrorContains(t, err, "inherits from itself")
+
+	_, err = c.ResolveTargets([]string{"missing"})
+	assert.ErrorContains(t, err, `failed to find target "missing"`)
+}
+
+func TestEvalVariablesCycle(t *testing.T) {
+	const dt = `
+variable "A" {
+  default = "${B}"
+}
+variable "B" {
+  default = "${A}"
+}
+`
+	_, err := Parse(context.Background(), []File{{Name: "docker-bake.hcl", Data: []byte(dt)}}, nil)
+	assert.ErrorContains(t, err, "refer to each other")
+}
@@ -0,0 +1,162 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package bake
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/loader"
+	composetypes "github.com/compose-spec/compose-go/v2/types"
+	"github.com/spf13/pflag"
+
+	"github.com/containerd/nerdctl/v2/pkg/composer/serviceparser"
+)
+
+// composeBuildFields are the fields of the compose build sections that are converted to target attributes
+// by parseCompose, in addition to the ones parsed by serviceparser.
+var composeBuildFields = []string{"Platforms", "Tags", "CacheTo", "Network", "NoCache", "Pull"}
+
+// parseCompose converts the services of a compose file that have a `build:` section into targets.
+// All of them are members of the default group.
+func parseCompose(ctx context.Context, f File, env map[string]string) (*Config, error) {
+	absPath, err := filepath.Abs(f.Name)
+	if err != nil {
+		return nil, err
+	}
+	workingDir := filepath.Dir(absPath)
+	project, err := loader.LoadWithContext(ctx, composetypes.ConfigDetails{
+		WorkingDir: workingDir,
+		ConfigFiles: []composetypes.ConfigFile{
+			{Filename: absPath, Content: f.Data},
+		},
+		Environment: env,
+	}, func(o *loader.Options) {
+		o.SetProjectName(loader.NormalizeProjectName(filepath.Base(workingDir)), false)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Config{
+		Groups:  map[string]*Group{},
+		Targets: map[string]*Target{},
+	}
+	var names []string
+	for _, svc := range project.Services {
+		b, err := serviceparser.ParseBuild(project, svc, composeBuildFields...)
+		if err != nil {
+			return nil, err
+		}
+		if b == nil {
+			continue
+		}
+		t, err := targetFromBuildArgs(b.BuildArgs)
+		if err != nil {
+			return nil, fmt.Errorf("service %s: %w", svc.Name, err)
+		}
+		t.Name = svc.Name
+		t.DockerfileInline = b.DockerfileInline
+		if svc.Platform != "" {
+			t.Platforms = []string{svc.Platform}
+		}
+		if len(svc.Build.Platforms) > 0 {
+			t.Platforms = svc.Build.Platforms
+		}
+		if len(svc.Build.Tags) > 0 {
+			t.Tags = append(t.Tags, svc.Build.Tags...)
+		}
+		if len(svc.Build.CacheTo) > 0 {
+			t.CacheTo = svc.Build.CacheTo
+		}
+		if svc.Build.Network != "" {
+			t.Network = svc.Build.Network
+		}
+		if svc.Build.NoCache {
+			t.NoCache = &svc.Build.NoCache
+		}
+		if svc.Build.Pull {
+			t.Pull = &svc.Build.Pull
+		}
+		c.Targets[svc.Name] = t
+		names = append(names, svc.Name)
+	}
+	if len(names) > 0 {
+		sort.Strings(names)
+		c.Groups[DefaultGroup] = &Group{Targets: names}
+	}
+	return c, nil
+}
+
+// targetFromBuildArgs converts the `nerdctl build` arguments generated by serviceparser into a target,
+// so that compose services can be merged with (and inherited by) the targets of bake files.
+func targetFromBuildArgs(args []string) (*Target, error) {
+	fs := pflag.NewFlagSet("build", pflag.ContinueOnError)
+	tags := fs.StringArrayP("tag", "t", nil, "")
+	file := fs.StringP("file", "f", "", "")
+	buildArgs := fs.StringArray("build-arg", nil, "")
+	cacheFrom := fs.StringArray("cache-from", nil, "")
+	buildContexts := fs.StringArray("build-context", nil, "")
+	target := fs.String("target", "", "")
+	labels := fs.StringArray("label", nil, "")
+	secrets := fs.StringArray("secret", nil, "")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() != 1 {
+		return nil, fmt.Errorf("expected exactly one build context, got %v", fs.Args())
+	}
+
+	t := &Target{
+		Context:    fs.Arg(0),
+		Dockerfile: *file,
+		Tags:       *tags,
+		Target:     *target,
+		CacheFrom:  *cacheFrom,
+		Secret:     *secrets,
+	}
+	for _, a := range *buildArgs {
+		if t.Args == nil {
+			t.Args = map[string]*string{}
+		}
+		k, v, ok := strings.Cut(a, "=")
+		if !ok {
+			// taken from the environment by `nerdctl build --build-arg KEY`
+			t.Args[k] = nil
+			continue
+		}
+		t.Args[k] = &v
+	}
+	for _, l := range *labels {
+		if t.Labels == nil {
+			t.Labels = map[string]string{}
+		}
+		k, v, _ := strings.Cut(l, "=")
+		t.Labels[k] = v
+	}
+	for _, bc := range *buildContexts {
+		if t.Contexts == nil {
+			t.Contexts = map[string]string{}
+		}
+		k, v, _ := strings.Cut(bc, "=")
+		t.Contexts[k] = v
+	}
+	return t, nil
+}
@@ -0,0 +1,350 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package bake
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	hcljson "github.com/hashicorp/hcl/v2/json"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+	"github.com/zclconf/go-cty/cty/function/stdlib"
+)
+
+var fileSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: "variable", LabelNames: []string{"name"}},
+		{Type: "group", LabelNames: []string{"name"}},
+		{Type: "target", LabelNames: []string{"name"}},
+	},
+}
+
+var variableSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "default"},
+		{Name: "description"},
+	},
+}
+
+var targetSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "name"},
+		{Name: "matrix"},
+	},
+}
+
+var targetNameRegexp = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+// functions are the functions available in expressions.
+var functions = map[string]function.Function{
+	"and":        stdlib.AndFunc,
+	"coalesce":   stdlib.CoalesceFunc,
+	"concat":     stdlib.ConcatFunc,
+	"contains":   stdlib.ContainsFunc,
+	"distinct":   stdlib.DistinctFunc,
+	"equal":      stdlib.EqualFunc,
+	"format":     stdlib.FormatFunc,
+	"join":       stdlib.JoinFunc,
+	"length":     stdlib.LengthFunc,
+	"lower":      stdlib.LowerFunc,
+	"not":        stdlib.NotFunc,
+	"notequal":   stdlib.NotEqualFunc,
+	"or":         stdlib.OrFunc,
+	"regex":      stdlib.RegexFunc,
+	"replace":    stdlib.ReplaceFunc,
+	"split":      stdlib.SplitFunc,
+	"substr":     stdlib.SubstrFunc,
+	"trim":       stdlib.TrimFunc,
+	"trimprefix": stdlib.TrimPrefixFunc,
+	"trimspace":  stdlib.TrimSpaceFunc,
+	"trimsuffix": stdlib.TrimSuffixFunc,
+	"upper":      stdlib.UpperFunc,
+}
+
+// parseHCL parses docker-bake files written in HCL, or in JSON using the HCL JSON syntax.
+func parseHCL(files []File, env map[string]string) (*Config, error) {
+	var contents []*hcl.BodyContent
+	for _, f := range files {
+		hf, diags := parseHCLFile(f)
+		if diags.HasErrors() {
+			return nil, diags
+		}
+		content, diags := hf.Body.Content(fileSchema)
+		if diags.HasErrors() {
+			return nil, diags
+		}
+		contents = append(contents, content)
+	}
+
+	var variables []*hcl.Block
+	for _, content := range contents {
+		variables = append(variables, content.Blocks.OfType("variable")...)
+	}
+	vars, err := evalVariables(variables, env)
+	if err != nil {
+		return nil, err
+	}
+	evalCtx := &hcl.EvalContext{
+		Variables: vars,
+		Functions: functions,
+	}
+
+	c := &Config{
+		Groups:  map[string]*Group{},
+		Targets: map[string]*Target{},
+	}
+	for _, content := range contents {
+		for _, block := range content.Blocks.OfType("target") {
+			targets, err := decodeTarget(block, evalCtx)
+			if err != nil {
+				return nil, err
+			}
+			if len(targets) > 1 || targets[0].Name != block.Labels[0] {
+				// the name of a matrix target refers to all its combinations
+				g := &Group{}
+				for _, t := range targets {
+					g.Targets = append(g.Targets, t.Name)
+				}
+				c.merge(&Config{Groups: map[string]*Group{block.Labels[0]: g}})
+			}
+			for _, t := range targets {
+				c.merge(&Config{Targets: map[string]*Target{t.Name: t}})
+			}
+		}
+		for _, block := range content.Blocks.OfType("group") {
+			var g Group
+			if diags := gohcl.DecodeBody(block.Body, evalCtx, &g); diags.HasErrors() {
+				return nil, diags
+			}
+			c.merge(&Config{Groups: map[string]*Group{block.Labels[0]: &g}})
+		}
+	}
+	return c, nil
+}
+
+func parseHCLFile(f File) (*hcl.File, hcl.Diagnostics) {
+	switch filepath.Ext(f.Name) {
+	case ".json":
+		return hcljson.Parse(f.Data, f.Name)
+	case ".hcl":
+		return hclsyntax.ParseConfig(f.Data, f.Name, hcl.InitialPos)
+	}
+	hf, diags := hclsyntax.ParseConfig(f.Data, f.Name, hcl.InitialPos)
+	if !diags.HasErrors() {
+		return hf, diags
+	}
+	if jf, jsonDiags := hcljson.Parse(f.Data, f.Name); !jsonDiags.HasErrors() {
+		return jf, jsonDiags
+	}
+	return hf, diags
+}
+
+// evalVariables evaluates the defaults of the variables, which may refer to each other.
+// A variable set in env takes precedence over its default.
+func evalVariables(blocks []*hcl.Block, env map[string]string) (map[string]cty.Value, error) {
+	defaults := map[string]hcl.Expression{}
+	for _, block := range blocks {
+		content, diags := block.Body.Content(variableSchema)
+		if diags.HasErrors() {
+			return nil, diags
+		}
+		name := block.Labels[0]
+		if attr, ok := content.Attributes["default"]; ok {
+			defaults[name] = attr.Expr
+		} else {
+			defaults[name] = nil
+		}
+	}
+
+	vars := map[string]cty.Value{}
+	pending := map[string]hcl.Expression{}
+	for name, expr := range defaults {
+		pending[name] = expr
+	}
+	for len(pending) > 0 {
+		progressed := false
+		var lastDiags hcl.Diagnostics
+		for _, name := range sortedKeys(pending) {
+			expr := pending[name]
+			if !referencesResolved(expr, vars, defaults) {
+				continue
+			}
+			var def cty.Value
+			if expr != nil {
+				v, diags := expr.Value(&hcl.EvalContext{Variables: vars, Functions: functions})
+				if diags.HasErrors() {
+					lastDiags = diags
+					continue
+				}
+				def = v
+			}
+			v, err := variableValue(name, def, env)
+			if err != nil {
+				return nil, err
+			}
+			vars[name] = v
+			delete(pending, name)
+			progressed = true
+		}
+		if !progressed {
+			if lastDiags.HasErrors() {
+				return nil, lastDiags
+			}
+			return nil, fmt.Errorf("variables %v refer to each other", sortedKeys(pending))
+		}
+	}
+	return vars, nil
+}
+
+// referencesResolved returns false when the expression refers to a variable that has not been evaluated yet.
+func referencesResolved(expr hcl.Expression, vars map[string]cty.Value, defaults map[string]hcl.Expression) bool {
+	if expr == nil {
+		return true
+	}
+	for _, traversal := range expr.Variables() {
+		name := traversal.RootName()
+		if _, declared := defaults[name]; !declared {
+			// reported when evaluating the expression
+			continue
+		}
+		if _, ok := vars[name]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// variableValue returns the value of the variable set in env, converted to the type of its default value.
+func variableValue(name string, def cty.Value, env map[string]string) (cty.Value, error) {
+	s, ok := env[name]
+	if !ok {
+		if def == cty.NilVal || def.IsNull() {
+			return cty.StringVal(""), nil
+		}
+		return def, nil
+	}
+	if def == cty.NilVal || def.IsNull() {
+		return cty.StringVal(s), nil
+	}
+	switch def.Type() {
+	case cty.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return cty.NilVal, fmt.Errorf("failed to parse variable %s=%q as bool: %w", name, s, err)
+		}
+		return cty.BoolVal(b), nil
+	case cty.Number:
+		n, err := cty.ParseNumberVal(s)
+		if err != nil {
+			return cty.NilVal, fmt.Errorf("failed to parse variable %s=%q as number: %w", name, s, err)
+		}
+		return n, nil
+	default:
+		return cty.StringVal(s), nil
+	}
+}
+
+// decodeTarget decodes a target block, expanding its matrix into one target per combination of values.
+func decodeTarget(block *hcl.Block, evalCtx *hcl.EvalContext) ([]*Target, error) {
+	content, remain, diags := block.Body.PartialContent(targetSchema)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	label := block.Labels[0]
+	combinations := []map[string]cty.Value{nil}
+	if attr, ok := content.Attributes["matrix"]; ok {
+		if _, ok := content.Attributes["name"]; !ok {
+			return nil, fmt.Errorf("target %q: name must be set when matrix is set", label)
+		}
+		m, diags := attr.Expr.Value(evalCtx)
+		if diags.HasErrors() {
+			return nil, diags
+		}
+		var err error
+		if combinations, err = matrixCombinations(m); err != nil {
+			return nil, fmt.Errorf("target %q: %w", label, err)
+		}
+	}
+
+	targets := make([]*Target, 0, len(combinations))
+	for _, combination := range combinations {
+		ctx := evalCtx
+		if combination != nil {
+			ctx = evalCtx.NewChild()
+			ctx.Variables = combination
+		}
+		name := label
+		if attr, ok := content.Attributes["name"]; ok {
+			if diags := gohcl.DecodeExpression(attr.Expr, ctx, &name); diags.HasErrors() {
+				return nil, diags
+			}
+		}
+		if !targetNameRegexp.MatchString(name) {
+			return nil, fmt.Errorf("invalid target name %q", name)
+		}
+		t := &Target{Name: name}
+		if diags := gohcl.DecodeBody(remain, ctx, t); diags.HasErrors() {
+			return nil, diags
+		}
+		targets = append(targets, t)
+	}
+	return targets, nil
+}
+
+// matrixCombinations returns the cartesian product of the values of the matrix, ordered by key.
+func matrixCombinations(m cty.Value) ([]map[string]cty.Value, error) {
+	if m.IsNull() || !(m.Type().IsObjectType() || m.Type().IsMapType()) {
+		return nil, fmt.Errorf("matrix must be a map of lists, got %s", m.Type().FriendlyName())
+	}
+	values := m.AsValueMap()
+	combinations := []map[string]cty.Value{{}}
+	for _, key := range sortedKeys(values) {
+		v := values[key]
+		if v.IsNull() || !v.CanIterateElements() || !(v.Type().IsListType() || v.Type().IsTupleType()) {
+			return nil, fmt.Errorf("matrix value %q must be a list", key)
+		}
+		var next []map[string]cty.Value
+		for _, combination := range combinations {
+			for _, elem := range v.AsValueSlice() {
+				c := make(map[string]cty.Value, len(combination)+1)
+				for k, cv := range combination {
+					c[k] = cv
+				}
+				c[key] = elem
+				next = append(next, c)
+			}
+		}
+		combinations = next
+	}
+	return combinations, nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,256 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package buildhistory records the builds run by `nerdctl build` under the data root,
+// so that they can be inspected with `nerdctl builder history`.
+package buildhistory
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+
+	"github.com/containerd/errdefs"
+
+	"github.com/containerd/nerdctl/v2/pkg/store"
+)
+
+// Record is a build recorded in the history.
+type Record struct {
+	// Ref is the BuildKit reference of the build
+	Ref string
+	// Args are the frontend attributes of the build (build args, target, platforms...)
+	Args map[string]string `json:",omitempty"`
+	// Tags are the names given to the resulting image
+	Tags []string `json:",omitempty"`
+	// Context is the path of the build context
+	Context string
+	// ContextDigest is the digest of the metadata of the files of the build context when the build started,
+	// excluding the ones matched by .dockerignore
+	ContextDigest digest.Digest `json:",omitempty"`
+	// Dockerfile is the name of the Dockerfile
+	Dockerfile string `json:",omitempty"`
+	// DockerfileContent is the content of the Dockerfile
+	DockerfileContent string `json:",omitempty"`
+	// ImageDigest is the digest of the resulting image
+	ImageDigest string `json:",omitempty"`
+	CreatedAt   time.Time
+	CompletedAt time.Time
+	// Error is set when the build failed
+	Error string `json:",omitempty"`
+	// Vertexes are the steps of the build, in the order they were started
+	Vertexes []*Vertex `json:",omitempty"`
+}
+
+// Duration returns how long the build took.
+func (r *Record) Duration() time.Duration {
+	return r.CompletedAt.Sub(r.CreatedAt)
+}
+
+// Status returns "Completed" or "Error".
+func (r *Record) Status() string {
+	if r.Error != "" {
+		return "Error"
+	}
+	return "Completed"
+}
+
+// Vertex is a step of a build.
+type Vertex struct {
+	Digest    digest.Digest
+	Name      string
+	Started   *time.Time `json:",omitempty"`
+	Completed *time.Time `json:",omitempty"`
+	Cached    bool       `json:",omitempty"`
+	Error     string     `json:",omitempty"`
+}
+
+// Duration returns how long the step took, or 0 if it did not complete.
+func (v *Vertex) Duration() time.Duration {
+	if v.Started == nil || v.Completed == nil {
+		return 0
+	}
+	return v.Completed.Sub(*v.Started)
+}
+
+// Store stores the build records of a namespace.
+type Store interface {
+	// Save saves a record, replacing the existing record with the same ref
+	Save(rec *Record) error
+	// Get returns the record whose ref is, or starts with, the given ref
+	Get(ref string) (*Record, error)
+	// List returns all the records, most recent first
+	List() ([]*Record, error)
+	// Remove removes the record whose ref is, or starts with, the given ref
+	Remove(ref string) error
+}
+
+const (
+	// MaxRecords is the number of records kept per namespace; the oldest ones are pruned on Save.
+	MaxRecords = 50
+	// MaxAge is how long a record is kept; older records are pruned on Save.
+	MaxAge = 30 * 24 * time.Hour
+)
+
+type historyStore struct {
+	store      store.Store
+	maxRecords int
+	maxAge     time.Duration
+}
+
+// NewStore returns the build history store of the namespace.
+// dataStore is the value returned by clientutil.DataStore.
+func NewStore(dataStore, namespace string) (Store, error) {
+	st, err := store.New(filepath.Join(dataStore, "builds", namespace), 0o700, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create build history store: %w", err)
+	}
+	return &historyStore{store: st, maxRecords: MaxRecords, maxAge: MaxAge}, nil
+}
+
+func (s *historyStore) Save(rec *Record) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	return s.store.WithLock(func() error {
+		if err := s.store.Set(data, rec.Ref); err != nil {
+			return err
+		}
+		return s.prune()
+	})
+}
+
+func (s *historyStore) Get(ref string) (*Record, error) {
+	var rec *Record
+	err := s.store.WithLock(func() error {
+		key, err := s.resolve(ref)
+		if err != nil {
+			return err
+		}
+		rec, err = s.get(key)
+		return err
+	})
+	return rec, err
+}
+
+func (s *historyStore) List() ([]*Record, error) {
+	var records []*Record
+	err := s.store.WithLock(func() error {
+		var err error
+		records, err = s.list()
+		return err
+	})
+	return records, err
+}
+
+func (s *historyStore) Remove(ref string) error {
+	return s.store.WithLock(func() error {
+		key, err := s.resolve(ref)
+		if err != nil {
+			return err
+		}
+		return s.store.Delete(key)
+	})
+}
+
+// list returns all the records, most recent first. The caller must hold the lock.
+func (s *historyStore) list() ([]*Record, error) {
+	keys, err := s.store.List()
+	if err != nil {
+		return nil, err
+	}
+	records := make([]*Record, 0, len(keys))
+	for _, key := range keys {
+		rec, err := s.get(key)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, rec)
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].CreatedAt.After(records[j].CreatedAt)
+	})
+	return records, nil
+}
+
+// prune removes the records beyond maxRecords and the ones older than maxAge.
+// The caller must hold the lock.
+func (s *historyStore) prune() error {
+	records, err := s.list()
+	if err != nil {
+		return err
+	}
+	cutoff := time.Now().Add(-s.maxAge)
+	for i, rec := range records {
+		if i < s.maxRecords && rec.CreatedAt.After(cutoff) {
+			continue
+		}
+		if err := s.store.Delete(rec.Ref); err != nil && !errors.Is(err, store.ErrNotFound) {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolve returns the key of the record whose ref is, or uniquely starts with, ref.
+func (s *historyStore) resolve(ref string) (string, error) {
+	if ref == "" {
+		return "", fmt.Errorf("empty build ref: %w", errdefs.ErrInvalidArgument)
+	}
+	keys, err := s.store.List()
+	if err != nil {
+		return "", err
+	}
+	var matches []string
+	for _, key := range keys {
+		if key == ref {
+			return key, nil
+		}
+		if strings.HasPrefix(key, ref) {
+			matches = append(matches, key)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("no such build %q: %w", ref, errdefs.ErrNotFound)
+	case 1:
+		return matches[0], nil
+	default:
+		return "", fmt.Errorf("multiple builds found with prefix %q: %w", ref, errdefs.ErrInvalidArgument)
+	}
+}
+
+func (s *historyStore) get(key string) (*Record, error) {
+	data, err := s.store.Get(key)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, fmt.Errorf("no such build %q: %w", key, errdefs.ErrNotFound)
+		}
+		return nil, err
+	}
+	var rec Record
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal build record %q: %w", key, err)
+	}
+	return &rec, nil
+}
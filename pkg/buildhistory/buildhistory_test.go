@@ -0,0 +1,172 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package buildhistory
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/moby/buildkit/client"
+	"github.com/opencontainers/go-digest"
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/errdefs"
+
+	"github.com/containerd/nerdctl/v2/pkg/internal/filesystem"
+)
+
+func TestStore(t *testing.T) {
+	st, err := NewStore(t.TempDir(), "default")
+	assert.NilError(t, err)
+
+	now := time.Now()
+	older := &Record{Ref: "abc123", CreatedAt: now.Add(-time.Hour), CompletedAt: now.Add(-time.Hour + time.Minute)}
+	newer := &Record{Ref: "abd456", CreatedAt: now, CompletedAt: now.Add(time.Second), Error: "failed to solve"}
+	assert.NilError(t, st.Save(older))
+	assert.NilError(t, st.Save(newer))
+
+	records, err := st.List()
+	assert.NilError(t, err)
+	assert.Equal(t, len(records), 2)
+	assert.Equal(t, records[0].Ref, "abd456")
+	assert.Equal(t, records[0].Status(), "Error")
+	assert.Equal(t, records[1].Ref, "abc123")
+	assert.Equal(t, records[1].Status(), "Completed")
+	assert.Equal(t, records[1].Duration(), time.Minute)
+
+	rec, err := st.Get("abc")
+	assert.NilError(t, err)
+	assert.Equal(t, rec.Ref, "abc123")
+
+	_, err = st.Get("ab")
+	assert.ErrorIs(t, err, errdefs.ErrInvalidArgument)
+
+	_, err = st.Get("xyz")
+	assert.ErrorIs(t, err, errdefs.ErrNotFound)
+
+	assert.NilError(t, st.Remove("abd"))
+	records, err = st.List()
+	assert.NilError(t, err)
+	assert.Equal(t, len(records), 1)
+	assert.ErrorIs(t, st.Remove("abd456"), errdefs.ErrNotFound)
+}
+
+func TestStorePrune(t *testing.T) {
+	st, err := NewStore(t.TempDir(), "default")
+	assert.NilError(t, err)
+	st.(*historyStore).maxRecords = 2
+
+	now := time.Now()
+	assert.NilError(t, st.Save(&Record{Ref: "expired", CreatedAt: now.Add(-MaxAge - time.Hour)}))
+	records, err := st.List()
+	assert.NilError(t, err)
+	assert.Equal(t, len(records), 0)
+
+	assert.NilError(t, st.Save(&Record{Ref: "first", CreatedAt: now.Add(-2 * time.Minute)}))
+	assert.NilError(t, st.Save(&Record{Ref: "second", CreatedAt: now.Add(-time.Minute)}))
+	assert.NilError(t, st.Save(&Record{Ref: "third", CreatedAt: now}))
+	records, err = st.List()
+	assert.NilError(t, err)
+	assert.Equal(t, len(records), 2)
+	assert.Equal(t, records[0].Ref, "third")
+	assert.Equal(t, records[1].Ref, "second")
+}
+
+func TestRecorder(t *testing.T) {
+	started := time.Now()
+	completed := started.Add(time.Second)
+	d1 := digest.FromString("step1")
+	d2 := digest.FromString("step2")
+
+	r := NewRecorder()
+	in := make(chan *client.SolveStatus)
+	out := r.Tee(in)
+	go func() {
+		in <- &client.SolveStatus{Vertexes: []*client.Vertex{{Digest: d1, Name: "[1/2] FROM", Started: &started}}}
+		in <- &client.SolveStatus{Vertexes: []*client.Vertex{{Digest: d2, Name: "[2/2] RUN", Cached: true}}}
+		in <- &client.SolveStatus{Vertexes: []*client.Vertex{{Digest: d1, Name: "[1/2] FROM", Started: &started, Completed: &completed}}}
+		close(in)
+	}()
+	var forwarded int
+	for range out {
+		forwarded++
+	}
+	assert.Equal(t, forwarded, 3)
+
+	vertexes := r.Vertexes()
+	assert.Equal(t, len(vertexes), 2)
+	assert.Equal(t, vertexes[0].Digest, d1)
+	assert.Equal(t, vertexes[0].Duration(), time.Second)
+	assert.Equal(t, vertexes[1].Name, "[2/2] RUN")
+	assert.Assert(t, vertexes[1].Cached)
+	assert.Equal(t, vertexes[1].Duration(), time.Duration(0))
+}
+
+func TestContextDigest(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	assert.NilError(t, filesystem.WriteFile(filepath.Join(dir, "Dockerfile"), []byte("FROM scratch\n"), 0o644))
+	assert.NilError(t, filesystem.WriteFile(filepath.Join(dir, "ignored.log"), []byte("foo"), 0o644))
+	assert.NilError(t, filesystem.WriteFile(filepath.Join(dir, ".dockerignore"), []byte("*.log\n"), 0o644))
+
+	d1, err := ContextDigest(ctx, dir)
+	assert.NilError(t, err)
+	assert.NilError(t, d1.Validate())
+
+	// files excluded by .dockerignore do not change the digest
+	assert.NilError(t, filesystem.WriteFile(filepath.Join(dir, "ignored.log"), []byte("bar"), 0o644))
+	d2, err := ContextDigest(ctx, dir)
+	assert.NilError(t, err)
+	assert.Equal(t, d1, d2)
+
+	assert.NilError(t, filesystem.WriteFile(filepath.Join(dir, "Dockerfile"), []byte("FROM busybox\n"), 0o644))
+	mtime := time.Now().Add(time.Hour)
+	assert.NilError(t, os.Chtimes(filepath.Join(dir, "Dockerfile"), mtime, mtime))
+	d3, err := ContextDigest(ctx, dir)
+	assert.NilError(t, err)
+	assert.Assert(t, d1 != d3)
+}
+
+func TestWriteTrace(t *testing.T) {
+	created := time.Unix(1700000000, 0)
+	started := created.Add(time.Second)
+	completed := started.Add(2 * time.Second)
+	rec := &Record{
+		Ref:         "abc123",
+		CreatedAt:   created,
+		CompletedAt: completed,
+		Vertexes: []*Vertex{
+			{Digest: digest.FromString("step1"), Name: "[1/1] RUN make", Started: &started, Completed: &completed},
+			{Digest: digest.FromString("step2"), Name: "never started"},
+		},
+	}
+	var b bytes.Buffer
+	assert.NilError(t, WriteTrace(&b, rec))
+
+	var tr trace
+	assert.NilError(t, json.Unmarshal(b.Bytes(), &tr))
+	assert.Equal(t, len(tr.TraceEvents), 2)
+	assert.Equal(t, tr.TraceEvents[0].Duration, int64(3_000_000))
+	assert.Equal(t, tr.TraceEvents[1].Name, "[1/1] RUN make")
+	assert.Equal(t, tr.TraceEvents[1].Timestamp, started.UnixMicro())
+	assert.Equal(t, tr.TraceEvents[1].Duration, int64(2_000_000))
+}
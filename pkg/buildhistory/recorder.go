@@ -0,0 +1,151 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package buildhistory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	gofs "io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/moby/buildkit/client"
+	"github.com/moby/patternmatcher/ignorefile"
+	"github.com/opencontainers/go-digest"
+	"github.com/tonistiigi/fsutil"
+)
+
+// Recorder collects the vertexes of a build from its status updates.
+type Recorder struct {
+	mu       sync.Mutex
+	vertexes map[digest.Digest]*Vertex
+	order    []digest.Digest
+}
+
+// NewRecorder returns an empty Recorder.
+func NewRecorder() *Recorder {
+	return &Recorder{vertexes: map[digest.Digest]*Vertex{}}
+}
+
+// Tee records the status updates received from in and forwards them to the returned channel,
+// which is closed when in is closed.
+func (r *Recorder) Tee(in <-chan *client.SolveStatus) chan *client.SolveStatus {
+	out := make(chan *client.SolveStatus)
+	go func() {
+		defer close(out)
+		for s := range in {
+			r.Add(s)
+			out <- s
+		}
+	}()
+	return out
+}
+
+// Add records a status update.
+func (r *Recorder) Add(s *client.SolveStatus) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, v := range s.Vertexes {
+		rv, ok := r.vertexes[v.Digest]
+		if !ok {
+			rv = &Vertex{Digest: v.Digest}
+			r.vertexes[v.Digest] = rv
+			r.order = append(r.order, v.Digest)
+		}
+		rv.Name = v.Name
+		if v.Started != nil {
+			rv.Started = v.Started
+		}
+		if v.Completed != nil {
+			rv.Completed = v.Completed
+		}
+		rv.Cached = v.Cached
+		rv.Error = v.Error
+	}
+}
+
+// Vertexes returns the recorded vertexes, in the order they were first reported.
+func (r *Recorder) Vertexes() []*Vertex {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	res := make([]*Vertex, 0, len(r.order))
+	for _, d := range r.order {
+		v := *r.vertexes[d]
+		res = append(res, &v)
+	}
+	return res
+}
+
+// ContextDigest returns a digest of the files of the build context directory
+// that are not excluded by its .dockerignore file.
+// The digest covers the paths, modes, sizes, modification times and symlink targets of the files,
+// like the change detection of the context transfer: the contents are not read, so that it is cheap
+// to compute before each build.
+func ContextDigest(ctx context.Context, dir string) (digest.Digest, error) {
+	var excludes []string
+	f, err := os.Open(filepath.Join(dir, ".dockerignore"))
+	switch {
+	case err == nil:
+		excludes, err = ignorefile.ReadAll(f)
+		f.Close()
+		if err != nil {
+			return "", fmt.Errorf("failed to read .dockerignore: %w", err)
+		}
+	case !errors.Is(err, os.ErrNotExist):
+		return "", err
+	}
+
+	fs, err := fsutil.NewFS(dir)
+	if err != nil {
+		return "", err
+	}
+	fs, err = fsutil.NewFilterFS(fs, &fsutil.FilterOpt{ExcludePatterns: excludes})
+	if err != nil {
+		return "", err
+	}
+
+	digester := digest.Canonical.Digester()
+	h := digester.Hash()
+	err = fs.Walk(ctx, "", func(path string, entry gofs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(h, "%s\x00%o\x00", path, info.Mode())
+		switch {
+		case info.Mode().IsRegular():
+			fmt.Fprintf(h, "%d\x00%d\x00", info.Size(), info.ModTime().UnixNano())
+		case info.Mode()&gofs.ModeSymlink != 0:
+			target, err := os.Readlink(filepath.Join(dir, path))
+			if err != nil {
+				return err
+			}
+			fmt.Fprint(h, target)
+		}
+		_, err = h.Write([]byte{0})
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	return digester.Digest(), nil
+}
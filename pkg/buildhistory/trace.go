@@ -0,0 +1,90 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package buildhistory
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// traceEvent is a complete event of the Trace Event Format.
+// https://docs.google.com/document/d/1CvAClvFfyA5R-PhYUmn5OOQtYMH4h6I0nSsKchNAySU
+type traceEvent struct {
+	Name      string         `json:"name"`
+	Category  string         `json:"cat,omitempty"`
+	Phase     string         `json:"ph"`
+	Timestamp int64          `json:"ts"`
+	Duration  int64          `json:"dur"`
+	PID       int            `json:"pid"`
+	TID       int            `json:"tid"`
+	Args      map[string]any `json:"args,omitempty"`
+}
+
+type trace struct {
+	TraceEvents     []traceEvent `json:"traceEvents"`
+	DisplayTimeUnit string       `json:"displayTimeUnit"`
+}
+
+// WriteTrace writes the timings of the build and of its steps in the Trace Event Format,
+// which can be opened with chrome://tracing or https://ui.perfetto.dev .
+func WriteTrace(w io.Writer, rec *Record) error {
+	t := trace{
+		TraceEvents: []traceEvent{
+			{
+				Name:      "build " + rec.Ref,
+				Category:  "build",
+				Phase:     "X",
+				Timestamp: rec.CreatedAt.UnixMicro(),
+				Duration:  rec.Duration().Microseconds(),
+				PID:       1,
+				TID:       0,
+				Args: map[string]any{
+					"tags":    rec.Tags,
+					"context": rec.Context,
+					"status":  rec.Status(),
+				},
+			},
+		},
+		DisplayTimeUnit: "ms",
+	}
+	for i, v := range rec.Vertexes {
+		if v.Started == nil {
+			continue
+		}
+		args := map[string]any{
+			"digest": v.Digest.String(),
+			"cached": v.Cached,
+		}
+		if v.Error != "" {
+			args["error"] = v.Error
+		}
+		t.TraceEvents = append(t.TraceEvents, traceEvent{
+			Name:      v.Name,
+			Category:  "vertex",
+			Phase:     "X",
+			Timestamp: v.Started.UnixMicro(),
+			Duration:  v.Duration().Microseconds(),
+			PID:       1,
+			// one row per vertex, as steps run concurrently
+			TID:  i + 1,
+			Args: args,
+		})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(t)
+}
@@ -25,18 +25,19 @@ package buildkitutil
 
 import (
 	"bytes"
-	"encoding/json"
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"io/fs"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"runtime"
 	"slices"
 	"strings"
 
+	"github.com/moby/buildkit/client"
+
 	"github.com/containerd/log"
 
 	"github.com/containerd/nerdctl/v2/pkg/internal/filesystem"
@@ -51,14 +52,6 @@ const (
 	TempDockerfileName string = "docker-build-tempdockerfile-"
 )
 
-func BuildctlBinary() (string, error) {
-	return exec.LookPath("buildctl")
-}
-
-func BuildctlBaseArgs(buildkitHost string) []string {
-	return []string{"--addr=" + buildkitHost}
-}
-
 func GetBuildkitHost(namespace string) (string, error) {
 	if buildkitHost := os.Getenv("BUILDKIT_HOST"); buildkitHost != "" {
 		if _, err := pingBKDaemon(buildkitHost); err != nil {
@@ -87,43 +80,33 @@ func GetBuildkitHost(namespace string) (string, error) {
 	return "", fmt.Errorf("no buildkit host is available, tried %d candidates: %w", len(paths), allErr)
 }
 
+// NewClient returns a BuildKit client connected to buildkitHost.
+func NewClient(ctx context.Context, buildkitHost string) (*client.Client, error) {
+	return client.New(ctx, buildkitHost)
+}
+
 func GetWorkerLabels(buildkitHost string) (labels map[string]string, _ error) {
-	buildctlBinary, err := BuildctlBinary()
+	ctx := context.Background()
+	c, err := NewClient(ctx, buildkitHost)
 	if err != nil {
 		return nil, err
 	}
-	args := BuildctlBaseArgs(buildkitHost)
-	args = append(args, "debug", "workers", "--format", "{{json .}}")
-	buildctlCheckCmd := exec.Command(buildctlBinary, args...)
-	buildctlCheckCmd.Env = os.Environ()
-	out, err := buildctlCheckCmd.Output()
+	defer c.Close()
+	workers, err := c.ListWorkers(ctx)
 	if err != nil {
 		return nil, err
 	}
-	var workers []json.RawMessage
-	if err := json.Unmarshal(out, &workers); err != nil {
-		return nil, err
-	}
 	if len(workers) == 0 {
 		return nil, fmt.Errorf("no worker available")
 	}
-	metadata := map[string]json.RawMessage{}
-	if err := json.Unmarshal(workers[0], &metadata); err != nil {
-		return nil, err
-	}
-	labelsRaw, ok := metadata["labels"]
-	if !ok {
+	if workers[0].Labels == nil {
 		return nil, fmt.Errorf("worker doesn't have labels")
 	}
-	labels = map[string]string{}
-	if err := json.Unmarshal(labelsRaw, &labels); err != nil {
-		return nil, err
-	}
-	return labels, nil
+	return workers[0].Labels, nil
 }
 
 func getHint() string {
-	hint := "`buildctl` needs to be installed and `buildkitd` needs to be running, see https://github.com/moby/buildkit"
+	hint := "`buildkitd` needs to be running, see https://github.com/moby/buildkit"
 	if rootlessutil.IsRootless() {
 		hint += " , and `containerd-rootless-setuptool.sh install-buildkit` for OCI worker or `containerd-rootless-setuptool.sh install-buildkit-containerd` for containerd worker"
 	}
@@ -145,16 +128,14 @@ func pingBKDaemon(buildkitHost string) (output string, _ error) {
 	if !slices.Contains(supportedOses, runtime.GOOS) {
 		return "", fmt.Errorf("only %s are supported", strings.Join(supportedOses, ", "))
 	}
-	buildctlBinary, err := BuildctlBinary()
+	ctx := context.Background()
+	c, err := NewClient(ctx, buildkitHost)
 	if err != nil {
 		return "", err
 	}
-	args := BuildctlBaseArgs(buildkitHost)
-	args = append(args, "debug", "workers")
-	buildctlCheckCmd := exec.Command(buildctlBinary, args...)
-	buildctlCheckCmd.Env = os.Environ()
-	if out, err := buildctlCheckCmd.CombinedOutput(); err != nil {
-		return string(out), err
+	defer c.Close()
+	if _, err := c.ListWorkers(ctx); err != nil {
+		return "", err
 	}
 	return "", nil
 }
@@ -0,0 +1,92 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package buildkitutil
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/moby/buildkit/client"
+
+	"github.com/containerd/nerdctl/v2/pkg/filterutil"
+)
+
+// FilterUntil selects the build cache records last used before the given duration or timestamp.
+const FilterUntil = filterutil.Until
+
+// ParseFilters converts Docker-style build cache filters (e.g., "type=regular", "until=24h")
+// into BuildKit filters, which use the containerd filter syntax (e.g., "type==regular").
+//
+// The "until" filter is not a BuildKit filter, so it is returned separately as a duration.
+// The value can be a duration (e.g., "24h") or a timestamp (e.g., "2006-01-02T15:04:05").
+func ParseFilters(filters []string) (bkFilters []string, until time.Duration, _ error) {
+	for _, f := range filters {
+		var (
+			key, value string
+			op         = "=="
+		)
+		if k, v, ok := strings.Cut(f, "!="); ok {
+			key, value, op = k, v, "!="
+		} else if k, v, ok := strings.Cut(f, "="); ok {
+			key, value = k, strings.TrimPrefix(v, "=")
+		} else {
+			return nil, 0, fmt.Errorf("invalid filter %q, expected key=value", f)
+		}
+		key = strings.TrimSpace(key)
+		if key == "" {
+			return nil, 0, fmt.Errorf("invalid filter %q, empty key", f)
+		}
+		switch key {
+		case FilterUntil:
+			if op != "==" {
+				return nil, 0, fmt.Errorf("invalid filter %q, %q does not support %q", f, key, op)
+			}
+			d, err := parseUntil(value)
+			if err != nil {
+				return nil, 0, fmt.Errorf("invalid filter %q: %w", f, err)
+			}
+			until = d
+		case "type":
+			if !isKnownRecordType(value) {
+				return nil, 0, fmt.Errorf("invalid filter %q, unknown record type %q", f, value)
+			}
+			bkFilters = append(bkFilters, key+op+value)
+		default:
+			bkFilters = append(bkFilters, key+op+value)
+		}
+	}
+	return bkFilters, until, nil
+}
+
+func parseUntil(value string) (time.Duration, error) {
+	now := time.Now()
+	t, err := filterutil.ParseUntil(value, now)
+	if err != nil {
+		return 0, err
+	}
+	return now.Sub(t), nil
+}
+
+func isKnownRecordType(t string) bool {
+	switch client.UsageRecordType(t) {
+	case client.UsageRecordTypeInternal, client.UsageRecordTypeFrontend, client.UsageRecordTypeLocalSource,
+		client.UsageRecordTypeGitCheckout, client.UsageRecordTypeCacheMount, client.UsageRecordTypeRegular:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package buildkitutil
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestParseFilters(t *testing.T) {
+	testCases := []struct {
+		name          string
+		filters       []string
+		expectFilters []string
+		expectUntil   time.Duration
+		expectErr     string
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name:          "type and until",
+			filters:       []string{"type=regular", "until=24h"},
+			expectFilters: []string{"type==regular"},
+			expectUntil:   24 * time.Hour,
+		},
+		{
+			name:          "passthrough",
+			filters:       []string{"description!=foo", "id==abc"},
+			expectFilters: []string{"description!=foo", "id==abc"},
+		},
+		{
+			name:      "unknown type",
+			filters:   []string{"type=foo"},
+			expectErr: "unknown record type",
+		},
+		{
+			name:      "invalid until",
+			filters:   []string{"until=yesterday"},
+			expectErr: "neither a duration nor a timestamp",
+		},
+		{
+			name:      "missing value",
+			filters:   []string{"inuse"},
+			expectErr: "expected key=value",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			filters, until, err := ParseFilters(tc.filters)
+			if tc.expectErr != "" {
+				assert.ErrorContains(t, err, tc.expectErr)
+				return
+			}
+			assert.NilError(t, err)
+			assert.DeepEqual(t, filters, tc.expectFilters)
+			assert.Equal(t, until, tc.expectUntil)
+		})
+	}
+}
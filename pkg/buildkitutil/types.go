@@ -14,31 +14,12 @@
    limitations under the License.
 */
 
-/*
-   Portions from https://github.com/moby/buildkit/blob/v0.11.0/client/diskusage.go
-   Copyright (C) The BuildKit authors.
-   Licensed under the Apache License, Version 2.0
-*/
-
 package buildkitutil
 
-import "time"
-
-// UsageInfo is from https://github.com/moby/buildkit/blob/v0.11.0/client/diskusage.go#L12-L25
-type UsageInfo struct {
-	ID      string `json:"id"`
-	Mutable bool   `json:"mutable"`
-	InUse   bool   `json:"inUse"`
-	Size    int64  `json:"size"`
+import "github.com/moby/buildkit/client"
 
-	CreatedAt   time.Time       `json:"createdAt"`
-	LastUsedAt  *time.Time      `json:"lastUsedAt"`
-	UsageCount  int             `json:"usageCount"`
-	Parents     []string        `json:"parents"`
-	Description string          `json:"description"`
-	RecordType  UsageRecordType `json:"recordType"`
-	Shared      bool            `json:"shared"`
-}
+// UsageInfo is a build cache record, as reported by `buildctl du`
+type UsageInfo = client.UsageInfo
 
-// UsageRecordType is from https://github.com/moby/buildkit/blob/v0.11.0/client/diskusage.go#L75
-type UsageRecordType string
+// UsageRecordType is the type of a build cache record (e.g., "regular", "exec.cachemount")
+type UsageRecordType = client.UsageRecordType
@@ -115,6 +115,15 @@ func (c *ncio) Cancel() {
 	}
 }
 
+// parseLogURI returns nil when the container IO does not need to forward the streams
+// to a logging binary or a log file.
+func parseLogURI(logURI string) (*url.URL, error) {
+	if runtime.GOOS == "windows" || logURI == "" || logURI == "none" {
+		return nil, nil
+	}
+	return url.Parse(logURI)
+}
+
 func NewContainerIO(namespace string, logURI string, tty bool, stdin io.Reader, stdout, stderr io.Writer) cio.Creator {
 	return func(id string) (_ cio.IO, err error) {
 		var (
@@ -150,7 +159,20 @@ func NewContainerIO(namespace string, logURI string, tty bool, stdin io.Reader,
 			stderrWriters = append(stderrWriters, stderr)
 		}
 
-		if runtime.GOOS != "windows" && logURI != "" && logURI != "none" {
+		u, err := parseLogURI(logURI)
+		if err != nil {
+			return nil, err
+		}
+		if u != nil && u.Scheme == "file" {
+			// the shim appends both stdout and stderr to the file
+			f, err := os.OpenFile(u.Path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0640)
+			if err != nil {
+				return nil, fmt.Errorf("failed to open log file %q: %w", u.Path, err)
+			}
+			closers = append(closers, f.Close)
+			stdoutWriters = append(stdoutWriters, f)
+			stderrWriters = append(stderrWriters, f)
+		} else if u != nil {
 			// starting logging binary logic is from https://github.com/containerd/containerd/blob/194a1fdd2cde35bc019ef138f30485e27fe0913e/cmd/containerd-shim-runc-v2/process/io.go#L247
 			stdoutr, stdoutw, err := os.Pipe()
 			if err != nil {
@@ -170,10 +192,6 @@ func NewContainerIO(namespace string, logURI string, tty bool, stdin io.Reader,
 			}
 			closers = append(closers, r.Close, w.Close)
 
-			u, err := url.Parse(logURI)
-			if err != nil {
-				return nil, err
-			}
 			cmd = process.NewBinaryCmd(u, id, namespace)
 			cmd.ExtraFiles = append(cmd.ExtraFiles, stdoutr, stderrr, w)
 
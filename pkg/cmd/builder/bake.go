@@ -0,0 +1,272 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package builder
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/bake"
+	"github.com/containerd/nerdctl/v2/pkg/composer/pipetagger"
+	"github.com/containerd/nerdctl/v2/pkg/strutil"
+)
+
+// Bake builds the targets defined in bake or compose files.
+// The targets are built concurrently by `nerdctl build` processes sharing the same BuildKit daemon,
+// hence the same build cache. At most options.Parallel processes run at the same time.
+func Bake(ctx context.Context, options types.BuilderBakeOptions) error {
+	files, err := bake.ReadFiles(options.Files)
+	if err != nil {
+		return err
+	}
+	cfg, err := bake.Parse(ctx, files, strutil.ConvertKVStringsToMap(os.Environ()))
+	if err != nil {
+		return err
+	}
+	targets, err := cfg.ResolveTargets(options.Targets)
+	if err != nil {
+		return err
+	}
+	for _, t := range targets {
+		if options.NoCache {
+			t.NoCache = &options.NoCache
+		}
+		if options.Pull {
+			t.Pull = &options.Pull
+		}
+	}
+
+	if options.Print {
+		return printBakeTargets(options.Stdout, targets)
+	}
+
+	progress := options.Progress
+	if progress == "" || progress == "auto" {
+		if len(targets) > 1 {
+			// the interactive progress of concurrent builds cannot share the terminal
+			progress = "plain"
+		}
+	}
+
+	width := 0
+	for _, t := range targets {
+		width = max(width, len(t.Name))
+	}
+
+	eg, ectx := errgroup.WithContext(ctx)
+	parallel := options.Parallel
+	if parallel == 0 {
+		parallel = runtime.NumCPU()
+	}
+	eg.SetLimit(parallel)
+	for _, t := range targets {
+		eg.Go(func() error {
+			args, cleanup, err := bakeBuildArgs(t, options.BuildKitHost, progress)
+			if cleanup != nil {
+				defer cleanup()
+			}
+			if err != nil {
+				return fmt.Errorf("target %q: %w", t.Name, err)
+			}
+			cmdArgs := append(append(append([]string{}, options.NerdctlArgs...), "build"), args...)
+			cmd := exec.CommandContext(ectx, options.NerdctlCmd, cmdArgs...)
+			log.G(ctx).Debugf("Running %v", cmd.Args)
+			if err := runTagged(cmd, t.Name, len(targets) > 1, width, options); err != nil {
+				return fmt.Errorf("failed to build target %q: %w", t.Name, err)
+			}
+			return nil
+		})
+	}
+	return eg.Wait()
+}
+
+// runTagged runs the command, prefixing each line of its output with the name of the target when tag is set.
+func runTagged(cmd *exec.Cmd, name string, tag bool, width int, options types.BuilderBakeOptions) error {
+	if !tag {
+		cmd.Stdout = options.Stdout
+		cmd.Stderr = options.Stderr
+		return cmd.Run()
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	var wg sync.WaitGroup
+	for _, p := range []struct {
+		w io.Writer
+		r io.Reader
+	}{{options.Stdout, stdout}, {options.Stderr, stderr}} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := pipetagger.New(p.w, p.r, name, width, options.NoColor).Run(); err != nil {
+				log.L.WithError(err).Debugf("failed to read the output of target %q", name)
+			}
+		}()
+	}
+	// the pipes must be drained before calling Wait
+	wg.Wait()
+	return cmd.Wait()
+}
+
+// bakeBuildArgs returns the `nerdctl build` arguments for building the target.
+func bakeBuildArgs(t *bake.Target, buildkitHost, progress string) (args []string, cleanup func(), _ error) {
+	buildCtx := t.Context
+	if buildCtx == "" {
+		buildCtx = "."
+	}
+	if buildkitHost != "" {
+		args = append(args, "--buildkit-host="+buildkitHost)
+	}
+	if progress != "" {
+		args = append(args, "--progress="+progress)
+	}
+	for _, tag := range t.Tags {
+		args = append(args, "--tag="+tag)
+	}
+
+	switch {
+	case t.DockerfileInline != "":
+		f, err := os.CreateTemp("", "inline-dockerfile-*.Dockerfile")
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create temp file for dockerfile-inline: %w", err)
+		}
+		cleanup = func() {
+			os.Remove(f.Name())
+		}
+		_, err = f.WriteString(t.DockerfileInline)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return nil, cleanup, fmt.Errorf("failed to write dockerfile-inline: %w", err)
+		}
+		args = append(args, "--file="+f.Name())
+	case t.Dockerfile != "":
+		dockerfile := t.Dockerfile
+		if !filepath.IsAbs(dockerfile) {
+			// relative to the context, as in docker buildx bake
+			dockerfile = filepath.Join(buildCtx, dockerfile)
+		}
+		args = append(args, "--file="+dockerfile)
+	}
+
+	for _, k := range sortedKeys(t.Args) {
+		if v := t.Args[k]; v != nil {
+			args = append(args, "--build-arg="+k+"="+*v)
+		} else {
+			args = append(args, "--build-arg="+k)
+		}
+	}
+	for _, k := range sortedKeys(t.Labels) {
+		args = append(args, "--label="+k+"="+t.Labels[k])
+	}
+	for _, k := range sortedKeys(t.Contexts) {
+		args = append(args, "--build-context="+k+"="+t.Contexts[k])
+	}
+	if t.Target != "" {
+		args = append(args, "--target="+t.Target)
+	}
+	if len(t.Platforms) > 0 {
+		args = append(args, "--platform="+strings.Join(t.Platforms, ","))
+	}
+	for _, s := range t.CacheFrom {
+		args = append(args, "--cache-from="+s)
+	}
+	for _, s := range t.CacheTo {
+		args = append(args, "--cache-to="+s)
+	}
+	for _, s := range t.Secret {
+		args = append(args, "--secret="+s)
+	}
+	for _, s := range t.SSH {
+		args = append(args, "--ssh="+s)
+	}
+	for _, s := range t.Attest {
+		args = append(args, "--attest="+s)
+	}
+	switch len(t.Output) {
+	case 0:
+	case 1:
+		args = append(args, "--output="+t.Output[0])
+	default:
+		return nil, cleanup, errors.New("multiple outputs are not supported")
+	}
+	if t.Network != "" {
+		args = append(args, "--network="+t.Network)
+	}
+	if t.NoCache != nil && *t.NoCache {
+		args = append(args, "--no-cache")
+	}
+	if t.Pull != nil {
+		args = append(args, fmt.Sprintf("--pull=%t", *t.Pull))
+	}
+	args = append(args, buildCtx)
+	return args, cleanup, nil
+}
+
+// printBakeTargets prints the resolved targets in the JSON format of `docker buildx bake --print`.
+// All the resolved targets are listed in the default group.
+func printBakeTargets(w io.Writer, targets []*bake.Target) error {
+	c := bake.Config{
+		Groups:  map[string]*bake.Group{},
+		Targets: map[string]*bake.Target{},
+	}
+	var targetNames []string
+	for _, t := range targets {
+		c.Targets[t.Name] = t
+		targetNames = append(targetNames, t.Name)
+	}
+	c.Groups[bake.DefaultGroup] = &bake.Group{Targets: targetNames}
+	b, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(b))
+	return err
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
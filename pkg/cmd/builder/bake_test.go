@@ -0,0 +1,87 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package builder
+
+import (
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/nerdctl/v2/pkg/bake"
+	"github.com/containerd/nerdctl/v2/pkg/internal/filesystem"
+)
+
+func TestBakeBuildArgs(t *testing.T) {
+	value := "1"
+	noCache := true
+	target := &bake.Target{
+		Name:       "app",
+		Context:    "app",
+		Dockerfile: "Dockerfile.app",
+		Args:       map[string]*string{"B": &value, "A": nil},
+		Labels:     map[string]string{"foo": "bar"},
+		Tags:       []string{"example.com/app:v1", "example.com/app:latest"},
+		Target:     "prod",
+		Platforms:  []string{"linux/amd64", "linux/arm64"},
+		CacheFrom:  []string{"type=local,src=/tmp/cache"},
+		NoCache:    &noCache,
+	}
+	args, cleanup, err := bakeBuildArgs(target, "unix:///run/buildkit/buildkitd.sock", "plain")
+	assert.NilError(t, err)
+	assert.Assert(t, cleanup == nil)
+	assert.DeepEqual(t, args, []string{
+		"--buildkit-host=unix:///run/buildkit/buildkitd.sock",
+		"--progress=plain",
+		"--tag=example.com/app:v1",
+		"--tag=example.com/app:latest",
+		"--file=app/Dockerfile.app",
+		"--build-arg=A",
+		"--build-arg=B=1",
+		"--label=foo=bar",
+		"--target=prod",
+		"--platform=linux/amd64,linux/arm64",
+		"--cache-from=type=local,src=/tmp/cache",
+		"--no-cache",
+		"app",
+	})
+
+	target = &bake.Target{
+		Name:             "inline",
+		DockerfileInline: "FROM scratch\n",
+		Output:           []string{"type=local,dest=out"},
+	}
+	args, cleanup, err = bakeBuildArgs(target, "", "")
+	assert.NilError(t, err)
+	assert.Assert(t, cleanup != nil)
+	assert.Equal(t, len(args), 3)
+	assert.Assert(t, strings.HasPrefix(args[0], "--file="))
+	dt, err := filesystem.ReadFile(strings.TrimPrefix(args[0], "--file="))
+	assert.NilError(t, err)
+	assert.Equal(t, string(dt), "FROM scratch\n")
+	assert.Equal(t, args[1], "--output=type=local,dest=out")
+	assert.Equal(t, args[2], ".")
+	cleanup()
+
+	target.Output = append(target.Output, "type=docker")
+	args, cleanup, err = bakeBuildArgs(target, "", "")
+	if cleanup != nil {
+		cleanup()
+	}
+	assert.ErrorContains(t, err, "multiple outputs")
+	assert.Assert(t, args == nil)
+}
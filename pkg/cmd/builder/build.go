@@ -23,12 +23,21 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strconv"
 	"strings"
 
+	dockercliconfig "github.com/docker/cli/cli/config"
+	bkclient "github.com/moby/buildkit/client"
+	"github.com/moby/buildkit/cmd/buildctl/build"
+	"github.com/moby/buildkit/exporter/containerimage/exptypes"
+	"github.com/moby/buildkit/identity"
+	"github.com/moby/buildkit/session"
+	"github.com/moby/buildkit/session/auth/authprovider"
+	"github.com/moby/buildkit/session/sshforward/sshprovider"
+	"github.com/moby/buildkit/util/progress/progressui"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"golang.org/x/sync/errgroup"
 
 	containerd "github.com/containerd/containerd/v2/client"
 	"github.com/containerd/containerd/v2/core/images"
@@ -38,6 +47,7 @@ import (
 	"github.com/containerd/platforms"
 
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/buildhistory"
 	"github.com/containerd/nerdctl/v2/pkg/buildkitutil"
 	"github.com/containerd/nerdctl/v2/pkg/clientutil"
 	"github.com/containerd/nerdctl/v2/pkg/containerutil"
@@ -62,8 +72,14 @@ func (p platformParser) DefaultSpec() platforms.Platform {
 	return platforms.DefaultSpec()
 }
 
-func Build(ctx context.Context, client *containerd.Client, options types.BuilderBuildOptions) error {
-	buildctlBinary, buildctlArgs, needsLoading, metaFile, tags, cleanup, err := generateBuildctlArgs(ctx, client, options)
+func Build(ctx context.Context, client *containerd.Client, options types.BuilderBuildOptions) (retErr error) {
+	bkClient, err := buildkitutil.NewClient(ctx, options.BuildKitHost)
+	if err != nil {
+		return err
+	}
+	defer bkClient.Close()
+
+	solveOpt, needsLoading, tags, cleanup, err := generateSolveOpt(ctx, client, options)
 	if err != nil {
 		return err
 	}
@@ -71,45 +87,81 @@ func Build(ctx context.Context, client *containerd.Client, options types.Builder
 		defer cleanup()
 	}
 
-	log.L.Debugf("running %s %v", buildctlBinary, buildctlArgs)
-	buildctlCmd := exec.Command(buildctlBinary, buildctlArgs...)
-	buildctlCmd.Env = os.Environ()
+	rec := newBuildRecord(ctx, solveOpt, tags, options)
+	recorder := buildhistory.NewRecorder()
+	defer func() {
+		saveBuildRecord(ctx, rec, recorder, retErr, options)
+	}()
 
-	var buildctlStdout io.Reader
+	progressMode := progressui.DisplayMode(options.Progress)
+	if options.Quiet {
+		progressMode = progressui.QuietMode
+	}
+	display, err := progressui.NewDisplay(options.Stderr, progressMode)
+	if err != nil {
+		return err
+	}
+
+	eg, ectx := errgroup.WithContext(ctx)
+
+	// When the result has to be loaded into containerd, the exporter streams the image archive
+	// through the session straight into the image store.
+	var (
+		loadReader *io.PipeReader
+		loadWriter *io.PipeWriter
+		platMC     platforms.MatchComparer
+	)
 	if needsLoading {
-		buildctlStdout, err = buildctlCmd.StdoutPipe()
+		// before starting the solve, which would block on the pipe if nothing reads it
+		platMC, err = platformutil.NewMatchComparer(false, options.Platform)
 		if err != nil {
 			return err
 		}
-	} else {
-		buildctlCmd.Stdout = options.Stdout
-	}
-	if !options.Quiet {
-		buildctlCmd.Stderr = options.Stderr
+		loadReader, loadWriter = io.Pipe()
+		solveOpt.Exports[0].Output = func(map[string]string) (io.WriteCloser, error) {
+			return loadWriter, nil
+		}
 	}
 
-	if err := buildctlCmd.Start(); err != nil {
+	statusCh := make(chan *bkclient.SolveStatus)
+	var resp *bkclient.SolveResponse
+	eg.Go(func() error {
+		var err error
+		log.L.Debugf("solving %s with frontend attrs %v", solveOpt.Ref, solveOpt.FrontendAttrs)
+		resp, err = bkClient.Solve(ectx, nil, *solveOpt, statusCh)
+		if loadWriter != nil {
+			// unblock the loader when the solve failed before the exporter closed the stream
+			loadWriter.CloseWithError(err)
+		}
 		return err
-	}
+	})
+	eg.Go(func() error {
+		// not using ectx, so that the display can finish reporting errors
+		_, err := display.UpdateFrom(context.TODO(), recorder.Tee(statusCh))
+		return err
+	})
 
 	if needsLoading {
-		platMC, err := platformutil.NewMatchComparer(false, options.Platform)
-		if err != nil {
-			return err
-		}
-		if err = loadImage(ctx, buildctlStdout, options.GOptions.Namespace, options.GOptions.Address, options.GOptions.Snapshotter, options.Stdout, platMC, options.Quiet); err != nil {
+		if err := loadImage(ctx, loadReader, options.GOptions.Namespace, options.GOptions.Address, options.GOptions.Snapshotter, options.Stdout, platMC, options.Quiet); err != nil {
+			loadReader.CloseWithError(err)
+			_ = eg.Wait()
 			return err
 		}
 	}
 
-	if err = buildctlCmd.Wait(); err != nil {
+	if err := eg.Wait(); err != nil {
 		return err
 	}
 
+	for k, v := range resp.ExporterResponse {
+		log.L.Debugf("exporter response: %s=%s", k, v)
+	}
+	rec.ImageDigest = resp.ExporterResponse[exptypes.ExporterImageDigestKey]
+
 	if options.IidFile != "" {
-		id, err := getDigestFromMetaFile(metaFile)
-		if err != nil {
-			return err
+		id, ok := resp.ExporterResponse[exptypes.ExporterImageDigestKey]
+		if !ok {
+			return fmt.Errorf("failed to find %s in exporter response", exptypes.ExporterImageDigestKey)
 		}
 		if err := filesystem.WriteFile(options.IidFile, []byte(id), 0644); err != nil {
 			return err
@@ -141,6 +193,7 @@ func Build(ctx context.Context, client *containerd.Client, options types.Builder
 		}
 	}
 
+	labelBuiltImages(ctx, client, tags, rec.Ref)
 	return nil
 }
 
@@ -194,23 +247,18 @@ func loadImage(ctx context.Context, in io.Reader, namespace, address, snapshotte
 	return nil
 }
 
-func generateBuildctlArgs(ctx context.Context, client *containerd.Client, options types.BuilderBuildOptions) (buildCtlBinary string,
-	buildctlArgs []string, needsLoading bool, metaFile string, tags []string, cleanup func(), err error) {
-
-	buildctlBinary, err := buildkitutil.BuildctlBinary()
-	if err != nil {
-		return "", nil, false, "", nil, nil, err
-	}
+func generateSolveOpt(ctx context.Context, client *containerd.Client, options types.BuilderBuildOptions) (solveOpt *bkclient.SolveOpt,
+	needsLoading bool, tags []string, cleanup func(), err error) {
 
 	output := options.Output
 	if output == "" {
 		info, err := client.Server(ctx)
 		if err != nil {
-			return "", nil, false, "", nil, nil, err
+			return nil, false, nil, nil, err
 		}
 		sharable, err := isImageSharable(options.BuildKitHost, options.GOptions.Namespace, info.UUID, options.GOptions.Snapshotter, options.Platform)
 		if err != nil {
-			return "", nil, false, "", nil, nil, err
+			return nil, false, nil, nil, err
 		}
 		if sharable {
 			output = "type=image,unpack=true" // ensure the target stage is unlazied (needed for any snapshotters)
@@ -239,7 +287,7 @@ func generateBuildctlArgs(ctx context.Context, client *containerd.Client, option
 		ref := tags[0]
 		parsedReference, err := referenceutil.Parse(ref)
 		if err != nil {
-			return "", nil, false, "", nil, nil, err
+			return nil, false, nil, nil, err
 		}
 		output += ",name=" + parsedReference.String()
 
@@ -247,7 +295,7 @@ func generateBuildctlArgs(ctx context.Context, client *containerd.Client, option
 		for idx, tag := range tags {
 			parsedReference, err = referenceutil.Parse(tag)
 			if err != nil {
-				return "", nil, false, "", nil, nil, err
+				return nil, false, nil, nil, err
 			}
 			tags[idx] = parsedReference.String()
 		}
@@ -255,15 +303,28 @@ func generateBuildctlArgs(ctx context.Context, client *containerd.Client, option
 		output = output + ",dangling-name-prefix=<none>"
 	}
 
-	buildctlArgs = buildkitutil.BuildctlBaseArgs(options.BuildKitHost)
+	var exports []bkclient.ExportEntry
+	if needsLoading {
+		// The output stream is connected to the image loader by the caller.
+		exports, err = parseLoadingOutput(output)
+	} else {
+		exports, err = build.ParseOutput([]string{output})
+	}
+	if err != nil {
+		return nil, false, nil, nil, err
+	}
+
+	attachable := []session.Attachable{authprovider.NewDockerAuthProvider(authprovider.DockerAuthProviderConfig{
+		ConfigFile: dockercliconfig.LoadDefaultConfigFile(options.Stderr),
+	})}
 
-	buildctlArgs = append(buildctlArgs, []string{
-		"build",
-		"--progress=" + options.Progress,
-		"--frontend=dockerfile.v0",
-		"--local=context=" + options.BuildContext,
-		"--output=" + output,
-	}...)
+	solveOpt = &bkclient.SolveOpt{
+		Exports:       exports,
+		Frontend:      "dockerfile.v0",
+		FrontendAttrs: map[string]string{},
+		Ref:           identity.NewID(),
+	}
+	locals := []string{"context=" + options.BuildContext}
 
 	dir := options.BuildContext
 	file := buildkitutil.DefaultDockerfileName
@@ -273,7 +334,7 @@ func generateBuildctlArgs(ctx context.Context, client *containerd.Client, option
 			var err error
 			dir, err = buildkitutil.WriteTempDockerfile(options.Stdin)
 			if err != nil {
-				return "", nil, false, "", nil, nil, err
+				return nil, false, nil, nil, err
 			}
 			cleanup = func() {
 				os.RemoveAll(dir)
@@ -288,52 +349,62 @@ func generateBuildctlArgs(ctx context.Context, client *containerd.Client, option
 	}
 	dir, file, err = buildkitutil.BuildKitFile(dir, file)
 	if err != nil {
-		return "", nil, false, "", nil, nil, err
+		return nil, false, nil, cleanup, err
 	}
 
 	buildCtx, err := parseContextNames(options.ExtendedBuildContext)
 	if err != nil {
-		return "", nil, false, "", nil, nil, err
+		return nil, false, nil, cleanup, err
 	}
 
+	var ociLayouts []string
 	for k, v := range buildCtx {
 		isURL := strings.HasPrefix(v, "https://") || strings.HasPrefix(v, "http://")
 		isDockerImage := strings.HasPrefix(v, "docker-image://") || strings.HasPrefix(v, "target:")
 
 		if isURL || isDockerImage {
-			buildctlArgs = append(buildctlArgs, fmt.Sprintf("--opt=context:%s=%s", k, v))
+			solveOpt.FrontendAttrs["context:"+k] = v
 			continue
 		}
 
 		if isOCILayout := strings.HasPrefix(v, "oci-layout://"); isOCILayout {
-			args, err := parseBuildContextFromOCILayout(k, v)
+			ociLayout, contextValue, err := parseBuildContextFromOCILayout(k, v)
 			if err != nil {
-				return "", nil, false, "", nil, nil, err
+				return nil, false, nil, cleanup, err
 			}
 
-			buildctlArgs = append(buildctlArgs, args...)
+			ociLayouts = append(ociLayouts, ociLayout)
+			solveOpt.FrontendAttrs["context:"+k] = contextValue
 			continue
 		}
 
 		path, err := filepath.Abs(v)
 		if err != nil {
-			return "", nil, false, "", nil, nil, err
+			return nil, false, nil, cleanup, err
 		}
-		buildctlArgs = append(buildctlArgs, fmt.Sprintf("--local=%s=%s", k, path))
-		buildctlArgs = append(buildctlArgs, fmt.Sprintf("--opt=context:%s=local:%s", k, k))
+		locals = append(locals, fmt.Sprintf("%s=%s", k, path))
+		solveOpt.FrontendAttrs["context:"+k] = "local:" + k
 	}
 
-	buildctlArgs = append(buildctlArgs, "--local=dockerfile="+dir)
-	buildctlArgs = append(buildctlArgs, "--opt=filename="+file)
+	locals = append(locals, "dockerfile="+dir)
+	solveOpt.FrontendAttrs["filename"] = file
+
+	if solveOpt.LocalMounts, err = build.ParseLocal(locals); err != nil {
+		return nil, false, nil, cleanup, fmt.Errorf("invalid local: %w", err)
+	}
+	if solveOpt.OCIStores, err = build.ParseOCILayout(ociLayouts); err != nil {
+		return nil, false, nil, cleanup, fmt.Errorf("invalid oci-layout: %w", err)
+	}
 
 	if options.Target != "" {
-		buildctlArgs = append(buildctlArgs, "--opt=target="+options.Target)
+		solveOpt.FrontendAttrs["target"] = options.Target
 	}
 
 	if len(options.Platform) > 0 {
-		buildctlArgs = append(buildctlArgs, "--opt=platform="+strings.Join(options.Platform, ","))
+		solveOpt.FrontendAttrs["platform"] = strings.Join(options.Platform, ",")
 	}
 
+	var exportCaches []string
 	seenBuildArgs := make(map[string]struct{})
 	for _, ba := range strutil.DedupeStrSlice(options.BuildArgs) {
 		arr := strings.Split(ba, "=")
@@ -343,12 +414,13 @@ func generateBuildctlArgs(ctx context.Context, client *containerd.Client, option
 			// https://github.com/moby/moby/issues/24101
 			val, ok := os.LookupEnv(arr[0])
 			if ok {
-				buildctlArgs = append(buildctlArgs, fmt.Sprintf("--opt=build-arg:%s=%s", ba, val))
+				solveOpt.FrontendAttrs["build-arg:"+ba] = val
 			} else {
 				log.L.Debugf("ignoring unset build arg %q", ba)
 			}
 		} else if len(arr) > 1 && len(arr[0]) > 0 {
-			buildctlArgs = append(buildctlArgs, "--opt=build-arg:"+ba)
+			k, v, _ := strings.Cut(ba, "=")
+			solveOpt.FrontendAttrs["build-arg:"+k] = v
 
 			// Support `--build-arg BUILDKIT_INLINE_CACHE=1` for compatibility with `docker buildx build`
 			// https://github.com/docker/buildx/blob/v0.6.3/docs/reference/buildx_build.md#-export-build-cache-to-an-external-cache-destination---cache-to
@@ -357,14 +429,14 @@ func generateBuildctlArgs(ctx context.Context, client *containerd.Client, option
 				bicParsed, err := strconv.ParseBool(bic)
 				if err == nil {
 					if bicParsed {
-						buildctlArgs = append(buildctlArgs, "--export-cache=type=inline")
+						exportCaches = append(exportCaches, "type=inline")
 					}
 				} else {
 					log.L.WithError(err).Warnf("invalid BUILDKIT_INLINE_CACHE: %q", bic)
 				}
 			}
 		} else {
-			return "", nil, false, "", nil, nil, fmt.Errorf("invalid build arg %q", ba)
+			return nil, false, nil, cleanup, fmt.Errorf("invalid build arg %q", ba)
 		}
 	}
 
@@ -372,34 +444,37 @@ func generateBuildctlArgs(ctx context.Context, client *containerd.Client, option
 	// https://github.com/docker/buildx/pull/1482
 	if v := os.Getenv("SOURCE_DATE_EPOCH"); v != "" {
 		if _, ok := seenBuildArgs["SOURCE_DATE_EPOCH"]; !ok {
-			buildctlArgs = append(buildctlArgs, "--opt=build-arg:SOURCE_DATE_EPOCH="+v)
+			solveOpt.FrontendAttrs["build-arg:SOURCE_DATE_EPOCH"] = v
 		}
 	}
 
 	for _, l := range strutil.DedupeStrSlice(options.Label) {
-		buildctlArgs = append(buildctlArgs, "--opt=label:"+l)
+		k, v, _ := strings.Cut(l, "=")
+		solveOpt.FrontendAttrs["label:"+k] = v
 	}
 
 	if options.NoCache {
-		buildctlArgs = append(buildctlArgs, "--no-cache")
+		solveOpt.FrontendAttrs["no-cache"] = ""
 	}
 
 	if options.Pull != nil {
 		switch *options.Pull {
 		case true:
-			buildctlArgs = append(buildctlArgs, "--opt=image-resolve-mode=pull")
+			solveOpt.FrontendAttrs["image-resolve-mode"] = "pull"
 		case false:
-			buildctlArgs = append(buildctlArgs, "--opt=image-resolve-mode=local")
+			solveOpt.FrontendAttrs["image-resolve-mode"] = "local"
 		}
 	}
 
-	for _, s := range strutil.DedupeStrSlice(options.Secret) {
-		buildctlArgs = append(buildctlArgs, "--secret="+s)
+	if secrets := strutil.DedupeStrSlice(options.Secret); len(secrets) > 0 {
+		secretProvider, err := build.ParseSecret(secrets)
+		if err != nil {
+			return nil, false, nil, cleanup, err
+		}
+		attachable = append(attachable, secretProvider)
 	}
 
-	for _, s := range strutil.DedupeStrSlice(options.Allow) {
-		buildctlArgs = append(buildctlArgs, "--allow="+s)
-	}
+	allow := strutil.DedupeStrSlice(options.Allow)
 
 	for _, s := range strutil.DedupeStrSlice(options.Attest) {
 		optAttestType, optAttestAttrs, _ := strings.Cut(s, ",")
@@ -407,96 +482,103 @@ func generateBuildctlArgs(ctx context.Context, client *containerd.Client, option
 			if strings.HasPrefix(optAttestAttrs, "disabled=") {
 				disabled, err := strconv.ParseBool(strings.TrimPrefix(optAttestAttrs, "disabled="))
 				if err != nil {
-					return "", nil, false, "", nil, nil, fmt.Errorf("invalid value for attribute \"disabled\"")
+					return nil, false, nil, cleanup, fmt.Errorf("invalid value for attribute \"disabled\"")
 				}
 				if disabled {
 					continue
 				}
 			}
 			optAttestType := strings.TrimPrefix(optAttestType, "type=")
-			buildctlArgs = append(buildctlArgs, fmt.Sprintf("--opt=attest:%s=%s", optAttestType, optAttestAttrs))
+			solveOpt.FrontendAttrs["attest:"+optAttestType] = optAttestAttrs
 		} else {
-			return "", nil, false, "", nil, nil, fmt.Errorf("attestation type not specified")
+			return nil, false, nil, cleanup, fmt.Errorf("attestation type not specified")
 		}
 	}
 
-	for _, s := range strutil.DedupeStrSlice(options.SSH) {
-		buildctlArgs = append(buildctlArgs, "--ssh="+s)
+	if ssh := strutil.DedupeStrSlice(options.SSH); len(ssh) > 0 {
+		configs, err := build.ParseSSH(ssh)
+		if err != nil {
+			return nil, false, nil, cleanup, err
+		}
+		sshProvider, err := sshprovider.NewSSHAgentProvider(configs)
+		if err != nil {
+			return nil, false, nil, cleanup, err
+		}
+		attachable = append(attachable, sshProvider)
 	}
 
+	var importCaches []string
 	for _, s := range strutil.DedupeStrSlice(options.CacheFrom) {
 		if !strings.Contains(s, "type=") {
 			s = "type=registry,ref=" + s
 		}
-		buildctlArgs = append(buildctlArgs, "--import-cache="+s)
+		importCaches = append(importCaches, s)
+	}
+	if solveOpt.CacheImports, err = build.ParseImportCache(importCaches); err != nil {
+		return nil, false, nil, cleanup, err
 	}
 
 	for _, s := range strutil.DedupeStrSlice(options.CacheTo) {
 		if !strings.Contains(s, "type=") {
 			s = "type=registry,ref=" + s
 		}
-		buildctlArgs = append(buildctlArgs, "--export-cache="+s)
+		exportCaches = append(exportCaches, s)
+	}
+	if solveOpt.CacheExports, err = build.ParseExportCache(exportCaches); err != nil {
+		return nil, false, nil, cleanup, err
 	}
 
 	if !options.Rm {
 		log.L.Warn("ignoring deprecated flag: '--rm=false'")
 	}
 
-	if options.IidFile != "" {
-		file, err := os.CreateTemp("", "buildkit-meta-*")
-		if err != nil {
-			return "", nil, false, "", nil, cleanup, err
-		}
-		defer file.Close()
-		metaFile = file.Name()
-		buildctlArgs = append(buildctlArgs, "--metadata-file="+metaFile)
-	}
-
 	if options.NetworkMode != "" {
 		switch options.NetworkMode {
 		case "none":
-			buildctlArgs = append(buildctlArgs, "--opt=force-network-mode="+options.NetworkMode)
+			solveOpt.FrontendAttrs["force-network-mode"] = options.NetworkMode
 		case "host":
-			buildctlArgs = append(buildctlArgs, "--opt=force-network-mode="+options.NetworkMode, "--allow=network.host", "--allow=security.insecure")
+			solveOpt.FrontendAttrs["force-network-mode"] = options.NetworkMode
+			allow = append(allow, "network.host", "security.insecure")
 		case "", "default":
 		default:
 			log.L.Debugf("ignoring network build arg %s", options.NetworkMode)
 		}
 	}
 
+	allow = strutil.DedupeStrSlice(allow)
+	if err := build.ValidateAllow(allow); err != nil {
+		return nil, false, nil, cleanup, err
+	}
+	solveOpt.AllowedEntitlements = allow
+
 	if len(options.ExtraHosts) > 0 {
 		extraHosts, err := containerutil.ParseExtraHosts(options.ExtraHosts, options.GOptions.HostGatewayIP, "=")
 		if err != nil {
-			return "", nil, false, "", nil, nil, err
+			return nil, false, nil, cleanup, err
 		}
-		buildctlArgs = append(buildctlArgs, "--opt=add-hosts="+strings.Join(extraHosts, ","))
+		solveOpt.FrontendAttrs["add-hosts"] = strings.Join(extraHosts, ",")
 	}
 
-	return buildctlBinary, buildctlArgs, needsLoading, metaFile, tags, cleanup, nil
+	solveOpt.Session = attachable
+	return solveOpt, needsLoading, tags, cleanup, nil
 }
 
-func getDigestFromMetaFile(path string) (string, error) {
-	data, err := filesystem.ReadFile(path)
+// parseLoadingOutput parses the `--output` CSV of an exporter whose tar stream is loaded into containerd.
+func parseLoadingOutput(output string) ([]bkclient.ExportEntry, error) {
+	attrs, err := strutil.ParseCSVMap(output)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	defer os.Remove(path)
-
-	metadata := map[string]json.RawMessage{}
-	if err := json.Unmarshal(data, &metadata); err != nil {
-		log.L.WithError(err).Errorf("failed to unmarshal metadata file %s", path)
-		return "", err
+	ex := bkclient.ExportEntry{
+		Type:  attrs["type"],
+		Attrs: map[string]string{},
 	}
-	digestRaw, ok := metadata["containerimage.digest"]
-	if !ok {
-		return "", errors.New("failed to find containerimage.digest in metadata file")
-	}
-	var digest string
-	if err := json.Unmarshal(digestRaw, &digest); err != nil {
-		log.L.WithError(err).Errorf("failed to unmarshal digset")
-		return "", err
+	for k, v := range attrs {
+		if k != "type" {
+			ex.Attrs[k] = v
+		}
 	}
-	return digest, nil
+	return []bkclient.ExportEntry{ex}, nil
 }
 
 func isMatchingRuntimePlatform(platform string, parser PlatformParser) bool {
@@ -572,20 +654,22 @@ var (
 	ErrOCILayoutEmptyDigest    = errors.New("OCI layout cannot have empty digest")
 )
 
-func parseBuildContextFromOCILayout(name, path string) ([]string, error) {
+// parseBuildContextFromOCILayout returns the OCI layout store (in the form of `buildctl --oci-layout`)
+// and the frontend context value for the named context.
+func parseBuildContextFromOCILayout(name, path string) (ociLayout string, contextValue string, _ error) {
 	path, found := strings.CutPrefix(path, "oci-layout://")
 	if !found {
-		return []string{}, ErrOCILayoutPrefixNotFound
+		return "", "", ErrOCILayoutPrefixNotFound
 	}
 
 	abspath, err := filepath.Abs(path)
 	if err != nil {
-		return []string{}, err
+		return "", "", err
 	}
 
 	ociIndex, err := readOCIIndexFromPath(abspath)
 	if err != nil {
-		return []string{}, err
+		return "", "", err
 	}
 
 	var digest string
@@ -596,13 +680,11 @@ func parseBuildContextFromOCILayout(name, path string) ([]string, error) {
 	}
 
 	if digest == "" {
-		return []string{}, ErrOCILayoutEmptyDigest
+		return "", "", ErrOCILayoutEmptyDigest
 	}
 
-	return []string{
-		fmt.Sprintf("--oci-layout=parent-image-key=%s", abspath),
-		fmt.Sprintf("--opt=context:%s=oci-layout:parent-image-key@%s", name, digest),
-	}, nil
+	return fmt.Sprintf("parent-image-key=%s", abspath),
+		fmt.Sprintf("oci-layout:parent-image-key@%s", digest), nil
 }
 
 func readOCIIndexFromPath(path string) (*ocispec.Index, error) {
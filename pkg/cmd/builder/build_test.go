@@ -17,7 +17,9 @@
 package builder
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"path/filepath"
 	"reflect"
 	"runtime"
@@ -26,6 +28,9 @@ import (
 	specs "github.com/opencontainers/image-spec/specs-go/v1"
 	"go.uber.org/mock/gomock"
 	"gotest.tools/v3/assert"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/internal/filesystem"
 )
 
 type MockParse struct {
@@ -191,27 +196,26 @@ func TestIsBuildPlatformDefault(t *testing.T) {
 	}
 }
 
-func TestParseBuildctlArgsForOCILayout(t *testing.T) {
+func TestParseBuildContextFromOCILayout(t *testing.T) {
 	tests := []struct {
-		name          string
-		ociLayoutName string
-		ociLayoutPath string
-		expectedArgs  []string
-		errorIsNil    bool
-		expectedErr   string
+		name                 string
+		ociLayoutName        string
+		ociLayoutPath        string
+		expectedOCILayout    string
+		expectedContextValue string
+		errorIsNil           bool
+		expectedErr          string
 	}{
 		{
 			name:          "PrefixNotFoundError",
 			ociLayoutName: "unit-test",
 			ociLayoutPath: "/tmp/oci-layout/",
-			expectedArgs:  []string{},
 			expectedErr:   ErrOCILayoutPrefixNotFound.Error(),
 		},
 		{
 			name:          "DirectoryNotFoundError",
 			ociLayoutName: "unit-test",
 			ociLayoutPath: "oci-layout:///tmp/oci-layout",
-			expectedArgs:  []string{},
 			expectedErr:   "open /tmp/oci-layout/index.json: no such file or directory",
 		},
 	}
@@ -227,14 +231,67 @@ func TestParseBuildctlArgsForOCILayout(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			args, err := parseBuildContextFromOCILayout(test.ociLayoutName, test.ociLayoutPath)
+			ociLayout, contextValue, err := parseBuildContextFromOCILayout(test.ociLayoutName, test.ociLayoutPath)
 			if test.errorIsNil {
 				assert.NilError(t, err)
 			} else {
 				assert.Error(t, err, test.expectedErr)
 			}
-			assert.Equal(t, len(args), len(test.expectedArgs))
-			assert.DeepEqual(t, args, test.expectedArgs)
+			assert.Equal(t, ociLayout, test.expectedOCILayout)
+			assert.Equal(t, contextValue, test.expectedContextValue)
 		})
 	}
 }
+
+func TestGenerateSolveOpt(t *testing.T) {
+	buildCtx := t.TempDir()
+	assert.NilError(t, filesystem.WriteFile(filepath.Join(buildCtx, "Dockerfile"), []byte("FROM scratch\n"), 0644))
+	outDir := t.TempDir()
+
+	solveOpt, needsLoading, tags, cleanup, err := generateSolveOpt(context.Background(), nil, types.BuilderBuildOptions{
+		Stderr:       io.Discard,
+		BuildContext: buildCtx,
+		Output:       outDir,
+		Tag:          []string{"foo:bar"},
+		Target:       "stage",
+		BuildArgs:    []string{"FOO=bar", "BUILDKIT_INLINE_CACHE=1"},
+		Label:        []string{"org.example=baz"},
+		NoCache:      true,
+		NetworkMode:  "host",
+		Rm:           true,
+	})
+	assert.NilError(t, err)
+	assert.Assert(t, cleanup == nil)
+	assert.Assert(t, !needsLoading)
+	assert.DeepEqual(t, tags, []string{"docker.io/library/foo:bar"})
+
+	assert.Equal(t, solveOpt.Frontend, "dockerfile.v0")
+	assert.Equal(t, solveOpt.FrontendAttrs["filename"], "Dockerfile")
+	assert.Equal(t, solveOpt.FrontendAttrs["target"], "stage")
+	assert.Equal(t, solveOpt.FrontendAttrs["build-arg:FOO"], "bar")
+	assert.Equal(t, solveOpt.FrontendAttrs["label:org.example"], "baz")
+	assert.Equal(t, solveOpt.FrontendAttrs["force-network-mode"], "host")
+	_, noCache := solveOpt.FrontendAttrs["no-cache"]
+	assert.Assert(t, noCache)
+	assert.DeepEqual(t, solveOpt.AllowedEntitlements, []string{"network.host", "security.insecure"})
+
+	assert.Equal(t, len(solveOpt.Exports), 1)
+	assert.Equal(t, solveOpt.Exports[0].Type, "local")
+	assert.Equal(t, solveOpt.Exports[0].OutputDir, outDir)
+	assert.Equal(t, len(solveOpt.CacheExports), 1)
+	assert.Equal(t, solveOpt.CacheExports[0].Type, "inline")
+
+	_, ok := solveOpt.LocalMounts["context"]
+	assert.Assert(t, ok)
+	_, ok = solveOpt.LocalMounts["dockerfile"]
+	assert.Assert(t, ok)
+}
+
+func TestParseLoadingOutput(t *testing.T) {
+	exports, err := parseLoadingOutput("type=docker,name=docker.io/library/foo:latest")
+	assert.NilError(t, err)
+	assert.Equal(t, len(exports), 1)
+	assert.Equal(t, exports[0].Type, "docker")
+	assert.DeepEqual(t, exports[0].Attrs, map[string]string{"name": "docker.io/library/foo:latest"})
+	assert.Assert(t, exports[0].Output == nil)
+}
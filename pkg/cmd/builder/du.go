@@ -0,0 +1,219 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package builder
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+	"time"
+
+	"github.com/docker/go-units"
+	bkclient "github.com/moby/buildkit/client"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/buildkitutil"
+	"github.com/containerd/nerdctl/v2/pkg/formatter"
+)
+
+// diskUsagePrintable is the printable form of a build cache record.
+type diskUsagePrintable struct {
+	ID          string
+	Parents     []string
+	CreatedAt   string
+	LastUsedAt  string
+	UsageCount  int
+	Mutable     bool
+	InUse       bool
+	Shared      bool
+	Reclaimable bool
+	Size        string
+	RawSize     int64
+	Type        string
+	Description string
+}
+
+// DiskUsage lists the records of the BuildKit build cache.
+func DiskUsage(ctx context.Context, options types.BuilderDiskUsageOptions) error {
+	records, err := ListCacheRecords(ctx, options.BuildKitHost, options.Filters)
+	if err != nil {
+		return err
+	}
+	return printDiskUsage(options.Stdout, records, options)
+}
+
+// ListCacheRecords returns the records of the BuildKit build cache that match the filters.
+func ListCacheRecords(ctx context.Context, buildkitHost string, filters []string) ([]*buildkitutil.UsageInfo, error) {
+	bkFilters, until, err := buildkitutil.ParseFilters(filters)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := buildkitutil.NewClient(ctx, buildkitHost)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	var duOpts []bkclient.DiskUsageOption
+	if len(bkFilters) > 0 {
+		duOpts = append(duOpts, bkclient.WithFilter([]string{strings.Join(bkFilters, ",")}))
+	}
+	records, err := c.DiskUsage(ctx, duOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get build cache usage: %w", err)
+	}
+	if until == 0 {
+		return records, nil
+	}
+	res := make([]*buildkitutil.UsageInfo, 0, len(records))
+	for _, r := range records {
+		if !lastUsedAt(r).After(time.Now().Add(-until)) {
+			res = append(res, r)
+		}
+	}
+	return res, nil
+}
+
+func lastUsedAt(r *buildkitutil.UsageInfo) time.Time {
+	if r.LastUsedAt != nil {
+		return *r.LastUsedAt
+	}
+	return r.CreatedAt
+}
+
+func newDiskUsagePrintable(r *buildkitutil.UsageInfo) diskUsagePrintable {
+	p := diskUsagePrintable{
+		ID:          r.ID,
+		Parents:     r.Parents,
+		CreatedAt:   r.CreatedAt.Round(time.Second).Local().String(),
+		UsageCount:  r.UsageCount,
+		Mutable:     r.Mutable,
+		InUse:       r.InUse,
+		Shared:      r.Shared,
+		Reclaimable: !r.InUse,
+		Size:        units.HumanSize(float64(r.Size)),
+		RawSize:     r.Size,
+		Type:        string(r.RecordType),
+		Description: r.Description,
+	}
+	if r.LastUsedAt != nil {
+		p.LastUsedAt = formatter.TimeSinceInHuman(*r.LastUsedAt)
+	}
+	return p
+}
+
+func printDiskUsage(w io.Writer, records []*buildkitutil.UsageInfo, options types.BuilderDiskUsageOptions) error {
+	var tmpl *template.Template
+	switch options.Format {
+	case "", "table":
+		if options.Verbose {
+			return printDiskUsageVerbose(w, records)
+		}
+		w = tabwriter.NewWriter(w, 4, 8, 4, ' ', 0)
+		fmt.Fprintln(w, "ID\tRECLAIMABLE\tSIZE\tLAST ACCESSED")
+	case "raw":
+		return errors.New("unsupported format: \"raw\"")
+	default:
+		var err error
+		tmpl, err = formatter.ParseTemplate(options.Format)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, r := range records {
+		p := newDiskUsagePrintable(r)
+		if tmpl != nil {
+			var b bytes.Buffer
+			if err := tmpl.Execute(&b, p); err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintln(w, b.String()); err != nil {
+				return err
+			}
+			continue
+		}
+		id := p.ID
+		if p.Mutable {
+			id += "*"
+		}
+		fmt.Fprintf(w, "%s\t%v\t%s\t%s\n", id, p.Reclaimable, p.Size, p.LastUsedAt)
+	}
+	if tmpl == nil {
+		printDiskUsageSummary(w, records)
+	}
+	if f, ok := w.(formatter.Flusher); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+func printDiskUsageVerbose(w io.Writer, records []*buildkitutil.UsageInfo) error {
+	tw := tabwriter.NewWriter(w, 1, 8, 1, '\t', 0)
+	for _, r := range records {
+		p := newDiskUsagePrintable(r)
+		fmt.Fprintf(tw, "ID:\t%s\n", p.ID)
+		for _, parent := range p.Parents {
+			fmt.Fprintf(tw, "Parent:\t%s\n", parent)
+		}
+		fmt.Fprintf(tw, "Created at:\t%s\n", p.CreatedAt)
+		fmt.Fprintf(tw, "Mutable:\t%v\n", p.Mutable)
+		fmt.Fprintf(tw, "Reclaimable:\t%v\n", p.Reclaimable)
+		fmt.Fprintf(tw, "Shared:\t%v\n", p.Shared)
+		fmt.Fprintf(tw, "Size:\t%s\n", p.Size)
+		if p.Description != "" {
+			fmt.Fprintf(tw, "Description:\t%s\n", p.Description)
+		}
+		fmt.Fprintf(tw, "Usage count:\t%d\n", p.UsageCount)
+		if p.LastUsedAt != "" {
+			fmt.Fprintf(tw, "Last used:\t%s\n", p.LastUsedAt)
+		}
+		if p.Type != "" {
+			fmt.Fprintf(tw, "Type:\t%s\n", p.Type)
+		}
+		fmt.Fprintln(tw)
+	}
+	printDiskUsageSummary(tw, records)
+	return tw.Flush()
+}
+
+func printDiskUsageSummary(w io.Writer, records []*buildkitutil.UsageInfo) {
+	var shared, private, reclaimable, total int64
+	for _, r := range records {
+		if r.Shared {
+			shared += r.Size
+		} else {
+			private += r.Size
+		}
+		if !r.InUse {
+			reclaimable += r.Size
+		}
+		total += r.Size
+	}
+	if shared > 0 {
+		fmt.Fprintf(w, "Shared:\t%s\n", units.HumanSize(float64(shared)))
+		fmt.Fprintf(w, "Private:\t%s\n", units.HumanSize(float64(private)))
+	}
+	fmt.Fprintf(w, "Reclaimable:\t%s\n", units.HumanSize(float64(reclaimable)))
+	fmt.Fprintf(w, "Total:\t%s\n", units.HumanSize(float64(total)))
+}
@@ -0,0 +1,284 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package builder
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"text/template"
+	"time"
+
+	bkclient "github.com/moby/buildkit/client"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/errdefs"
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/buildhistory"
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
+	"github.com/containerd/nerdctl/v2/pkg/formatter"
+	"github.com/containerd/nerdctl/v2/pkg/labels"
+)
+
+// historyPrintable is the printable form of a build record in `nerdctl builder history ls`.
+type historyPrintable struct {
+	Ref         string
+	Name        string
+	Status      string
+	CreatedAt   string
+	CompletedAt string
+	Duration    string
+	ImageDigest string
+}
+
+func historyStore(gOptions types.GlobalCommandOptions) (buildhistory.Store, error) {
+	dataStore, err := clientutil.DataStore(gOptions.DataRoot, gOptions.Address)
+	if err != nil {
+		return nil, err
+	}
+	return buildhistory.NewStore(dataStore, gOptions.Namespace)
+}
+
+// newBuildRecord returns the record of a build, before the solve.
+func newBuildRecord(ctx context.Context, solveOpt *bkclient.SolveOpt, tags []string, options types.BuilderBuildOptions) *buildhistory.Record {
+	rec := &buildhistory.Record{
+		Ref:        solveOpt.Ref,
+		Args:       solveOpt.FrontendAttrs,
+		Tags:       tags,
+		Context:    options.BuildContext,
+		Dockerfile: solveOpt.FrontendAttrs["filename"],
+		CreatedAt:  time.Now(),
+	}
+	if abs, err := filepath.Abs(options.BuildContext); err == nil {
+		rec.Context = abs
+	}
+	if dgst, err := buildhistory.ContextDigest(ctx, options.BuildContext); err != nil {
+		log.G(ctx).WithError(err).Debug("failed to compute the digest of the build context")
+	} else {
+		rec.ContextDigest = dgst
+	}
+	// The Dockerfile may be a temporary file (`-f -`), so it is read before it gets removed.
+	if fs, ok := solveOpt.LocalMounts["dockerfile"]; ok {
+		if rc, err := fs.Open(rec.Dockerfile); err == nil {
+			if dt, err := io.ReadAll(rc); err == nil {
+				rec.DockerfileContent = string(dt)
+			}
+			rc.Close()
+		}
+	}
+	return rec
+}
+
+// saveBuildRecord completes the record with the result of the build and saves it in the history.
+// Failing to record the build does not fail the build.
+func saveBuildRecord(ctx context.Context, rec *buildhistory.Record, recorder *buildhistory.Recorder, buildErr error, options types.BuilderBuildOptions) {
+	rec.CompletedAt = time.Now()
+	rec.Vertexes = recorder.Vertexes()
+	if buildErr != nil {
+		rec.Error = buildErr.Error()
+	}
+	st, err := historyStore(options.GOptions)
+	if err == nil {
+		err = st.Save(rec)
+	}
+	if err != nil {
+		log.G(ctx).WithError(err).Warnf("failed to record build %s in the build history", rec.Ref)
+	}
+}
+
+// labelBuiltImages links the images to the build that produced them.
+func labelBuiltImages(ctx context.Context, client *containerd.Client, tags []string, ref string) {
+	imageService := client.ImageService()
+	for _, tag := range tags {
+		image, err := imageService.Get(ctx, tag)
+		if err != nil {
+			// e.g., the image was exported to a local directory
+			log.G(ctx).WithError(err).Debugf("not labeling image %s with the build ref", tag)
+			continue
+		}
+		if image.Labels == nil {
+			image.Labels = map[string]string{}
+		}
+		image.Labels[labels.BuildRef] = ref
+		if _, err := imageService.Update(ctx, image, "labels."+labels.BuildRef); err != nil {
+			log.G(ctx).WithError(err).Warnf("failed to label image %s with the build ref", tag)
+		}
+	}
+}
+
+// HistoryList lists the builds recorded in the history.
+func HistoryList(ctx context.Context, options types.BuilderHistoryListOptions) error {
+	st, err := historyStore(options.GOptions)
+	if err != nil {
+		return err
+	}
+	records, err := st.List()
+	if err != nil {
+		return err
+	}
+
+	w := options.Stdout
+	var tmpl *template.Template
+	switch options.Format {
+	case "", "table":
+		w = tabwriter.NewWriter(w, 4, 8, 4, ' ', 0)
+		if !options.Quiet {
+			fmt.Fprintln(w, "BUILD ID\tNAME\tSTATUS\tCREATED AT\tDURATION")
+		}
+	case "raw":
+		return errors.New("unsupported format: \"raw\"")
+	default:
+		if options.Quiet {
+			return errors.New("format and quiet must not be specified together")
+		}
+		tmpl, err = formatter.ParseTemplate(options.Format)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, rec := range records {
+		p := newHistoryPrintable(rec)
+		switch {
+		case tmpl != nil:
+			var b bytes.Buffer
+			if err := tmpl.Execute(&b, p); err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintln(w, b.String()); err != nil {
+				return err
+			}
+		case options.Quiet:
+			fmt.Fprintln(w, p.Ref)
+		default:
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", p.Ref, p.Name, p.Status, p.CreatedAt, p.Duration)
+		}
+	}
+	if f, ok := w.(formatter.Flusher); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+func newHistoryPrintable(rec *buildhistory.Record) historyPrintable {
+	name := rec.Context
+	if len(rec.Tags) > 0 {
+		name = rec.Tags[0]
+	}
+	return historyPrintable{
+		Ref:         rec.Ref,
+		Name:        name,
+		Status:      rec.Status(),
+		CreatedAt:   formatter.TimeSinceInHuman(rec.CreatedAt),
+		CompletedAt: formatter.TimeSinceInHuman(rec.CompletedAt),
+		Duration:    rec.Duration().Round(100 * time.Millisecond).String(),
+		ImageDigest: rec.ImageDigest,
+	}
+}
+
+// HistoryInspect prints the records of the builds, or exports the timings of a build with options.Export.
+func HistoryInspect(ctx context.Context, refs []string, options types.BuilderHistoryInspectOptions) error {
+	st, err := historyStore(options.GOptions)
+	if err != nil {
+		return err
+	}
+	if options.Export != "" {
+		if len(refs) != 1 {
+			return errors.New("exactly one build must be specified with --export")
+		}
+		rec, err := st.Get(refs[0])
+		if err != nil {
+			return err
+		}
+		return exportTrace(options.Export, rec)
+	}
+
+	var (
+		result []interface{}
+		errs   []error
+	)
+	for _, ref := range refs {
+		rec, err := st.Get(ref)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		result = append(result, rec)
+	}
+	if len(result) > 0 {
+		if err := formatter.FormatSlice(options.Format, options.Stdout, result); err != nil {
+			return err
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%d errors: %w", len(errs), errors.Join(errs...))
+	}
+	return nil
+}
+
+func exportTrace(path string, rec *buildhistory.Record) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := buildhistory.WriteTrace(f, rec); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// HistoryRemove removes the records of the builds from the history.
+func HistoryRemove(ctx context.Context, refs []string, options types.BuilderHistoryRemoveOptions) error {
+	st, err := historyStore(options.GOptions)
+	if err != nil {
+		return err
+	}
+	if options.All {
+		records, err := st.List()
+		if err != nil {
+			return err
+		}
+		refs = refs[:0]
+		for _, rec := range records {
+			refs = append(refs, rec.Ref)
+		}
+	}
+
+	var errs []error
+	for _, ref := range refs {
+		if err := st.Remove(ref); err != nil {
+			if errors.Is(err, errdefs.ErrNotFound) || errors.Is(err, errdefs.ErrInvalidArgument) {
+				errs = append(errs, err)
+				continue
+			}
+			return err
+		}
+		fmt.Fprintln(options.Stdout, ref)
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%d errors: %w", len(errs), errors.Join(errs...))
+	}
+	return nil
+}
@@ -18,10 +18,11 @@ package builder
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"io"
-	"os/exec"
+	"strings"
+
+	bkclient "github.com/moby/buildkit/client"
+	"golang.org/x/sync/errgroup"
 
 	"github.com/containerd/log"
 
@@ -31,39 +32,44 @@ import (
 
 // Prune will prune all build cache.
 func Prune(ctx context.Context, options types.BuilderPruneOptions) ([]buildkitutil.UsageInfo, error) {
-	buildctlBinary, err := buildkitutil.BuildctlBinary()
+	bkFilters, until, err := buildkitutil.ParseFilters(options.Filters)
 	if err != nil {
 		return nil, err
 	}
-	buildctlArgs := buildkitutil.BuildctlBaseArgs(options.BuildKitHost)
-	buildctlArgs = append(buildctlArgs, "prune", "--format={{json .}}")
-	if options.All {
-		buildctlArgs = append(buildctlArgs, "--all")
-	}
-	buildctlCmd := exec.Command(buildctlBinary, buildctlArgs...)
-	log.G(ctx).Debugf("running %v", buildctlCmd.Args)
-	buildctlCmd.Stderr = options.Stderr
-	stdout, err := buildctlCmd.StdoutPipe()
+
+	c, err := buildkitutil.NewClient(ctx, options.BuildKitHost)
 	if err != nil {
-		return nil, fmt.Errorf("faild to get stdout piper for %v: %w", buildctlCmd.Args, err)
+		return nil, err
 	}
-	defer stdout.Close()
-	if err = buildctlCmd.Start(); err != nil {
-		return nil, fmt.Errorf("faild to start %v: %w", buildctlCmd.Args, err)
+	defer c.Close()
+
+	pruneOpts := []bkclient.PruneOption{
+		bkclient.WithKeepOpt(until, options.KeepStorage, 0, 0),
 	}
-	dec := json.NewDecoder(stdout)
+	if len(bkFilters) > 0 {
+		// filters are ANDed, as in `docker builder prune`
+		pruneOpts = append(pruneOpts, bkclient.WithFilter([]string{strings.Join(bkFilters, ",")}))
+	}
+	if options.All {
+		pruneOpts = append(pruneOpts, bkclient.PruneAll)
+	}
+	log.G(ctx).Debugf("pruning build cache (all=%v, filters=%v, until=%v, keep-storage=%d)", options.All, bkFilters, until, options.KeepStorage)
+
+	ch := make(chan bkclient.UsageInfo)
 	result := make([]buildkitutil.UsageInfo, 0)
-	for {
-		var v buildkitutil.UsageInfo
-		if err := dec.Decode(&v); err == io.EOF {
-			break
-		} else if err != nil {
-			return nil, fmt.Errorf("faild to decode output from %v: %w", buildctlCmd.Args, err)
+	eg, ectx := errgroup.WithContext(ctx)
+	eg.Go(func() error {
+		defer close(ch)
+		return c.Prune(ectx, ch, pruneOpts...)
+	})
+	eg.Go(func() error {
+		for v := range ch {
+			result = append(result, v)
 		}
-		result = append(result, v)
-	}
-	if err = buildctlCmd.Wait(); err != nil {
-		return nil, fmt.Errorf("faild to wait for %v to complete: %w", buildctlCmd.Args, err)
+		return nil
+	})
+	if err := eg.Wait(); err != nil {
+		return nil, fmt.Errorf("failed to prune build cache: %w", err)
 	}
 
 	return result, nil
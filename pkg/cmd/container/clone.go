@@ -0,0 +1,160 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	containerd "github.com/containerd/containerd/v2/client"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/containerutil"
+	"github.com/containerd/nerdctl/v2/pkg/idutil/containerwalker"
+	"github.com/containerd/nerdctl/v2/pkg/labels"
+	"github.com/containerd/nerdctl/v2/pkg/portutil"
+)
+
+// Clone creates a new container from the configuration of the src container.
+// src is container name, short ID, or long ID.
+// If newName is empty, the clone is named "<src name>-clone".
+func Clone(ctx context.Context, client *containerd.Client, src, newName string, options types.ContainerCloneOptions) error {
+	var srcContainer containerd.Container
+	walker := &containerwalker.ContainerWalker{
+		Client: client,
+		OnFound: func(ctx context.Context, found containerwalker.Found) error {
+			if found.MatchCount > 1 {
+				return fmt.Errorf("multiple IDs found with provided prefix: %s", found.Req)
+			}
+			srcContainer = found.Container
+			return nil
+		},
+	}
+	if n, err := walker.Walk(ctx, src); err != nil {
+		return err
+	} else if n == 0 {
+		return fmt.Errorf("no such container %s", src)
+	}
+
+	config, err := LoadCreateConfig(ctx, srcContainer)
+	if err != nil {
+		return err
+	}
+	if newName == "" {
+		l, err := srcContainer.Labels(ctx)
+		if err != nil {
+			return err
+		}
+		newName = l[labels.Name] + "-clone"
+	}
+
+	args := config.Args
+	createOpt := config.Options
+	createOpt.Name = newName
+	// The ID file belongs to the source container
+	createOpt.CidFile = ""
+	netOpts := config.NetworkOptions
+	// Static addresses would conflict with the ones of the source container
+	netOpts.IPAddress = ""
+	netOpts.IP6Address = ""
+	netOpts.MACAddress = ""
+
+	overridden := false
+	if options.Image != "" {
+		args[0] = options.Image
+		overridden = true
+	}
+	if len(options.Env) > 0 {
+		createOpt.Env = append(createOpt.Env, options.Env...)
+		overridden = true
+	}
+	if len(options.Label) > 0 {
+		createOpt.Label = append(createOpt.Label, options.Label...)
+		overridden = true
+	}
+	if len(options.Publish) > 0 {
+		netOpts.PortMappings = nil
+		for _, p := range options.Publish {
+			pm, err := portutil.ParseFlagP(p)
+			if err != nil {
+				return err
+			}
+			netOpts.PortMappings = append(netOpts.PortMappings, pm...)
+		}
+		overridden = true
+	}
+	if options.CPUs != 0 {
+		createOpt.CPUs = options.CPUs
+		overridden = true
+	}
+	if options.Memory != "" {
+		createOpt.Memory = options.Memory
+		overridden = true
+	}
+	if options.Restart != "" {
+		createOpt.Restart = options.Restart
+		overridden = true
+	}
+	if overridden {
+		// The recorded command line no longer describes the container
+		createOpt.CreateArgs = nil
+	}
+
+	c, err := createFromConfig(ctx, client, args, netOpts, createOpt, options.Stdout, options.Stderr, options.GOptions)
+	if err != nil {
+		return err
+	}
+
+	if options.Destroy {
+		if err := RemoveContainer(ctx, srcContainer, options.GOptions, true, false, client); err != nil {
+			return err
+		}
+	}
+	if options.Start {
+		if err := containerutil.Start(ctx, c, false, false, client, ""); err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintln(options.Stdout, c.ID())
+	return err
+}
+
+// createFromConfig creates a container from a recorded configuration,
+// restoring the fields that are not recorded.
+func createFromConfig(ctx context.Context, client *containerd.Client, args []string, netOpts types.NetworkOptions,
+	createOpt types.ContainerCreateOptions, stdout, stderr io.Writer, globalOptions types.GlobalCommandOptions) (containerd.Container, error) {
+	createOpt.Stdout = stdout
+	createOpt.Stderr = stderr
+	createOpt.GOptions = globalOptions
+	createOpt.ImagePullOpt.Stdout = stdout
+	createOpt.ImagePullOpt.Stderr = stderr
+	createOpt.ImagePullOpt.GOptions = globalOptions
+
+	netManager, err := containerutil.NewNetworkingOptionsManager(globalOptions, netOpts, client)
+	if err != nil {
+		return nil, err
+	}
+	c, gc, err := Create(ctx, client, args, netManager, createOpt)
+	if err != nil {
+		if gc != nil {
+			gc()
+		}
+		return nil, err
+	}
+	return c, nil
+}
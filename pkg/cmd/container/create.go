@@ -27,6 +27,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"runtime"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -56,6 +57,7 @@ import (
 	"github.com/containerd/nerdctl/v2/pkg/ipcutil"
 	"github.com/containerd/nerdctl/v2/pkg/labels"
 	"github.com/containerd/nerdctl/v2/pkg/logging"
+	"github.com/containerd/nerdctl/v2/pkg/logging/loguri"
 	"github.com/containerd/nerdctl/v2/pkg/maputil"
 	"github.com/containerd/nerdctl/v2/pkg/mountutil"
 	"github.com/containerd/nerdctl/v2/pkg/namestore"
@@ -89,6 +91,8 @@ func Create(ctx context.Context, client *containerd.Client, args []string, netMa
 		newArg = append(newArg, args[2:]...)
 		args = newArg
 	}
+	// args[0] may be rewritten below (e.g., when loading an OCI archive), keep the original ones
+	createConfigArgs := slices.Clone(args)
 	var internalLabels internalLabels
 	internalLabels.platform = options.Platform
 	internalLabels.namespace = options.GOptions.Namespace
@@ -383,6 +387,7 @@ func Create(ctx context.Context, client *containerd.Client, args []string, netMa
 	internalLabels.extraHosts = extraHosts
 
 	internalLabels.rm = containerutil.EncodeContainerRmOptLabel(options.Rm)
+	internalLabels.pod = options.Pod
 
 	// TODO: abolish internal labels and only use annotations
 	ilOpt, err := withInternalLabels(internalLabels)
@@ -399,6 +404,15 @@ func Create(ctx context.Context, client *containerd.Client, args []string, netMa
 		return nil, generateRemoveOrphanedDirsFunc(ctx, id, dataStore, internalLabels), fmt.Errorf("Error writing to network-config.json: %v", err)
 	}
 
+	createConfig := CreateConfig{
+		Args:           createConfigArgs,
+		Options:        options,
+		NetworkOptions: netManager.NetworkOptions(),
+	}
+	if err := writeCreateConfig(internalLabels.stateDir, createConfig); err != nil {
+		return nil, generateRemoveOrphanedDirsFunc(ctx, id, dataStore, internalLabels), fmt.Errorf("failed to write %s: %w", createConfigFile, err)
+	}
+
 	opts = append(opts, propagateInternalContainerdLabelsToOCIAnnotations(),
 		oci.WithAnnotations(strutil.ConvertKVStringsToMap(options.Annotations)))
 
@@ -712,7 +726,9 @@ type internalLabels struct {
 	// log
 	logURI string
 	// a label to check whether the --rm option is specified.
-	rm        string
+	rm string
+	// the pod the container belongs to
+	pod       string
 	logConfig logging.LogConfig
 
 	// a label to chek if --cidfile is set
@@ -807,6 +823,10 @@ func withInternalLabels(internalLabels internalLabels) (containerd.NewContainerO
 		m[labels.ContainerAutoRemove] = internalLabels.rm
 	}
 
+	if internalLabels.pod != "" {
+		m[labels.Pod] = internalLabels.pod
+	}
+
 	if internalLabels.cidFile != "" {
 		hostConfigLabel.CidFile = internalLabels.cidFile
 	}
@@ -996,9 +1016,23 @@ func writeCIDFile(path, id string) error {
 
 // generateLogConfig creates a LogConfig for the current container store
 func generateLogConfig(dataStore string, id string, logDriver string, logOpt []string, ns, address string) (logConfig logging.LogConfig, err error) {
-	var u *url.URL
-	if u, err = url.Parse(logDriver); err == nil && (u.Scheme != "" || logDriver == "none") {
+	if logDriver == "none" {
 		logConfig.LogURI = logDriver
+	} else if loguri.IsLogURI(logDriver) {
+		// The logs are handled by the shim, and cannot be read back by nerdctl
+		var (
+			logOpts map[string]string
+			u       *url.URL
+		)
+		logOpts, err = parseKVStringsMapFromLogOpt(logOpt, logDriver)
+		if err != nil {
+			return logConfig, err
+		}
+		u, err = loguri.Parse(logDriver, logOpts)
+		if err != nil {
+			return logConfig, err
+		}
+		logConfig.LogURI = u.String()
 	} else {
 		logConfig.Driver = logDriver
 		logConfig.Address = address
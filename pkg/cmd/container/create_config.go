@@ -0,0 +1,80 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	containerd "github.com/containerd/containerd/v2/client"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/internal/filesystem"
+	"github.com/containerd/nerdctl/v2/pkg/labels"
+)
+
+// createConfigFile is the name of the file, in the container state directory,
+// that records the options the container was created with.
+const createConfigFile = "create-config.json"
+
+// CreateConfig is the configuration a container was created with.
+// It is too large to fit in a containerd label, so it is stored as a file
+// in the container state directory.
+type CreateConfig struct {
+	// Args is the image (or rootfs) followed by the command and its arguments
+	Args []string
+	// Options holds the create options, except for the output writers and the global options
+	Options types.ContainerCreateOptions
+	// NetworkOptions holds the networking options
+	NetworkOptions types.NetworkOptions
+}
+
+func writeCreateConfig(stateDir string, config CreateConfig) error {
+	b, err := json.Marshal(config)
+	if err != nil {
+		return err
+	}
+	return filesystem.WriteFile(filepath.Join(stateDir, createConfigFile), b, 0600)
+}
+
+// LoadCreateConfig loads the configuration the container was created with.
+func LoadCreateConfig(ctx context.Context, c containerd.Container) (*CreateConfig, error) {
+	l, err := c.Labels(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stateDir := l[labels.StateDir]
+	if stateDir == "" {
+		return nil, fmt.Errorf("container %s has no state directory", c.ID())
+	}
+	b, err := filesystem.ReadFile(filepath.Join(stateDir, createConfigFile))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("container %s was created without recording its configuration", c.ID())
+		}
+		return nil, err
+	}
+	var config CreateConfig
+	if err := json.Unmarshal(b, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse the configuration of container %s: %w", c.ID(), err)
+	}
+	return &config, nil
+}
@@ -0,0 +1,317 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/core/leases"
+	"github.com/containerd/containerd/v2/core/mount"
+	"github.com/containerd/continuity/fs"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/formatter"
+	"github.com/containerd/nerdctl/v2/pkg/idgen"
+	"github.com/containerd/nerdctl/v2/pkg/idutil/containerwalker"
+	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/dockercompat"
+	"github.com/containerd/nerdctl/v2/pkg/labels"
+)
+
+// DiffChange is a change to a file or a directory on the filesystem of a container.
+type DiffChange struct {
+	// Kind is "A" (added), "C" (changed) or "D" (deleted).
+	Kind string
+	Path string
+	// Size and Mode are empty for the deleted files.
+	Size int64
+	Mode string
+}
+
+// Diff prints the changes to files or directories on the filesystem of a container, relative to its image.
+func Diff(ctx context.Context, client *containerd.Client, containerReq string, options types.ContainerDiffOptions) error {
+	filter, err := newPathFilter(options.Filter, options.Exclude)
+	if err != nil {
+		return err
+	}
+	var tmpl *template.Template
+	if options.Format != "" {
+		tmpl, err = formatter.ParseTemplate(options.Format)
+		if err != nil {
+			return err
+		}
+	}
+
+	walker := &containerwalker.ContainerWalker{
+		Client: client,
+		OnFound: func(ctx context.Context, found containerwalker.Found) error {
+			if found.MatchCount > 1 {
+				return fmt.Errorf("multiple IDs found with provided prefix: %s", found.Req)
+			}
+			filter, err := filter.withoutVolumes(ctx, found.Container)
+			if err != nil {
+				return err
+			}
+			changes, err := getChanges(ctx, client, found.Container, filter)
+			if err != nil {
+				return err
+			}
+			for _, change := range changes {
+				if tmpl == nil {
+					fmt.Fprintln(options.Stdout, change.Kind, change.Path)
+					continue
+				}
+				var b bytes.Buffer
+				if err := tmpl.Execute(&b, change); err != nil {
+					return err
+				}
+				if _, err := fmt.Fprintln(options.Stdout, b.String()); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+
+	n, err := walker.Walk(ctx, containerReq)
+	if err != nil {
+		return err
+	} else if n == 0 {
+		return fmt.Errorf("no such container %s", containerReq)
+	}
+	return nil
+}
+
+func getChanges(ctx context.Context, client *containerd.Client, container containerd.Container, filter *pathFilter) ([]DiffChange, error) {
+	var changes []DiffChange
+	err := withContainerLayers(ctx, client, container, func(lower, upper string) error {
+		// The parent directories of the changed files are reported as changed, like Docker
+		seen := map[string]bool{"/": true}
+		add := func(kind fs.ChangeKind, p string, fi os.FileInfo) {
+			if seen[p] {
+				return
+			}
+			seen[p] = true
+			change := DiffChange{Path: p}
+			switch kind {
+			case fs.ChangeKindAdd:
+				change.Kind = "A"
+			case fs.ChangeKindModify:
+				change.Kind = "C"
+			case fs.ChangeKindDelete:
+				change.Kind = "D"
+			}
+			if fi != nil && kind != fs.ChangeKindDelete {
+				change.Size = fi.Size()
+				change.Mode = fi.Mode().String()
+			}
+			changes = append(changes, change)
+		}
+		return fs.Changes(ctx, lower, upper, func(kind fs.ChangeKind, p string, fi os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if kind == fs.ChangeKindUnmodified || !filter.match(p) {
+				return nil
+			}
+			for _, dir := range parentDirs(p) {
+				if !seen[dir] {
+					// e.g., a parent directory that does not match the filter
+					parent, err := os.Lstat(filepath.Join(upper, dir))
+					if err != nil {
+						return err
+					}
+					add(parentChangeKind(lower, dir), dir, parent)
+				}
+			}
+			add(kind, p, fi)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// parentChangeKind returns the kind of change of a parent directory of a changed path:
+// added if it does not exist in lower, modified otherwise.
+func parentChangeKind(lower, dir string) fs.ChangeKind {
+	if _, err := os.Lstat(filepath.Join(lower, dir)); errors.Is(err, os.ErrNotExist) {
+		return fs.ChangeKindAdd
+	}
+	return fs.ChangeKindModify
+}
+
+// parentDirs returns the parent directories of p, from the top-level one, excluding "/".
+func parentDirs(p string) []string {
+	var dirs []string
+	for dir := path.Dir(p); dir != "/" && dir != "."; dir = path.Dir(dir) {
+		dirs = append([]string{dir}, dirs...)
+	}
+	return dirs
+}
+
+// withContainerLayers calls fn with the read-only mounts of the image layers of the container (lower),
+// and of the filesystem of the container (upper).
+func withContainerLayers(ctx context.Context, client *containerd.Client, container containerd.Container, fn func(lower, upper string) error) error {
+	info, err := container.Info(ctx)
+	if err != nil {
+		return err
+	}
+	sn := client.SnapshotService(info.Snapshotter)
+	mounts, err := sn.Mounts(ctx, info.SnapshotKey)
+	if err != nil {
+		return fmt.Errorf("failed to get container mounts: %w", err)
+	}
+	snInfo, err := sn.Stat(ctx, info.SnapshotKey)
+	if err != nil {
+		return err
+	}
+
+	// Don't gc me and clean the dirty data after 1 hour!
+	ctx, done, err := client.WithLease(ctx, leases.WithRandomID(), leases.WithExpiration(1*time.Hour))
+	if err != nil {
+		return fmt.Errorf("failed to create lease for diff: %w", err)
+	}
+	defer done(ctx)
+
+	// A container without a parent snapshot (e.g., created from an empty image) is compared with an empty directory
+	var parent []mount.Mount
+	if snInfo.Parent != "" {
+		randomID := idgen.GenerateID()
+		parent, err = sn.View(ctx, randomID, snInfo.Parent)
+		if err != nil {
+			return err
+		}
+		defer sn.Remove(ctx, randomID)
+	}
+
+	return mount.WithReadonlyTempMount(ctx, parent, func(lower string) error {
+		return mount.WithReadonlyTempMount(ctx, mounts, func(upper string) error {
+			return fn(lower, upper)
+		})
+	})
+}
+
+// pathFilter selects paths with glob patterns. A pattern matching a directory matches all its content.
+type pathFilter struct {
+	include []string
+	exclude []string
+}
+
+func newPathFilter(include, exclude []string) (*pathFilter, error) {
+	f := &pathFilter{}
+	for _, p := range include {
+		p, err := cleanPattern(p)
+		if err != nil {
+			return nil, err
+		}
+		f.include = append(f.include, p)
+	}
+	for _, p := range exclude {
+		p, err := cleanPattern(p)
+		if err != nil {
+			return nil, err
+		}
+		f.exclude = append(f.exclude, p)
+	}
+	return f, nil
+}
+
+// withoutVolumes returns a filter that also excludes the volumes of the container and their contents,
+// which are not part of the filesystem of the container.
+func (f *pathFilter) withoutVolumes(ctx context.Context, container containerd.Container) (*pathFilter, error) {
+	l, err := container.Labels(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var mounts []dockercompat.MountPoint
+	if s := l[labels.Mounts]; s != "" {
+		if err := json.Unmarshal([]byte(s), &mounts); err != nil {
+			return nil, err
+		}
+	}
+	res := &pathFilter{}
+	if f != nil {
+		res.include = f.include
+		res.exclude = slices.Clone(f.exclude)
+	}
+	for _, m := range mounts {
+		if m.Type == "volume" {
+			res.exclude = append(res.exclude, escapePattern(path.Clean("/"+m.Destination)))
+		}
+	}
+	return res, nil
+}
+
+// escapePattern escapes the special characters of path.Match in p.
+func escapePattern(p string) string {
+	var b strings.Builder
+	for _, r := range p {
+		if strings.ContainsRune(`\*?[`, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func cleanPattern(pattern string) (string, error) {
+	p := path.Clean("/" + pattern)
+	if _, err := path.Match(p, ""); err != nil {
+		return "", fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+	return p, nil
+}
+
+// match returns whether p (an absolute path) matches any include pattern, if any, and no exclude pattern.
+func (f *pathFilter) match(p string) bool {
+	if f == nil {
+		return true
+	}
+	if len(f.include) > 0 && !matchAny(f.include, p) {
+		return false
+	}
+	return !matchAny(f.exclude, p)
+}
+
+func matchAny(patterns []string, p string) bool {
+	for _, pattern := range patterns {
+		for dir := p; ; dir = path.Dir(dir) {
+			// the patterns are validated by cleanPattern
+			if ok, _ := path.Match(pattern, dir); ok {
+				return true
+			}
+			if dir == "/" || dir == "." {
+				break
+			}
+		}
+	}
+	return false
+}
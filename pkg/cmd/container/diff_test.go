@@ -0,0 +1,70 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/containerd/continuity/fs"
+	"gotest.tools/v3/assert"
+)
+
+func TestPathFilter(t *testing.T) {
+	f, err := newPathFilter([]string{"/var/log/*.log", "etc"}, []string{"/etc/ssl"})
+	assert.NilError(t, err)
+	for p, expected := range map[string]bool{
+		"/var/log/app.log":     true,
+		"/var/log/app.log.1":   false,
+		"/var/log":             false,
+		"/etc":                 true,
+		"/etc/passwd":          true,
+		"/etc/ssl":             false,
+		"/etc/ssl/certs/a.pem": false,
+		"/tmp/a":               false,
+	} {
+		assert.Equal(t, f.match(p), expected, p)
+	}
+
+	f, err = newPathFilter(nil, []string{"/"})
+	assert.NilError(t, err)
+	assert.Assert(t, !f.match("/a"))
+
+	_, err = newPathFilter([]string{"[invalid"}, nil)
+	assert.ErrorContains(t, err, "invalid pattern")
+}
+
+func TestParentDirs(t *testing.T) {
+	assert.DeepEqual(t, parentDirs("/a/b/c"), []string{"/a", "/a/b"})
+	assert.Equal(t, len(parentDirs("/a")), 0)
+}
+
+func TestParentChangeKind(t *testing.T) {
+	lower := t.TempDir()
+	assert.NilError(t, os.Mkdir(filepath.Join(lower, "etc"), 0o755))
+	assert.Assert(t, parentChangeKind(lower, "/etc") == fs.ChangeKindModify)
+	assert.Assert(t, parentChangeKind(lower, "/data") == fs.ChangeKindAdd)
+}
+
+func TestEscapePattern(t *testing.T) {
+	p := escapePattern("/data[1]/*")
+	assert.Equal(t, p, `/data\[1]/\*`)
+	f := &pathFilter{exclude: []string{p}}
+	assert.Assert(t, !f.match("/data[1]/*/file"))
+	assert.Assert(t, f.match("/data1/a"))
+}
@@ -76,10 +76,27 @@ func execActionWithContainer(ctx context.Context, client *containerd.Client, con
 		stdinC    = &taskutil.StdinCloser{
 			Stdin: os.Stdin,
 		}
+		process containerd.Process
+		detachC = make(chan struct{}, 1)
 	)
 
 	if options.Interactive {
 		in = stdinC
+		if options.TTY && !options.Detach {
+			// Detaching leaves the command running, like detaching from `nerdctl run -it`
+			closer := func() {
+				detachC <- struct{}{}
+				if process != nil {
+					if pio := process.IO(); pio != nil {
+						pio.Cancel()
+					}
+				}
+			}
+			in, err = consoleutil.NewDetachableStdin(stdinC, options.DetachKeys, closer)
+			if err != nil {
+				return err
+			}
+		}
 	}
 	cioOpts := []cio.Opt{cio.WithStreams(in, os.Stdout, os.Stderr)}
 	if options.TTY {
@@ -88,7 +105,7 @@ func execActionWithContainer(ctx context.Context, client *containerd.Client, con
 	ioCreator = cio.NewCreator(cioOpts...)
 
 	execID := "exec-" + idgen.GenerateID()
-	process, err := task.Exec(ctx, execID, pspec, ioCreator)
+	process, err = task.Exec(ctx, execID, pspec, ioCreator)
 	if err != nil {
 		return err
 	}
@@ -96,9 +113,12 @@ func execActionWithContainer(ctx context.Context, client *containerd.Client, con
 		process.CloseIO(ctx, containerd.WithStdinCloser)
 	}
 	// if detach, we should not call this defer
-	if !options.Detach {
-		defer process.Delete(ctx)
-	}
+	detached := options.Detach
+	defer func() {
+		if !detached {
+			process.Delete(ctx)
+		}
+	}()
 
 	statusC, err := process.Wait(ctx)
 	if err != nil {
@@ -133,7 +153,15 @@ func execActionWithContainer(ctx context.Context, client *containerd.Client, con
 	if options.Detach {
 		return nil
 	}
-	status := <-statusC
+	var status containerd.ExitStatus
+	select {
+	case <-detachC:
+		detached = true
+		process.IO().Wait()
+		log.G(ctx).Debugf("detached from exec %s", execID)
+		return nil
+	case status = <-statusC:
+	}
 
 	process.IO().Wait()
 	process.IO().Close()
@@ -197,6 +225,9 @@ func generateExecProcessSpec(ctx context.Context, client *containerd.Client, con
 			return nil, err
 		}
 	}
+	if err := adjustExecCapabilities(ctx, pspec, options.CapAdd, options.CapDrop); err != nil {
+		return nil, err
+	}
 
 	return pspec, nil
 }
@@ -17,9 +17,12 @@
 package container
 
 import (
+	"context"
+
 	"github.com/opencontainers/runtime-spec/specs-go"
 
 	"github.com/containerd/containerd/v2/pkg/cap"
+	"github.com/containerd/containerd/v2/pkg/oci"
 )
 
 func setExecCapabilities(pspec *specs.Process) error {
@@ -40,3 +43,18 @@ func setExecCapabilities(pspec *specs.Process) error {
 	// > profiles. Privileged configuration of the container is inherited
 	return nil
 }
+
+// adjustExecCapabilities adds and drops capabilities to the process, like `--cap-add` and `--cap-drop` of `nerdctl run`.
+func adjustExecCapabilities(ctx context.Context, pspec *specs.Process, capAdd, capDrop []string) error {
+	opts, err := generateCapOpts(capAdd, capDrop)
+	if err != nil {
+		return err
+	}
+	s := &oci.Spec{Process: pspec}
+	for _, opt := range opts {
+		if err := opt(ctx, nil, nil, s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
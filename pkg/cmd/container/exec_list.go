@@ -0,0 +1,215 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+	"time"
+
+	runcoptions "github.com/containerd/containerd/api/types/runc/options"
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/typeurl/v2"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/formatter"
+	"github.com/containerd/nerdctl/v2/pkg/idutil/containerwalker"
+)
+
+// ExecSession is a process started in a container by `nerdctl exec`, or by any other client of containerd.
+type ExecSession struct {
+	ID          string
+	ContainerID string
+	Pid         uint32
+	Command     []string
+	Status      string
+	StartedAt   time.Time
+}
+
+type execSessionPrintable struct {
+	ID        string
+	Pid       uint32
+	Command   string
+	Status    string
+	StartedAt string
+}
+
+// ExecList lists the exec sessions of a running container.
+func ExecList(ctx context.Context, client *containerd.Client, req string, options types.ContainerExecListOptions) error {
+	container, err := findOneContainer(ctx, client, req)
+	if err != nil {
+		return err
+	}
+	sessions, err := execSessions(ctx, container)
+	if err != nil {
+		return err
+	}
+
+	w := options.Stdout
+	var tmpl *template.Template
+	switch options.Format {
+	case "", "table":
+		w = tabwriter.NewWriter(w, 4, 8, 4, ' ', 0)
+		if !options.Quiet {
+			fmt.Fprintln(w, "EXEC ID\tPID\tCOMMAND\tSTARTED\tSTATUS")
+		}
+	case "raw":
+		return errors.New("unsupported format: \"raw\"")
+	default:
+		if options.Quiet {
+			return errors.New("format and quiet must not be specified together")
+		}
+		tmpl, err = formatter.ParseTemplate(options.Format)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, s := range sessions {
+		p := execSessionPrintable{
+			ID:      s.ID,
+			Pid:     s.Pid,
+			Command: strings.Join(s.Command, " "),
+			Status:  s.Status,
+		}
+		if !s.StartedAt.IsZero() {
+			p.StartedAt = formatter.TimeSinceInHuman(s.StartedAt)
+		}
+		switch {
+		case tmpl != nil:
+			var b bytes.Buffer
+			if err := tmpl.Execute(&b, p); err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintln(w, b.String()); err != nil {
+				return err
+			}
+		case options.Quiet:
+			fmt.Fprintln(w, p.ID)
+		default:
+			fmt.Fprintf(w, "%s\t%d\t%q\t%s\t%s\n", p.ID, p.Pid, formatter.Ellipsis(p.Command, 40), p.StartedAt, p.Status)
+		}
+	}
+	if f, ok := w.(formatter.Flusher); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+// ExecInspect prints the exec sessions of a running container matching the IDs (or unique ID prefixes).
+func ExecInspect(ctx context.Context, client *containerd.Client, req string, ids []string, options types.ContainerExecInspectOptions) error {
+	container, err := findOneContainer(ctx, client, req)
+	if err != nil {
+		return err
+	}
+	sessions, err := execSessions(ctx, container)
+	if err != nil {
+		return err
+	}
+
+	var (
+		result []interface{}
+		errs   []error
+	)
+	for _, id := range ids {
+		var matches []*ExecSession
+		for _, s := range sessions {
+			if strings.HasPrefix(s.ID, id) {
+				matches = append(matches, s)
+			}
+		}
+		switch len(matches) {
+		case 0:
+			errs = append(errs, fmt.Errorf("no such exec session: %s", id))
+		case 1:
+			result = append(result, matches[0])
+		default:
+			errs = append(errs, fmt.Errorf("multiple exec sessions found with provided prefix: %s", id))
+		}
+	}
+	if len(result) > 0 {
+		if err := formatter.FormatSlice(options.Format, options.Stdout, result); err != nil {
+			return err
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%d errors: %w", len(errs), errors.Join(errs...))
+	}
+	return nil
+}
+
+func findOneContainer(ctx context.Context, client *containerd.Client, req string) (containerd.Container, error) {
+	var container containerd.Container
+	walker := &containerwalker.ContainerWalker{
+		Client: client,
+		OnFound: func(ctx context.Context, found containerwalker.Found) error {
+			if found.MatchCount > 1 {
+				return fmt.Errorf("multiple IDs found with provided prefix: %s", found.Req)
+			}
+			container = found.Container
+			return nil
+		},
+	}
+	n, err := walker.Walk(ctx, req)
+	if err != nil {
+		return nil, err
+	} else if n == 0 {
+		return nil, fmt.Errorf("no such container %s", req)
+	}
+	return container, nil
+}
+
+// execSessions returns the exec processes of the task of the container.
+func execSessions(ctx context.Context, container containerd.Container) ([]*ExecSession, error) {
+	task, err := container.Task(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("container %s is not running: %w", container.ID(), err)
+	}
+	procs, err := task.Pids(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var sessions []*ExecSession
+	for _, p := range procs {
+		if p.Info == nil {
+			continue
+		}
+		// Only the runc shim reports the exec ID of the processes
+		var details runcoptions.ProcessDetails
+		if err := typeurl.UnmarshalTo(p.Info, &details); err != nil || details.ExecID == "" {
+			continue
+		}
+		s := &ExecSession{
+			ID:          details.ExecID,
+			ContainerID: container.ID(),
+			Pid:         p.Pid,
+		}
+		if process, err := task.LoadProcess(ctx, details.ExecID, nil); err == nil {
+			if status, err := process.Status(ctx); err == nil {
+				s.Status = string(status.Status)
+			}
+		}
+		s.Command, s.StartedAt = processCommandAndStartTime(p.Pid)
+		sessions = append(sessions, s)
+	}
+	return sessions, nil
+}
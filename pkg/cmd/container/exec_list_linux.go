@@ -0,0 +1,85 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/containerd/nerdctl/v2/pkg/internal/filesystem"
+)
+
+// clockTicks is the USER_HZ of /proc/<pid>/stat, which is 100 on all the architectures supported by Linux.
+const clockTicks = 100
+
+// processCommandAndStartTime returns the command line and the start time of a process, read from /proc.
+// Zero values are returned when they cannot be read (e.g., the process exited meanwhile).
+func processCommandAndStartTime(pid uint32) ([]string, time.Time) {
+	var command []string
+	if b, err := filesystem.ReadFile(fmt.Sprintf("/proc/%d/cmdline", pid)); err == nil {
+		for _, arg := range bytes.Split(bytes.TrimSuffix(b, []byte{0}), []byte{0}) {
+			command = append(command, string(arg))
+		}
+	}
+	return command, processStartTime(pid)
+}
+
+func processStartTime(pid uint32) time.Time {
+	b, err := filesystem.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return time.Time{}
+	}
+	// The command in the second field may contain spaces and parentheses
+	i := bytes.LastIndexByte(b, ')')
+	if i < 0 {
+		return time.Time{}
+	}
+	// starttime is the 22nd field, the fields after the command start with the 3rd one
+	fields := strings.Fields(string(b[i+1:]))
+	if len(fields) < 20 {
+		return time.Time{}
+	}
+	ticks, err := strconv.ParseUint(fields[19], 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	boot, err := bootTime()
+	if err != nil {
+		return time.Time{}
+	}
+	return boot.Add(time.Duration(ticks) * time.Second / clockTicks)
+}
+
+func bootTime() (time.Time, error) {
+	b, err := filesystem.ReadFile("/proc/stat")
+	if err != nil {
+		return time.Time{}, err
+	}
+	for _, line := range strings.Split(string(b), "\n") {
+		if v, ok := strings.CutPrefix(line, "btime "); ok {
+			sec, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+			if err != nil {
+				return time.Time{}, err
+			}
+			return time.Unix(sec, 0), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("btime not found in /proc/stat")
+}
@@ -19,6 +19,9 @@
 package container
 
 import (
+	"context"
+	"errors"
+
 	"github.com/opencontainers/runtime-spec/specs-go"
 )
 
@@ -26,3 +29,10 @@ func setExecCapabilities(pspec *specs.Process) error {
 	//no op freebsd
 	return nil
 }
+
+func adjustExecCapabilities(ctx context.Context, pspec *specs.Process, capAdd, capDrop []string) error {
+	if len(capAdd) > 0 || len(capDrop) > 0 {
+		return errors.New("--cap-add and --cap-drop are only supported on Linux")
+	}
+	return nil
+}
@@ -19,12 +19,14 @@ package container
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"runtime"
 
 	containerd "github.com/containerd/containerd/v2/client"
 	"github.com/containerd/containerd/v2/core/mount"
 	"github.com/containerd/containerd/v2/pkg/archive"
+	"github.com/containerd/continuity/fs"
 	"github.com/containerd/log"
 
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
@@ -36,6 +38,10 @@ func Export(ctx context.Context, client *containerd.Client, containerReq string,
 	if runtime.GOOS == "windows" {
 		return fmt.Errorf("export command is not supported on Windows")
 	}
+	filter, err := newPathFilter(options.Filter, options.Exclude)
+	if err != nil {
+		return err
+	}
 
 	walker := &containerwalker.ContainerWalker{
 		Client: client,
@@ -43,7 +49,7 @@ func Export(ctx context.Context, client *containerd.Client, containerReq string,
 			if found.MatchCount > 1 {
 				return fmt.Errorf("multiple IDs found with provided prefix: %s", found.Req)
 			}
-			return exportContainer(ctx, client, found.Container, options)
+			return exportContainer(ctx, client, found.Container, filter, options)
 		},
 	}
 
@@ -56,7 +62,17 @@ func Export(ctx context.Context, client *containerd.Client, containerReq string,
 	return nil
 }
 
-func exportContainer(ctx context.Context, client *containerd.Client, container containerd.Container, options types.ContainerExportOptions) error {
+func exportContainer(ctx context.Context, client *containerd.Client, container containerd.Container, filter *pathFilter, options types.ContainerExportOptions) error {
+	filter, err := filter.withoutVolumes(ctx, container)
+	if err != nil {
+		return err
+	}
+	if options.ChangesOnly {
+		return withContainerLayers(ctx, client, container, func(lower, upper string) error {
+			return writeChanges(ctx, options.Stdout, lower, upper, filter)
+		})
+	}
+
 	// Get container info to access the snapshot
 	conInfo, err := container.Info(ctx)
 	if err != nil {
@@ -92,10 +108,10 @@ func exportContainer(ctx context.Context, client *containerd.Client, container c
 	log.G(ctx).Debugf("Mounted container snapshot at %s", tempDir)
 
 	// Create tar archive using WriteDiff
-	return createTarArchiveWithWriteDiff(ctx, tempDir, options)
+	return createTarArchiveWithWriteDiff(ctx, tempDir, filter, options)
 }
 
-func createTarArchiveWithWriteDiff(ctx context.Context, rootPath string, options types.ContainerExportOptions) error {
+func createTarArchiveWithWriteDiff(ctx context.Context, rootPath string, filter *pathFilter, options types.ContainerExportOptions) error {
 	// Create a temporary empty directory to use as the "before" state for WriteDiff
 	emptyDir, err := os.MkdirTemp("", "nerdctl-export-empty-")
 	if err != nil {
@@ -140,7 +156,7 @@ func createTarArchiveWithWriteDiff(ctx context.Context, rootPath string, options
 
 	// Use WriteDiff to create a tar stream comparing the container rootfs (rootPath)
 	// with an empty directory (emptyDir). This produces a complete export of the container.
-	err = archive.WriteDiff(ctx, options.Stdout, emptyDir, rootPath)
+	err = writeChanges(ctx, options.Stdout, emptyDir, rootPath, filter)
 	if err != nil {
 		return fmt.Errorf("failed to write tar diff: %w", err)
 	}
@@ -149,3 +165,19 @@ func createTarArchiveWithWriteDiff(ctx context.Context, rootPath string, options
 
 	return nil
 }
+
+// writeChanges writes the changes from lower to upper matching the filter as an OCI layer tar, like archive.WriteDiff.
+// The parent directories of the matching paths are always written.
+func writeChanges(ctx context.Context, w io.Writer, lower, upper string, filter *pathFilter) error {
+	cw := archive.NewChangeWriter(w, upper)
+	err := fs.Changes(ctx, lower, upper, func(kind fs.ChangeKind, p string, fi os.FileInfo, err error) error {
+		if err == nil && !filter.match(p) {
+			return nil
+		}
+		return cw.HandleChange(kind, p, fi, err)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create diff tar stream: %w", err)
+	}
+	return cw.Close()
+}
@@ -30,6 +30,7 @@ import (
 	"github.com/containerd/log"
 
 	"github.com/containerd/nerdctl/v2/pkg/containerutil"
+	"github.com/containerd/nerdctl/v2/pkg/filterutil"
 )
 
 func foldContainerFilters(ctx context.Context, containers []containerd.Container, filters []string) (*containerFilterContext, error) {
@@ -179,21 +180,8 @@ func (cl *containerFilterContext) foldNameFilter(_ context.Context, filter, valu
 }
 
 func (cl *containerFilterContext) foldLabelFilter(_ context.Context, filter, value string) error {
-	k, v, hasValue := value, "", false
-	if subs := strings.SplitN(value, "=", 2); len(subs) == 2 {
-		hasValue = true
-		k, v = subs[0], subs[1]
-	}
-	cl.labelFilterFuncs = append(cl.labelFilterFuncs, func(labels map[string]string) bool {
-		if labels == nil {
-			return false
-		}
-		val, ok := labels[k]
-		if !ok || (hasValue && val != v) {
-			return false
-		}
-		return true
-	})
+	labelFilter := filterutil.ParseLabel(value, strings.HasPrefix(filter, filterutil.Label+"!="))
+	cl.labelFilterFuncs = append(cl.labelFilterFuncs, labelFilter.Match)
 	return nil
 }
 
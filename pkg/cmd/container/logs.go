@@ -37,9 +37,13 @@ import (
 	"github.com/containerd/nerdctl/v2/pkg/labels"
 	"github.com/containerd/nerdctl/v2/pkg/labels/k8slabels"
 	"github.com/containerd/nerdctl/v2/pkg/logging"
+	"github.com/containerd/nerdctl/v2/pkg/logging/logfilter"
 )
 
 func Logs(ctx context.Context, client *containerd.Client, container string, options types.ContainerLogsOptions) error {
+	if _, err := logFilter(options); err != nil {
+		return err
+	}
 	dataStore, err := clientutil.DataStore(options.GOptions.DataRoot, options.GOptions.Address)
 	if err != nil {
 		return err
@@ -53,6 +57,20 @@ func Logs(ctx context.Context, client *containerd.Client, container string, opti
 	stopChannel := make(chan os.Signal, 1)
 	// catch OS signals:
 	signal.Notify(stopChannel, syscall.SIGTERM, syscall.SIGINT)
+	defer signal.Stop(stopChannel)
+	// the log viewer is stopped when ctx is done too, e.g. when an API client goes away
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			select {
+			case stopChannel <- os.Interrupt:
+			default:
+			}
+		case <-done:
+		}
+	}()
 
 	walker := &containerwalker.ContainerWalker{
 		Client: client,
@@ -60,119 +78,141 @@ func Logs(ctx context.Context, client *containerd.Client, container string, opti
 			if found.MatchCount > 1 {
 				return fmt.Errorf("multiple IDs found with provided prefix: %s", found.Req)
 			}
-			l, err := found.Container.Labels(ctx)
-			if err != nil {
+			return printContainerLogs(ctx, dataStore, found.Container, options, stopChannel)
+		},
+	}
+	n, err := walker.Walk(ctx, container)
+	if err != nil {
+		return err
+	} else if n == 0 {
+		return fmt.Errorf("no such container %s", container)
+	}
+	return nil
+}
+
+// printContainerLogs prints the logs of a container to options.Stdout and options.Stderr.
+// With options.Follow, it returns when the container exits or when stopChannel receives a signal.
+func printContainerLogs(ctx context.Context, dataStore string, container containerd.Container, options types.ContainerLogsOptions, stopChannel chan os.Signal) error {
+	l, err := container.Labels(ctx)
+	if err != nil {
+		return err
+	}
+
+	logPath, err := getLogPath(ctx, container)
+	if err != nil {
+		return err
+	}
+
+	filter, err := logFilter(options)
+	if err != nil {
+		return err
+	}
+
+	follow := options.Follow
+	if follow {
+		task, err := container.Task(ctx, nil)
+		if err != nil {
+			if !errdefs.IsNotFound(err) {
 				return err
 			}
-
-			logPath, err := getLogPath(ctx, found.Container)
+			follow = false
+		} else {
+			status, err := task.Status(ctx)
 			if err != nil {
 				return err
 			}
-
-			follow := options.Follow
-			if follow {
-				task, err := found.Container.Task(ctx, nil)
+			if status.Status != containerd.Running {
+				follow = false
+			} else {
+				waitCh, err := task.Wait(ctx)
 				if err != nil {
-					if !errdefs.IsNotFound(err) {
-						return err
-					}
-					follow = false
-				} else {
-					status, err := task.Status(ctx)
-					if err != nil {
-						return err
-					}
-					if status.Status != containerd.Running {
-						follow = false
-					} else {
-						waitCh, err := task.Wait(ctx)
-						if err != nil {
-							return fmt.Errorf("failed to get wait channel for task %#v: %w", task, err)
-						}
-
-						// Setup goroutine to send stop event if container task finishes:
-						go func() {
-							<-waitCh
-							// Wait for logger to process remaining logs after container exit
-							if err = logging.WaitForLogger(dataStore, l[labels.Namespace], found.Container.ID()); err != nil {
-								log.G(ctx).WithError(err).Error("failed to wait for logger shutdown")
-							}
-							log.G(ctx).Debugf("container task has finished, sending kill signal to log viewer")
-							stopChannel <- os.Interrupt
-						}()
-					}
+					return fmt.Errorf("failed to get wait channel for task %#v: %w", task, err)
 				}
-			}
 
-			var detailPrefix string
-			if options.Details {
-				if logConfigJSON, ok := l["nerdctl/log-config"]; ok {
-					type logConfig struct {
-						Opts map[string]string `json:"opts"`
+				// Setup goroutine to send stop event if container task finishes:
+				go func() {
+					<-waitCh
+					// Wait for logger to process remaining logs after container exit
+					if err = logging.WaitForLogger(dataStore, l[labels.Namespace], container.ID()); err != nil {
+						log.G(ctx).WithError(err).Error("failed to wait for logger shutdown")
 					}
+					log.G(ctx).Debugf("container task has finished, sending kill signal to log viewer")
+					stopChannel <- os.Interrupt
+				}()
+			}
+		}
+	}
+
+	var detailPrefix string
+	if options.Details {
+		if logConfigJSON, ok := l["nerdctl/log-config"]; ok {
+			type logConfig struct {
+				Opts map[string]string `json:"opts"`
+			}
 
-					e, err := getContainerEnvs(ctx, found.Container)
-					if err != nil {
-						return err
+			e, err := getContainerEnvs(ctx, container)
+			if err != nil {
+				return err
+			}
+
+			var logCfg logConfig
+			var optPairs []string
+
+			if err := json.Unmarshal([]byte(logConfigJSON), &logCfg); err == nil {
+				envOpts, labelOpts := getLogOpts(logCfg.Opts)
+
+				for _, v := range envOpts {
+					if env, ok := e[v]; ok {
+						optPairs = append(optPairs, fmt.Sprintf("%s=%s", v, env))
 					}
+				}
 
-					var logCfg logConfig
-					var optPairs []string
-
-					if err := json.Unmarshal([]byte(logConfigJSON), &logCfg); err == nil {
-						envOpts, labelOpts := getLogOpts(logCfg.Opts)
-
-						for _, v := range envOpts {
-							if env, ok := e[v]; ok {
-								optPairs = append(optPairs, fmt.Sprintf("%s=%s", v, env))
-							}
-						}
-
-						for _, v := range labelOpts {
-							if label, ok := l[v]; ok {
-								optPairs = append(optPairs, fmt.Sprintf("%s=%s", v, label))
-							}
-						}
-
-						if len(optPairs) > 0 {
-							sort.Strings(optPairs)
-							detailPrefix = strings.Join(optPairs, ",")
-						}
-					} else {
-						log.L.Warn("failed to parse `--details` option, detailed information might not be displayed")
+				for _, v := range labelOpts {
+					if label, ok := l[v]; ok {
+						optPairs = append(optPairs, fmt.Sprintf("%s=%s", v, label))
 					}
 				}
-			}
 
-			logViewOpts := logging.LogViewOptions{
-				ContainerID:       found.Container.ID(),
-				Namespace:         l[labels.Namespace],
-				DatastoreRootPath: dataStore,
-				LogPath:           logPath,
-				Follow:            follow,
-				Timestamps:        options.Timestamps,
-				Tail:              options.Tail,
-				Since:             options.Since,
-				Until:             options.Until,
-				Details:           options.Details,
-				DetailPrefix:      &detailPrefix,
-			}
-			logViewer, err := logging.InitContainerLogViewer(l, logViewOpts, stopChannel, options.GOptions.Experimental)
-			if err != nil {
-				return err
+				if len(optPairs) > 0 {
+					sort.Strings(optPairs)
+					detailPrefix = strings.Join(optPairs, ",")
+				}
+			} else {
+				log.L.Warn("failed to parse `--details` option, detailed information might not be displayed")
 			}
+		}
+	}
 
-			return logViewer.PrintLogsTo(options.Stdout, options.Stderr)
-		},
+	logViewOpts := logging.LogViewOptions{
+		ContainerID:       container.ID(),
+		Namespace:         l[labels.Namespace],
+		DatastoreRootPath: dataStore,
+		LogPath:           logPath,
+		Follow:            follow,
+		Timestamps:        options.Timestamps,
+		Tail:              options.Tail,
+		Since:             options.Since,
+		Until:             options.Until,
+		Details:           options.Details,
+		DetailPrefix:      &detailPrefix,
+		Filter:            filter,
 	}
-	n, err := walker.Walk(ctx, container)
+	logViewer, err := logging.InitContainerLogViewer(l, logViewOpts, stopChannel, options.GOptions.Experimental)
 	if err != nil {
 		return err
-	} else if n == 0 {
-		return fmt.Errorf("no such container %s", container)
 	}
-	return nil
+
+	return logViewer.PrintLogsTo(options.Stdout, options.Stderr)
+}
+
+// logFilter returns the filter of the log lines for the options.
+// The "json" format is the output format of nerdctl, not a template of the fields.
+func logFilter(options types.ContainerLogsOptions) (*logfilter.Filter, error) {
+	format := options.Format
+	if format == "json" {
+		format = ""
+	}
+	return logfilter.New(options.Grep, options.Level, format)
 }
 
 func getLogPath(ctx context.Context, container containerd.Container) (string, error) {
@@ -0,0 +1,457 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	eventstypes "github.com/containerd/containerd/api/events"
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
+	"github.com/containerd/nerdctl/v2/pkg/composer/pipetagger"
+	"github.com/containerd/nerdctl/v2/pkg/idgen"
+	"github.com/containerd/nerdctl/v2/pkg/idutil/containerwalker"
+	"github.com/containerd/nerdctl/v2/pkg/labels"
+	"github.com/containerd/nerdctl/v2/pkg/logging"
+	"github.com/containerd/nerdctl/v2/pkg/logging/logfilter"
+)
+
+// logsMergeInterval is the interval at which the lines received while following
+// the logs of several containers are sorted and printed.
+const logsMergeInterval = 100 * time.Millisecond
+
+// logsMergeBuffer is the number of lines of a container buffered while the logs
+// of several containers are merged, without --follow.
+const logsMergeBuffer = 100
+
+// logLine is a line of the logs of a container.
+type logLine struct {
+	name   string
+	id     string
+	stream string
+	time   time.Time
+	// text is the line without the trailing newline, and without the timestamp
+	// unless the timestamps were requested
+	text string
+}
+
+// logJSONEntry is a line printed with `--format json`.
+type logJSONEntry struct {
+	Container string    `json:"container"`
+	ID        string    `json:"id"`
+	Stream    string    `json:"stream"`
+	Time      time.Time `json:"time"`
+	Log       string    `json:"log"`
+	// Fields are the fields of the structured (JSON or logfmt) log lines
+	Fields map[string]any `json:"fields,omitempty"`
+}
+
+// MultiLogs prints the logs of the containers selected by names or IDs and by filters,
+// merged in timestamp order and prefixed with the container names.
+// With options.Follow, the matching containers that start later are followed too,
+// until ctx is done or an interrupt is received.
+func MultiLogs(ctx context.Context, client *containerd.Client, reqs []string, options types.ContainerLogsOptions) error {
+	if _, err := logFilter(options); err != nil {
+		return err
+	}
+	dataStore, err := clientutil.DataStore(options.GOptions.DataRoot, options.GOptions.Address)
+	if err != nil {
+		return err
+	}
+	targets, err := logsTargets(ctx, client, reqs, options.Filters)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	sigC := make(chan os.Signal, 1)
+	signal.Notify(sigC, syscall.SIGTERM, syscall.SIGINT)
+	defer signal.Stop(sigC)
+	go func() {
+		select {
+		case <-sigC:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	m := &logMultiplexer{
+		options:   options,
+		dataStore: dataStore,
+		lines:     make(chan logLine, 1000),
+		followed:  make(map[string]chan os.Signal),
+		seen:      make(map[string]bool),
+		taggers:   make(map[string]*pipetagger.PipeTagger),
+	}
+	for _, c := range targets {
+		m.width = max(m.width, len(logsContainerName(ctx, c)))
+	}
+	for _, c := range targets {
+		m.follow(ctx, c)
+	}
+	if options.Follow {
+		go m.watch(ctx, client, reqs)
+	}
+	go func() {
+		if options.Follow {
+			<-ctx.Done()
+			m.stopAll()
+		}
+		m.wg.Wait()
+		close(m.lines)
+	}()
+	return m.print(options.Follow)
+}
+
+// logsTargets returns the containers matching the requests (all the containers if empty) and the filters.
+func logsTargets(ctx context.Context, client *containerd.Client, reqs []string, filters []string) ([]containerd.Container, error) {
+	var containers []containerd.Container
+	if len(reqs) == 0 {
+		var err error
+		if containers, err = client.Containers(ctx); err != nil {
+			return nil, err
+		}
+	} else {
+		seen := make(map[string]bool)
+		walker := &containerwalker.ContainerWalker{
+			Client: client,
+			OnFound: func(ctx context.Context, found containerwalker.Found) error {
+				if found.MatchCount > 1 {
+					return fmt.Errorf("multiple IDs found with provided prefix: %s", found.Req)
+				}
+				if !seen[found.Container.ID()] {
+					seen[found.Container.ID()] = true
+					containers = append(containers, found.Container)
+				}
+				return nil
+			},
+		}
+		for _, req := range reqs {
+			n, err := walker.Walk(ctx, req)
+			if err != nil {
+				return nil, err
+			} else if n == 0 {
+				return nil, fmt.Errorf("no such container %s", req)
+			}
+		}
+	}
+	if len(filters) == 0 {
+		return containers, nil
+	}
+	filterCtx, err := foldContainerFilters(ctx, containers, filters)
+	if err != nil {
+		return nil, err
+	}
+	return filterCtx.MatchesFilters(ctx), nil
+}
+
+func logsContainerName(ctx context.Context, c containerd.Container) string {
+	if l, err := c.Labels(ctx); err == nil && l[labels.Name] != "" {
+		return l[labels.Name]
+	}
+	return idgen.TruncateID(c.ID())
+}
+
+// logMultiplexer merges the logs of several containers.
+type logMultiplexer struct {
+	options   types.ContainerLogsOptions
+	dataStore string
+	// lines receives the lines of all the containers, with --follow
+	lines chan logLine
+	// sources receive the lines of each container, without --follow
+	sources []chan logLine
+	wg      sync.WaitGroup
+
+	mu sync.Mutex
+	// followed holds the stop channels of the log viewers, by container ID
+	followed map[string]chan os.Signal
+	// seen is true for the containers whose logs have been printed
+	seen    map[string]bool
+	stopped bool
+	width   int
+
+	// taggers is only used by print
+	taggers map[string]*pipetagger.PipeTagger
+}
+
+// follow starts printing the logs of a container, unless they are already being printed.
+// The logs of a container that was followed before, and that was restarted, are printed from now on.
+func (m *logMultiplexer) follow(ctx context.Context, c containerd.Container) {
+	id := c.ID()
+	name := logsContainerName(ctx, c)
+	options := m.options
+	m.mu.Lock()
+	if _, ok := m.followed[id]; ok || m.stopped {
+		m.mu.Unlock()
+		return
+	}
+	if m.seen[id] {
+		options.Since = time.Now().UTC().Format(time.RFC3339Nano)
+	}
+	stopChannel := make(chan os.Signal, 1)
+	m.followed[id] = stopChannel
+	m.seen[id] = true
+	m.width = max(m.width, len(name))
+	m.wg.Add(1)
+	out := m.lines
+	if !m.options.Follow {
+		out = make(chan logLine, logsMergeBuffer)
+		m.sources = append(m.sources, out)
+	}
+	m.mu.Unlock()
+
+	// The timestamps are needed to merge the lines, but journald does not print them
+	timestamps := true
+	if l, err := c.Labels(ctx); err == nil {
+		if logConfig, err := logging.LoadLogConfig(m.dataStore, l[labels.Namespace], id); err == nil && logConfig.Driver == "journald" {
+			timestamps = false
+		}
+	}
+
+	go func() {
+		defer m.wg.Done()
+		defer func() {
+			m.mu.Lock()
+			delete(m.followed, id)
+			m.mu.Unlock()
+		}()
+		stdoutR, stdoutW := io.Pipe()
+		stderrR, stderrW := io.Pipe()
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go m.scan(&wg, stdoutR, out, name, id, "stdout", timestamps)
+		go m.scan(&wg, stderrR, out, name, id, "stderr", timestamps)
+
+		options.Stdout = stdoutW
+		options.Stderr = stderrW
+		options.Timestamps = timestamps
+		if err := printContainerLogs(ctx, m.dataStore, c, options, stopChannel); err != nil {
+			log.G(ctx).WithError(err).Warnf("failed to print the logs of container %q", name)
+		}
+		stdoutW.Close()
+		stderrW.Close()
+		wg.Wait()
+		if out != m.lines {
+			close(out)
+		}
+	}()
+}
+
+// stopAll stops the log viewers and prevents new ones from being started.
+func (m *logMultiplexer) stopAll() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.stopped = true
+	for _, stopChannel := range m.followed {
+		select {
+		case stopChannel <- os.Interrupt:
+		default:
+		}
+	}
+}
+
+// watch follows the matching containers when they start.
+func (m *logMultiplexer) watch(ctx context.Context, client *containerd.Client, reqs []string) {
+	eventsCh, errCh := client.EventService().Subscribe(ctx, `topic=="/tasks/start"`)
+	for {
+		v, err := nextEvent(ctx, eventsCh, errCh)
+		if err != nil {
+			if !errors.Is(err, context.Canceled) {
+				log.G(ctx).WithError(err).Warn("failed to watch the containers that start")
+			}
+			return
+		}
+		start, ok := v.(*eventstypes.TaskStart)
+		if !ok {
+			continue
+		}
+		targets, err := logsTargets(ctx, client, reqs, m.options.Filters)
+		if err != nil {
+			log.G(ctx).WithError(err).Debug("failed to list the containers to follow")
+			continue
+		}
+		for _, c := range targets {
+			if c.ID() == start.ContainerID {
+				m.follow(ctx, c)
+			}
+		}
+	}
+}
+
+// scan reads the lines printed by a log viewer, and sends them to out.
+func (m *logMultiplexer) scan(wg *sync.WaitGroup, r io.Reader, out chan<- logLine, name, id, stream string, timestamps bool) {
+	defer wg.Done()
+	br := bufio.NewReader(r)
+	for {
+		s, err := br.ReadString('\n')
+		if s != "" {
+			line := logLine{name: name, id: id, stream: stream, time: time.Now()}
+			line.text = strings.TrimSuffix(s, "\n")
+			if timestamps {
+				line.time, line.text = parseTimestampedLine(line.text, m.options.Timestamps && m.options.Format != "json")
+			}
+			out <- line
+		}
+		if err != nil {
+			// the pipe is closed when the viewer returns
+			return
+		}
+	}
+}
+
+// parseTimestampedLine parses a line printed by a log viewer with timestamps.
+// The timestamp is kept in the text if keep is true.
+func parseTimestampedLine(s string, keep bool) (time.Time, string) {
+	ts, text, ok := strings.Cut(s, " ")
+	if !ok {
+		return time.Now(), s
+	}
+	t, err := time.Parse(time.RFC3339Nano, ts)
+	if err != nil {
+		return time.Now(), s
+	}
+	if keep {
+		return t, s
+	}
+	return t, text
+}
+
+// print prints the lines in timestamp order. While following the logs, the lines
+// received within logsMergeInterval are sorted. Otherwise, the lines of the containers
+// are merged as they are read.
+func (m *logMultiplexer) print(follow bool) error {
+	if !follow {
+		return m.merge()
+	}
+	var pending []logLine
+	flush := func() error {
+		sort.SliceStable(pending, func(i, j int) bool {
+			return pending[i].time.Before(pending[j].time)
+		})
+		for _, line := range pending {
+			if err := m.printLine(line); err != nil {
+				return err
+			}
+		}
+		pending = pending[:0]
+		return nil
+	}
+	ticker := time.NewTicker(logsMergeInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case line, ok := <-m.lines:
+			if !ok {
+				return flush()
+			}
+			pending = append(pending, line)
+		case <-ticker.C:
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// merge prints the lines of m.sources in timestamp order, with a k-way merge:
+// the lines of each container are already in timestamp order, so the next line
+// is the oldest of the first lines of the containers.
+func (m *logMultiplexer) merge() error {
+	m.mu.Lock()
+	sources := slices.Clone(m.sources)
+	m.mu.Unlock()
+	heads := make([]*logLine, len(sources))
+	for {
+		next := -1
+		for i, src := range sources {
+			if src == nil {
+				continue
+			}
+			if heads[i] == nil {
+				line, ok := <-src
+				if !ok {
+					sources[i] = nil
+					continue
+				}
+				heads[i] = &line
+			}
+			if next < 0 || heads[i].time.Before(heads[next].time) {
+				next = i
+			}
+		}
+		if next < 0 {
+			return nil
+		}
+		if err := m.printLine(*heads[next]); err != nil {
+			return err
+		}
+		heads[next] = nil
+	}
+}
+
+func (m *logMultiplexer) printLine(line logLine) error {
+	if m.options.Format == "json" {
+		b, err := json.Marshal(logJSONEntry{
+			Container: line.name,
+			ID:        line.id,
+			Stream:    line.stream,
+			Time:      line.time.UTC(),
+			Log:       line.text,
+			Fields:    logfilter.ParseFields(line.text),
+		})
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(m.options.Stdout, string(b))
+		return err
+	}
+	key := line.id + "/" + line.stream
+	tagger, ok := m.taggers[key]
+	if !ok {
+		w := m.options.Stdout
+		if line.stream == "stderr" {
+			w = m.options.Stderr
+		}
+		m.mu.Lock()
+		width := m.width + 1
+		m.mu.Unlock()
+		if m.options.NoLogPrefix {
+			width = -1
+		}
+		tagger = pipetagger.New(w, nil, line.name, width, m.options.NoColor)
+		m.taggers[key] = tagger
+	}
+	tagger.WriteLine(line.text)
+	return nil
+}
@@ -0,0 +1,94 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/composer/pipetagger"
+)
+
+func TestParseTimestampedLine(t *testing.T) {
+	expected := time.Date(2024, 4, 11, 12, 1, 9, 800288974, time.UTC)
+	ts, text := parseTimestampedLine("2024-04-11T12:01:09.800288974Z hello world", false)
+	assert.Assert(t, ts.Equal(expected))
+	assert.Equal(t, text, "hello world")
+
+	ts, text = parseTimestampedLine("2024-04-11T12:01:09.800288974Z hello world", true)
+	assert.Assert(t, ts.Equal(expected))
+	assert.Equal(t, text, "2024-04-11T12:01:09.800288974Z hello world")
+
+	// lines without a timestamp are kept as is
+	_, text = parseTimestampedLine("hello world", false)
+	assert.Equal(t, text, "hello world")
+}
+
+func TestLogMultiplexerPrint(t *testing.T) {
+	base := time.Date(2024, 4, 11, 12, 0, 0, 0, time.UTC)
+	lines := []logLine{
+		{name: "web", id: "1", stream: "stdout", time: base.Add(2 * time.Second), text: "third"},
+		{name: "db", id: "2", stream: "stdout", time: base, text: "first"},
+		{name: "web", id: "1", stream: "stderr", time: base.Add(time.Second), text: "second"},
+	}
+	newMultiplexer := func(options types.ContainerLogsOptions) *logMultiplexer {
+		m := &logMultiplexer{
+			options: options,
+			lines:   make(chan logLine, len(lines)),
+			width:   3,
+			taggers: make(map[string]*pipetagger.PipeTagger),
+		}
+		for _, line := range lines {
+			m.lines <- line
+		}
+		close(m.lines)
+		// Without --follow, each container has its own source, in timestamp order
+		web, db := make(chan logLine, 2), make(chan logLine, 1)
+		web <- lines[2]
+		web <- lines[0]
+		db <- lines[1]
+		close(web)
+		close(db)
+		m.sources = []chan logLine{web, db}
+		return m
+	}
+
+	for _, follow := range []bool{false, true} {
+		var stdout, stderr bytes.Buffer
+		m := newMultiplexer(types.ContainerLogsOptions{Stdout: &stdout, Stderr: &stderr, NoColor: true, Follow: follow})
+		assert.NilError(t, m.print(follow))
+		assert.Equal(t, stdout.String(), "db  |first\nweb |third\n")
+		assert.Equal(t, stderr.String(), "web |second\n")
+	}
+
+	var stdout bytes.Buffer
+	m := newMultiplexer(types.ContainerLogsOptions{Stdout: &stdout, Format: "json"})
+	assert.NilError(t, m.print(false))
+	var texts []string
+	for _, s := range strings.Split(strings.TrimSpace(stdout.String()), "\n") {
+		var e logJSONEntry
+		assert.NilError(t, json.Unmarshal([]byte(s), &e))
+		texts = append(texts, e.Container+"/"+e.Stream+"/"+e.Log)
+	}
+	assert.DeepEqual(t, texts, []string{"db/stdout/first", "web/stderr/second", "web/stdout/third"})
+}
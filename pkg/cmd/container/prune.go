@@ -26,10 +26,16 @@ import (
 	"github.com/containerd/log"
 
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/filterutil"
 )
 
-// Prune remove all stopped containers
+// Prune remove all stopped containers matching the filters
 func Prune(ctx context.Context, client *containerd.Client, options types.ContainerPruneOptions) error {
+	filters, err := filterutil.ParsePruneFilters(options.Filters)
+	if err != nil {
+		return err
+	}
+
 	containers, err := client.Containers(ctx)
 	if err != nil {
 		return err
@@ -37,6 +43,14 @@ func Prune(ctx context.Context, client *containerd.Client, options types.Contain
 
 	var deleted []string
 	for _, c := range containers {
+		info, err := c.Info(ctx, containerd.WithoutRefreshedMetadata)
+		if err != nil {
+			log.G(ctx).WithError(err).Warnf("failed to get info of container %s", c.ID())
+			continue
+		}
+		if !filters.Match(info.CreatedAt, info.Labels) {
+			continue
+		}
 		if err = RemoveContainer(ctx, c, options.GOptions, false, true, client); err == nil {
 			deleted = append(deleted, c.ID())
 			continue
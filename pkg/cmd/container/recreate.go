@@ -0,0 +1,298 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/image"
+	"github.com/containerd/nerdctl/v2/pkg/containerutil"
+	"github.com/containerd/nerdctl/v2/pkg/dnsutil/hostsstore"
+	"github.com/containerd/nerdctl/v2/pkg/idutil/containerwalker"
+	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/dockercompat"
+	"github.com/containerd/nerdctl/v2/pkg/internal/filesystem"
+	"github.com/containerd/nerdctl/v2/pkg/labels"
+	"github.com/containerd/nerdctl/v2/pkg/namestore"
+	"github.com/containerd/nerdctl/v2/pkg/platformutil"
+)
+
+// Recreate stops the given containers and creates them again with the configuration
+// they were created with, then removes the original containers and starts the new ones
+// that were running. Anonymous volumes and networks are reattached to the new containers.
+// If a container cannot be created again, the original one is restored.
+func Recreate(ctx context.Context, client *containerd.Client, reqs []string, options types.ContainerRecreateOptions) error {
+	walker := &containerwalker.ContainerWalker{
+		Client: client,
+		OnFound: func(ctx context.Context, found containerwalker.Found) error {
+			if found.MatchCount > 1 {
+				return fmt.Errorf("multiple IDs found with provided prefix: %s", found.Req)
+			}
+			return recreateContainer(ctx, client, found.Container, options)
+		},
+	}
+	return walker.WalkAll(ctx, reqs, true)
+}
+
+func recreateContainer(ctx context.Context, client *containerd.Client, c containerd.Container, options types.ContainerRecreateOptions) error {
+	config, err := LoadCreateConfig(ctx, c)
+	if err != nil {
+		return err
+	}
+	l, err := c.Labels(ctx)
+	if err != nil {
+		return err
+	}
+	args := config.Args
+	createOpt := config.Options
+
+	anonVolumes, err := reattachAnonymousVolumes(l, &createOpt)
+	if err != nil {
+		return err
+	}
+
+	// Pull before touching the container, so that a failing pull leaves it untouched
+	if options.Pull && !createOpt.Rootfs {
+		var platformSS []string
+		if createOpt.Platform != "" {
+			platformSS = append(platformSS, createOpt.Platform)
+		}
+		ocispecPlatforms, err := platformutil.NewOCISpecPlatformSlice(false, platformSS)
+		if err != nil {
+			return err
+		}
+		pullOpt := createOpt.ImagePullOpt
+		pullOpt.Stdout = options.Stdout
+		pullOpt.Stderr = options.Stderr
+		pullOpt.GOptions = options.GOptions
+		pullOpt.Mode = "always"
+		pullOpt.OCISpecPlatform = ocispecPlatforms
+		pullOpt.Unpack = nil
+		if _, err := image.EnsureImage(ctx, client, args[0], pullOpt); err != nil {
+			return err
+		}
+	}
+
+	running := false
+	if task, err := c.Task(ctx, nil); err == nil {
+		if st, err := task.Status(ctx); err == nil {
+			running = st.Status == containerd.Running || st.Status == containerd.Paused
+		}
+	}
+
+	dataStore, err := clientutil.DataStore(options.GOptions.DataRoot, options.GOptions.Address)
+	if err != nil {
+		return err
+	}
+	namest, err := namestore.New(dataStore, options.GOptions.Namespace)
+	if err != nil {
+		return err
+	}
+	hostst, err := hostsstore.New(dataStore, options.GOptions.Namespace)
+	if err != nil {
+		return err
+	}
+
+	if err := containerutil.Stop(ctx, c, options.Timeout, ""); err != nil {
+		return err
+	}
+	// The container is kept under a temporary name until the new one is created,
+	// so that it can be restored if the creation fails.
+	name := l[labels.Name]
+	tmpName := fmt.Sprintf("%s-recreate-%s", name, c.ID()[:12])
+	if err := renameContainer(ctx, c, tmpName, options.GOptions.Namespace, namest, hostst); err != nil {
+		return err
+	}
+	restore := func() {
+		if err := renameContainer(ctx, c, name, options.GOptions.Namespace, namest, hostst); err != nil {
+			log.G(ctx).WithError(err).Errorf("failed to restore the name of container %s, it is kept as %s", c.ID(), tmpName)
+			return
+		}
+		if running {
+			if err := containerutil.Start(ctx, c, false, false, client, ""); err != nil {
+				log.G(ctx).WithError(err).Errorf("failed to restart container %s", name)
+			}
+		}
+	}
+
+	// The ID file of the original container is rewritten below, rather than created by Create,
+	// as it already exists or is in a directory that may be gone (e.g., the ones of compose).
+	cidFile := createOpt.CidFile
+	createOpt.CidFile = ""
+	newContainer, err := createFromConfig(ctx, client, args, config.NetworkOptions, createOpt, options.Stdout, options.Stderr, options.GOptions)
+	if err != nil {
+		restore()
+		return fmt.Errorf("failed to re-create container %s, the original container was kept: %w", name, err)
+	}
+	if err := RemoveContainer(ctx, c, options.GOptions, true, false, client); err != nil {
+		return fmt.Errorf("failed to remove the original container of %s, kept as %s: %w", name, tmpName, err)
+	}
+	if cidFile != "" {
+		if err := rewriteCIDFile(cidFile, newContainer.ID()); err != nil {
+			return err
+		}
+	}
+
+	if len(anonVolumes) > 0 {
+		// The reattached volumes are passed as named volumes, keep tracking them as anonymous ones
+		// so that `nerdctl rm -v` still removes them.
+		newLabels, err := newContainer.Labels(ctx)
+		if err != nil {
+			return err
+		}
+		var names []string
+		if s := newLabels[labels.AnonymousVolumes]; s != "" {
+			if err := json.Unmarshal([]byte(s), &names); err != nil {
+				return err
+			}
+		}
+		for _, name := range anonVolumes {
+			if !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+		b, err := json.Marshal(names)
+		if err != nil {
+			return err
+		}
+		if _, err := newContainer.SetLabels(ctx, map[string]string{labels.AnonymousVolumes: string(b)}); err != nil {
+			return err
+		}
+	}
+
+	if running {
+		if err := containerutil.Start(ctx, newContainer, false, false, client, ""); err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintln(options.Stdout, newContainer.ID())
+	return err
+}
+
+// reattachAnonymousVolumes rewrites the volume options so that the anonymous volumes
+// of the container are mounted again, by name, on the same destinations.
+// It returns the names of the reattached volumes.
+func reattachAnonymousVolumes(containerLabels map[string]string, createOpt *types.ContainerCreateOptions) ([]string, error) {
+	var anonymous []string
+	if s := containerLabels[labels.AnonymousVolumes]; s != "" {
+		if err := json.Unmarshal([]byte(s), &anonymous); err != nil {
+			return nil, err
+		}
+	}
+	if len(anonymous) == 0 {
+		return nil, nil
+	}
+	var mounts []dockercompat.MountPoint
+	if s := containerLabels[labels.Mounts]; s != "" {
+		if err := json.Unmarshal([]byte(s), &mounts); err != nil {
+			return nil, err
+		}
+	}
+
+	var reattached []string
+	for _, m := range mounts {
+		if m.Name == "" || !slices.Contains(anonymous, m.Name) {
+			continue
+		}
+		dest := filepath.Clean(m.Destination)
+		found := false
+		for i, v := range createOpt.Volume {
+			// An anonymous volume is specified as "DEST[:OPTIONS]",
+			// and as "NAME:DEST[:OPTIONS]" once reattached by a previous recreate
+			fields := strings.Split(v, ":")
+			if len(fields) > 1 && fields[0] == m.Name && filepath.Clean(fields[1]) == dest {
+				found = true
+				break
+			}
+			if filepath.Clean(fields[0]) != dest || (len(fields) > 1 && strings.HasPrefix(fields[1], "/")) {
+				continue
+			}
+			createOpt.Volume[i] = m.Name + ":" + v
+			found = true
+			break
+		}
+		if !found {
+			for i, v := range createOpt.Mount {
+				if s, ok := reattachMountFlag(v, dest, m.Name); ok {
+					createOpt.Mount[i] = s
+					found = true
+					break
+				}
+			}
+		}
+		if !found {
+			// Declared with a "VOLUME" instruction in the image
+			spec := m.Name + ":" + dest
+			if !m.RW {
+				spec += ":ro"
+			}
+			createOpt.Volume = append(createOpt.Volume, spec)
+		}
+		reattached = append(reattached, m.Name)
+	}
+	return reattached, nil
+}
+
+// reattachMountFlag adds src=name to a "--mount type=volume" flag without a source
+// that targets dest. The type defaults to volume.
+// A flag that already has src=name, from a previous recreate, is returned unchanged.
+func reattachMountFlag(flag, dest, name string) (string, bool) {
+	isVolume := true
+	target, source := "", ""
+	for _, field := range strings.Split(flag, ",") {
+		k, v, _ := strings.Cut(field, "=")
+		switch strings.ToLower(k) {
+		case "type":
+			isVolume = v == "volume"
+		case "source", "src":
+			source = v
+		case "target", "destination", "dst":
+			target = v
+		}
+	}
+	if !isVolume || target == "" || filepath.Clean(target) != dest {
+		return "", false
+	}
+	switch source {
+	case "":
+		return flag + ",src=" + name, true
+	case name:
+		return flag, true
+	default:
+		return "", false
+	}
+}
+
+// rewriteCIDFile writes the ID of a re-created container to the ID file of the original container.
+// The file is not written if its directory no longer exists.
+func rewriteCIDFile(path, id string) error {
+	if _, err := os.Stat(filepath.Dir(path)); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return filesystem.WriteFile(path, []byte(id), 0644)
+}
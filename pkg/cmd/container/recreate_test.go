@@ -0,0 +1,86 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package container
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/labels"
+)
+
+func TestReattachAnonymousVolumes(t *testing.T) {
+	l := map[string]string{
+		labels.AnonymousVolumes: `["anon1","anon2","anon3"]`,
+		labels.Mounts: `[{"Type":"volume","Name":"anon1","Destination":"/data","RW":false},` +
+			`{"Type":"volume","Name":"anon2","Destination":"/cache","RW":true},` +
+			`{"Type":"volume","Name":"anon3","Destination":"/var/lib/app","RW":true},` +
+			`{"Type":"bind","Source":"/host","Destination":"/host","RW":true}]`,
+	}
+	createOpt := types.ContainerCreateOptions{
+		Volume: []string{"/host:/host", "/data:ro"},
+		Mount:  []string{"type=volume,dst=/cache"},
+	}
+	reattached, err := reattachAnonymousVolumes(l, &createOpt)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, reattached, []string{"anon1", "anon2", "anon3"})
+	assert.DeepEqual(t, createOpt.Volume, []string{"/host:/host", "anon1:/data:ro", "anon3:/var/lib/app"})
+	assert.DeepEqual(t, createOpt.Mount, []string{"type=volume,dst=/cache,src=anon2"})
+
+	// Recreating again keeps the volumes reattached by the first recreate
+	reattached, err = reattachAnonymousVolumes(l, &createOpt)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, reattached, []string{"anon1", "anon2", "anon3"})
+	assert.DeepEqual(t, createOpt.Volume, []string{"/host:/host", "anon1:/data:ro", "anon3:/var/lib/app"})
+	assert.DeepEqual(t, createOpt.Mount, []string{"type=volume,dst=/cache,src=anon2"})
+
+	reattached, err = reattachAnonymousVolumes(map[string]string{}, &createOpt)
+	assert.NilError(t, err)
+	assert.Assert(t, reattached == nil)
+}
+
+func TestReattachMountFlag(t *testing.T) {
+	s, ok := reattachMountFlag("dst=/data,readonly", "/data", "v")
+	assert.Assert(t, ok)
+	assert.Equal(t, s, "dst=/data,readonly,src=v")
+	s, ok = reattachMountFlag(s, "/data", "v")
+	assert.Assert(t, ok)
+	assert.Equal(t, s, "dst=/data,readonly,src=v")
+
+	_, ok = reattachMountFlag("type=volume,src=named,dst=/data", "/data", "v")
+	assert.Assert(t, !ok)
+	_, ok = reattachMountFlag("type=tmpfs,dst=/data", "/data", "v")
+	assert.Assert(t, !ok)
+	_, ok = reattachMountFlag("type=volume,dst=/other", "/data", "v")
+	assert.Assert(t, !ok)
+}
+
+func TestRewriteCIDFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cid")
+	assert.NilError(t, os.WriteFile(path, []byte("old"), 0644))
+	assert.NilError(t, rewriteCIDFile(path, "new"))
+	b, err := os.ReadFile(path)
+	assert.NilError(t, err)
+	assert.Equal(t, string(b), "new")
+
+	// The directory of the ID file is gone
+	assert.NilError(t, rewriteCIDFile(filepath.Join(t.TempDir(), "gone", "cid"), "new"))
+}
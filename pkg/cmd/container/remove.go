@@ -32,6 +32,7 @@ import (
 
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/clientutil"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/volume"
 	"github.com/containerd/nerdctl/v2/pkg/containerutil"
 	"github.com/containerd/nerdctl/v2/pkg/dnsutil/hostsstore"
 	"github.com/containerd/nerdctl/v2/pkg/idutil/containerwalker"
@@ -72,6 +73,9 @@ func Remove(ctx context.Context, client *containerd.Client, containers []string,
 			if found.MatchCount > 1 {
 				return fmt.Errorf("multiple IDs found with provided prefix: %s", found.Req)
 			}
+			if err := checkPodInfra(ctx, client, found.Container); err != nil {
+				return err
+			}
 			if err := RemoveContainer(ctx, found.Container, options.GOptions, options.Force, options.Volumes, client); err != nil {
 				if errors.As(err, &ErrContainerStatus{}) {
 					err = fmt.Errorf("%s. unpause/stop container first or force removal", err)
@@ -91,6 +95,23 @@ func Remove(ctx context.Context, client *containerd.Client, containers []string,
 	return err
 }
 
+// checkPodInfra refuses the removal of the infra container of a pod that still has other containers,
+// as they would lose their namespaces.
+func checkPodInfra(ctx context.Context, client *containerd.Client, c containerd.Container) error {
+	l, err := c.Labels(ctx)
+	if err != nil || l[labels.PodInfra] != "true" {
+		return nil
+	}
+	containers, err := client.Containers(ctx, fmt.Sprintf("labels.%q==%q", labels.Pod, l[labels.Pod]))
+	if err != nil {
+		return err
+	}
+	if len(containers) > 1 {
+		return fmt.Errorf("container %s is the infra container of pod %s, which still has other containers. use \"nerdctl pod rm\" instead", c.ID(), l[labels.Pod])
+	}
+	return nil
+}
+
 // RemoveContainer removes a container from containerd store.
 // It will first retrieve system objects (namestore, etcetera), then assess whether we should remove the container or not
 // based of "force" and the status of the task.
@@ -262,7 +283,22 @@ func RemoveContainer(ctx context.Context, c containerd.Container, globalOptions
 			} else {
 				var errs []error
 				_, errs, err = volStore.Remove(func() ([]string, []error, error) {
-					return anonVolumes, nil, nil
+					// Like Docker, keep the anonymous volumes still used by other containers (e.g., with --volumes-from)
+					containers, err := client.Containers(ctx)
+					if err != nil {
+						return nil, nil, err
+					}
+					used, err := volume.UsedVolumes(ctx, containers)
+					if err != nil {
+						return nil, nil, err
+					}
+					var toRemove []string
+					for _, name := range anonVolumes {
+						if _, ok := used[name]; !ok {
+							toRemove = append(toRemove, name)
+						}
+					}
+					return toRemove, nil, nil
 				})
 				if err != nil || len(errs) > 0 {
 					log.G(ctx).WithError(err).Warnf("failed to remove anonymous volumes %v", anonVolumes)
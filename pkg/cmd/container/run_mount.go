@@ -299,7 +299,6 @@ func generateMountOpts(ctx context.Context, client *containerd.Client, ensuredIm
 
 	vfSet := strutil.SliceToSet(options.VolumesFrom)
 	var vfMountPoints []dockercompat.MountPoint
-	var vfAnonVolumes []string
 
 	for _, c := range containers {
 		ls, err := c.Labels(ctx)
@@ -319,12 +318,6 @@ func generateMountOpts(ctx context.Context, client *containerd.Client, ensuredIm
 		}
 
 		if idMatch || nameMatch {
-			if av, found := ls[labels.AnonymousVolumes]; found {
-				err = json.Unmarshal([]byte(av), &vfAnonVolumes)
-				if err != nil {
-					return nil, nil, nil, err
-				}
-			}
 			if m, found := ls[labels.Mounts]; found {
 				err = json.Unmarshal([]byte(m), &vfMountPoints)
 				if err != nil {
@@ -337,8 +330,9 @@ func generateMountOpts(ctx context.Context, client *containerd.Client, ensuredIm
 			if err != nil {
 				return nil, nil, nil, err
 			}
+			// The anonymous volumes of the source container are not recorded as anonymous volumes of this container,
+			// so that they are not removed along with it (e.g., with --rm)
 			opts = append(opts, withMounts(s.Mounts))
-			anonVolumes = append(anonVolumes, vfAnonVolumes...)
 			mountPoints = append(mountPoints, ps...)
 		}
 	}
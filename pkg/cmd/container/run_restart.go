@@ -23,7 +23,9 @@ import (
 
 	containerd "github.com/containerd/containerd/v2/client"
 	"github.com/containerd/containerd/v2/core/runtime/restart"
+	"github.com/containerd/errdefs"
 
+	"github.com/containerd/nerdctl/v2/pkg/labels"
 	"github.com/containerd/nerdctl/v2/pkg/strutil"
 )
 
@@ -92,22 +94,28 @@ func UpdateContainerRestartPolicyLabel(ctx context.Context, client *containerd.C
 	}
 	_, statusLabelExist := lables[restart.StatusLabel]
 	if !statusLabelExist {
+		desireStatus := containerd.Created
 		task, err := container.Task(ctx, nil)
-		if err != nil {
-			return fmt.Errorf("failed to get task:%w", err)
-		}
-		desireStatus := containerd.Running
-		status, err := task.Status(ctx)
 		if err == nil {
-			switch status.Status {
-			case containerd.Stopped:
-				desireStatus = containerd.Stopped
-			case containerd.Created:
-				desireStatus = containerd.Created
+			desireStatus = containerd.Running
+			status, err := task.Status(ctx)
+			if err == nil {
+				switch status.Status {
+				case containerd.Stopped:
+					desireStatus = containerd.Stopped
+				case containerd.Created:
+					desireStatus = containerd.Created
+				}
 			}
+		} else if !errdefs.IsNotFound(err) {
+			return fmt.Errorf("failed to get task:%w", err)
 		}
 		updateOpts = append(updateOpts, restart.WithStatus(desireStatus))
 	}
+	// The restart monitor needs the log URI to restart the task with its logs
+	if _, ok := lables[restart.LogURILabel]; !ok && lables[labels.LogURI] != "" {
+		updateOpts = append(updateOpts, restart.WithLogURIString(lables[labels.LogURI]))
+	}
 
 	return container.Update(ctx, updateOpts...)
 }
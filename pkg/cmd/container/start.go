@@ -21,10 +21,12 @@ import (
 	"fmt"
 
 	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/log"
 
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
 	"github.com/containerd/nerdctl/v2/pkg/containerutil"
 	"github.com/containerd/nerdctl/v2/pkg/idutil/containerwalker"
+	"github.com/containerd/nerdctl/v2/pkg/labels"
 )
 
 // Start starts a list of `containers`. If attach is true, it only starts a single container.
@@ -40,6 +42,9 @@ func Start(ctx context.Context, client *containerd.Client, reqs []string, option
 			if found.MatchCount > 1 {
 				return fmt.Errorf("multiple IDs found with provided prefix: %s", found.Req)
 			}
+			if options.Attach {
+				defer autoRemove(ctx, client, found.Container, options.GOptions)
+			}
 			if err := containerutil.Start(ctx, found.Container, options.Attach, options.Interactive, client, options.DetachKeys); err != nil {
 				return err
 			}
@@ -55,3 +60,24 @@ func Start(ctx context.Context, client *containerd.Client, reqs []string, option
 
 	return walker.WalkAll(ctx, reqs, true)
 }
+
+// autoRemove removes a stopped container created with --rm, along with its anonymous volumes, like `nerdctl run --rm`.
+func autoRemove(ctx context.Context, client *containerd.Client, container containerd.Container, globalOptions types.GlobalCommandOptions) {
+	containerLabels, err := container.Labels(ctx)
+	if err != nil {
+		log.G(ctx).WithError(err).Warnf("failed to get labels of container %s", container.ID())
+		return
+	}
+	if rm, err := containerutil.DecodeContainerRmOptLabel(containerLabels[labels.ContainerAutoRemove]); err != nil || !rm {
+		return
+	}
+	// The container may have been detached from, rather than stopped
+	if task, err := container.Task(ctx, nil); err == nil {
+		if status, err := task.Status(ctx); err != nil || status.Status != containerd.Stopped {
+			return
+		}
+	}
+	if err := RemoveContainer(ctx, container, globalOptions, true, true, client); err != nil {
+		log.G(ctx).WithError(err).Warnf("failed to remove container %s", container.ID())
+	}
+}
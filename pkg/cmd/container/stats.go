@@ -19,6 +19,7 @@ package container
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
@@ -94,12 +95,16 @@ func Stats(ctx context.Context, client *containerd.Client, containerIDs []string
 
 	var err error
 	w := options.Stdout
-	var tmpl *template.Template
+	var (
+		tmpl *template.Template
+		// jsonEnc prints a stream of JSON entries with the raw counters, one per line
+		jsonEnc *json.Encoder
+	)
 	switch options.Format {
 	case "", "table":
 		w = tabwriter.NewWriter(options.Stdout, 10, 1, 3, ' ', 0)
-	case "raw":
-		return errors.New("unsupported format: \"raw\"")
+	case "json", "raw":
+		jsonEnc = json.NewEncoder(options.Stdout)
 	default:
 		tmpl, err = formatter.ParseTemplate(options.Format)
 		if err != nil {
@@ -253,7 +258,7 @@ func Stats(ctx context.Context, client *containerd.Client, containerIDs []string
 	}
 
 	cleanScreen := func() {
-		if !options.NoStream {
+		if !options.NoStream && jsonEnc == nil {
 			fmt.Fprint(options.Stdout, "\033[2J")
 			fmt.Fprint(options.Stdout, "\033[H")
 		}
@@ -265,6 +270,9 @@ func Stats(ctx context.Context, client *containerd.Client, containerIDs []string
 	// firstTick is for creating distant CPU readings.
 	// firstTick stats are not displayed.
 	firstTick := true
+	// lastRead is the time of the last sample printed for each container, so that
+	// the JSON stream does not repeat the samples that were not updated since the previous tick.
+	lastRead := make(map[string]time.Time)
 	for range ticker.C {
 		cleanScreen()
 		ccstats := []statsutil.StatsEntry{}
@@ -290,7 +298,15 @@ func Stats(ctx context.Context, client *containerd.Client, containerIDs []string
 			}
 			rc := statsutil.RenderEntry(&c, options.NoTrunc)
 			if !firstTick {
-				if tmpl != nil {
+				if jsonEnc != nil {
+					if !c.Read.After(lastRead[c.ID]) {
+						continue
+					}
+					lastRead[c.ID] = c.Read
+					if err := jsonEnc.Encode(statsutil.RenderJSONEntry(&c, options.NoTrunc)); err != nil {
+						return err
+					}
+				} else if tmpl != nil {
 					var b bytes.Buffer
 					if err := tmpl.Execute(&b, rc); err != nil {
 						break
@@ -372,43 +388,8 @@ func collect(ctx context.Context, globalOptions types.GlobalCommandOptions, s *s
 		previousStats := new(statsutil.ContainerStats)
 		firstSet := true
 		for {
-			// task is in the for loop to avoid nil task just after Container creation
-			task, err := container.Task(ctx, nil)
-			if err != nil {
-				u <- err
-				continue
-			}
-
-			// Sample system CPU usage close to container usage to avoid
-			// noise in metric calculations.
-			systemUsage, onlineCPUs, err := getSystemCPUUsage()
-			if err != nil {
-				u <- err
-				continue
-			}
-			systemInfo := statsutil.SystemInfo{
-				OnlineCPUs:  onlineCPUs,
-				SystemUsage: systemUsage,
-			}
-			metric, err := task.Metrics(ctx)
-			if err != nil {
-				u <- err
-				continue
-			}
-			anydata, err := typeurl.UnmarshalAny(metric.Data)
-			if err != nil {
-				u <- err
-				continue
-			}
-
-			netNS, err := containerinspector.InspectNetNS(ctx, int(task.Pid()))
-			if err != nil {
-				u <- err
-				continue
-			}
-
 			// when (firstSet == true), we only set container stats without rendering stat entry
-			statsEntry, err := setContainerStatsAndRenderStatsEntry(previousStats, firstSet, anydata, int(task.Pid()), netNS.Interfaces, systemInfo)
+			statsEntry, err := StatsSample(ctx, container, previousStats, firstSet)
 			if err != nil {
 				u <- err
 				continue
@@ -450,3 +431,40 @@ func collect(ctx context.Context, globalOptions types.GlobalCommandOptions, s *s
 		}
 	}
 }
+
+// StatsSample samples the statistics of the task of a container.
+// The CPU usage is computed since previousStats, which is updated with the sample.
+// When firstSet is true, only previousStats is updated, and the returned entry is empty.
+func StatsSample(ctx context.Context, container containerd.Container, previousStats *statsutil.ContainerStats, firstSet bool) (statsutil.StatsEntry, error) {
+	// the task is loaded for each sample to avoid nil task just after Container creation
+	task, err := container.Task(ctx, nil)
+	if err != nil {
+		return statsutil.StatsEntry{}, err
+	}
+
+	// Sample system CPU usage close to container usage to avoid
+	// noise in metric calculations.
+	systemUsage, onlineCPUs, err := getSystemCPUUsage()
+	if err != nil {
+		return statsutil.StatsEntry{}, err
+	}
+	systemInfo := statsutil.SystemInfo{
+		OnlineCPUs:  onlineCPUs,
+		SystemUsage: systemUsage,
+	}
+	metric, err := task.Metrics(ctx)
+	if err != nil {
+		return statsutil.StatsEntry{}, err
+	}
+	anydata, err := typeurl.UnmarshalAny(metric.Data)
+	if err != nil {
+		return statsutil.StatsEntry{}, err
+	}
+
+	netNS, err := containerinspector.InspectNetNS(ctx, int(task.Pid()))
+	if err != nil {
+		return statsutil.StatsEntry{}, err
+	}
+
+	return setContainerStatsAndRenderStatsEntry(previousStats, firstSet, anydata, int(task.Pid()), netNS.Interfaces, systemInfo)
+}
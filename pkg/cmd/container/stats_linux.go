@@ -118,7 +118,7 @@ func setContainerStatsAndRenderStatsEntry(previousStats *statsutil.ContainerStat
 		}
 	} else if data2 != nil {
 		if !firstSet {
-			statsEntry, err = statsutil.SetCgroup2StatsFields(previousStats, data2, nlinks)
+			statsEntry, err = statsutil.SetCgroup2StatsFields(previousStats, data2, nlinks, systemInfo)
 		}
 		previousStats.Cgroup2CPU = data2.CPU.UsageUsec * 1000
 		previousStats.Cgroup2System = data2.CPU.SystemUsec * 1000
@@ -20,16 +20,49 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"io"
 
+	eventstypes "github.com/containerd/containerd/api/events"
 	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/core/events"
+	"github.com/containerd/errdefs"
+	"github.com/containerd/typeurl/v2"
 
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/errutil"
+	"github.com/containerd/nerdctl/v2/pkg/healthcheck"
 	"github.com/containerd/nerdctl/v2/pkg/idutil/containerwalker"
+	"github.com/containerd/nerdctl/v2/pkg/labels"
 )
 
-// Wait blocks until all the containers specified by reqs have stopped, then print their exit codes.
+// Conditions of `nerdctl wait --condition`
+const (
+	WaitConditionNotRunning = "not-running"
+	WaitConditionNextExit   = "next-exit"
+	WaitConditionRemoved    = "removed"
+	WaitConditionHealthy    = "healthy"
+)
+
+// WaitConditions are the supported conditions of `nerdctl wait --condition`.
+var WaitConditions = []string{WaitConditionNotRunning, WaitConditionNextExit, WaitConditionRemoved, WaitConditionHealthy}
+
+type waitResult struct {
+	index int
+	code  uint32
+	err   error
+}
+
+// Wait blocks until all the containers specified by reqs meet the condition, then print their exit codes.
+// With options.Any, it returns as soon as one of them meets the condition, with its exit code.
 func Wait(ctx context.Context, client *containerd.Client, reqs []string, options types.ContainerWaitOptions) error {
+	condition := options.Condition
+	switch condition {
+	case "":
+		condition = WaitConditionNotRunning
+	case WaitConditionNotRunning, WaitConditionNextExit, WaitConditionRemoved, WaitConditionHealthy:
+	default:
+		return fmt.Errorf("invalid condition %q, must be one of %v", condition, WaitConditions)
+	}
+
 	var containers []containerd.Container
 	walker := &containerwalker.ContainerWalker{
 		Client: client,
@@ -47,32 +80,231 @@ func Wait(ctx context.Context, client *containerd.Client, reqs []string, options
 		return err
 	}
 
-	var errs []error
+	if options.Timeout > 0 {
+		var cancelTimeout context.CancelFunc
+		ctx, cancelTimeout = context.WithTimeout(ctx, options.Timeout)
+		defer cancelTimeout()
+	}
+	// the containers still being waited for are abandoned when returning early with options.Any
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	done := make(chan waitResult, len(containers))
+	for i, container := range containers {
+		go func() {
+			code, err := waitContainer(ctx, client, container, condition)
+			if err != nil {
+				if errors.Is(err, context.DeadlineExceeded) {
+					err = fmt.Errorf("timed out after %s", options.Timeout)
+				}
+				err = fmt.Errorf("container %s: %w", reqs[i], err)
+			}
+			done <- waitResult{index: i, code: code, err: err}
+		}()
+	}
+
+	var (
+		errs    []error
+		results = make([]*waitResult, len(containers))
+		next    int
+	)
 	w := options.Stdout
-	for _, container := range containers {
-		if waitErr := waitContainer(ctx, w, container); waitErr != nil {
-			errs = append(errs, waitErr)
+	for range containers {
+		res := <-done
+		if options.Any {
+			if res.err != nil {
+				errs = append(errs, res.err)
+				continue
+			}
+			if condition != WaitConditionHealthy {
+				fmt.Fprintln(w, res.code)
+			}
+			if res.code != 0 {
+				return errutil.NewExitCoderErr(int(res.code))
+			}
+			return nil
+		}
+		// exit codes are printed in the order of the containers
+		results[res.index] = &res
+		for ; next < len(results) && results[next] != nil; next++ {
+			if err := results[next].err; err != nil {
+				errs = append(errs, err)
+			} else if condition != WaitConditionHealthy {
+				fmt.Fprintln(w, results[next].code)
+			}
 		}
 	}
 	return errors.Join(errs...)
 }
 
-func waitContainer(ctx context.Context, w io.Writer, container containerd.Container) error {
+func waitContainer(ctx context.Context, client *containerd.Client, container containerd.Container, condition string) (uint32, error) {
+	switch condition {
+	case WaitConditionNextExit:
+		return waitNextExit(ctx, client, container)
+	case WaitConditionRemoved:
+		return waitRemoved(ctx, client, container)
+	case WaitConditionHealthy:
+		return 0, waitHealthy(ctx, client, container)
+	default:
+		return waitNotRunning(ctx, container)
+	}
+}
+
+// waitNotRunning returns the exit code of the container once it is not running.
+// A container that was never started is not running, with exit code 0.
+func waitNotRunning(ctx context.Context, container containerd.Container) (uint32, error) {
 	task, err := container.Task(ctx, nil)
 	if err != nil {
-		return err
+		if errdefs.IsNotFound(err) {
+			return 0, nil
+		}
+		return 0, err
 	}
 
 	statusC, err := task.Wait(ctx)
 	if err != nil {
-		return err
+		return 0, err
+	}
+
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	case status := <-statusC:
+		code, _, err := status.Result()
+		return code, err
+	}
+}
+
+// waitNextExit returns the exit code of the next exit of the container, which may not be running yet.
+func waitNextExit(ctx context.Context, client *containerd.Client, container containerd.Container) (uint32, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	eventsCh, errCh := client.EventService().Subscribe(ctx, taskExitFilter(container.ID()))
+	for {
+		e, err := nextEvent(ctx, eventsCh, errCh)
+		if err != nil {
+			return 0, err
+		}
+		if exit, ok := e.(*eventstypes.TaskExit); ok && exit.ID == exit.ContainerID {
+			return exit.ExitStatus, nil
+		}
 	}
+}
+
+// waitRemoved returns the last exit code of the container once it is removed.
+func waitRemoved(ctx context.Context, client *containerd.Client, container containerd.Container) (uint32, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	id := container.ID()
+	eventsCh, errCh := client.EventService().Subscribe(ctx,
+		taskExitFilter(id),
+		fmt.Sprintf(`topic=="/tasks/delete",event.container_id==%q`, id),
+		fmt.Sprintf(`topic=="/containers/delete",event.id==%q`, id),
+	)
 
-	status := <-statusC
-	code, _, err := status.Result()
+	// The container may have exited, or even been removed, before subscribing
+	var code uint32
+	if task, err := container.Task(ctx, nil); err == nil {
+		if status, err := task.Status(ctx); err == nil && status.Status == containerd.Stopped {
+			code = status.ExitStatus
+		}
+	}
+	if _, err := client.LoadContainer(ctx, id); errdefs.IsNotFound(err) {
+		return code, nil
+	}
+
+	for {
+		e, err := nextEvent(ctx, eventsCh, errCh)
+		if err != nil {
+			return 0, err
+		}
+		switch v := e.(type) {
+		case *eventstypes.TaskExit:
+			if v.ID == v.ContainerID {
+				code = v.ExitStatus
+			}
+		case *eventstypes.TaskDelete:
+			if v.ID == "" || v.ID == v.ContainerID {
+				code = v.ExitStatus
+			}
+		case *eventstypes.ContainerDelete:
+			return code, nil
+		}
+	}
+}
+
+// waitHealthy returns once the health check of the container reports it healthy.
+func waitHealthy(ctx context.Context, client *containerd.Client, container containerd.Container) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	id := container.ID()
+	eventsCh, errCh := client.EventService().Subscribe(ctx,
+		taskExitFilter(id),
+		fmt.Sprintf(`topic=="/containers/update",event.id==%q`, id),
+	)
+
+	lbls, err := container.Labels(ctx)
 	if err != nil {
 		return err
 	}
-	fmt.Fprintln(w, code)
-	return nil
+	if _, ok := lbls[labels.HealthCheck]; !ok {
+		return errors.New("no health check is configured")
+	}
+	if isHealthy(lbls[labels.HealthState]) {
+		return nil
+	}
+
+	for {
+		e, err := nextEvent(ctx, eventsCh, errCh)
+		if err != nil {
+			return err
+		}
+		switch v := e.(type) {
+		case *eventstypes.TaskExit:
+			if v.ID == v.ContainerID {
+				return fmt.Errorf("exited with code %d before becoming healthy", v.ExitStatus)
+			}
+		case *eventstypes.ContainerUpdate:
+			if isHealthy(v.Labels[labels.HealthState]) {
+				return nil
+			}
+		}
+	}
+}
+
+func isHealthy(state string) bool {
+	if state == "" {
+		return false
+	}
+	hs, err := healthcheck.HealthStateFromJSON(state)
+	return err == nil && hs.Status == healthcheck.Healthy
+}
+
+func taskExitFilter(id string) string {
+	return fmt.Sprintf(`topic=="/tasks/exit",event.container_id==%q`, id)
+}
+
+// nextEvent returns the decoded payload of the next event.
+func nextEvent(ctx context.Context, eventsCh <-chan *events.Envelope, errCh <-chan error) (any, error) {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case err := <-errCh:
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			return nil, err
+		case e := <-eventsCh:
+			if e.Event == nil {
+				continue
+			}
+			v, err := typeurl.UnmarshalAny(e.Event)
+			if err != nil {
+				// just skip
+				continue
+			}
+			return v, nil
+		}
+	}
 }
@@ -0,0 +1,445 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package generate implements `nerdctl generate`.
+package generate
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/core/runtime/restart"
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/container"
+	"github.com/containerd/nerdctl/v2/pkg/idutil/containerwalker"
+	"github.com/containerd/nerdctl/v2/pkg/internal/filesystem"
+	"github.com/containerd/nerdctl/v2/pkg/labels"
+	"github.com/containerd/nerdctl/v2/pkg/rootlessutil"
+)
+
+// DefaultStopTimeout is the stop timeout of the containers without a stop timeout label, in seconds.
+const DefaultStopTimeout = 10
+
+var restartPolicies = []string{"no", "on-success", "on-failure", "on-abnormal", "on-watchdog", "on-abort", "always"}
+
+// unit is a generated unit file.
+type unit struct {
+	// FileName is the name of the unit file, e.g. "nerdctl-foo.service"
+	FileName    string
+	Description string
+	Wants       []string
+	After       []string
+	Requires    []string
+	WantedBy    string
+	// WorkingDirectory is the working directory of the service, if any
+	WorkingDirectory string
+	// Exec* are the command lines of the service, already quoted
+	ExecStartPre   string
+	ExecStart      string
+	ExecStop       string
+	ExecStopPost   string
+	Restart        string
+	RestartSec     uint
+	TimeoutStopSec uint
+	// Container is set for Quadlet .container files
+	Container *quadletContainer
+}
+
+type quadletContainer struct {
+	ContainerName string
+	Image         string
+	// Rootfs is set instead of Image for the containers created with --rootfs
+	Rootfs string
+	// PodmanArgs are the create flags, already quoted
+	PodmanArgs string
+	// Exec is the command of the container, already quoted
+	Exec string
+}
+
+var unitTemplate = template.Must(template.New("unit").Parse(`# {{.FileName}}
+# autogenerated by nerdctl
+{{- if .Container}}
+# Install it in {{if .Rootless}}~/.config/containers/systemd{{else}}/etc/containers/systemd{{end}} to be converted to a service unit by Quadlet.
+{{- else}}
+# Install it in {{if .Rootless}}~/.config/systemd/user, and enable it with "systemctl --user enable --now {{.FileName}}"{{else}}/etc/systemd/system, and enable it with "systemctl enable --now {{.FileName}}"{{end}}.
+{{- end}}
+
+[Unit]
+Description={{.Description}}
+{{- range .Wants}}
+Wants={{.}}
+{{- end}}
+{{- range .After}}
+After={{.}}
+{{- end}}
+{{- range .Requires}}
+Requires={{.}}
+{{- end}}
+{{- with .Container}}
+
+[Container]
+ContainerName={{.ContainerName}}
+{{- if .Rootfs}}
+Rootfs={{.Rootfs}}
+{{- else}}
+Image={{.Image}}
+{{- end}}
+{{- if .PodmanArgs}}
+PodmanArgs={{.PodmanArgs}}
+{{- end}}
+{{- if .Exec}}
+Exec={{.Exec}}
+{{- end}}
+{{- end}}
+
+[Service]
+{{- if not .Container}}
+Type=simple
+{{- if .WorkingDirectory}}
+WorkingDirectory={{.WorkingDirectory}}
+{{- end}}
+{{- if .ExecStartPre}}
+ExecStartPre={{.ExecStartPre}}
+{{- end}}
+ExecStart={{.ExecStart}}
+ExecStop={{.ExecStop}}
+{{- if .ExecStopPost}}
+ExecStopPost={{.ExecStopPost}}
+{{- end}}
+{{- end}}
+Restart={{.Restart}}
+{{- if .RestartSec}}
+RestartSec={{.RestartSec}}
+{{- end}}
+TimeoutStopSec={{.TimeoutStopSec}}
+
+[Install]
+WantedBy={{.WantedBy}}
+`))
+
+// Systemd generates systemd units running containers or compose projects with nerdctl.
+func Systemd(ctx context.Context, client *containerd.Client, reqs []string, options types.GenerateSystemdOptions) error {
+	if options.RestartPolicy == "" {
+		options.RestartPolicy = "on-failure"
+	}
+	if !slices.Contains(restartPolicies, options.RestartPolicy) {
+		return fmt.Errorf("invalid restart policy %q, must be one of %s", options.RestartPolicy, strings.Join(restartPolicies, ", "))
+	}
+	var units []*unit
+	for _, req := range reqs {
+		u, err := generateUnit(ctx, client, req, options)
+		if err != nil {
+			return err
+		}
+		units = append(units, u)
+	}
+	for i, u := range units {
+		var buf bytes.Buffer
+		if err := unitTemplate.Execute(&buf, struct {
+			*unit
+			Rootless bool
+		}{u, rootlessutil.IsRootless()}); err != nil {
+			return err
+		}
+		if options.Files {
+			if err := filesystem.WriteFile(u.FileName, buf.Bytes(), 0o644); err != nil {
+				return err
+			}
+			p, err := filepath.Abs(u.FileName)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(options.Stdout, p)
+			continue
+		}
+		if i > 0 {
+			fmt.Fprintln(options.Stdout)
+		}
+		if _, err := options.Stdout.Write(buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// generateUnit generates the unit of a container, or of a compose project when req is not a container.
+func generateUnit(ctx context.Context, client *containerd.Client, req string, options types.GenerateSystemdOptions) (*unit, error) {
+	var found containerd.Container
+	walker := &containerwalker.ContainerWalker{
+		Client: client,
+		OnFound: func(ctx context.Context, f containerwalker.Found) error {
+			if f.MatchCount > 1 {
+				return fmt.Errorf("multiple IDs found with provided prefix: %s", f.Req)
+			}
+			found = f.Container
+			return nil
+		},
+	}
+	n, err := walker.Walk(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if n > 0 {
+		return containerUnit(ctx, found, options)
+	}
+	containers, err := client.Containers(ctx, fmt.Sprintf("labels.%q==%s", labels.ComposeProject, req))
+	if err != nil {
+		return nil, err
+	}
+	if len(containers) == 0 {
+		return nil, fmt.Errorf("no such container or compose project: %s", req)
+	}
+	return projectUnit(ctx, req, containers[0], options)
+}
+
+func newUnit(fileName, description string, options types.GenerateSystemdOptions) *unit {
+	u := &unit{
+		FileName:    fileName,
+		Description: description,
+		Restart:     options.RestartPolicy,
+		RestartSec:  options.RestartSec,
+		Requires:    options.Requires,
+	}
+	// containerd runs as a user unit in rootless mode, which cannot depend on the system units such as network-online.target
+	if rootlessutil.IsRootless() {
+		u.Wants = append([]string{"containerd.service"}, options.Wants...)
+		u.After = append([]string{"containerd.service"}, options.After...)
+		u.WantedBy = "default.target"
+	} else {
+		u.Wants = append([]string{"network-online.target", "containerd.service"}, options.Wants...)
+		u.After = append([]string{"network-online.target", "containerd.service"}, options.After...)
+		u.WantedBy = "multi-user.target"
+	}
+	return u
+}
+
+func containerUnit(ctx context.Context, c containerd.Container, options types.GenerateSystemdOptions) (*unit, error) {
+	l, err := c.Labels(ctx)
+	if err != nil {
+		return nil, err
+	}
+	name := l[labels.Name]
+	if name == "" {
+		name = c.ID()
+	}
+	stopTimeout := uint(DefaultStopTimeout)
+	if options.StopTimeout != nil {
+		stopTimeout = *options.StopTimeout
+	} else if t, err := strconv.Atoi(l[labels.StopTimeout]); err == nil && t >= 0 {
+		stopTimeout = uint(t)
+	}
+
+	u := newUnit("nerdctl-"+name+".service", "nerdctl container "+name, options)
+	u.TimeoutStopSec = stopTimeout + DefaultStopTimeout
+
+	var createArgs []string
+	if options.New || options.Quadlet {
+		config, err := container.LoadCreateConfig(ctx, c)
+		if err != nil {
+			return nil, err
+		}
+		if createArgs, err = recreateArgs(config.Options.CreateArgs); err != nil {
+			return nil, fmt.Errorf("container %s cannot be recreated: %w", name, err)
+		}
+	} else if policy := l[restart.PolicyLabel]; policy != "" && policy != "no" {
+		log.G(ctx).Warnf("container %s has the restart policy %q, which conflicts with the restart policy of the unit. "+
+			"Use \"nerdctl update --restart=no %s\" or --new", name, policy, name)
+	}
+
+	if options.Quadlet {
+		u.FileName = name + ".container"
+		if u.Container, err = quadletContainerOf(name, createArgs); err != nil {
+			return nil, fmt.Errorf("container %s cannot be converted to a Quadlet file: %w", name, err)
+		}
+		return u, nil
+	}
+
+	nerdctl := nerdctlCommand(options)
+	stopCmd := slices.Concat(nerdctl, []string{"stop", "--time=" + strconv.FormatUint(uint64(stopTimeout), 10), name})
+	rmCmd := slices.Concat(nerdctl, []string{"rm", "--force", name})
+	if options.New {
+		u.ExecStartPre = "-" + quoteCommand(rmCmd)
+		u.ExecStart = quoteCommand(slices.Concat(nerdctl, []string{"run", "--rm", "--name=" + name}, createArgs))
+		u.ExecStopPost = "-" + quoteCommand(rmCmd)
+	} else {
+		u.ExecStart = quoteCommand(slices.Concat(nerdctl, []string{"start", "--attach", name}))
+	}
+	u.ExecStop = quoteCommand(stopCmd)
+	return u, nil
+}
+
+// recreateArgsSkippedFlags are the create flags handled by the units: the unit restarts the container,
+// and the container runs in the foreground of the unit, without a terminal.
+var recreateArgsSkippedFlags = []string{"restart", "tty", "interactive"}
+
+// recreateArgs returns the recorded create args of a container, without the flags handled by the units.
+func recreateArgs(createArgs []string) ([]string, error) {
+	if len(createArgs) == 0 {
+		return nil, errors.New("its create args were not recorded, create it again with this version of nerdctl")
+	}
+	var res []string
+	for i, arg := range createArgs {
+		if !strings.HasPrefix(arg, "-") {
+			return append(res, createArgs[i:]...), nil
+		}
+		name, _, _ := strings.Cut(strings.TrimPrefix(arg, "--"), "=")
+		if !slices.Contains(recreateArgsSkippedFlags, name) {
+			res = append(res, arg)
+		}
+	}
+	return nil, errors.New("its create args have no image")
+}
+
+// podmanUnsupportedFlags are the create flags of nerdctl that podman does not have,
+// or that have another meaning for podman.
+var podmanUnsupportedFlags = []string{
+	"pod", "init-binary", "isolation", "kernel-memory", "userns-remap", "ipfs-address",
+	"cosign-key", "cosign-certificate-identity", "cosign-certificate-identity-regexp",
+	"cosign-certificate-oidc-issuer", "cosign-certificate-oidc-issuer-regexp",
+}
+
+// podmanLogDrivers are the log drivers of nerdctl that podman has too.
+var podmanLogDrivers = []string{"json-file", "journald", "none"}
+
+// quadletContainerOf returns the [Container] section of a container created with createArgs,
+// translating the flags to podman flags.
+func quadletContainerOf(name string, createArgs []string) (*quadletContainer, error) {
+	qc := &quadletContainer{ContainerName: name}
+	rootfs := false
+	var podmanArgs []string
+	i := 0
+	for ; i < len(createArgs) && strings.HasPrefix(createArgs[i], "-"); i++ {
+		arg := createArgs[i]
+		flag, value, _ := strings.Cut(strings.TrimPrefix(arg, "--"), "=")
+		switch {
+		case slices.Contains(podmanUnsupportedFlags, flag):
+			return nil, fmt.Errorf("podman does not support --%s", flag)
+		case flag == "verify":
+			if value != "none" {
+				return nil, fmt.Errorf("podman does not support --verify=%s", value)
+			}
+			continue
+		case flag == "rootfs":
+			rootfs = value == "true"
+			continue
+		case flag == "log-driver":
+			if !slices.Contains(podmanLogDrivers, value) {
+				return nil, fmt.Errorf("podman does not support the log driver %q", value)
+			}
+		case flag == "log-opt":
+			k, v, _ := strings.Cut(value, "=")
+			switch k {
+			case "log-path":
+				arg = "--log-opt=path=" + v
+			case "max-size", "tag":
+			default:
+				return nil, fmt.Errorf("podman does not support the log option %q", k)
+			}
+		case flag == "security-opt" && value == "privileged-without-host-devices":
+			return nil, fmt.Errorf("podman does not support --security-opt=%s", value)
+		}
+		podmanArgs = append(podmanArgs, arg)
+	}
+	if i == len(createArgs) {
+		return nil, errors.New("its create args have no image")
+	}
+	image := createArgs[i]
+	switch {
+	case rootfs:
+		qc.Rootfs = image
+	case strings.HasPrefix(image, "ipfs://"):
+		return nil, errors.New("podman does not support IPFS images")
+	case strings.HasPrefix(image, "oci-archive://"):
+		qc.Image = "oci-archive:" + strings.TrimPrefix(image, "oci-archive://")
+	default:
+		qc.Image = image
+	}
+	qc.PodmanArgs = quoteCommand(podmanArgs)
+	qc.Exec = quoteCommand(createArgs[i+1:])
+	return qc, nil
+}
+
+func projectUnit(ctx context.Context, project string, c containerd.Container, options types.GenerateSystemdOptions) (*unit, error) {
+	if options.Quadlet {
+		return nil, fmt.Errorf("compose project %s: Quadlet files can only be generated for containers", project)
+	}
+	l, err := c.Labels(ctx)
+	if err != nil {
+		return nil, err
+	}
+	workingDir, configFiles := l[labels.ComposeWorkingDir], l[labels.ComposeConfigFiles]
+	if workingDir == "" || configFiles == "" {
+		return nil, fmt.Errorf("the compose files of project %s were not recorded, run \"nerdctl compose up\" again with this version of nerdctl", project)
+	}
+	compose := slices.Concat(nerdctlCommand(options), []string{"compose", "--project-directory=" + workingDir, "--project-name=" + project})
+	for _, f := range strings.Split(configFiles, ",") {
+		compose = append(compose, "--file="+f)
+	}
+
+	u := newUnit("nerdctl-compose-"+project+".service", "nerdctl compose project "+project, options)
+	stopTimeout := uint(DefaultStopTimeout)
+	if options.StopTimeout != nil {
+		stopTimeout = *options.StopTimeout
+	}
+	u.TimeoutStopSec = stopTimeout + DefaultStopTimeout
+	u.WorkingDirectory = workingDir
+	up := slices.Concat(compose, []string{"up"})
+	if options.New {
+		up = append(up, "--force-recreate")
+		u.ExecStopPost = "-" + quoteCommand(slices.Concat(compose, []string{"down"}))
+	}
+	u.ExecStart = quoteCommand(up)
+	u.ExecStop = quoteCommand(slices.Concat(compose, []string{"stop", "--timeout=" + strconv.FormatUint(uint64(stopTimeout), 10)}))
+	return u, nil
+}
+
+// nerdctlCommand returns the nerdctl command line run by the units, with the namespace of the containers.
+func nerdctlCommand(options types.GenerateSystemdOptions) []string {
+	cmd := slices.Concat([]string{options.NerdctlCmd}, options.NerdctlArgs)
+	if !slices.ContainsFunc(options.NerdctlArgs, func(arg string) bool { return strings.HasPrefix(arg, "--namespace=") }) {
+		cmd = append(cmd, "--namespace="+options.GOptions.Namespace)
+	}
+	return cmd
+}
+
+// quoteCommand quotes a command line for systemd.
+func quoteCommand(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = quote(arg)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// quote quotes a word of a command line for systemd, escaping the specifiers ("%") and the variables ("$").
+// See systemd.service(5) and systemd.syntax(7).
+func quote(arg string) string {
+	arg = strings.NewReplacer("%", "%%", "$", "$$").Replace(arg)
+	if arg != "" && !strings.ContainsAny(arg, " \t\n\"'\\;") {
+		return arg
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`).Replace(arg) + `"`
+}
@@ -0,0 +1,148 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package generate
+
+import (
+	"bytes"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestQuote(t *testing.T) {
+	for arg, expected := range map[string]string{
+		"--name=foo":    "--name=foo",
+		"":              `""`,
+		"echo $HOME":    `"echo $$HOME"`,
+		"100%":          "100%%",
+		`say "hello"`:   `"say \"hello\""`,
+		"a;b":           `"a;b"`,
+		`C:\path`:       `"C:\\path"`,
+		"--env=A=b c":   `"--env=A=b c"`,
+		"--label=a=b,c": "--label=a=b,c",
+	} {
+		assert.Equal(t, quote(arg), expected, arg)
+	}
+}
+
+func TestRecreateArgs(t *testing.T) {
+	args, err := recreateArgs([]string{"--restart=always", "--tty=true", "--publish=8080:80", "alpine", "sh", "--restart=no"})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, args, []string{"--publish=8080:80", "alpine", "sh", "--restart=no"})
+
+	_, err = recreateArgs(nil)
+	assert.ErrorContains(t, err, "were not recorded")
+}
+
+func TestQuadletContainerOf(t *testing.T) {
+	qc, err := quadletContainerOf("foo", []string{"--publish=8080:80", "--log-opt=log-path=/var/log/foo.log", "--verify=none", "alpine", "sleep", "infinity"})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, *qc, quadletContainer{
+		ContainerName: "foo",
+		Image:         "alpine",
+		PodmanArgs:    "--publish=8080:80 --log-opt=path=/var/log/foo.log",
+		Exec:          "sleep infinity",
+	})
+
+	qc, err = quadletContainerOf("foo", []string{"--rootfs=true", "/var/lib/rootfs"})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, *qc, quadletContainer{ContainerName: "foo", Rootfs: "/var/lib/rootfs"})
+
+	qc, err = quadletContainerOf("foo", []string{"oci-archive:///tmp/alpine.tar"})
+	assert.NilError(t, err)
+	assert.Equal(t, qc.Image, "oci-archive:/tmp/alpine.tar")
+
+	for _, args := range [][]string{
+		{"--pod=mypod", "alpine"},
+		{"--log-driver=loki", "alpine"},
+		{"--log-opt=max-file=3", "alpine"},
+		{"--verify=cosign", "alpine"},
+		{"ipfs://bafkreicq4dg6nkef5ju422ptedcwfz6kcvpvvhuqeykfrwq5krazf3muze"},
+		{"--publish=8080:80"},
+	} {
+		_, err := quadletContainerOf("foo", args)
+		assert.Assert(t, err != nil, args)
+	}
+}
+
+func TestUnitTemplate(t *testing.T) {
+	u := &unit{
+		FileName:       "nerdctl-foo.service",
+		Description:    "nerdctl container foo",
+		Wants:          []string{"containerd.service"},
+		After:          []string{"containerd.service"},
+		WantedBy:       "multi-user.target",
+		ExecStart:      "/usr/local/bin/nerdctl start --attach foo",
+		ExecStop:       "/usr/local/bin/nerdctl stop --time=10 foo",
+		Restart:        "on-failure",
+		TimeoutStopSec: 20,
+	}
+	var buf bytes.Buffer
+	assert.NilError(t, unitTemplate.Execute(&buf, struct {
+		*unit
+		Rootless bool
+	}{u, false}))
+	assert.Equal(t, buf.String(), `# nerdctl-foo.service
+# autogenerated by nerdctl
+# Install it in /etc/systemd/system, and enable it with "systemctl enable --now nerdctl-foo.service".
+
+[Unit]
+Description=nerdctl container foo
+Wants=containerd.service
+After=containerd.service
+
+[Service]
+Type=simple
+ExecStart=/usr/local/bin/nerdctl start --attach foo
+ExecStop=/usr/local/bin/nerdctl stop --time=10 foo
+Restart=on-failure
+TimeoutStopSec=20
+
+[Install]
+WantedBy=multi-user.target
+`)
+
+	u.FileName = "foo.container"
+	u.Container = &quadletContainer{ContainerName: "foo", Image: "alpine", PodmanArgs: "--publish=8080:80", Exec: "sleep infinity"}
+	buf.Reset()
+	assert.NilError(t, unitTemplate.Execute(&buf, struct {
+		*unit
+		Rootless bool
+	}{u, true}))
+	assert.Equal(t, buf.String(), `# foo.container
+# autogenerated by nerdctl
+# Install it in ~/.config/containers/systemd to be converted to a service unit by Quadlet.
+
+[Unit]
+Description=nerdctl container foo
+Wants=containerd.service
+After=containerd.service
+
+[Container]
+ContainerName=foo
+Image=alpine
+PodmanArgs=--publish=8080:80
+Exec=sleep infinity
+
+[Service]
+Restart=on-failure
+TimeoutStopSec=20
+
+[Install]
+WantedBy=multi-user.target
+`)
+}
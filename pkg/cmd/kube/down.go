@@ -0,0 +1,96 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package kube
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/errdefs"
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/pod"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/volume"
+	"github.com/containerd/nerdctl/v2/pkg/kube"
+)
+
+// Down removes the pods of the manifest read from r, with their emptyDir and configMap volumes.
+// The volumes of the persistent volume claims are kept.
+func Down(ctx context.Context, client *containerd.Client, r io.Reader, options types.KubeDownOptions) error {
+	manifest, _, err := kube.Decode(r)
+	if err != nil {
+		return err
+	}
+	dataStore, err := clientutil.DataStore(options.GOptions.DataRoot, options.GOptions.Address)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, p := range manifest.Pods {
+		if err := downPod(ctx, client, p, dataStore, options); err != nil {
+			errs = append(errs, fmt.Errorf("failed to remove pod %s: %w", p.Metadata.Name, err))
+			continue
+		}
+		fmt.Fprintln(options.Stdout, p.Metadata.Name)
+	}
+	return errors.Join(errs...)
+}
+
+func downPod(ctx context.Context, client *containerd.Client, p kube.Pod, dataStore string, options types.KubeDownOptions) error {
+	name := p.Metadata.Name
+	if _, err := pod.FindInfra(ctx, client, name); err == nil {
+		if err := pod.Remove(ctx, client, []string{name}, types.PodRemoveOptions{Stdout: io.Discard, GOptions: options.GOptions, Force: true}); err != nil {
+			return err
+		}
+	} else if errdefs.IsNotFound(err) {
+		log.G(ctx).Debugf("pod %s does not exist", name)
+	} else {
+		return err
+	}
+
+	var emptyDirs []string
+	for _, v := range p.Spec.Volumes {
+		if v.EmptyDir != nil && v.EmptyDir.Medium != "Memory" {
+			emptyDirs = append(emptyDirs, emptyDirVolume(name, v.Name))
+		}
+	}
+	if len(emptyDirs) > 0 {
+		volStore, err := volume.Store(options.GOptions.Namespace, options.GOptions.DataRoot, options.GOptions.Address)
+		if err != nil {
+			return err
+		}
+		var existing []string
+		for _, v := range emptyDirs {
+			if ok, err := volStore.Exists(v); err == nil && ok {
+				existing = append(existing, v)
+			}
+		}
+		if len(existing) > 0 {
+			if err := volume.Remove(ctx, client, existing, types.VolumeRemoveOptions{Stdout: io.Discard, GOptions: options.GOptions}); err != nil {
+				return err
+			}
+		}
+	}
+	// the parent directory of the configMap volumes of the pod
+	return os.RemoveAll(configMapDir(dataStore, options.GOptions.Namespace, name, ""))
+}
@@ -0,0 +1,351 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package kube
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/core/runtime/restart"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/pod"
+	"github.com/containerd/nerdctl/v2/pkg/idutil/containerwalker"
+	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/dockercompat"
+	"github.com/containerd/nerdctl/v2/pkg/kube"
+	"github.com/containerd/nerdctl/v2/pkg/labels"
+	"github.com/containerd/nerdctl/v2/pkg/portutil"
+)
+
+const (
+	TypePod        = "pod"
+	TypeDeployment = "deployment"
+)
+
+// generator accumulates the containers and the volumes of the generated pod.
+type generator struct {
+	client    *containerd.Client
+	dataStore string
+	options   types.KubeGenerateOptions
+
+	// podName is the name of the nerdctl pod of the containers, if any
+	podName    string
+	containers []containerd.Container
+	// portSources are the containers owning the published ports: the infra containers, and the standalone containers
+	portSources map[string]containerd.Container
+	hostname    string
+	spec        kube.PodSpec
+}
+
+// Generate prints a Kubernetes manifest running the containers.
+// The containers of a nerdctl pod, or a pod itself, are generated as a single pod with its published ports.
+func Generate(ctx context.Context, client *containerd.Client, reqs []string, options types.KubeGenerateOptions) error {
+	switch options.Type {
+	case "", TypePod, TypeDeployment:
+	default:
+		return fmt.Errorf("invalid type %q, must be %q or %q", options.Type, TypePod, TypeDeployment)
+	}
+	dataStore, err := clientutil.DataStore(options.GOptions.DataRoot, options.GOptions.Address)
+	if err != nil {
+		return err
+	}
+	g := &generator{
+		client:      client,
+		dataStore:   dataStore,
+		options:     options,
+		portSources: map[string]containerd.Container{},
+	}
+	walker := &containerwalker.ContainerWalker{
+		Client: client,
+		OnFound: func(ctx context.Context, found containerwalker.Found) error {
+			if found.MatchCount > 1 {
+				return fmt.Errorf("multiple IDs found with provided prefix: %s", found.Req)
+			}
+			return g.add(ctx, found.Container)
+		},
+	}
+	if err := walker.WalkAll(ctx, reqs, true); err != nil {
+		return err
+	}
+	if len(g.containers) == 0 {
+		return errors.New("no containers to generate")
+	}
+
+	name := options.Name
+	if name == "" {
+		name = g.podName
+	}
+	if name == "" {
+		l, err := g.containers[0].Labels(ctx)
+		if err != nil {
+			return err
+		}
+		name = containerName(g.containers[0].ID(), l) + "-pod"
+	}
+	name = kube.SanitizeName(name)
+	if g.hostname != "" && g.hostname != name {
+		g.spec.Hostname = g.hostname
+	}
+
+	annotations := map[string]string{}
+	for _, c := range g.containers {
+		kc, err := g.container(ctx, c, annotations)
+		if err != nil {
+			return err
+		}
+		g.spec.Containers = append(g.spec.Containers, kc)
+	}
+	if err := g.ports(ctx); err != nil {
+		return err
+	}
+	if len(annotations) == 0 {
+		annotations = nil
+	}
+
+	meta := kube.ObjectMeta{Name: name, Labels: map[string]string{"app": name}, Annotations: annotations}
+	if options.Type == TypeDeployment {
+		replicas := options.Replicas
+		if replicas == 0 {
+			replicas = 1
+		}
+		return kube.Encode(options.Stdout, kube.Deployment{
+			TypeMeta: kube.TypeMeta{APIVersion: "apps/v1", Kind: kube.KindDeployment},
+			Metadata: kube.ObjectMeta{Name: name, Labels: meta.Labels},
+			Spec: kube.DeploymentSpec{
+				Replicas: &replicas,
+				Selector: &kube.LabelSelector{MatchLabels: meta.Labels},
+				Template: kube.PodTemplateSpec{Metadata: meta, Spec: g.spec},
+			},
+		})
+	}
+	return kube.Encode(options.Stdout, kube.Pod{
+		TypeMeta: kube.TypeMeta{APIVersion: "v1", Kind: kube.KindPod},
+		Metadata: meta,
+		Spec:     g.spec,
+	})
+}
+
+// add adds a container, or the containers of a pod when c is an infra container.
+func (g *generator) add(ctx context.Context, c containerd.Container) error {
+	l, err := c.Labels(ctx)
+	if err != nil {
+		return err
+	}
+	podName := l[labels.Pod]
+	if podName != "" {
+		if g.podName != "" && g.podName != podName {
+			return fmt.Errorf("containers of different pods (%s, %s) cannot be generated together", g.podName, podName)
+		}
+		g.podName = podName
+		infra := c
+		if l[labels.PodInfra] != "true" {
+			if infra, err = pod.FindInfra(ctx, g.client, podName); err != nil {
+				return err
+			}
+		}
+		g.portSources[infra.ID()] = infra
+		if spec, err := infra.Spec(ctx); err == nil {
+			g.hostname = spec.Hostname
+		}
+		if l[labels.PodInfra] == "true" {
+			members, err := pod.Members(ctx, g.client, c)
+			if err != nil {
+				return err
+			}
+			for _, m := range members {
+				g.addContainer(m)
+			}
+			return nil
+		}
+	} else {
+		g.portSources[c.ID()] = c
+	}
+	g.addContainer(c)
+	return nil
+}
+
+func (g *generator) addContainer(c containerd.Container) {
+	if !slices.ContainsFunc(g.containers, func(e containerd.Container) bool { return e.ID() == c.ID() }) {
+		g.containers = append(g.containers, c)
+	}
+}
+
+// containerName returns the name of a container, without the prefix of its pod.
+func containerName(id string, l map[string]string) string {
+	name := l[labels.Name]
+	if name == "" {
+		name = id[:12]
+	}
+	if podName := l[labels.Pod]; podName != "" {
+		name = strings.TrimPrefix(name, podName+"-")
+	}
+	return name
+}
+
+func (g *generator) container(ctx context.Context, c containerd.Container, annotations map[string]string) (kube.Container, error) {
+	info, err := c.Info(ctx, containerd.WithoutRefreshedMetadata)
+	if err != nil {
+		return kube.Container{}, err
+	}
+	spec, err := c.Spec(ctx)
+	if err != nil {
+		return kube.Container{}, err
+	}
+	name := kube.SanitizeName(containerName(c.ID(), info.Labels))
+	kc := kube.Container{
+		Name:  name,
+		Image: info.Image,
+	}
+	for k, v := range info.Labels {
+		if !strings.HasPrefix(k, labels.Prefix) && !strings.HasPrefix(k, "containerd.io/") {
+			annotations[k] = v
+		}
+	}
+	if g.spec.RestartPolicy == "" {
+		g.spec.RestartPolicy = restartPolicy(info.Labels[restart.PolicyLabel])
+	}
+
+	if p := spec.Process; p != nil {
+		kc.Command = p.Args
+		if p.Cwd != "/" {
+			kc.WorkingDir = p.Cwd
+		}
+		for _, e := range p.Env {
+			if !strings.HasPrefix(e, "HOSTNAME=") {
+				k, v, _ := strings.Cut(e, "=")
+				kc.Env = append(kc.Env, kube.EnvVar{Name: k, Value: v})
+			}
+		}
+		if p.User.UID != 0 || p.User.GID != 0 {
+			uid, gid := int64(p.User.UID), int64(p.User.GID)
+			kc.SecurityContext = &kube.SecurityContext{RunAsUser: &uid, RunAsGroup: &gid}
+		}
+	}
+	kc.Resources = resources(spec)
+
+	if err := g.mounts(&kc, info.Labels); err != nil {
+		return kube.Container{}, err
+	}
+	return kc, nil
+}
+
+func restartPolicy(policy string) string {
+	switch {
+	case policy == "always", policy == "unless-stopped":
+		return "Always"
+	case strings.HasPrefix(policy, "on-failure"):
+		return "OnFailure"
+	default:
+		return "Never"
+	}
+}
+
+func resources(spec *specs.Spec) *kube.ResourceRequirements {
+	if spec.Linux == nil || spec.Linux.Resources == nil {
+		return nil
+	}
+	limits := map[string]string{}
+	r := spec.Linux.Resources
+	if cpu := r.CPU; cpu != nil && cpu.Quota != nil && *cpu.Quota > 0 && cpu.Period != nil && *cpu.Period > 0 {
+		limits["cpu"] = kube.FormatCPU(float64(*cpu.Quota) / float64(*cpu.Period))
+	}
+	if mem := r.Memory; mem != nil && mem.Limit != nil && *mem.Limit > 0 {
+		limits["memory"] = kube.FormatMemory(*mem.Limit)
+	}
+	if len(limits) == 0 {
+		return nil
+	}
+	return &kube.ResourceRequirements{Limits: limits}
+}
+
+// mounts adds the volume mounts of a container, and the corresponding volumes of the pod.
+func (g *generator) mounts(kc *kube.Container, l map[string]string) error {
+	mountsJSON := l[labels.Mounts]
+	if mountsJSON == "" {
+		return nil
+	}
+	var mounts []dockercompat.MountPoint
+	if err := json.Unmarshal([]byte(mountsJSON), &mounts); err != nil {
+		return fmt.Errorf("failed to parse the mounts of container %s: %w", kc.Name, err)
+	}
+	var anonymous []string
+	if anonJSON := l[labels.AnonymousVolumes]; anonJSON != "" {
+		if err := json.Unmarshal([]byte(anonJSON), &anonymous); err != nil {
+			return fmt.Errorf("failed to parse the anonymous volumes of container %s: %w", kc.Name, err)
+		}
+	}
+	for _, m := range mounts {
+		var v kube.Volume
+		switch {
+		case m.Type == "tmpfs":
+			v = kube.Volume{Name: kube.SanitizeName(kc.Name + "-" + m.Destination), EmptyDir: &kube.EmptyDirVolumeSource{Medium: "Memory"}}
+		case m.Type == "volume" && slices.Contains(anonymous, m.Name):
+			v = kube.Volume{Name: kube.SanitizeName(kc.Name + "-" + m.Destination), EmptyDir: &kube.EmptyDirVolumeSource{}}
+		case m.Type == "volume":
+			v = kube.Volume{Name: kube.SanitizeName(m.Name), PersistentVolumeClaim: &kube.PersistentVolumeClaimVolumeSource{ClaimName: m.Name}}
+		case m.Type == "bind":
+			name := kube.SanitizeName("host-" + m.Source)
+			v = kube.Volume{Name: name, HostPath: &kube.HostPathVolumeSource{Path: m.Source}}
+		default:
+			continue
+		}
+		if !slices.ContainsFunc(g.spec.Volumes, func(e kube.Volume) bool { return e.Name == v.Name }) {
+			g.spec.Volumes = append(g.spec.Volumes, v)
+		}
+		kc.VolumeMounts = append(kc.VolumeMounts, kube.VolumeMount{Name: v.Name, MountPath: m.Destination, ReadOnly: !m.RW && m.Type != "tmpfs"})
+	}
+	return nil
+}
+
+// ports adds the published ports of the pod to its first container, or of each container to itself.
+func (g *generator) ports(ctx context.Context) error {
+	for _, c := range slices.SortedFunc(maps.Values(g.portSources), func(a, b containerd.Container) int { return strings.Compare(a.ID(), b.ID()) }) {
+		l, err := c.Labels(ctx)
+		if err != nil {
+			return err
+		}
+		mappings, err := portutil.LoadPortMappings(g.dataStore, g.options.GOptions.Namespace, c.ID(), l)
+		if err != nil {
+			return err
+		}
+		i := 0
+		if l[labels.PodInfra] != "true" {
+			i = slices.IndexFunc(g.containers, func(e containerd.Container) bool { return e.ID() == c.ID() })
+		}
+		for _, m := range mappings {
+			port := kube.ContainerPort{
+				ContainerPort: int32(m.ContainerPort),
+				HostPort:      int32(m.HostPort),
+				Protocol:      strings.ToUpper(m.Protocol),
+			}
+			if m.HostIP != "" && m.HostIP != "0.0.0.0" && m.HostIP != "::" {
+				port.HostIP = m.HostIP
+			}
+			g.spec.Containers[i].Ports = append(g.spec.Containers[i].Ports, port)
+		}
+	}
+	return nil
+}
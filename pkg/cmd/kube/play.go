@@ -0,0 +1,455 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package kube implements `nerdctl kube`, which converts containers to Kubernetes manifests and back.
+//
+// A Kubernetes pod is played as a nerdctl pod (see package pod), with one container per container of the pod,
+// named "<pod>-<container>". The emptyDir volumes of a pod are named volumes "<pod>-<volume>", the
+// persistentVolumeClaim volumes are named volumes named after the claims, and the configMap volumes are
+// directories of the data store, bind-mounted read-only.
+package kube
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/errdefs"
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/pod"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/volume"
+	"github.com/containerd/nerdctl/v2/pkg/internal/filesystem"
+	"github.com/containerd/nerdctl/v2/pkg/kube"
+)
+
+// Play creates and starts the pods of the manifest read from r.
+func Play(ctx context.Context, client *containerd.Client, r io.Reader, options types.KubePlayOptions) error {
+	manifest, warnings, err := kube.Decode(r)
+	if err != nil {
+		return err
+	}
+	for _, w := range warnings {
+		log.G(ctx).Warn(w)
+	}
+	if len(manifest.Pods) == 0 {
+		return errors.New("no pod or deployment found")
+	}
+	dataStore, err := clientutil.DataStore(options.GOptions.DataRoot, options.GOptions.Address)
+	if err != nil {
+		return err
+	}
+	for _, p := range manifest.Pods {
+		if err := playPod(ctx, client, p, manifest.ConfigMaps, dataStore, options); err != nil {
+			return fmt.Errorf("failed to play pod %s: %w", p.Metadata.Name, err)
+		}
+		fmt.Fprintln(options.Stdout, p.Metadata.Name)
+	}
+	return nil
+}
+
+func playPod(ctx context.Context, client *containerd.Client, p kube.Pod, configMaps map[string]kube.ConfigMap, dataStore string, options types.KubePlayOptions) (retErr error) {
+	name := p.Metadata.Name
+	if _, err := pod.FindInfra(ctx, client, name); err == nil {
+		if !options.Replace {
+			return fmt.Errorf("pod %s already exists, remove it or use --replace", name)
+		}
+		if err := pod.Remove(ctx, client, []string{name}, types.PodRemoveOptions{Stdout: io.Discard, GOptions: options.GOptions, Force: true}); err != nil {
+			return err
+		}
+	} else if !errdefs.IsNotFound(err) {
+		return err
+	}
+	if len(p.Spec.InitContainers) > 0 {
+		log.G(ctx).Warnf("pod %s: init containers are not supported, ignoring them", name)
+	}
+
+	createOptions, err := podCreateOptions(p, options)
+	if err != nil {
+		return err
+	}
+	if err := pod.Create(ctx, client, createOptions); err != nil {
+		return err
+	}
+	defer func() {
+		if retErr != nil {
+			if err := pod.Remove(ctx, client, []string{name}, types.PodRemoveOptions{Stdout: io.Discard, GOptions: options.GOptions, Force: true}); err != nil {
+				log.G(ctx).WithError(err).Warnf("failed to remove pod %s", name)
+			}
+		}
+	}()
+
+	volumes, err := playVolumes(p, configMaps, dataStore, options)
+	if err != nil {
+		return err
+	}
+	for _, c := range p.Spec.Containers {
+		args, err := containerArgs(p, c, volumes, configMaps, options.Start)
+		if err != nil {
+			return fmt.Errorf("container %s: %w", c.Name, err)
+		}
+		cmd := exec.CommandContext(ctx, options.NerdctlCmd, append(options.NerdctlArgs, args...)...)
+		cmd.Stderr = options.Stderr
+		log.G(ctx).Debugf("creating container %s of pod %s: %v", c.Name, name, cmd.Args)
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("failed to create container %s: %w", c.Name, err)
+		}
+	}
+	return nil
+}
+
+func podCreateOptions(p kube.Pod, options types.KubePlayOptions) (types.PodCreateOptions, error) {
+	createOptions := types.PodCreateOptions{
+		Stdout:      io.Discard,
+		Stderr:      options.Stderr,
+		GOptions:    options.GOptions,
+		NerdctlCmd:  options.NerdctlCmd,
+		NerdctlArgs: options.NerdctlArgs,
+		Name:        p.Metadata.Name,
+		Hostname:    p.Spec.Hostname,
+		Networks:    options.Networks,
+	}
+	if p.Spec.HostNetwork {
+		createOptions.Networks = []string{"host"}
+	}
+	if p.Spec.ShareProcessNamespace != nil && *p.Spec.ShareProcessNamespace {
+		createOptions.Share = []string{pod.ShareNet, pod.ShareIPC, pod.SharePID}
+	}
+	for _, k := range slices.Sorted(maps.Keys(p.Metadata.Labels)) {
+		createOptions.Labels = append(createOptions.Labels, k+"="+p.Metadata.Labels[k])
+	}
+	for _, c := range p.Spec.Containers {
+		for _, port := range c.Ports {
+			if port.HostPort == 0 {
+				continue
+			}
+			publish := fmt.Sprintf("%d:%d", port.HostPort, port.ContainerPort)
+			if port.HostIP != "" {
+				publish = port.HostIP + ":" + publish
+			}
+			if port.Protocol != "" {
+				publish += "/" + strings.ToLower(port.Protocol)
+			}
+			createOptions.Publish = append(createOptions.Publish, publish)
+		}
+	}
+	if dns := p.Spec.DNSConfig; dns != nil {
+		createOptions.DNS = dns.Nameservers
+		createOptions.DNSSearch = dns.Searches
+		for _, o := range dns.Options {
+			if o.Value != nil {
+				createOptions.DNSOptions = append(createOptions.DNSOptions, o.Name+":"+*o.Value)
+			} else {
+				createOptions.DNSOptions = append(createOptions.DNSOptions, o.Name)
+			}
+		}
+	}
+	for _, alias := range p.Spec.HostAliases {
+		for _, host := range alias.Hostnames {
+			createOptions.AddHost = append(createOptions.AddHost, host+":"+alias.IP)
+		}
+	}
+	return createOptions, nil
+}
+
+// podVolume is a volume of a pod, as mounted by nerdctl.
+type podVolume struct {
+	// source is the name of the volume, or the host path of a bind mount
+	source   string
+	bind     bool
+	tmpfs    bool
+	readOnly bool
+}
+
+// configMapDir returns the directory of the files of a configMap volume.
+func configMapDir(dataStore, namespace, podName, volumeName string) string {
+	return filepath.Join(dataStore, "kube", namespace, podName, volumeName)
+}
+
+// emptyDirVolume returns the name of the named volume backing an emptyDir volume.
+func emptyDirVolume(podName, volumeName string) string {
+	return podName + "-" + volumeName
+}
+
+func playVolumes(p kube.Pod, configMaps map[string]kube.ConfigMap, dataStore string, options types.KubePlayOptions) (map[string]podVolume, error) {
+	volumes := map[string]podVolume{}
+	createVolume := func(name string) error {
+		_, err := volume.Create(name, types.VolumeCreateOptions{Stdout: io.Discard, GOptions: options.GOptions})
+		return err
+	}
+	for _, v := range p.Spec.Volumes {
+		switch {
+		case v.EmptyDir != nil:
+			if v.EmptyDir.Medium == "Memory" {
+				volumes[v.Name] = podVolume{tmpfs: true}
+				continue
+			}
+			name := emptyDirVolume(p.Metadata.Name, v.Name)
+			if err := createVolume(name); err != nil {
+				return nil, err
+			}
+			volumes[v.Name] = podVolume{source: name}
+		case v.HostPath != nil:
+			switch v.HostPath.Type {
+			case "DirectoryOrCreate":
+				if err := os.MkdirAll(v.HostPath.Path, 0o755); err != nil {
+					return nil, err
+				}
+			case "FileOrCreate":
+				if _, err := os.Stat(v.HostPath.Path); errors.Is(err, os.ErrNotExist) {
+					if err := filesystem.WriteFile(v.HostPath.Path, nil, 0o644); err != nil {
+						return nil, err
+					}
+				}
+			}
+			volumes[v.Name] = podVolume{source: v.HostPath.Path, bind: true}
+		case v.PersistentVolumeClaim != nil:
+			if err := createVolume(v.PersistentVolumeClaim.ClaimName); err != nil {
+				return nil, err
+			}
+			volumes[v.Name] = podVolume{source: v.PersistentVolumeClaim.ClaimName, readOnly: v.PersistentVolumeClaim.ReadOnly}
+		case v.ConfigMap != nil:
+			cm, ok := configMaps[v.ConfigMap.Name]
+			if !ok {
+				if v.ConfigMap.Optional != nil && *v.ConfigMap.Optional {
+					continue
+				}
+				return nil, fmt.Errorf("configmap %s not found", v.ConfigMap.Name)
+			}
+			dir := configMapDir(dataStore, options.GOptions.Namespace, p.Metadata.Name, v.Name)
+			if err := writeConfigMap(dir, cm, v.ConfigMap.Items); err != nil {
+				return nil, err
+			}
+			volumes[v.Name] = podVolume{source: dir, bind: true, readOnly: true}
+		default:
+			return nil, fmt.Errorf("volume %s: unsupported volume type", v.Name)
+		}
+	}
+	return volumes, nil
+}
+
+func writeConfigMap(dir string, cm kube.ConfigMap, items []kube.KeyToPath) error {
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	if len(items) == 0 {
+		for k := range cm.Data {
+			items = append(items, kube.KeyToPath{Key: k, Path: k})
+		}
+	}
+	for _, item := range items {
+		data, ok := cm.Data[item.Key]
+		if !ok {
+			return fmt.Errorf("configmap %s has no key %q", cm.Metadata.Name, item.Key)
+		}
+		p := filepath.Join(dir, filepath.Clean("/"+item.Path))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			return err
+		}
+		if err := filesystem.WriteFile(p, []byte(data), 0o644); err != nil {
+			return err
+		}
+	}
+	return os.MkdirAll(dir, 0o755)
+}
+
+func containerArgs(p kube.Pod, c kube.Container, volumes map[string]podVolume, configMaps map[string]kube.ConfigMap, start bool) ([]string, error) {
+	args := []string{"create"}
+	if start {
+		args = []string{"run", "-d"}
+	}
+	args = append(args, "--pod", p.Metadata.Name, "--name", p.Metadata.Name+"-"+c.Name)
+
+	switch p.Spec.RestartPolicy {
+	case "", "Always":
+		args = append(args, "--restart", "always")
+	case "OnFailure":
+		args = append(args, "--restart", "on-failure")
+	case "Never":
+	default:
+		return nil, fmt.Errorf("invalid restart policy %q", p.Spec.RestartPolicy)
+	}
+	switch c.ImagePullPolicy {
+	case "", "IfNotPresent":
+	case "Always":
+		args = append(args, "--pull", "always")
+	case "Never":
+		args = append(args, "--pull", "never")
+	default:
+		return nil, fmt.Errorf("invalid image pull policy %q", c.ImagePullPolicy)
+	}
+	if c.WorkingDir != "" {
+		args = append(args, "--workdir", c.WorkingDir)
+	}
+	if c.TTY {
+		args = append(args, "--tty")
+	}
+	if c.Stdin {
+		args = append(args, "--interactive")
+	}
+
+	env, err := containerEnv(c, configMaps)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range env {
+		args = append(args, "--env", e)
+	}
+
+	if r := c.Resources; r != nil {
+		limits := r.Limits
+		if limits == nil {
+			limits = r.Requests
+		}
+		if cpu, ok := limits["cpu"]; ok {
+			cpus, err := kube.ParseCPU(cpu)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, "--cpus", strconv.FormatFloat(cpus, 'f', -1, 64))
+		}
+		if memory, ok := limits["memory"]; ok {
+			bytes, err := kube.ParseMemory(memory)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, "--memory", strconv.FormatInt(bytes, 10))
+		}
+	}
+
+	if sc := c.SecurityContext; sc != nil {
+		if sc.Privileged != nil && *sc.Privileged {
+			args = append(args, "--privileged")
+		}
+		if sc.RunAsUser != nil {
+			user := strconv.FormatInt(*sc.RunAsUser, 10)
+			if sc.RunAsGroup != nil {
+				user += ":" + strconv.FormatInt(*sc.RunAsGroup, 10)
+			}
+			args = append(args, "--user", user)
+		}
+		if sc.ReadOnlyRootFilesystem != nil && *sc.ReadOnlyRootFilesystem {
+			args = append(args, "--read-only")
+		}
+		if caps := sc.Capabilities; caps != nil {
+			for _, capability := range caps.Add {
+				args = append(args, "--cap-add", capability)
+			}
+			for _, capability := range caps.Drop {
+				args = append(args, "--cap-drop", capability)
+			}
+		}
+	}
+
+	for _, m := range c.VolumeMounts {
+		v, ok := volumes[m.Name]
+		if !ok {
+			// optional configMap volumes are skipped when the configMap does not exist
+			if isOptionalConfigMap(p, m.Name) {
+				continue
+			}
+			return nil, fmt.Errorf("volume %s not found", m.Name)
+		}
+		if v.tmpfs {
+			args = append(args, "--tmpfs", m.MountPath)
+			continue
+		}
+		source := v.source
+		if m.SubPath != "" {
+			if !v.bind {
+				return nil, fmt.Errorf("volume %s: subPath is only supported for hostPath and configMap volumes", m.Name)
+			}
+			source = filepath.Join(source, filepath.Clean("/"+m.SubPath))
+		}
+		spec := source + ":" + m.MountPath
+		if m.ReadOnly || v.readOnly {
+			spec += ":ro"
+		}
+		args = append(args, "--volume", spec)
+	}
+
+	if len(c.Command) > 0 {
+		args = append(args, "--entrypoint", c.Command[0])
+	}
+	args = append(args, c.Image)
+	if len(c.Command) > 1 {
+		args = append(args, c.Command[1:]...)
+	}
+	return append(args, c.Args...), nil
+}
+
+func isOptionalConfigMap(p kube.Pod, name string) bool {
+	for _, v := range p.Spec.Volumes {
+		if v.Name == name && v.ConfigMap != nil {
+			return v.ConfigMap.Optional != nil && *v.ConfigMap.Optional
+		}
+	}
+	return false
+}
+
+func containerEnv(c kube.Container, configMaps map[string]kube.ConfigMap) ([]string, error) {
+	var env []string
+	for _, from := range c.EnvFrom {
+		if from.ConfigMapRef == nil {
+			continue
+		}
+		cm, ok := configMaps[from.ConfigMapRef.Name]
+		if !ok {
+			if from.ConfigMapRef.Optional != nil && *from.ConfigMapRef.Optional {
+				continue
+			}
+			return nil, fmt.Errorf("configmap %s not found", from.ConfigMapRef.Name)
+		}
+		for _, k := range slices.Sorted(maps.Keys(cm.Data)) {
+			env = append(env, from.Prefix+k+"="+cm.Data[k])
+		}
+	}
+	for _, e := range c.Env {
+		if e.ValueFrom == nil || e.ValueFrom.ConfigMapKeyRef == nil {
+			env = append(env, e.Name+"="+e.Value)
+			continue
+		}
+		ref := e.ValueFrom.ConfigMapKeyRef
+		optional := ref.Optional != nil && *ref.Optional
+		cm, ok := configMaps[ref.Name]
+		if !ok {
+			if optional {
+				continue
+			}
+			return nil, fmt.Errorf("configmap %s not found", ref.Name)
+		}
+		v, ok := cm.Data[ref.Key]
+		if !ok {
+			if optional {
+				continue
+			}
+			return nil, fmt.Errorf("configmap %s has no key %q", ref.Name, ref.Key)
+		}
+		env = append(env, e.Name+"="+v)
+	}
+	return env, nil
+}
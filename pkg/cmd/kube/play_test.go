@@ -0,0 +1,109 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package kube
+
+import (
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/kube"
+)
+
+const testManifest = `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+data:
+  GREETING: hello
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: web
+  labels:
+    app: web
+spec:
+  restartPolicy: OnFailure
+  volumes:
+  - name: data
+    persistentVolumeClaim:
+      claimName: web-data
+  - name: cache
+    emptyDir:
+      medium: Memory
+  containers:
+  - name: nginx
+    image: nginx:alpine
+    command: ["nginx", "-g"]
+    args: ["daemon off;"]
+    env:
+    - name: FOO
+      value: bar
+    - name: GREETING
+      valueFrom:
+        configMapKeyRef:
+          name: config
+          key: GREETING
+    ports:
+    - containerPort: 80
+      hostPort: 8080
+      protocol: TCP
+    resources:
+      limits:
+        cpu: 500m
+        memory: 64Mi
+    volumeMounts:
+    - name: data
+      mountPath: /data
+      readOnly: true
+    - name: cache
+      mountPath: /cache
+`
+
+func TestPlayArgs(t *testing.T) {
+	m, _, err := kube.Decode(strings.NewReader(testManifest))
+	assert.NilError(t, err)
+	p := m.Pods[0]
+
+	createOptions, err := podCreateOptions(p, types.KubePlayOptions{})
+	assert.NilError(t, err)
+	assert.Equal(t, createOptions.Name, "web")
+	assert.DeepEqual(t, createOptions.Publish, []string{"8080:80/tcp"})
+	assert.DeepEqual(t, createOptions.Labels, []string{"app=web"})
+
+	volumes := map[string]podVolume{
+		"data":  {source: "web-data"},
+		"cache": {tmpfs: true},
+	}
+	args, err := containerArgs(p, p.Spec.Containers[0], volumes, m.ConfigMaps, true)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, args, []string{
+		"run", "-d", "--pod", "web", "--name", "web-nginx",
+		"--restart", "on-failure",
+		"--env", "FOO=bar", "--env", "GREETING=hello",
+		"--cpus", "0.5", "--memory", "67108864",
+		"--volume", "web-data:/data:ro", "--tmpfs", "/cache",
+		"--entrypoint", "nginx", "nginx:alpine", "-g", "daemon off;",
+	})
+
+	_, err = containerArgs(p, p.Spec.Containers[0], volumes, nil, false)
+	assert.ErrorContains(t, err, "configmap config not found")
+}
@@ -19,16 +19,23 @@ package network
 import (
 	"context"
 	"fmt"
+	"os"
 
 	containerd "github.com/containerd/containerd/v2/client"
 	"github.com/containerd/log"
 
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/filterutil"
 	"github.com/containerd/nerdctl/v2/pkg/netutil"
 	"github.com/containerd/nerdctl/v2/pkg/strutil"
 )
 
 func Prune(ctx context.Context, client *containerd.Client, options types.NetworkPruneOptions) error {
+	filters, err := filterutil.ParsePruneFilters(options.Filters)
+	if err != nil {
+		return err
+	}
+
 	e, err := netutil.NewCNIEnv(options.GOptions.CNIPath, options.GOptions.CNINetConfPath, netutil.WithNamespace(options.GOptions.Namespace))
 	if err != nil {
 		return err
@@ -55,6 +62,9 @@ func Prune(ctx context.Context, client *containerd.Client, options types.Network
 		if _, ok := usedNetworks[net.Name]; ok {
 			continue
 		}
+		if !networkMatchesPruneFilters(net, filters) {
+			continue
+		}
 		if err := e.RemoveNetwork(net); err != nil {
 			log.G(ctx).WithError(err).Errorf("failed to remove network %s", net.Name)
 			continue
@@ -71,3 +81,19 @@ func Prune(ctx context.Context, client *containerd.Client, options types.Network
 	}
 	return nil
 }
+
+func networkMatchesPruneFilters(net *netutil.NetworkConfig, filters *filterutil.PruneFilters) bool {
+	var labels map[string]string
+	if net.NerdctlLabels != nil {
+		labels = *net.NerdctlLabels
+	}
+	if filters.Until.IsZero() {
+		return filters.MatchLabels(labels)
+	}
+	// The network config file is written once on creation
+	st, err := os.Stat(net.File)
+	if err != nil {
+		return false
+	}
+	return filters.Match(st.ModTime(), labels)
+}
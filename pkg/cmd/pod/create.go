@@ -0,0 +1,135 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package pod
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/container"
+	"github.com/containerd/nerdctl/v2/pkg/containerutil"
+	"github.com/containerd/nerdctl/v2/pkg/identifiers"
+	"github.com/containerd/nerdctl/v2/pkg/labels"
+)
+
+// Create creates a pod, with its infra container, and starts it.
+func Create(ctx context.Context, client *containerd.Client, options types.PodCreateOptions) error {
+	if err := identifiers.ValidateDockerCompat(options.Name); err != nil {
+		return fmt.Errorf("invalid pod name: %w", err)
+	}
+	share := options.Share
+	if len(share) == 0 {
+		share = DefaultShare
+	}
+	if err := ValidateShare(share); err != nil {
+		return err
+	}
+	infras, err := infraContainers(ctx, client)
+	if err != nil {
+		return err
+	}
+	for _, c := range infras {
+		if l, err := c.Labels(ctx); err == nil && l[labels.Pod] == options.Name {
+			return fmt.Errorf("pod %q already exists", options.Name)
+		}
+	}
+
+	// The infra container is created with nerdctl itself, so that all the networking options are supported
+	cmd := exec.CommandContext(ctx, options.NerdctlCmd, append(options.NerdctlArgs, infraCreateArgs(options, share)...)...)
+	cmd.Stderr = options.Stderr
+	log.G(ctx).Debugf("creating the infra container of pod %s: %v", options.Name, cmd.Args)
+	out, err := cmd.Output()
+	if err != nil {
+		return fmt.Errorf("failed to create the infra container of pod %s: %w", options.Name, err)
+	}
+	infra, err := client.LoadContainer(ctx, strings.TrimSpace(string(out)))
+	if err != nil {
+		return err
+	}
+
+	err = func() error {
+		if _, err := infra.SetLabels(ctx, map[string]string{
+			labels.Pod:      options.Name,
+			labels.PodInfra: "true",
+			labels.PodShare: strings.Join(share, ","),
+		}); err != nil {
+			return err
+		}
+		return containerutil.Start(ctx, infra, false, false, client, "")
+	}()
+	if err != nil {
+		if rmErr := container.RemoveContainer(ctx, infra, options.GOptions, true, true, client); rmErr != nil {
+			log.G(ctx).WithError(rmErr).Warnf("failed to remove the infra container of pod %s", options.Name)
+		}
+		return err
+	}
+
+	_, err = fmt.Fprintln(options.Stdout, infra.ID())
+	return err
+}
+
+func infraCreateArgs(options types.PodCreateOptions, share []string) []string {
+	hostname := options.Hostname
+	if hostname == "" {
+		hostname = options.Name
+	}
+	args := []string{"create", "--name", options.Name + "-infra", "--hostname", hostname}
+	for _, ns := range share {
+		if ns == ShareIPC {
+			args = append(args, "--ipc", "shareable")
+		}
+	}
+	for _, flag := range []struct {
+		name   string
+		values []string
+	}{
+		{"--network", options.Networks},
+		{"--publish", options.Publish},
+		{"--dns", options.DNS},
+		{"--dns-search", options.DNSSearch},
+		{"--dns-option", options.DNSOptions},
+		{"--add-host", options.AddHost},
+		{"--label", options.Labels},
+	} {
+		for _, v := range flag.values {
+			args = append(args, flag.name, v)
+		}
+	}
+	for _, flag := range []struct {
+		name  string
+		value string
+	}{
+		{"--ip", options.IP},
+		{"--ip6", options.IP6},
+		{"--mac-address", options.MACAddress},
+	} {
+		if flag.value != "" {
+			args = append(args, flag.name, flag.value)
+		}
+	}
+	image := options.InfraImage
+	if image == "" {
+		image = DefaultInfraImage
+	}
+	return append(args, image)
+}
@@ -0,0 +1,59 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package pod
+
+import (
+	"context"
+	"errors"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/formatter"
+)
+
+// Inspect prints detailed information on the pods.
+func Inspect(ctx context.Context, client *containerd.Client, reqs []string, options types.PodInspectOptions) error {
+	var result []interface{}
+	var errs []error
+	for _, req := range reqs {
+		infra, err := FindInfra(ctx, client, req)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		pod, err := inspect(ctx, client, infra)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		result = append(result, pod)
+	}
+	if len(result) > 0 {
+		if err := formatter.FormatSlice(options.Format, options.Stdout, result); err != nil {
+			return err
+		}
+	}
+	for _, err := range errs {
+		log.G(ctx).Error(err)
+	}
+	if len(errs) > 0 {
+		return errors.New("some pods could not be inspected")
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package pod
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"text/tabwriter"
+	"text/template"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/errdefs"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/formatter"
+)
+
+type podPrintable struct {
+	ID         string
+	Name       string
+	Status     string
+	Created    string
+	Containers int
+	Labels     string
+}
+
+// List prints the pods.
+func List(ctx context.Context, client *containerd.Client, options types.PodListOptions) error {
+	infras, err := infraContainers(ctx, client)
+	if err != nil {
+		return err
+	}
+	var pods []*Pod
+	for _, infra := range infras {
+		pod, err := inspect(ctx, client, infra)
+		if err != nil {
+			if errdefs.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+		pods = append(pods, pod)
+	}
+	sort.Slice(pods, func(i, j int) bool {
+		return pods[i].Created.After(pods[j].Created)
+	})
+
+	w := options.Stdout
+	var tmpl *template.Template
+	switch options.Format {
+	case "", "table", "wide":
+		w = tabwriter.NewWriter(w, 4, 8, 4, ' ', 0)
+		if !options.Quiet {
+			fmt.Fprintln(w, "POD ID\tNAME\tSTATUS\tCREATED\tCONTAINERS")
+		}
+	case "raw":
+		return errors.New("unsupported format: \"raw\"")
+	default:
+		if options.Quiet {
+			return errors.New("format and quiet must not be specified together")
+		}
+		tmpl, err = formatter.ParseTemplate(options.Format)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, pod := range pods {
+		p := podPrintable{
+			ID:      pod.ID,
+			Name:    pod.Name,
+			Status:  pod.State,
+			Created: formatter.TimeSinceInHuman(pod.Created),
+			// The infra container is not counted
+			Containers: len(pod.Containers) - 1,
+			Labels:     formatter.FormatLabels(pod.Labels),
+		}
+		if !options.NoTrunc && len(p.ID) > 12 {
+			p.ID = p.ID[:12]
+		}
+		if tmpl != nil {
+			var b bytes.Buffer
+			if err := tmpl.Execute(&b, p); err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintln(w, b.String()); err != nil {
+				return err
+			}
+		} else if options.Quiet {
+			fmt.Fprintln(w, p.ID)
+		} else {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\n", p.ID, p.Name, p.Status, p.Created, p.Containers)
+		}
+	}
+	if f, ok := w.(formatter.Flusher); ok {
+		return f.Flush()
+	}
+	return nil
+}
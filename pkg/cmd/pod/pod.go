@@ -0,0 +1,247 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package pod implements `nerdctl pod`: groups of containers sharing the namespaces (network, IPC, PID)
+// of an infra container, like Kubernetes pods.
+//
+// A pod is represented by its infra container, labeled with labels.PodInfra, and its ID is the ID of the infra
+// container. The members of a pod are labeled with labels.Pod, and join the namespaces of the infra container
+// with the `container:<ID>` modes of `--network`, `--ipc` and `--pid`.
+package pod
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/errdefs"
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/containerutil"
+	"github.com/containerd/nerdctl/v2/pkg/formatter"
+	"github.com/containerd/nerdctl/v2/pkg/labels"
+)
+
+// DefaultInfraImage is the default image of the infra containers, which only hold the namespaces of the pods.
+const DefaultInfraImage = "registry.k8s.io/pause:3.10"
+
+// The namespaces that can be shared by the containers of a pod.
+const (
+	ShareNet = "net"
+	ShareIPC = "ipc"
+	SharePID = "pid"
+)
+
+// DefaultShare is the default list of the namespaces shared by the containers of a pod.
+var DefaultShare = []string{ShareNet, ShareIPC}
+
+// Pod is a group of containers sharing the namespaces of an infra container.
+type Pod struct {
+	// ID is the ID of the infra container of the pod
+	ID      string
+	Name    string
+	Created time.Time
+	// State is "Running" when all the containers of the pod are running, "Degraded" when only some of them are,
+	// and "Created" or "Exited" when the infra container is not running.
+	State            string
+	SharedNamespaces []string
+	Labels           map[string]string
+	Containers       []Container
+}
+
+// Container is a container of a pod.
+type Container struct {
+	ID     string
+	Name   string
+	Status string
+	// Infra is true for the infra container of the pod
+	Infra bool
+}
+
+// infraContainers returns the infra containers of all the pods.
+func infraContainers(ctx context.Context, client *containerd.Client) ([]containerd.Container, error) {
+	return client.Containers(ctx, fmt.Sprintf("labels.%q==true", labels.PodInfra))
+}
+
+// FindInfra returns the infra container of the pod with the given name, ID or ID prefix.
+func FindInfra(ctx context.Context, client *containerd.Client, req string) (containerd.Container, error) {
+	infras, err := infraContainers(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	var matches []containerd.Container
+	for _, c := range infras {
+		l, err := c.Labels(ctx)
+		if err != nil {
+			if errdefs.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		if l[labels.Pod] == req || c.ID() == req {
+			return c, nil
+		}
+		if strings.HasPrefix(c.ID(), req) {
+			matches = append(matches, c)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("no such pod %s: %w", req, errdefs.ErrNotFound)
+	case 1:
+		return matches[0], nil
+	default:
+		return nil, fmt.Errorf("multiple IDs found with provided prefix: %s", req)
+	}
+}
+
+// members returns the containers of the pod, except the infra container.
+func members(ctx context.Context, client *containerd.Client, name string) ([]containerd.Container, error) {
+	containers, err := client.Containers(ctx, fmt.Sprintf("labels.%q==%q", labels.Pod, name))
+	if err != nil {
+		return nil, err
+	}
+	var res []containerd.Container
+	for _, c := range containers {
+		l, err := c.Labels(ctx)
+		if err != nil {
+			if errdefs.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		if l[labels.PodInfra] != "true" {
+			res = append(res, c)
+		}
+	}
+	return res, nil
+}
+
+// Members returns the containers of the pod whose infra container is infra, except the infra container.
+func Members(ctx context.Context, client *containerd.Client, infra containerd.Container) ([]containerd.Container, error) {
+	l, err := infra.Labels(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return members(ctx, client, l[labels.Pod])
+}
+
+func isRunning(ctx context.Context, c containerd.Container) bool {
+	status, err := containerutil.ContainerStatus(ctx, c)
+	return err == nil && status.Status == containerd.Running
+}
+
+func inspect(ctx context.Context, client *containerd.Client, infra containerd.Container) (*Pod, error) {
+	info, err := infra.Info(ctx, containerd.WithoutRefreshedMetadata)
+	if err != nil {
+		return nil, err
+	}
+	pod := &Pod{
+		ID:               infra.ID(),
+		Name:             info.Labels[labels.Pod],
+		Created:          info.CreatedAt,
+		SharedNamespaces: strings.Split(info.Labels[labels.PodShare], ","),
+		Labels:           map[string]string{},
+		Containers: []Container{{
+			ID:     infra.ID(),
+			Name:   info.Labels[labels.Name],
+			Status: formatter.ContainerStatus(ctx, infra),
+			Infra:  true,
+		}},
+	}
+	for k, v := range info.Labels {
+		// The internal labels of the infra container are not labels of the pod
+		if !strings.HasPrefix(k, labels.Prefix) && !strings.HasPrefix(k, "containerd.io/") {
+			pod.Labels[k] = v
+		}
+	}
+
+	containers, err := members(ctx, client, pod.Name)
+	if err != nil {
+		return nil, err
+	}
+	running := 0
+	for _, c := range containers {
+		l, err := c.Labels(ctx)
+		if err != nil {
+			if errdefs.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		if isRunning(ctx, c) {
+			running++
+		}
+		pod.Containers = append(pod.Containers, Container{
+			ID:     c.ID(),
+			Name:   l[labels.Name],
+			Status: formatter.ContainerStatus(ctx, c),
+		})
+	}
+
+	switch {
+	case !isRunning(ctx, infra):
+		if _, err := infra.Task(ctx, nil); errdefs.IsNotFound(err) {
+			pod.State = "Created"
+		} else {
+			pod.State = "Exited"
+		}
+	case running == len(containers):
+		pod.State = "Running"
+	default:
+		pod.State = "Degraded"
+	}
+	return pod, nil
+}
+
+// Join returns the infra container of the pod that a container joins, starting it if it is not running,
+// and the namespaces shared by the pod.
+func Join(ctx context.Context, client *containerd.Client, req string) (infra containerd.Container, name string, share []string, _ error) {
+	infra, err := FindInfra(ctx, client, req)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	l, err := infra.Labels(ctx)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	if !isRunning(ctx, infra) {
+		log.G(ctx).Debugf("starting the infra container of pod %s", l[labels.Pod])
+		if err := containerutil.Start(ctx, infra, false, false, client, ""); err != nil {
+			return nil, "", nil, fmt.Errorf("failed to start the infra container of pod %s: %w", l[labels.Pod], err)
+		}
+	}
+	return infra, l[labels.Pod], strings.Split(l[labels.PodShare], ","), nil
+}
+
+// ValidateShare validates a list of namespaces shared by the containers of a pod.
+func ValidateShare(share []string) error {
+	if !slices.Contains(share, ShareNet) {
+		return errors.New("the network namespace of a pod is always shared, --share must contain \"net\"")
+	}
+	for _, ns := range share {
+		switch ns {
+		case ShareNet, ShareIPC, SharePID:
+		default:
+			return fmt.Errorf("invalid namespace %q, --share supports %q, %q and %q", ns, ShareNet, ShareIPC, SharePID)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package pod
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/container"
+)
+
+// Remove removes the containers of each pod, then its infra container.
+func Remove(ctx context.Context, client *containerd.Client, reqs []string, options types.PodRemoveOptions) error {
+	var errs []error
+	for _, req := range reqs {
+		if err := remove(ctx, client, req, options); err != nil {
+			log.G(ctx).WithError(err).Errorf("failed to remove pod %s", req)
+			errs = append(errs, err)
+			continue
+		}
+		fmt.Fprintln(options.Stdout, req)
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	return nil
+}
+
+func remove(ctx context.Context, client *containerd.Client, req string, options types.PodRemoveOptions) error {
+	infra, err := FindInfra(ctx, client, req)
+	if err != nil {
+		return err
+	}
+	containers, err := Members(ctx, client, infra)
+	if err != nil {
+		return err
+	}
+	all := append(containers, infra)
+	if !options.Force {
+		for _, c := range all {
+			if isRunning(ctx, c) {
+				return fmt.Errorf("pod %s is running, stop it before removing it or use --force", req)
+			}
+		}
+	}
+	// The infra container is removed last, as the other containers use its namespaces
+	for _, c := range all {
+		if err := container.RemoveContainer(ctx, c, options.GOptions, true, true, client); err != nil {
+			return fmt.Errorf("failed to remove container %s: %w", c.ID(), err)
+		}
+	}
+	return nil
+}
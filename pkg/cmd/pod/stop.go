@@ -0,0 +1,67 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package pod
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/containerutil"
+)
+
+// Stop stops the containers of each pod, then its infra container.
+func Stop(ctx context.Context, client *containerd.Client, reqs []string, options types.PodStopOptions) error {
+	var errs []error
+	for _, req := range reqs {
+		if err := stop(ctx, client, req, options); err != nil {
+			log.G(ctx).WithError(err).Errorf("failed to stop pod %s", req)
+			errs = append(errs, err)
+			continue
+		}
+		fmt.Fprintln(options.Stdout, req)
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	return nil
+}
+
+func stop(ctx context.Context, client *containerd.Client, req string, options types.PodStopOptions) error {
+	infra, err := FindInfra(ctx, client, req)
+	if err != nil {
+		return err
+	}
+	containers, err := Members(ctx, client, infra)
+	if err != nil {
+		return err
+	}
+	// The infra container is stopped last, as the other containers use its namespaces
+	for _, c := range append(containers, infra) {
+		if !isRunning(ctx, c) {
+			continue
+		}
+		if err := containerutil.Stop(ctx, c, options.Timeout, ""); err != nil {
+			return fmt.Errorf("failed to stop container %s: %w", c.ID(), err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,269 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package system
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net"
+	"net/http"
+	"strconv"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/core/runtime/restart"
+	"github.com/containerd/containerd/v2/pkg/namespaces"
+	"github.com/containerd/errdefs"
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/container"
+	"github.com/containerd/nerdctl/v2/pkg/cmd/volume"
+	"github.com/containerd/nerdctl/v2/pkg/containerutil"
+	"github.com/containerd/nerdctl/v2/pkg/healthcheck"
+	"github.com/containerd/nerdctl/v2/pkg/labels"
+	"github.com/containerd/nerdctl/v2/pkg/metricsutil"
+	"github.com/containerd/nerdctl/v2/pkg/netutil"
+	"github.com/containerd/nerdctl/v2/pkg/statsutil"
+)
+
+// Metrics serves the metrics of the containers, images, volumes and networks in the Prometheus format,
+// on the "/metrics" path of options.Listen, until ctx is done.
+func Metrics(ctx context.Context, client *containerd.Client, options types.SystemMetricsOptions) error {
+	mc := &metricsCollector{
+		client:  client,
+		options: options,
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /metrics", func(w http.ResponseWriter, r *http.Request) {
+		families, err := mc.collect(r.Context())
+		if err != nil {
+			log.G(ctx).WithError(err).Warn("failed to collect the metrics")
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		openMetrics, contentType := metricsutil.Negotiate(r)
+		w.Header().Set("Content-Type", contentType)
+		if err := metricsutil.Write(w, families, openMetrics); err != nil {
+			log.G(ctx).WithError(err).Debug("failed to write the metrics")
+		}
+	})
+
+	l, err := net.Listen("tcp", options.Listen)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(options.Stdout, "Serving the metrics on http://%s/metrics\n", l.Addr())
+	srv := &http.Server{
+		Handler: mux,
+		BaseContext: func(net.Listener) context.Context {
+			return ctx
+		},
+	}
+	go func() {
+		<-ctx.Done()
+		srv.Close()
+	}()
+	if err := srv.Serve(l); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// metricsCollector collects the metrics on each scrape. It keeps no state between the scrapes,
+// so that several scrapers get consistent values: the rates are computed from the counters by Prometheus.
+type metricsCollector struct {
+	client  *containerd.Client
+	options types.SystemMetricsOptions
+}
+
+// containerFamilies are the families of the metrics of the containers.
+type containerFamilies struct {
+	info, running, restarts, health, failingStreak                  *metricsutil.Family
+	cpu, memory, memoryLimit, pids, netRx, netTx, blkRead, blkWrite *metricsutil.Family
+}
+
+func newContainerFamilies() *containerFamilies {
+	gauge := func(name, help string) *metricsutil.Family {
+		return &metricsutil.Family{Name: name, Help: help, Type: metricsutil.Gauge}
+	}
+	counter := func(name, help string) *metricsutil.Family {
+		return &metricsutil.Family{Name: name, Help: help, Type: metricsutil.Counter}
+	}
+	return &containerFamilies{
+		info:          gauge("nerdctl_container_info", "Information about the container, always 1"),
+		running:       gauge("nerdctl_container_running", "Whether the container is running (1) or not (0)"),
+		restarts:      gauge("nerdctl_container_restarts", "Number of restarts of the container by its restart policy"),
+		health:        gauge("nerdctl_container_health_status", "Health status of the container, 1 for the current status"),
+		failingStreak: gauge("nerdctl_container_health_failing_streak", "Number of consecutive failed health checks of the container"),
+		cpu:           counter("nerdctl_container_cpu_usage_seconds", "CPU time consumed by the container, in seconds"),
+		memory:        gauge("nerdctl_container_memory_usage_bytes", "Memory used by the container, without the inactive file cache"),
+		memoryLimit:   gauge("nerdctl_container_memory_limit_bytes", "Memory limit of the container"),
+		pids:          gauge("nerdctl_container_pids", "Number of processes of the container"),
+		netRx:         counter("nerdctl_container_network_receive_bytes", "Bytes received by the container"),
+		netTx:         counter("nerdctl_container_network_transmit_bytes", "Bytes transmitted by the container"),
+		blkRead:       counter("nerdctl_container_block_read_bytes", "Bytes read by the container from block devices"),
+		blkWrite:      counter("nerdctl_container_block_write_bytes", "Bytes written by the container to block devices"),
+	}
+}
+
+func (f *containerFamilies) list() []*metricsutil.Family {
+	return []*metricsutil.Family{
+		f.info, f.running, f.restarts, f.health, f.failingStreak,
+		f.cpu, f.memory, f.memoryLimit, f.pids, f.netRx, f.netTx, f.blkRead, f.blkWrite,
+	}
+}
+
+func (mc *metricsCollector) collect(ctx context.Context) ([]*metricsutil.Family, error) {
+	nsList := []string{mc.options.GOptions.Namespace}
+	if mc.options.AllNamespaces {
+		var err error
+		nsList, err = mc.client.NamespaceService().List(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	cf := newContainerFamilies()
+	containers := &metricsutil.Family{Name: "nerdctl_containers", Help: "Number of containers by state", Type: metricsutil.Gauge}
+	images := &metricsutil.Family{Name: "nerdctl_images", Help: "Number of images", Type: metricsutil.Gauge}
+	volumes := &metricsutil.Family{Name: "nerdctl_volumes", Help: "Number of volumes", Type: metricsutil.Gauge}
+	networks := &metricsutil.Family{Name: "nerdctl_networks", Help: "Number of networks", Type: metricsutil.Gauge}
+	for _, ns := range nsList {
+		nsCtx := namespaces.WithNamespace(ctx, ns)
+		states, err := mc.collectContainers(nsCtx, ns, cf)
+		if err != nil {
+			return nil, err
+		}
+		for _, state := range []containerd.ProcessStatus{containerd.Created, containerd.Running, containerd.Paused, containerd.Stopped} {
+			containers.Add(float64(states[state]), map[string]string{"namespace": ns, "state": string(state)})
+		}
+
+		imageList, err := mc.client.ImageService().List(nsCtx)
+		if err != nil {
+			return nil, err
+		}
+		images.Add(float64(len(imageList)), map[string]string{"namespace": ns})
+
+		g := mc.options.GOptions
+		vols, err := volume.Volumes(ns, g.DataRoot, g.Address, false, nil)
+		if err != nil {
+			log.G(ctx).WithError(err).Debugf("failed to list the volumes of namespace %s", ns)
+		} else {
+			volumes.Add(float64(len(vols)), map[string]string{"namespace": ns})
+		}
+
+		cniEnv, err := netutil.NewCNIEnv(g.CNIPath, g.CNINetConfPath, netutil.WithNamespace(ns))
+		if err != nil {
+			return nil, err
+		}
+		netConfigs, err := cniEnv.NetworkList()
+		if err != nil {
+			return nil, err
+		}
+		networks.Add(float64(len(netConfigs)), map[string]string{"namespace": ns})
+	}
+	return append(cf.list(), containers, images, volumes, networks), nil
+}
+
+// collectContainers adds the metrics of the containers of a namespace, and returns the number of containers by state.
+func (mc *metricsCollector) collectContainers(ctx context.Context, ns string, cf *containerFamilies) (map[containerd.ProcessStatus]int, error) {
+	containers, err := mc.client.Containers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	states := make(map[containerd.ProcessStatus]int)
+	for _, c := range containers {
+		info, err := c.Info(ctx, containerd.WithoutRefreshedMetadata)
+		if err != nil {
+			// the container was removed in the meantime
+			continue
+		}
+		lbls := map[string]string{
+			"id":              c.ID(),
+			"name":            containerutil.GetContainerName(info.Labels),
+			"namespace":       ns,
+			"image":           info.Image,
+			"compose_project": info.Labels[labels.ComposeProject],
+			"compose_service": info.Labels[labels.ComposeService],
+		}
+
+		state := containerd.Stopped
+		if status, err := containerutil.ContainerStatus(ctx, c); err == nil {
+			state = status.Status
+		} else if !errdefs.IsNotFound(err) {
+			log.G(ctx).WithError(err).Debugf("failed to get the status of container %s", c.ID())
+		}
+		if state == containerd.Unknown {
+			state = containerd.Stopped
+		}
+		states[state]++
+
+		cf.info.Add(1, lbls)
+		running := 0.0
+		if state == containerd.Running {
+			running = 1
+		}
+		cf.running.Add(running, lbls)
+		restarts, _ := strconv.Atoi(info.Labels[restart.CountLabel])
+		cf.restarts.Add(float64(restarts), lbls)
+		if hs, err := healthcheck.HealthStateFromJSON(info.Labels[labels.HealthState]); err == nil {
+			for _, status := range []healthcheck.HealthStatus{healthcheck.Starting, healthcheck.Healthy, healthcheck.Unhealthy} {
+				v := 0.0
+				if hs.Status == status {
+					v = 1
+				}
+				cf.health.Add(v, withLabel(lbls, "status", string(status)))
+			}
+			cf.failingStreak.Add(float64(hs.FailingStreak), lbls)
+		}
+
+		if state != containerd.Running {
+			continue
+		}
+		if err := collectStats(ctx, c, cf, lbls); err != nil {
+			log.G(ctx).WithError(err).Debugf("failed to get the statistics of container %s", c.ID())
+		}
+	}
+	return states, nil
+}
+
+// collectStats adds the statistics of a running container.
+func collectStats(ctx context.Context, c containerd.Container, cf *containerFamilies, lbls map[string]string) error {
+	// the CPU percentage of the sample is not used, so there is no previous sample to pass
+	entry, err := container.StatsSample(ctx, c, new(statsutil.ContainerStats), false)
+	if err != nil {
+		return err
+	}
+	cf.cpu.Add(float64(entry.CPUUsage)/1e9, lbls)
+	cf.memory.Add(entry.Memory, lbls)
+	cf.memoryLimit.Add(entry.MemoryLimit, lbls)
+	cf.pids.Add(float64(entry.PidsCurrent), lbls)
+	cf.netRx.Add(entry.NetworkRx, lbls)
+	cf.netTx.Add(entry.NetworkTx, lbls)
+	cf.blkRead.Add(entry.BlockRead, lbls)
+	cf.blkWrite.Add(entry.BlockWrite, lbls)
+	return nil
+}
+
+// withLabel returns a copy of labels with an additional label.
+func withLabel(labels map[string]string, key, value string) map[string]string {
+	res := maps.Clone(labels)
+	res[key] = value
+	return res
+}
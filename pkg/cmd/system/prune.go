@@ -36,6 +36,7 @@ func Prune(ctx context.Context, client *containerd.Client, options types.SystemP
 	if err := container.Prune(ctx, client, types.ContainerPruneOptions{
 		GOptions: options.GOptions,
 		Stdout:   options.Stdout,
+		Filters:  options.Filters,
 	}); err != nil {
 		return err
 	}
@@ -43,6 +44,7 @@ func Prune(ctx context.Context, client *containerd.Client, options types.SystemP
 		GOptions:             options.GOptions,
 		NetworkDriversToKeep: options.NetworkDriversToKeep,
 		Stdout:               options.Stdout,
+		Filters:              options.Filters,
 	}); err != nil {
 		return err
 	}
@@ -52,6 +54,7 @@ func Prune(ctx context.Context, client *containerd.Client, options types.SystemP
 			All:      false,
 			Force:    true,
 			Stdout:   options.Stdout,
+			Filters:  options.Filters,
 		}); err != nil {
 			return err
 		}
@@ -60,6 +63,7 @@ func Prune(ctx context.Context, client *containerd.Client, options types.SystemP
 		Stdout:   options.Stdout,
 		GOptions: options.GOptions,
 		All:      options.All,
+		Filters:  options.Filters,
 	}); err != nil {
 		return nil
 	}
@@ -30,6 +30,7 @@ import (
 	"github.com/containerd/log"
 
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/filterutil"
 	"github.com/containerd/nerdctl/v2/pkg/formatter"
 	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/native"
 )
@@ -230,17 +231,13 @@ func getVolumeFilterFuncs(filters []string) ([]func(*map[string]string) bool, []
 				nameFilterFuncs = append(nameFilterFuncs, func(name string) bool {
 					return re.MatchString(name)
 				})
-			case "label":
-				k, v, hasValue := strings.Cut(value, "=")
+			case filterutil.Label, filterutil.Label + "!":
+				labelFilter := filterutil.ParseLabel(value, filter != filterutil.Label)
 				labelFilterFuncs = append(labelFilterFuncs, func(labels *map[string]string) bool {
 					if labels == nil {
-						return false
+						return labelFilter.Match(nil)
 					}
-					val, ok := (*labels)[k]
-					if !ok || (hasValue && val != v) {
-						return false
-					}
-					return true
+					return labelFilter.Match(*labels)
 				})
 			}
 			continue
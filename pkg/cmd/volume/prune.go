@@ -24,11 +24,17 @@ import (
 	containerd "github.com/containerd/containerd/v2/client"
 
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/filterutil"
 	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/native"
 	"github.com/containerd/nerdctl/v2/pkg/labels"
 )
 
 func Prune(ctx context.Context, client *containerd.Client, options types.VolumePruneOptions) error {
+	filters, err := filterutil.ParsePruneFilters(options.Filters)
+	if err != nil {
+		return err
+	}
+
 	// Get the volume store and lock it until we are done.
 	// This will prevent racing new containers from being created or removed until we are done with the cleanup of volumes
 	volStore, err := Store(options.GOptions.Namespace, options.GOptions.DataRoot, options.GOptions.Address)
@@ -45,7 +51,7 @@ func Prune(ctx context.Context, client *containerd.Client, options types.VolumeP
 			return nil, err
 		}
 
-		usedVolumesList, err := usedVolumes(ctx, containers)
+		usedVolumesList, err := UsedVolumes(ctx, containers)
 		if err != nil {
 			return nil, err
 		}
@@ -64,6 +70,13 @@ func Prune(ctx context.Context, client *containerd.Client, options types.VolumeP
 					continue
 				}
 			}
+			var volLabels map[string]string
+			if volume.Labels != nil {
+				volLabels = *volume.Labels
+			}
+			if !filters.Match(volume.CreatedAt, volLabels) {
+				continue
+			}
 			toRemove = append(toRemove, volume.Name)
 		}
 
@@ -45,7 +45,7 @@ func Remove(ctx context.Context, client *containerd.Client, volumes []string, op
 
 	// Note: to avoid racy behavior, this is called by volStore.Remove *inside a lock*
 	removableVolumes := func() (volumeNames []string, cannotRemove []error, err error) {
-		usedVolumesList, err := usedVolumes(ctx, containers)
+		usedVolumesList, err := UsedVolumes(ctx, containers)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -79,7 +79,8 @@ func Remove(ctx context.Context, client *containerd.Client, volumes []string, op
 	return nil
 }
 
-func usedVolumes(ctx context.Context, containers []containerd.Container) (map[string]struct{}, error) {
+// UsedVolumes returns the names of the volumes mounted by the containers.
+func UsedVolumes(ctx context.Context, containers []containerd.Container) (map[string]struct{}, error) {
 	usedVolumesList := make(map[string]struct{})
 	for _, c := range containers {
 		l, err := c.Labels(ctx)
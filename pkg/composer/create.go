@@ -192,6 +192,8 @@ func (c *Composer) createServiceContainer(ctx context.Context, service *servicep
 		"--cidfile=" + cidFilename,
 		fmt.Sprintf("-l=%s=%s", labels.ComposeProject, c.project.Name),
 		fmt.Sprintf("-l=%s=%s", labels.ComposeService, service.Unparsed.Name),
+		fmt.Sprintf("-l=%s=%s", labels.ComposeWorkingDir, c.project.WorkingDir),
+		fmt.Sprintf("-l=%s=%s", labels.ComposeConfigFiles, strings.Join(c.project.ComposeFiles, ",")),
 	}, container.RunArgs...)
 
 	cmd := c.createNerdctlCmd(ctx, append([]string{"create"}, container.RunArgs...)...)
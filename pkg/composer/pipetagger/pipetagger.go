@@ -96,16 +96,21 @@ type PipeTagger struct {
 func (x *PipeTagger) Run() error {
 	scanner := bufio.NewScanner(x.r)
 	for scanner.Scan() {
-		line := scanner.Text()
-		if x.width < 0 {
-			fmt.Fprintln(x.w, line)
-		} else {
-			fmt.Fprintf(x.w, "%s%s|%s\n",
-				x.color.Sprint(x.tag),
-				strings.Repeat(" ", x.width-len(x.tag)),
-				line,
-			)
-		}
+		x.WriteLine(scanner.Text())
 	}
 	return scanner.Err()
 }
+
+// WriteLine writes a line prefixed with the tag.
+// The reader of the PipeTagger is not used, so it can be nil.
+func (x *PipeTagger) WriteLine(line string) {
+	if x.width < 0 {
+		fmt.Fprintln(x.w, line)
+	} else {
+		fmt.Fprintf(x.w, "%s%s|%s\n",
+			x.color.Sprint(x.tag),
+			strings.Repeat(" ", max(x.width-len(x.tag), 0)),
+			line,
+		)
+	}
+}
@@ -32,10 +32,31 @@ import (
 	"github.com/containerd/nerdctl/v2/pkg/reflectutil"
 )
 
-func parseBuildConfig(c *types.BuildConfig, project *types.Project, imageName string) (*Build, error) {
-	if unknown := reflectutil.UnknownNonEmptyFields(c,
+// ParseBuild parses the build section of the service without parsing the rest of the service.
+// The image is tagged with the same name as the one used by Parse.
+// handledFields are the fields of the build section that the caller handles itself,
+// which are not reported as ignored.
+// It returns nil if the service has no build section.
+func ParseBuild(project *types.Project, svc types.ServiceConfig, handledFields ...string) (*Build, error) {
+	if svc.Build == nil {
+		return nil, nil
+	}
+	imageName := svc.Image
+	if imageName == "" {
+		imageName = DefaultImageName(project.Name, svc.Name)
+	}
+	b, err := parseBuildConfig(svc.Build, project, imageName, handledFields...)
+	if err != nil {
+		return nil, fmt.Errorf("service %s: failed to parse build: %w", svc.Name, err)
+	}
+	return b, nil
+}
+
+func parseBuildConfig(c *types.BuildConfig, project *types.Project, imageName string, handledFields ...string) (*Build, error) {
+	knownFields := append([]string{
 		"Context", "Dockerfile", "Args", "CacheFrom", "Target", "Labels", "Secrets", "DockerfileInline", "AdditionalContexts",
-	); len(unknown) > 0 {
+	}, handledFields...)
+	if unknown := reflectutil.UnknownNonEmptyFields(c, knownFields...); len(unknown) > 0 {
 		log.L.Warnf("Ignoring: build: %+v", unknown)
 	}
 
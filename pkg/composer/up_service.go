@@ -188,6 +188,8 @@ func (c *Composer) upServiceContainer(ctx context.Context, service *serviceparse
 		"--cidfile=" + cidFilename,
 		fmt.Sprintf("-l=%s=%s", labels.ComposeProject, c.project.Name),
 		fmt.Sprintf("-l=%s=%s", labels.ComposeService, service.Unparsed.Name),
+		fmt.Sprintf("-l=%s=%s", labels.ComposeWorkingDir, c.project.WorkingDir),
+		fmt.Sprintf("-l=%s=%s", labels.ComposeConfigFiles, strings.Join(c.project.ComposeFiles, ",")),
 	}, container.RunArgs...)
 
 	cmd := c.createNerdctlCmd(ctx, append([]string{"run"}, container.RunArgs...)...)
@@ -17,6 +17,11 @@
 package config
 
 import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
 	"github.com/containerd/containerd/v2/defaults"
 	"github.com/containerd/containerd/v2/pkg/namespaces"
 
@@ -46,6 +51,53 @@ type Config struct {
 	DNS              []string `toml:"dns,omitempty"`
 	DNSOpts          []string `toml:"dns_opts,omitempty"`
 	DNSSearch        []string `toml:"dns_search,omitempty"`
+	// DefaultAddressPools are used for allocating subnets to networks created without --subnet.
+	DefaultAddressPools []AddressPool `toml:"default_address_pools,omitempty"`
+}
+
+// AddressPool is a base CIDR that is split into subnets of the given prefix size.
+type AddressPool struct {
+	Base string `toml:"base"`
+	Size int    `toml:"size"`
+}
+
+// String returns the pool in the "base=CIDR,size=N" form accepted by ParseAddressPool.
+func (p AddressPool) String() string {
+	return fmt.Sprintf("base=%s,size=%d", p.Base, p.Size)
+}
+
+// ParseAddressPool parses a pool in the "base=CIDR,size=N" form, as in `dockerd --default-address-pool`.
+func ParseAddressPool(s string) (AddressPool, error) {
+	var p AddressPool
+	for _, field := range strings.Split(s, ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(field), "=")
+		if !ok {
+			return p, fmt.Errorf("invalid address pool %q: expected key=value", s)
+		}
+		switch k {
+		case "base":
+			p.Base = v
+		case "size":
+			size, err := strconv.Atoi(v)
+			if err != nil {
+				return p, fmt.Errorf("invalid address pool %q: invalid size %q", s, v)
+			}
+			p.Size = size
+		default:
+			return p, fmt.Errorf("invalid address pool %q: unknown key %q", s, k)
+		}
+	}
+	if p.Base == "" || p.Size == 0 {
+		return p, fmt.Errorf("invalid address pool %q: both base and size must be specified", s)
+	}
+	_, base, err := net.ParseCIDR(p.Base)
+	if err != nil {
+		return p, fmt.Errorf("invalid address pool %q: invalid base %q", s, p.Base)
+	}
+	if ones, bits := base.Mask.Size(); p.Size < ones || p.Size > bits {
+		return p, fmt.Errorf("invalid address pool %q: size must be between %d and %d", s, ones, bits)
+	}
+	return p, nil
 }
 
 // New creates a default Config object statically,
@@ -0,0 +1,50 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package config
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestParseAddressPool(t *testing.T) {
+	p, err := ParseAddressPool("base=10.10.0.0/16,size=24")
+	assert.NilError(t, err)
+	assert.Equal(t, p, AddressPool{Base: "10.10.0.0/16", Size: 24})
+	assert.Equal(t, p.String(), "base=10.10.0.0/16,size=24")
+
+	p, err = ParseAddressPool(" size=64, base=fd00::/48")
+	assert.NilError(t, err)
+	assert.Equal(t, p, AddressPool{Base: "fd00::/48", Size: 64})
+
+	for s, expected := range map[string]string{
+		"base=10.10.0.0/16":             "both base and size must be specified",
+		"size=24":                       "both base and size must be specified",
+		"base=10.10.0.0/16,size=abc":    "invalid size",
+		"base=10.10.0.0/16,size":        "expected key=value",
+		"base=10.10.0.0/16,size=24,x=y": "unknown key",
+		"base=10.10.0.0,size=24":        "invalid base",
+		"base=10.10.0.300/16,size=24":   "invalid base",
+		"base=10.10.0.0/16,size=8":      "size must be between 16 and 32",
+		"base=10.10.0.0/16,size=33":     "size must be between 16 and 32",
+		"base=fd00::/48,size=129":       "size must be between 48 and 128",
+	} {
+		_, err := ParseAddressPool(s)
+		assert.ErrorContains(t, err, expected, s)
+	}
+}
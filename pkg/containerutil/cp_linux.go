@@ -21,15 +21,18 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"syscall"
 
 	containerd "github.com/containerd/containerd/v2/client"
 	"github.com/containerd/containerd/v2/core/containers"
 	"github.com/containerd/containerd/v2/core/mount"
+	"github.com/containerd/containerd/v2/pkg/oci"
 	"github.com/containerd/errdefs"
 	"github.com/containerd/log"
 
@@ -81,19 +84,9 @@ func getRoot(ctx context.Context, container containerd.Container) (string, int,
 // CopyFiles implements `nerdctl cp`
 // It currently depends on the following assumptions:
 // - linux only
-// - tar binary exists on the system
-// - nsenter binary exists on the system
+// - nsenter binary exists on the system (rootless only)
 // - if rootless, the container is running (aka: /proc/pid/root)
 func CopyFiles(ctx context.Context, client *containerd.Client, container containerd.Container, options types.ContainerCpOptions) (err error) {
-	// We do rely on the tar binary as a shortcut - could also be replaced by archive/tar, though that would mean
-	// we need to replace nsenter calls with re-exec
-	tarBinary, isGNUTar, err := tarutil.FindTarBinary()
-	if err != nil {
-		return err
-	}
-
-	log.G(ctx).Debugf("Detected tar binary %q (GNU=%v)", tarBinary, isGNUTar)
-
 	// This can happen if the container being passed has been deleted since in a racy way
 	conSpec, err := container.Spec(ctx)
 	if err != nil {
@@ -143,6 +136,20 @@ func CopyFiles(ctx context.Context, client *containerd.Client, container contain
 		log.G(ctx).Debugf("Got new root %s", root)
 	}
 
+	// In rootless mode, the files of the container are archived and extracted from the user namespace of the
+	// container, so that their uid/gid are mapped.
+	var userNSPid int
+	if rootlessutil.IsRootless() {
+		userNSPid = pid
+	}
+
+	if options.SrcPath == "-" {
+		return copyFromStdin(ctx, conSpec, root, userNSPid, options)
+	}
+	if options.DestPath == "-" {
+		return copyToStdout(ctx, conSpec, root, userNSPid, options)
+	}
+
 	var sourceSpec, destinationSpec *pathSpecifier
 	var sourceErr, destErr error
 	if options.Container2Host {
@@ -154,23 +161,11 @@ func CopyFiles(ctx context.Context, client *containerd.Client, container contain
 	}
 
 	if destErr != nil {
-		if errors.Is(destErr, errDoesNotExist) {
-			return ErrDestinationParentMustExist
-		} else if errors.Is(destErr, errIsNotADir) {
-			return ErrDestinationIsNotADir
-		}
-
-		return errors.Join(ErrFilesystem, destErr)
+		return destinationSpecError(destErr)
 	}
 
 	if sourceErr != nil {
-		if errors.Is(sourceErr, errDoesNotExist) {
-			return ErrSourceDoesNotExist
-		} else if errors.Is(sourceErr, errIsNotADir) {
-			return ErrSourceIsNotADir
-		}
-
-		return errors.Join(ErrFilesystem, sourceErr)
+		return sourceSpecError(sourceErr)
 	}
 
 	// Now, resolve cp shenanigans
@@ -194,130 +189,177 @@ func CopyFiles(ctx context.Context, client *containerd.Client, container contain
 		return ErrDestinationDirMustExist
 	}
 
-	// XXX FIXME: this seems wrong. What about ownership? We could be doing that inside a container
-	if !destinationSpec.exists {
-		if err = os.Mkdir(destinationSpec.resolvedPath, 0o755); err != nil {
-			return errors.Join(ErrFilesystem, err)
-		}
+	archiveOpts := tarutil.ArchiveOptions{
+		Name:           filepath.Base(sourceSpec.resolvedPath),
+		FollowSymlinks: options.FollowSymLink,
 	}
-
-	var tarCDir, tarCArg string
+	extractDir := destinationSpec.resolvedPath
 	if sourceSpec.isADir {
 		if !destinationSpec.exists || sourceSpec.endsWithSeparatorDot {
-			// the content of the source directory is copied into this directory
-			tarCDir = sourceSpec.resolvedPath
-			tarCArg = "."
-		} else {
-			// the source directory is copied into this directory
-			tarCDir = filepath.Dir(sourceSpec.resolvedPath)
-			tarCArg = filepath.Base(sourceSpec.resolvedPath)
+			// the content of the source directory is copied into this directory,
+			// which gets created by the extraction if it does not exist
+			archiveOpts.Name = "."
 		}
+	} else if !destinationSpec.endsWithSeparator && !(destinationSpec.exists && destinationSpec.isADir) {
+		// Handle `nerdctl cp /path/to/file some-container:/path/to/file-with-another-name`
+		archiveOpts.Name = filepath.Base(destinationSpec.resolvedPath)
+		extractDir = filepath.Dir(destinationSpec.resolvedPath)
+	}
+
+	var (
+		extractOpts                        tarutil.ExtractOptions
+		archiveUserNSPid, extractUserNSPid int
+	)
+	if options.Container2Host {
+		// Like `tar --no-same-owner`, the files are owned by the user running nerdctl, unless in archive mode
+		extractOpts.SameOwner = options.Archive
+		archiveUserNSPid = userNSPid
 	} else {
-		// Prepare a single-file directory to create an archive of the source file
-		td, err := os.MkdirTemp("", "nerdctl-cp")
-		if err != nil {
-			return err
-		}
-		defer os.RemoveAll(td)
-		tarCDir = td
-		cp := []string{"cp", "-a"}
-		if options.FollowSymLink {
-			cp = append(cp, "-L")
-		}
-		if destinationSpec.endsWithSeparator || (destinationSpec.exists && destinationSpec.isADir) {
-			tarCArg = filepath.Base(sourceSpec.resolvedPath)
-		} else {
-			// Handle `nerdctl cp /path/to/file some-container:/path/to/file-with-another-name`
-			tarCArg = filepath.Base(destinationSpec.resolvedPath)
-		}
-		cp = append(cp, sourceSpec.resolvedPath, filepath.Join(td, tarCArg))
-		cpCmd := exec.CommandContext(ctx, cp[0], cp[1:]...)
-		log.G(ctx).Debugf("executing %v", cpCmd.Args)
-		if out, err := cpCmd.CombinedOutput(); err != nil {
-			return fmt.Errorf("failed to execute %v: %w (out=%q)", cpCmd.Args, err, string(out))
-		}
+		extractOpts = containerExtractOptions(conSpec, options.Archive)
+		extractUserNSPid = userNSPid
 	}
-	tarC := []string{tarBinary}
-	if options.FollowSymLink {
-		tarC = append(tarC, "-h")
+
+	pr, pw := io.Pipe()
+	archiveErrCh := make(chan error, 1)
+	go func() {
+		err := archive(ctx, pw, sourceSpec.resolvedPath, archiveOpts, archiveUserNSPid)
+		pw.Close()
+		archiveErrCh <- err
+	}()
+	extractErr := extract(ctx, pr, extractDir, extractOpts, extractUserNSPid)
+	// Unblock the archiver if the extraction was aborted
+	pr.Close()
+	archiveErr := <-archiveErrCh
+	if errors.Is(archiveErr, io.ErrClosedPipe) {
+		archiveErr = nil
 	}
-	tarC = append(tarC, "-c", "-f", "-", tarCArg)
+	return errors.Join(archiveErr, extractErr)
+}
 
-	tarXDir := destinationSpec.resolvedPath
-	if !sourceSpec.isADir && !destinationSpec.endsWithSeparator && !(destinationSpec.exists && destinationSpec.isADir) {
-		tarXDir = filepath.Dir(destinationSpec.resolvedPath)
+// copyFromStdin implements `nerdctl cp - CONTAINER:DEST_PATH`, extracting a tar archive read from stdin
+// into a directory of the container.
+func copyFromStdin(ctx context.Context, conSpec *oci.Spec, root string, userNSPid int, options types.ContainerCpOptions) error {
+	destinationSpec, err := getPathSpecFromContainer(options.DestPath, conSpec, root)
+	if err != nil {
+		return destinationSpecError(err)
 	}
-	tarX := []string{tarBinary, "-x"}
-	if options.Container2Host && isGNUTar {
-		tarX = append(tarX, "--no-same-owner")
+	if !destinationSpec.exists {
+		return ErrDestinationDirMustExist
 	}
-	tarX = append(tarX, "-f", "-")
-
-	if rootlessutil.IsRootless() {
-		nsenter := []string{"nsenter", "-t", strconv.Itoa(pid), "-U", "--preserve-credentials", "--"}
-		if options.Container2Host {
-			tarC = append(nsenter, tarC...)
-		} else {
-			tarX = append(nsenter, tarX...)
-		}
+	if !destinationSpec.isADir {
+		return ErrDestinationIsNotADir
 	}
+	if destinationSpec.readOnly {
+		return ErrTargetIsReadOnly
+	}
+	return extract(ctx, options.Stdin, destinationSpec.resolvedPath, containerExtractOptions(conSpec, options.Archive), userNSPid)
+}
 
-	// FIXME: moving to archive/tar should allow better error management than this
-	// WARNING: some of our testing on stderr might not be portable across different versions of tar
-	// In these cases (readonly target), we will just get the straight tar output instead
-	tarCCmd := exec.CommandContext(ctx, tarC[0], tarC[1:]...)
-	tarCCmd.Dir = tarCDir
-	tarCCmd.Stdin = nil
-	tarCCmd.Stderr = os.Stderr
-
-	tarXCmd := exec.CommandContext(ctx, tarX[0], tarX[1:]...)
-	tarXCmd.Dir = tarXDir
-	tarXCmd.Stdin, err = tarCCmd.StdoutPipe()
+// copyToStdout implements `nerdctl cp CONTAINER:SRC_PATH -`, writing a tar archive of the source to stdout.
+func copyToStdout(ctx context.Context, conSpec *oci.Spec, root string, userNSPid int, options types.ContainerCpOptions) error {
+	sourceSpec, err := getPathSpecFromContainer(options.SrcPath, conSpec, root)
 	if err != nil {
-		return err
+		return sourceSpecError(err)
 	}
-	tarXCmd.Stdout = os.Stderr
-	var tarErr bytes.Buffer
-	tarXCmd.Stderr = &tarErr
+	if !sourceSpec.exists {
+		return ErrSourceDoesNotExist
+	}
+	archiveOpts := tarutil.ArchiveOptions{
+		Name:           filepath.Base(sourceSpec.resolvedPath),
+		FollowSymlinks: options.FollowSymLink,
+	}
+	if sourceSpec.isADir && sourceSpec.endsWithSeparatorDot {
+		archiveOpts.Name = "."
+	}
+	return archive(ctx, options.Stdout, sourceSpec.resolvedPath, archiveOpts, userNSPid)
+}
 
-	log.G(ctx).Debugf("executing %v in %q", tarCCmd.Args, tarCCmd.Dir)
-	if err := tarCCmd.Start(); err != nil {
-		return errors.Join(fmt.Errorf("failed to execute %v", tarCCmd.Args), err)
+func sourceSpecError(err error) error {
+	if errors.Is(err, errDoesNotExist) {
+		return ErrSourceDoesNotExist
+	} else if errors.Is(err, errIsNotADir) {
+		return ErrSourceIsNotADir
 	}
 
-	log.G(ctx).Debugf("executing %v in %q", tarXCmd.Args, tarXCmd.Dir)
-	if err := tarXCmd.Start(); err != nil {
-		if strings.Contains(err.Error(), "permission denied") {
-			return ErrTargetIsReadOnly
-		}
+	return errors.Join(ErrFilesystem, err)
+}
 
-		// Other errors, just put them back on stderr
-		_, fpErr := fmt.Fprint(os.Stderr, tarErr.String())
-		if fpErr != nil {
-			return errors.Join(fpErr, err)
-		}
+func destinationSpecError(err error) error {
+	if errors.Is(err, errDoesNotExist) {
+		return ErrDestinationParentMustExist
+	} else if errors.Is(err, errIsNotADir) {
+		return ErrDestinationIsNotADir
+	}
+
+	return errors.Join(ErrFilesystem, err)
+}
 
-		return errors.Join(fmt.Errorf("failed to execute %v", tarXCmd.Args), err)
+// containerExtractOptions returns the options to extract files into the container.
+// Like `docker cp`, the files are owned by root:root, unless in archive mode where they are
+// owned by the user of the container.
+func containerExtractOptions(conSpec *oci.Spec, archiveMode bool) tarutil.ExtractOptions {
+	var owner tarutil.Owner
+	if archiveMode && conSpec.Process != nil {
+		owner.UID = int(conSpec.Process.User.UID)
+		owner.GID = int(conSpec.Process.User.GID)
 	}
+	return tarutil.ExtractOptions{Chown: &owner}
+}
 
-	if err := tarCCmd.Wait(); err != nil {
-		return fmt.Errorf("failed to wait %v: %w", tarCCmd.Args, err)
+// archive writes a tar archive of p to w.
+// When userNSPid is set, the archive is created from the user namespace of this process.
+func archive(ctx context.Context, w io.Writer, p string, opts tarutil.ArchiveOptions, userNSPid int) error {
+	if userNSPid == 0 {
+		return tarutil.Archive(w, p, opts)
 	}
+	args := []string{"archive", "--name=" + opts.Name}
+	if opts.FollowSymlinks {
+		args = append(args, "--follow-link")
+	}
+	return runInUserNS(ctx, userNSPid, nil, w, append(args, p)...)
+}
 
-	if err := tarXCmd.Wait(); err != nil {
-		if strings.Contains(tarErr.String(), "Read-only file system") {
-			return ErrTargetIsReadOnly
+// extract extracts the tar archive read from r into dir.
+// When userNSPid is set, the archive is extracted from the user namespace of this process.
+func extract(ctx context.Context, r io.Reader, dir string, opts tarutil.ExtractOptions, userNSPid int) error {
+	var err error
+	if userNSPid == 0 {
+		err = tarutil.Extract(r, dir, opts)
+	} else {
+		args := []string{"extract"}
+		if opts.SameOwner {
+			args = append(args, "--same-owner")
 		}
-
-		// Other errors, just put them back on stderr
-		_, fpErr := fmt.Fprint(os.Stderr, tarErr.String())
-		if fpErr != nil {
-			return errors.Join(fpErr, err)
+		if opts.Chown != nil {
+			args = append(args, fmt.Sprintf("--chown=%d:%d", opts.Chown.UID, opts.Chown.GID))
 		}
-
-		return errors.Join(fmt.Errorf("failed to wait %v", tarXCmd.Args), err)
+		err = runInUserNS(ctx, userNSPid, r, nil, append(args, dir)...)
+	}
+	if errors.Is(err, syscall.EROFS) || (err != nil && strings.Contains(err.Error(), syscall.EROFS.Error())) {
+		return errors.Join(ErrTargetIsReadOnly, err)
 	}
+	return err
+}
 
+// runInUserNS runs `nerdctl internal cp` in the user namespace of the process pid.
+func runInUserNS(ctx context.Context, pid int, stdin io.Reader, stdout io.Writer, args ...string) error {
+	self, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	nsenter := []string{"nsenter", "-t", strconv.Itoa(pid), "-U", "--preserve-credentials", "--", self, "internal", "cp"}
+	cmd := exec.CommandContext(ctx, nsenter[0], append(nsenter[1:], args...)...)
+	cmd.Stdin = stdin
+	cmd.Stdout = stdout
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	log.G(ctx).Debugf("executing %v", cmd.Args)
+	if err := cmd.Run(); err != nil {
+		if stderr.Len() == 0 {
+			return fmt.Errorf("failed to execute %v: %w", cmd.Args, err)
+		}
+		return errors.New(strings.TrimSpace(stderr.String()))
+	}
 	return nil
 }
 
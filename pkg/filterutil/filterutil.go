@@ -0,0 +1,151 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package filterutil parses the `--filter` values shared by the list and prune commands of containers, images,
+// networks and volumes.
+package filterutil
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Filter types shared by the prune commands.
+const (
+	// Until selects the objects created before a timestamp, or a duration ago.
+	Until = "until"
+	// Label selects the objects with a label (`label=<key>` or `label=<key>=<value>`),
+	// or without it with `label!=`.
+	Label = "label"
+)
+
+// Split splits a filter into its type and value. negate is true for the `type!=value` form.
+func Split(filter string) (typ, value string, negate bool, err error) {
+	typ, value, ok := strings.Cut(filter, "=")
+	if !ok {
+		return "", "", false, fmt.Errorf("invalid filter %q, expected type=value", filter)
+	}
+	if t, found := strings.CutSuffix(typ, "!"); found {
+		typ, negate = t, true
+	}
+	if typ == "" {
+		return "", "", false, fmt.Errorf("invalid filter %q, empty type", filter)
+	}
+	return typ, value, negate, nil
+}
+
+// LabelFilter selects the objects with (or, when negated, without) a label, optionally with a given value.
+type LabelFilter struct {
+	Key      string
+	Value    string
+	HasValue bool
+	Negate   bool
+}
+
+// ParseLabel parses the value of a `label` filter (`<key>` or `<key>=<value>`).
+func ParseLabel(value string, negate bool) LabelFilter {
+	k, v, hasValue := strings.Cut(value, "=")
+	return LabelFilter{Key: k, Value: v, HasValue: hasValue, Negate: negate}
+}
+
+// Match returns whether the labels match the filter.
+func (f LabelFilter) Match(labels map[string]string) bool {
+	v, ok := labels[f.Key]
+	matched := ok && (!f.HasValue || v == f.Value)
+	return matched != f.Negate
+}
+
+// ParseUntil parses the value of an `until` filter, which is either a duration relative to now (e.g., "24h"),
+// an RFC 3339 timestamp with or without time zone (e.g., "2006-01-02T15:04:05"), a date (e.g., "2006-01-02"),
+// or a Unix timestamp (e.g., "1136214245").
+func ParseUntil(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, errors.New("no until timestamp provided")
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999", time.DateOnly} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	sec, nsec, _ := strings.Cut(value, ".")
+	if s, err := strconv.ParseInt(sec, 10, 64); err == nil {
+		var ns int64
+		if nsec != "" {
+			if ns, err = strconv.ParseInt((nsec + "000000000")[:9], 10, 64); err != nil {
+				return time.Time{}, fmt.Errorf("unable to parse until timestamp: %q is neither a duration nor a timestamp", value)
+			}
+		}
+		return time.Unix(s, ns), nil
+	}
+	return time.Time{}, fmt.Errorf("unable to parse until timestamp: %q is neither a duration nor a timestamp", value)
+}
+
+// PruneFilters are the filters of the prune commands.
+type PruneFilters struct {
+	// Until is zero when there is no `until` filter.
+	Until  time.Time
+	Labels []LabelFilter
+}
+
+// ParsePruneFilters parses the `until` and `label` filters of the prune commands.
+func ParsePruneFilters(filters []string) (*PruneFilters, error) {
+	now := time.Now()
+	res := &PruneFilters{}
+	for _, filter := range filters {
+		typ, value, negate, err := Split(filter)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case typ == Until && !negate:
+			if !res.Until.IsZero() {
+				return nil, errors.New("more than one until filter provided")
+			}
+			if res.Until, err = ParseUntil(value, now); err != nil {
+				return nil, err
+			}
+		case typ == Label:
+			res.Labels = append(res.Labels, ParseLabel(value, negate))
+		default:
+			return nil, fmt.Errorf("invalid filter %q, only %q and %q are supported", filter, Until, Label)
+		}
+	}
+	return res, nil
+}
+
+// Match returns whether an object created at the given time and with the given labels matches all the filters.
+func (f *PruneFilters) Match(created time.Time, labels map[string]string) bool {
+	if !f.Until.IsZero() && !created.Before(f.Until) {
+		return false
+	}
+	return f.MatchLabels(labels)
+}
+
+// MatchLabels returns whether the labels match all the label filters.
+func (f *PruneFilters) MatchLabels(labels map[string]string) bool {
+	for _, l := range f.Labels {
+		if !l.Match(labels) {
+			return false
+		}
+	}
+	return true
+}
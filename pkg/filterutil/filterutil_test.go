@@ -0,0 +1,102 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package filterutil
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestParseUntil(t *testing.T) {
+	now := time.Date(2024, 1, 2, 15, 4, 5, 0, time.Local)
+	testCases := []struct {
+		value     string
+		expected  time.Time
+		expectErr string
+	}{
+		{value: "24h", expected: now.Add(-24 * time.Hour)},
+		{value: "2006-01-02T15:04:05Z", expected: time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)},
+		{value: "2006-01-02T15:04:05.5+07:00", expected: time.Date(2006, 1, 2, 15, 4, 5, 500000000, time.FixedZone("", 7*3600))},
+		{value: "2006-01-02T15:04:05", expected: time.Date(2006, 1, 2, 15, 4, 5, 0, time.Local)},
+		{value: "2006-01-02", expected: time.Date(2006, 1, 2, 0, 0, 0, 0, time.Local)},
+		{value: "1136214245", expected: time.Unix(1136214245, 0)},
+		{value: "1136214245.25", expected: time.Unix(1136214245, 250000000)},
+		{value: "", expectErr: "no until timestamp provided"},
+		{value: "yesterday", expectErr: "neither a duration nor a timestamp"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.value, func(t *testing.T) {
+			actual, err := ParseUntil(tc.value, now)
+			if tc.expectErr != "" {
+				assert.ErrorContains(t, err, tc.expectErr)
+				return
+			}
+			assert.NilError(t, err)
+			assert.Assert(t, actual.Equal(tc.expected), "expected %v, got %v", tc.expected, actual)
+		})
+	}
+}
+
+func TestLabelFilter(t *testing.T) {
+	labels := map[string]string{"foo": "bar", "empty": ""}
+	testCases := []struct {
+		value    string
+		negate   bool
+		expected bool
+	}{
+		{value: "foo", expected: true},
+		{value: "foo=bar", expected: true},
+		{value: "foo=baz", expected: false},
+		{value: "empty=", expected: true},
+		{value: "missing", expected: false},
+		{value: "foo", negate: true, expected: false},
+		{value: "foo=baz", negate: true, expected: true},
+		{value: "missing", negate: true, expected: true},
+	}
+	for _, tc := range testCases {
+		f := ParseLabel(tc.value, tc.negate)
+		assert.Equal(t, f.Match(labels), tc.expected, "value=%q negate=%v", tc.value, tc.negate)
+	}
+}
+
+func TestParsePruneFilters(t *testing.T) {
+	filters, err := ParsePruneFilters([]string{"until=1h", "label=foo=bar", "label!=baz"})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, filters.Labels, []LabelFilter{
+		{Key: "foo", Value: "bar", HasValue: true},
+		{Key: "baz", Negate: true},
+	})
+
+	old := time.Now().Add(-2 * time.Hour)
+	assert.Assert(t, filters.Match(old, map[string]string{"foo": "bar"}))
+	assert.Assert(t, !filters.Match(time.Now(), map[string]string{"foo": "bar"}))
+	assert.Assert(t, !filters.Match(old, map[string]string{"foo": "bar", "baz": ""}))
+	assert.Assert(t, !filters.Match(old, nil))
+
+	_, err = ParsePruneFilters([]string{"until=1h", "until=2h"})
+	assert.ErrorContains(t, err, "more than one until filter")
+	_, err = ParsePruneFilters([]string{"dangling=true"})
+	assert.ErrorContains(t, err, "invalid filter")
+	_, err = ParsePruneFilters([]string{"label"})
+	assert.ErrorContains(t, err, "expected type=value")
+
+	filters, err = ParsePruneFilters(nil)
+	assert.NilError(t, err)
+	assert.Assert(t, filters.Match(time.Now(), nil))
+}
@@ -28,6 +28,7 @@ import (
 	"github.com/containerd/containerd/v2/core/images"
 	"github.com/containerd/log"
 
+	"github.com/containerd/nerdctl/v2/pkg/filterutil"
 	"github.com/containerd/nerdctl/v2/pkg/referenceutil"
 )
 
@@ -52,7 +53,7 @@ type Filters struct {
 	Before    []string
 	Since     []string
 	Until     string
-	Labels    map[string]string
+	Labels    []filterutil.LabelFilter
 	Reference []string
 	Dangling  *bool
 }
@@ -61,59 +62,54 @@ type Filter func([]images.Image) ([]images.Image, error)
 
 // ParseFilters parse filter strings.
 func ParseFilters(filters []string) (*Filters, error) {
-	f := &Filters{Labels: make(map[string]string)}
+	f := &Filters{}
 	for _, filter := range filters {
-		tempFilterToken := strings.Split(filter, "=")
-		switch len(tempFilterToken) {
-		case 1:
+		typ, value, negate, err := filterutil.Split(filter)
+		if err != nil {
+			return nil, err
+		}
+		if negate && typ != FilterLabelType {
 			return nil, fmt.Errorf("invalid filter %q", filter)
-		case 2:
-			if tempFilterToken[0] == FilterDanglingType {
-				var isDangling bool
-				if tempFilterToken[1] == "true" {
-					isDangling = true
-				} else if tempFilterToken[1] == "false" {
-					isDangling = false
-				} else {
-					return nil, fmt.Errorf("invalid filter %q", filter)
-				}
-				f.Dangling = &isDangling
-			} else if tempFilterToken[0] == FilterBeforeType {
-				parsedReference, err := referenceutil.Parse(tempFilterToken[1])
-				if err != nil {
-					return nil, err
-				}
-
-				f.Before = append(f.Before, fmt.Sprintf("name==%s", parsedReference.String()))
-				f.Before = append(f.Before, fmt.Sprintf("name==%s", tempFilterToken[1]))
-			} else if tempFilterToken[0] == FilterSinceType {
-				parsedReference, err := referenceutil.Parse(tempFilterToken[1])
-				if err != nil {
-					return nil, err
-				}
-				f.Since = append(f.Since, fmt.Sprintf("name==%s", parsedReference.String()))
-				f.Since = append(f.Since, fmt.Sprintf("name==%s", tempFilterToken[1]))
-			} else if tempFilterToken[0] == FilterUntilType {
-				if len(tempFilterToken[0]) == 0 {
-					return nil, errNoUntilTimestamp
-				} else if len(f.Until) > 0 {
-					return nil, errMultipleUntilFilters
-				}
-				f.Until = tempFilterToken[1]
-			} else if tempFilterToken[0] == FilterLabelType {
-				// To support filtering labels by keys.
-				f.Labels[tempFilterToken[1]] = ""
-			} else if tempFilterToken[0] == FilterReferenceType {
-				f.Reference = append(f.Reference, tempFilterToken[1])
+		}
+		switch typ {
+		case FilterDanglingType:
+			var isDangling bool
+			if value == "true" {
+				isDangling = true
+			} else if value == "false" {
+				isDangling = false
 			} else {
 				return nil, fmt.Errorf("invalid filter %q", filter)
 			}
-		case 3:
-			if tempFilterToken[0] == FilterLabelType {
-				f.Labels[tempFilterToken[1]] = tempFilterToken[2]
-			} else {
-				return nil, fmt.Errorf("invalid filter %q", filter)
+			f.Dangling = &isDangling
+		case FilterBeforeType:
+			parsedReference, err := referenceutil.Parse(value)
+			if err != nil {
+				return nil, err
+			}
+			f.Before = append(f.Before, fmt.Sprintf("name==%s", parsedReference.String()))
+			f.Before = append(f.Before, fmt.Sprintf("name==%s", value))
+		case FilterSinceType:
+			parsedReference, err := referenceutil.Parse(value)
+			if err != nil {
+				return nil, err
 			}
+			f.Since = append(f.Since, fmt.Sprintf("name==%s", parsedReference.String()))
+			f.Since = append(f.Since, fmt.Sprintf("name==%s", value))
+		case FilterUntilType:
+			if len(value) == 0 {
+				return nil, errNoUntilTimestamp
+			} else if len(f.Until) > 0 {
+				return nil, errMultipleUntilFilters
+			}
+			f.Until = value
+		case FilterLabelType:
+			label := filterutil.ParseLabel(value, negate)
+			// `label=<key>=` selects the images with the label, whatever its value
+			label.HasValue = label.Value != ""
+			f.Labels = append(f.Labels, label)
+		case FilterReferenceType:
+			f.Reference = append(f.Reference, value)
 		default:
 			return nil, fmt.Errorf("invalid filter %q", filter)
 		}
@@ -197,40 +193,7 @@ func FilterUntil(until string) Filter {
 			return []images.Image{}, errNoUntilTimestamp
 		}
 
-		var (
-			parsedTime time.Time
-			err        error
-		)
-
-		type parseUntilFunc func(string) (time.Time, error)
-		parsingFuncs := []parseUntilFunc{
-			func(until string) (time.Time, error) {
-				return time.Parse(time.RFC3339, until)
-			},
-			func(until string) (time.Time, error) {
-				return time.Parse(time.RFC3339Nano, until)
-			},
-			func(until string) (time.Time, error) {
-				return time.Parse(time.DateOnly, until)
-			},
-			func(until string) (time.Time, error) {
-				// Go duration strings
-				d, err := time.ParseDuration(until)
-				if err != nil {
-					return time.Time{}, err
-				}
-				return time.Now().Add(-d), nil
-			},
-		}
-
-		for _, parse := range parsingFuncs {
-			parsedTime, err = parse(until)
-			if err != nil {
-				continue
-			}
-			break
-		}
-
+		parsedTime, err := filterutil.ParseUntil(until, time.Now())
 		if err != nil {
 			return []images.Image{}, errUnparsableUntilTimestamp
 		}
@@ -243,7 +206,7 @@ func FilterUntil(until string) Filter {
 
 // FilterByLabel filters an image list based on labels applied to the image's config specification for the platform.
 // Any matching label will include the image in the list.
-func FilterByLabel(ctx context.Context, client *containerd.Client, labels map[string]string) Filter {
+func FilterByLabel(ctx context.Context, client *containerd.Client, labels []filterutil.LabelFilter) Filter {
 	return func(imageList []images.Image) ([]images.Image, error) {
 		return filter(imageList, func(i images.Image) (bool, error) {
 			clientImage := containerd.NewImage(client, i)
@@ -308,16 +271,13 @@ func imageCreatedBefore(image images.Image, maxTime time.Time) bool {
 	return image.CreatedAt.Before(maxTime)
 }
 
-func matchesAllLabels(imageCfgLabels map[string]string, filterLabels map[string]string) bool {
-	var matches int
-	for lk, lv := range filterLabels {
-		if val, ok := imageCfgLabels[lk]; ok {
-			if val == lv || lv == "" {
-				matches++
-			}
+func matchesAllLabels(imageCfgLabels map[string]string, filterLabels []filterutil.LabelFilter) bool {
+	for _, l := range filterLabels {
+		if !l.Match(imageCfgLabels) {
+			return false
 		}
 	}
-	return matches == len(filterLabels)
+	return true
 }
 
 func matchesReferences(image images.Image, referencePatterns []string) (bool, error) {
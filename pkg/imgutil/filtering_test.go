@@ -23,6 +23,8 @@ import (
 	"gotest.tools/v3/assert"
 
 	"github.com/containerd/containerd/v2/core/images"
+
+	"github.com/containerd/nerdctl/v2/pkg/filterutil"
 )
 
 func TestApplyFilters(t *testing.T) {
@@ -444,43 +446,65 @@ func TestImageCreatedBetween(t *testing.T) {
 	}
 }
 
+func TestParseFiltersLabel(t *testing.T) {
+	f, err := ParseFilters([]string{"label=foo", "label=bar=", "label!=baz=qux"})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, f.Labels, []filterutil.LabelFilter{
+		{Key: "foo"},
+		{Key: "bar"},
+		{Key: "baz", Value: "qux", HasValue: true, Negate: true},
+	})
+}
+
 func TestMatchesAnyLabel(t *testing.T) {
 	tests := []struct {
 		name          string
 		imageLabels   map[string]string
-		labelsToMatch map[string]string
+		labelsToMatch []filterutil.LabelFilter
 		matches       bool
 	}{
 		{
 			name:          "ImageHasNoLabels",
 			imageLabels:   map[string]string{},
-			labelsToMatch: map[string]string{"foo": "bar"},
+			labelsToMatch: []filterutil.LabelFilter{{Key: "foo", Value: "bar", HasValue: true}},
 			matches:       false,
 		},
 		{
 			name:          "SingleMatchingLabel",
 			imageLabels:   map[string]string{"org": "com.example.nerdctl"},
-			labelsToMatch: map[string]string{"org": "com.example.nerdctl"},
+			labelsToMatch: []filterutil.LabelFilter{{Key: "org", Value: "com.example.nerdctl", HasValue: true}},
 			matches:       true,
 		},
 		{
 			name:          "KeyOnlyMatchingLabel",
 			imageLabels:   map[string]string{"org": "com.example.nerdctl"},
-			labelsToMatch: map[string]string{"org": ""},
+			labelsToMatch: []filterutil.LabelFilter{{Key: "org"}},
 			matches:       true,
 		},
 		{
 			name:          "KeyValueDoesNotMatch",
 			imageLabels:   map[string]string{"org": "com.example.nerdctl"},
-			labelsToMatch: map[string]string{"org": "com.example.containerd"},
+			labelsToMatch: []filterutil.LabelFilter{{Key: "org", Value: "com.example.containerd", HasValue: true}},
 			matches:       false,
 		},
 		{
 			name:          "AllMatchingLabel",
 			imageLabels:   map[string]string{"org": "com.example.nerdctl", "foo": "bar"},
-			labelsToMatch: map[string]string{"org": "com.example.containerd", "foo": "bar"},
+			labelsToMatch: []filterutil.LabelFilter{{Key: "org", Value: "com.example.containerd", HasValue: true}, {Key: "foo", Value: "bar", HasValue: true}},
 			matches:       false,
 		},
+		{
+			name:          "NegatedLabel",
+			imageLabels:   map[string]string{"org": "com.example.nerdctl"},
+			labelsToMatch: []filterutil.LabelFilter{{Key: "org", Negate: true}},
+			matches:       false,
+		},
+		{
+			name:          "NegatedMissingLabel",
+			imageLabels:   map[string]string{"org": "com.example.nerdctl"},
+			labelsToMatch: []filterutil.LabelFilter{{Key: "foo", Value: "bar", HasValue: true, Negate: true}},
+			matches:       true,
+		},
 	}
 
 	for _, test := range tests {
@@ -27,9 +27,11 @@ import (
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/docker/docker/pkg/sysinfo"
+	bkclient "github.com/moby/buildkit/client"
 
 	containerd "github.com/containerd/containerd/v2/client"
 	"github.com/containerd/containerd/v2/core/introspection"
+	"github.com/containerd/containerd/v2/pkg/namespaces"
 	ptypes "github.com/containerd/containerd/v2/pkg/protobuf/types"
 	"github.com/containerd/log"
 
@@ -121,9 +123,6 @@ func ClientVersion() dockercompat.ClientVersion {
 		GoVersion: runtime.Version(),
 		Os:        runtime.GOOS,
 		Arch:      runtime.GOARCH,
-		Components: []dockercompat.ComponentVersion{
-			buildctlVersion(),
-		},
 	}
 }
 
@@ -143,6 +142,9 @@ func ServerVersion(ctx context.Context, client *containerd.Client) (*dockercompa
 			runcVersion(),
 		},
 	}
+	if bv, ok := buildkitVersion(ctx); ok {
+		v.Components = append(v.Components, bv)
+	}
 	return v, nil
 }
 
@@ -158,49 +160,42 @@ func ServerSemVer(ctx context.Context, client *containerd.Client) (*semver.Versi
 	return sv, nil
 }
 
-func buildctlVersion() dockercompat.ComponentVersion {
-	buildctlBinary, err := buildkitutil.BuildctlBinary()
+// buildkitVersion returns the version of the BuildKit daemon of the namespace of ctx.
+// It returns false when the daemon is not running.
+func buildkitVersion(ctx context.Context) (dockercompat.ComponentVersion, bool) {
+	ns, err := namespaces.NamespaceRequired(ctx)
 	if err != nil {
-		log.L.WithError(err).Warnf("unable to determine buildctl version")
-		return dockercompat.ComponentVersion{Name: "buildctl"}
+		log.G(ctx).WithError(err).Warn("unable to determine buildkitd version")
+		return dockercompat.ComponentVersion{}, false
 	}
-
-	stdout, err := exec.Command(buildctlBinary, "--version").Output()
+	buildkitHost, err := buildkitutil.GetBuildkitHost(ns)
 	if err != nil {
-		log.L.WithError(err).Warnf("unable to determine buildctl version")
-		return dockercompat.ComponentVersion{Name: "buildctl"}
+		log.G(ctx).WithError(err).Debug("unable to determine buildkitd version")
+		return dockercompat.ComponentVersion{}, false
 	}
-
-	v, err := parseBuildctlVersion(stdout)
+	c, err := buildkitutil.NewClient(ctx, buildkitHost)
 	if err != nil {
-		log.L.Warn(err)
-		return dockercompat.ComponentVersion{Name: "buildctl"}
+		log.G(ctx).WithError(err).Warn("unable to determine buildkitd version")
+		return dockercompat.ComponentVersion{}, false
 	}
-	return *v
+	defer c.Close()
+	info, err := c.Info(ctx)
+	if err != nil {
+		log.G(ctx).WithError(err).Warn("unable to determine buildkitd version")
+		return dockercompat.ComponentVersion{}, false
+	}
+	return buildkitComponentVersion(info.BuildkitVersion), true
 }
 
-func parseBuildctlVersion(buildctlVersionStdout []byte) (*dockercompat.ComponentVersion, error) {
-	fields := strings.Fields(strings.TrimSpace(string(buildctlVersionStdout)))
-	var v *dockercompat.ComponentVersion
-	switch len(fields) {
-	case 4:
-		v = &dockercompat.ComponentVersion{
-			Name:    fields[0],
-			Version: fields[2],
-			Details: map[string]string{"GitCommit": fields[3]},
-		}
-	case 3:
-		v = &dockercompat.ComponentVersion{
-			Name:    fields[0],
-			Version: fields[2],
-		}
-	default:
-		return nil, fmt.Errorf("unable to determine buildctl version, got %q", string(buildctlVersionStdout))
+func buildkitComponentVersion(v bkclient.BuildkitVersion) dockercompat.ComponentVersion {
+	cv := dockercompat.ComponentVersion{
+		Name:    "buildkitd",
+		Version: v.Version,
 	}
-	if v.Name != "buildctl" {
-		return nil, fmt.Errorf("unable to determine buildctl version, got %q", string(buildctlVersionStdout))
+	if v.Revision != "" {
+		cv.Details = map[string]string{"GitCommit": v.Revision}
 	}
-	return v, nil
+	return cv
 }
 
 func runcVersion() dockercompat.ComponentVersion {
@@ -19,43 +19,24 @@ package infoutil
 import (
 	"testing"
 
+	bkclient "github.com/moby/buildkit/client"
 	"gotest.tools/v3/assert"
 
 	"github.com/containerd/nerdctl/v2/pkg/inspecttypes/dockercompat"
 )
 
-func TestParseBuildctlVersion(t *testing.T) {
-	testCases := map[string]*dockercompat.ComponentVersion{
-		"buildctl github.com/moby/buildkit v0.10.3 c8d25d9a103b70dc300a4fd55e7e576472284e31": {
-			Name:    "buildctl",
-			Version: "v0.10.3",
-			Details: map[string]string{
-				"GitCommit": "c8d25d9a103b70dc300a4fd55e7e576472284e31",
-			},
-		},
-		"buildctl github.com/moby/buildkit v0.10.0-380-g874eef9b 874eef9b70dbaf4f074d2bc8f4dc64237f8e83a0": {
-			Name:    "buildctl",
-			Version: "v0.10.0-380-g874eef9b",
-			Details: map[string]string{
-				"GitCommit": "874eef9b70dbaf4f074d2bc8f4dc64237f8e83a0",
-			},
-		},
-		"buildctl github.com/moby/buildkit 0.0.0+unknown": {
-			Name:    "buildctl",
-			Version: "0.0.0+unknown",
-		},
-		"foo bar baz": nil,
-	}
-
-	for s, expected := range testCases {
-		got, err := parseBuildctlVersion([]byte(s))
-		if expected != nil {
-			assert.NilError(t, err)
-			assert.DeepEqual(t, expected, got)
-		} else {
-			assert.Assert(t, err != nil)
-		}
-	}
+func TestBuildkitComponentVersion(t *testing.T) {
+	assert.DeepEqual(t, buildkitComponentVersion(bkclient.BuildkitVersion{
+		Package:  "github.com/moby/buildkit",
+		Version:  "v0.23.2",
+		Revision: "40b2ad8bc1e5a8e8e5d2a5c6a4d2e0a8b2b0a6e4",
+	}), dockercompat.ComponentVersion{
+		Name:    "buildkitd",
+		Version: "v0.23.2",
+		Details: map[string]string{"GitCommit": "40b2ad8bc1e5a8e8e5d2a5c6a4d2e0a8b2b0a6e4"},
+	})
+	assert.DeepEqual(t, buildkitComponentVersion(bkclient.BuildkitVersion{Version: "v0.23.2"}),
+		dockercompat.ComponentVersion{Name: "buildkitd", Version: "v0.23.2"})
 }
 
 func TestParseRuncVersion(t *testing.T) {
@@ -27,6 +27,7 @@ package dockercompat
 import (
 	"encoding/json"
 	"fmt"
+	"maps"
 	"net"
 	"os"
 	"path/filepath"
@@ -143,8 +144,8 @@ type HostConfig struct {
 	ContainerIDFile string          // File (path) where the containerId is written
 	LogConfig       loggerLogConfig // Configuration of the logs for this container
 	// NetworkMode     NetworkMode   // Network mode to use for the container
-	PortBindings nat.PortMap // Port mapping between the exposed port (container) and the host
-	// RestartPolicy   RestartPolicy // Restart policy to be used for the container
+	PortBindings  nat.PortMap   // Port mapping between the exposed port (container) and the host
+	RestartPolicy RestartPolicy // Restart policy to be used for the container
 	// AutoRemove      bool          // Automatically remove container when it exits
 	// VolumeDriver    string        // Name of the volume driver used to mount volumes
 	// VolumesFrom     []string      // List of volumes to take from other container
@@ -181,6 +182,7 @@ type HostConfig struct {
 	Memory             int64             // Memory limit (in bytes)
 	MemorySwap         int64             // Total memory usage (memory + swap); set `-1` to enable unlimited swap
 	OomKillDisable     bool              // specifies whether to disable OOM Killer
+	PidsLimit          int64             // Setting PIDs limit for a container; Set `0` or `-1` for unlimited
 	Devices            []DeviceMapping   // List of devices to map inside the container
 	LinuxBlkioSettings
 }
@@ -256,6 +258,12 @@ type DNSSettings struct {
 	DNSSearchDomains     []string
 }
 
+// RestartPolicy represents the restart policy of a container.
+type RestartPolicy struct {
+	Name              string
+	MaximumRetryCount int
+}
+
 type HostConfigLabel struct {
 	BlkioWeight uint16
 	CidFile     string
@@ -507,6 +515,8 @@ func ContainerFromNative(n *native.Container) (*Container, error) {
 	}
 
 	c.HostConfig.OomKillDisable = memorySettings.DisableOOMKiller
+	c.HostConfig.PidsLimit = getPidsLimitFromNative(n.Spec.(*specs.Spec))
+	c.HostConfig.RestartPolicy = getRestartPolicyFromNative(n.Labels)
 	c.HostConfig.Memory = memorySettings.Limit
 	c.HostConfig.MemorySwap = memorySettings.Swap
 
@@ -661,6 +671,15 @@ func ImageFromNative(nativeImage *native.Image) (*Image, error) {
 		image.Config.Healthcheck = healthCheckConfig
 	}
 
+	// The build history reference is stored as a containerd image label, not in the image config.
+	if buildRef, ok := nativeImage.Image.Labels[labels.BuildRef]; ok && buildRef != "" {
+		image.Config.Labels = maps.Clone(image.Config.Labels)
+		if image.Config.Labels == nil {
+			image.Config.Labels = make(map[string]string)
+		}
+		image.Config.Labels[labels.BuildRef] = buildRef
+	}
+
 	return image, nil
 }
 
@@ -863,6 +882,25 @@ func getMemorySettingsFromNative(sp *specs.Spec) (*MemorySetting, error) {
 	return res, nil
 }
 
+func getPidsLimitFromNative(sp *specs.Spec) int64 {
+	if sp.Linux != nil && sp.Linux.Resources != nil && sp.Linux.Resources.Pids != nil {
+		return sp.Linux.Resources.Pids.Limit
+	}
+	return 0
+}
+
+func getRestartPolicyFromNative(lbls map[string]string) RestartPolicy {
+	res := RestartPolicy{Name: "no"}
+	// An empty policy label means "always" for containerd, but the label is not set without a policy
+	if v, ok := lbls[restart.PolicyLabel]; ok {
+		if policy, err := restart.NewPolicy(v); err == nil {
+			res.Name = policy.Name()
+			res.MaximumRetryCount = policy.MaximumRetryCount()
+		}
+	}
+	return res
+}
+
 func getDNSFromNative(lbls map[string]string) (*DNSSettings, error) {
 	res := &DNSSettings{}
 
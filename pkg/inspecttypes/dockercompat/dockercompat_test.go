@@ -99,8 +99,9 @@ func TestContainerFromNative(t *testing.T) {
 					FinishedAt: "",
 				},
 				HostConfig: &HostConfig{
-					PortBindings: nat.PortMap{},
-					GroupAdd:     []string{},
+					PortBindings:  nat.PortMap{},
+					RestartPolicy: RestartPolicy{Name: "no"},
+					GroupAdd:      []string{},
 					LogConfig: loggerLogConfig{
 						Driver: "json-file",
 						Opts:   map[string]string{},
@@ -195,8 +196,9 @@ func TestContainerFromNative(t *testing.T) {
 					FinishedAt: "",
 				},
 				HostConfig: &HostConfig{
-					PortBindings: nat.PortMap{},
-					GroupAdd:     []string{},
+					PortBindings:  nat.PortMap{},
+					RestartPolicy: RestartPolicy{Name: "no"},
+					GroupAdd:      []string{},
 					LogConfig: loggerLogConfig{
 						Driver: "json-file",
 						Opts:   map[string]string{},
@@ -286,8 +288,9 @@ func TestContainerFromNative(t *testing.T) {
 					FinishedAt: "",
 				},
 				HostConfig: &HostConfig{
-					PortBindings: nat.PortMap{},
-					GroupAdd:     []string{},
+					PortBindings:  nat.PortMap{},
+					RestartPolicy: RestartPolicy{Name: "no"},
+					GroupAdd:      []string{},
 					LogConfig: loggerLogConfig{
 						Driver: "json-file",
 						Opts:   map[string]string{},
@@ -344,6 +347,7 @@ func TestContainerFromNative(t *testing.T) {
 				HostConfig: &HostConfig{
 					LogConfig:          loggerLogConfig{Driver: "json-file", Opts: map[string]string{}},
 					PortBindings:       nat.PortMap{},
+					RestartPolicy:      RestartPolicy{Name: "no"},
 					GroupAdd:           []string{},
 					Tmpfs:              map[string]string{},
 					UTSMode:            "host",
@@ -627,6 +631,26 @@ func TestImageFromNative(t *testing.T) {
 		assert.Equal(t, out.Created, createdTime.Format(time.RFC3339Nano))
 	})
 
+	t.Run("surfaces the build ref image label", func(t *testing.T) {
+		configLabels := map[string]string{"foo": "bar"}
+		img := native.Image{
+			Image: images.Image{
+				Name:   "docker.io/library/alpine:latest",
+				Labels: map[string]string{labels.BuildRef: "abcdef"},
+			},
+			ImageConfig: ocispec.Image{
+				Config: ocispec.ImageConfig{
+					Labels: configLabels,
+				},
+			},
+		}
+
+		out, err := ImageFromNative(&img)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, out.Config.Labels, map[string]string{"foo": "bar", labels.BuildRef: "abcdef"})
+		assert.DeepEqual(t, configLabels, map[string]string{"foo": "bar"})
+	})
+
 	t.Run("parses Healthcheck label", func(t *testing.T) {
 		testcases := []struct {
 			name     string
@@ -16,10 +16,13 @@
 
 package native
 
+import "time"
+
 // Volume is also compatible with Docker
 type Volume struct {
 	Name       string             `json:"Name"`
 	Mountpoint string             `json:"Mountpoint"`
 	Labels     *map[string]string `json:"Labels,omitempty"`
 	Size       int64              `json:"Size,omitempty"`
+	CreatedAt  time.Time          `json:"CreatedAt,omitzero"`
 }
@@ -0,0 +1,395 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package kube implements the subset of the Kubernetes API objects used by `nerdctl kube`.
+//
+// Only the fields that can be mapped to nerdctl containers are defined; unknown fields are ignored when decoding.
+package kube
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+const (
+	KindPod        = "Pod"
+	KindDeployment = "Deployment"
+	KindConfigMap  = "ConfigMap"
+)
+
+type TypeMeta struct {
+	APIVersion string `yaml:"apiVersion"`
+	Kind       string `yaml:"kind"`
+}
+
+type ObjectMeta struct {
+	Name        string            `yaml:"name"`
+	Namespace   string            `yaml:"namespace,omitempty"`
+	Labels      map[string]string `yaml:"labels,omitempty"`
+	Annotations map[string]string `yaml:"annotations,omitempty"`
+}
+
+type Pod struct {
+	TypeMeta `yaml:",inline"`
+	Metadata ObjectMeta `yaml:"metadata"`
+	Spec     PodSpec    `yaml:"spec"`
+}
+
+type Deployment struct {
+	TypeMeta `yaml:",inline"`
+	Metadata ObjectMeta     `yaml:"metadata"`
+	Spec     DeploymentSpec `yaml:"spec"`
+}
+
+type DeploymentSpec struct {
+	Replicas *int32          `yaml:"replicas,omitempty"`
+	Selector *LabelSelector  `yaml:"selector,omitempty"`
+	Template PodTemplateSpec `yaml:"template"`
+}
+
+type LabelSelector struct {
+	MatchLabels map[string]string `yaml:"matchLabels,omitempty"`
+}
+
+type PodTemplateSpec struct {
+	Metadata ObjectMeta `yaml:"metadata"`
+	Spec     PodSpec    `yaml:"spec"`
+}
+
+type ConfigMap struct {
+	TypeMeta `yaml:",inline"`
+	Metadata ObjectMeta        `yaml:"metadata"`
+	Data     map[string]string `yaml:"data,omitempty"`
+}
+
+type PodSpec struct {
+	Containers            []Container   `yaml:"containers"`
+	InitContainers        []Container   `yaml:"initContainers,omitempty"`
+	Volumes               []Volume      `yaml:"volumes,omitempty"`
+	RestartPolicy         string        `yaml:"restartPolicy,omitempty"`
+	Hostname              string        `yaml:"hostname,omitempty"`
+	HostNetwork           bool          `yaml:"hostNetwork,omitempty"`
+	ShareProcessNamespace *bool         `yaml:"shareProcessNamespace,omitempty"`
+	DNSConfig             *PodDNSConfig `yaml:"dnsConfig,omitempty"`
+	HostAliases           []HostAlias   `yaml:"hostAliases,omitempty"`
+}
+
+type PodDNSConfig struct {
+	Nameservers []string             `yaml:"nameservers,omitempty"`
+	Searches    []string             `yaml:"searches,omitempty"`
+	Options     []PodDNSConfigOption `yaml:"options,omitempty"`
+}
+
+type PodDNSConfigOption struct {
+	Name  string  `yaml:"name"`
+	Value *string `yaml:"value,omitempty"`
+}
+
+type HostAlias struct {
+	IP        string   `yaml:"ip"`
+	Hostnames []string `yaml:"hostnames"`
+}
+
+type Container struct {
+	Name            string                `yaml:"name"`
+	Image           string                `yaml:"image"`
+	Command         []string              `yaml:"command,omitempty"`
+	Args            []string              `yaml:"args,omitempty"`
+	WorkingDir      string                `yaml:"workingDir,omitempty"`
+	Ports           []ContainerPort       `yaml:"ports,omitempty"`
+	Env             []EnvVar              `yaml:"env,omitempty"`
+	EnvFrom         []EnvFromSource       `yaml:"envFrom,omitempty"`
+	Resources       *ResourceRequirements `yaml:"resources,omitempty"`
+	VolumeMounts    []VolumeMount         `yaml:"volumeMounts,omitempty"`
+	ImagePullPolicy string                `yaml:"imagePullPolicy,omitempty"`
+	SecurityContext *SecurityContext      `yaml:"securityContext,omitempty"`
+	Stdin           bool                  `yaml:"stdin,omitempty"`
+	TTY             bool                  `yaml:"tty,omitempty"`
+}
+
+type ContainerPort struct {
+	Name          string `yaml:"name,omitempty"`
+	ContainerPort int32  `yaml:"containerPort"`
+	HostPort      int32  `yaml:"hostPort,omitempty"`
+	HostIP        string `yaml:"hostIP,omitempty"`
+	Protocol      string `yaml:"protocol,omitempty"`
+}
+
+type EnvVar struct {
+	Name      string        `yaml:"name"`
+	Value     string        `yaml:"value,omitempty"`
+	ValueFrom *EnvVarSource `yaml:"valueFrom,omitempty"`
+}
+
+type EnvVarSource struct {
+	ConfigMapKeyRef *ConfigMapKeySelector `yaml:"configMapKeyRef,omitempty"`
+}
+
+type ConfigMapKeySelector struct {
+	Name     string `yaml:"name"`
+	Key      string `yaml:"key"`
+	Optional *bool  `yaml:"optional,omitempty"`
+}
+
+type EnvFromSource struct {
+	Prefix       string              `yaml:"prefix,omitempty"`
+	ConfigMapRef *ConfigMapEnvSource `yaml:"configMapRef,omitempty"`
+}
+
+type ConfigMapEnvSource struct {
+	Name     string `yaml:"name"`
+	Optional *bool  `yaml:"optional,omitempty"`
+}
+
+type ResourceRequirements struct {
+	Limits   map[string]string `yaml:"limits,omitempty"`
+	Requests map[string]string `yaml:"requests,omitempty"`
+}
+
+type VolumeMount struct {
+	Name      string `yaml:"name"`
+	MountPath string `yaml:"mountPath"`
+	SubPath   string `yaml:"subPath,omitempty"`
+	ReadOnly  bool   `yaml:"readOnly,omitempty"`
+}
+
+type SecurityContext struct {
+	Privileged             *bool         `yaml:"privileged,omitempty"`
+	RunAsUser              *int64        `yaml:"runAsUser,omitempty"`
+	RunAsGroup             *int64        `yaml:"runAsGroup,omitempty"`
+	ReadOnlyRootFilesystem *bool         `yaml:"readOnlyRootFilesystem,omitempty"`
+	Capabilities           *Capabilities `yaml:"capabilities,omitempty"`
+}
+
+type Capabilities struct {
+	Add  []string `yaml:"add,omitempty"`
+	Drop []string `yaml:"drop,omitempty"`
+}
+
+type Volume struct {
+	Name                  string                             `yaml:"name"`
+	EmptyDir              *EmptyDirVolumeSource              `yaml:"emptyDir,omitempty"`
+	HostPath              *HostPathVolumeSource              `yaml:"hostPath,omitempty"`
+	PersistentVolumeClaim *PersistentVolumeClaimVolumeSource `yaml:"persistentVolumeClaim,omitempty"`
+	ConfigMap             *ConfigMapVolumeSource             `yaml:"configMap,omitempty"`
+}
+
+type EmptyDirVolumeSource struct {
+	Medium    string `yaml:"medium,omitempty"`
+	SizeLimit string `yaml:"sizeLimit,omitempty"`
+}
+
+type HostPathVolumeSource struct {
+	Path string `yaml:"path"`
+	Type string `yaml:"type,omitempty"`
+}
+
+type PersistentVolumeClaimVolumeSource struct {
+	ClaimName string `yaml:"claimName"`
+	ReadOnly  bool   `yaml:"readOnly,omitempty"`
+}
+
+type ConfigMapVolumeSource struct {
+	Name     string      `yaml:"name"`
+	Items    []KeyToPath `yaml:"items,omitempty"`
+	Optional *bool       `yaml:"optional,omitempty"`
+}
+
+type KeyToPath struct {
+	Key  string `yaml:"key"`
+	Path string `yaml:"path"`
+}
+
+// Manifest is the set of objects of a YAML file.
+type Manifest struct {
+	// Pods contains the pods, and the pods templated by the deployments, named after the deployments.
+	Pods       []Pod
+	ConfigMaps map[string]ConfigMap
+}
+
+// Decode decodes the objects of a multi-document YAML stream.
+// The objects of unsupported kinds are returned as warnings.
+func Decode(r io.Reader) (*Manifest, []string, error) {
+	m := &Manifest{ConfigMaps: map[string]ConfigMap{}}
+	var warnings []string
+	dec := yaml.NewDecoder(r)
+	for {
+		var node yaml.Node
+		if err := dec.Decode(&node); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, nil, err
+		}
+		var meta TypeMeta
+		if err := node.Decode(&meta); err != nil {
+			return nil, nil, err
+		}
+		switch meta.Kind {
+		case "":
+			// empty document
+			if len(node.Content) == 0 || node.Content[0].Tag == "!!null" {
+				continue
+			}
+			return nil, nil, errors.New("object without kind")
+		case KindPod:
+			var pod Pod
+			if err := node.Decode(&pod); err != nil {
+				return nil, nil, fmt.Errorf("failed to decode pod: %w", err)
+			}
+			m.Pods = append(m.Pods, pod)
+		case KindDeployment:
+			var d Deployment
+			if err := node.Decode(&d); err != nil {
+				return nil, nil, fmt.Errorf("failed to decode deployment: %w", err)
+			}
+			if d.Spec.Replicas != nil && *d.Spec.Replicas > 1 {
+				warnings = append(warnings, fmt.Sprintf("deployment %s: only one replica is created", d.Metadata.Name))
+			}
+			labels := d.Spec.Template.Metadata.Labels
+			if labels == nil {
+				labels = d.Metadata.Labels
+			}
+			m.Pods = append(m.Pods, Pod{
+				TypeMeta: TypeMeta{APIVersion: "v1", Kind: KindPod},
+				Metadata: ObjectMeta{Name: d.Metadata.Name, Namespace: d.Metadata.Namespace, Labels: labels},
+				Spec:     d.Spec.Template.Spec,
+			})
+		case KindConfigMap:
+			var cm ConfigMap
+			if err := node.Decode(&cm); err != nil {
+				return nil, nil, fmt.Errorf("failed to decode configmap: %w", err)
+			}
+			m.ConfigMaps[cm.Metadata.Name] = cm
+		default:
+			warnings = append(warnings, fmt.Sprintf("unsupported kind %q, ignoring it", meta.Kind))
+		}
+	}
+	for _, pod := range m.Pods {
+		if pod.Metadata.Name == "" {
+			return nil, nil, errors.New("pod without name")
+		}
+		if len(pod.Spec.Containers) == 0 {
+			return nil, nil, fmt.Errorf("pod %s has no containers", pod.Metadata.Name)
+		}
+	}
+	return m, warnings, nil
+}
+
+// Encode encodes objects as a multi-document YAML stream.
+func Encode(w io.Writer, objects ...any) error {
+	var buf bytes.Buffer
+	for i, o := range objects {
+		if i > 0 {
+			buf.WriteString("---\n")
+		}
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(o); err != nil {
+			return err
+		}
+		if err := enc.Close(); err != nil {
+			return err
+		}
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// ParseCPU parses a CPU quantity (e.g. "500m", "1.5") as a number of CPUs.
+func ParseCPU(q string) (float64, error) {
+	if s, ok := strings.CutSuffix(q, "m"); ok {
+		m, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || m < 0 {
+			return 0, fmt.Errorf("invalid CPU quantity %q", q)
+		}
+		return float64(m) / 1000, nil
+	}
+	f, err := strconv.ParseFloat(q, 64)
+	if err != nil || f < 0 {
+		return 0, fmt.Errorf("invalid CPU quantity %q", q)
+	}
+	return f, nil
+}
+
+// FormatCPU formats a number of CPUs as a CPU quantity, in millicores.
+func FormatCPU(cpus float64) string {
+	return strconv.FormatInt(int64(math.Round(cpus*1000)), 10) + "m"
+}
+
+var memorySuffixes = []struct {
+	suffix string
+	factor float64
+}{
+	// binary suffixes first, as "Mi" would also match "M"
+	{"Ki", 1 << 10}, {"Mi", 1 << 20}, {"Gi", 1 << 30}, {"Ti", 1 << 40}, {"Pi", 1 << 50}, {"Ei", 1 << 60},
+	{"k", 1e3}, {"M", 1e6}, {"G", 1e9}, {"T", 1e12}, {"P", 1e15}, {"E", 1e18},
+}
+
+// ParseMemory parses a memory quantity (e.g. "128Mi", "1G", "1e9") as a number of bytes.
+func ParseMemory(q string) (int64, error) {
+	factor := 1.0
+	s := q
+	for _, m := range memorySuffixes {
+		if v, ok := strings.CutSuffix(q, m.suffix); ok {
+			s, factor = v, m.factor
+			break
+		}
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || f < 0 {
+		return 0, fmt.Errorf("invalid memory quantity %q", q)
+	}
+	return int64(f * factor), nil
+}
+
+// FormatMemory formats a number of bytes as a memory quantity, using the largest exact binary suffix.
+func FormatMemory(bytes int64) string {
+	for i := len(memorySuffixes[:6]) - 1; i >= 0; i-- {
+		m := memorySuffixes[i]
+		if f := int64(m.factor); bytes >= f && bytes%f == 0 {
+			return strconv.FormatInt(bytes/f, 10) + m.suffix
+		}
+	}
+	return strconv.FormatInt(bytes, 10)
+}
+
+// SanitizeName converts a name into a valid Kubernetes object name (RFC 1123 label).
+func SanitizeName(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	s := strings.TrimRight(b.String(), "-")
+	if len(s) > 63 {
+		s = strings.TrimRight(s[:63], "-")
+	}
+	return s
+}
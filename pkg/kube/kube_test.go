@@ -0,0 +1,114 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package kube
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestDecode(t *testing.T) {
+	const manifest = `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+data:
+  key: value
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 3
+  template:
+    metadata:
+      labels:
+        app: web
+    spec:
+      containers:
+      - name: nginx
+        image: nginx:alpine
+        ports:
+        - containerPort: 80
+          hostPort: 8080
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+---
+`
+	m, warnings, err := Decode(strings.NewReader(manifest))
+	assert.NilError(t, err)
+	assert.Equal(t, len(warnings), 2)
+	assert.Equal(t, len(m.Pods), 1)
+	assert.Equal(t, m.Pods[0].Metadata.Name, "web")
+	assert.Equal(t, m.Pods[0].Metadata.Labels["app"], "web")
+	assert.Equal(t, m.Pods[0].Spec.Containers[0].Ports[0].HostPort, int32(8080))
+	assert.Equal(t, m.ConfigMaps["config"].Data["key"], "value")
+
+	_, _, err = Decode(strings.NewReader("apiVersion: v1\nkind: Pod\nmetadata:\n  name: empty\n"))
+	assert.ErrorContains(t, err, "has no containers")
+}
+
+func TestEncodeDecode(t *testing.T) {
+	pod := Pod{
+		TypeMeta: TypeMeta{APIVersion: "v1", Kind: KindPod},
+		Metadata: ObjectMeta{Name: "foo"},
+		Spec: PodSpec{
+			Containers: []Container{{Name: "bar", Image: "alpine", Command: []string{"sleep", "infinity"}}},
+		},
+	}
+	var buf bytes.Buffer
+	assert.NilError(t, Encode(&buf, pod, pod))
+	m, warnings, err := Decode(&buf)
+	assert.NilError(t, err)
+	assert.Equal(t, len(warnings), 0)
+	assert.DeepEqual(t, m.Pods, []Pod{pod, pod})
+}
+
+func TestQuantities(t *testing.T) {
+	for q, expected := range map[string]float64{"500m": 0.5, "2": 2, "1.5": 1.5} {
+		cpus, err := ParseCPU(q)
+		assert.NilError(t, err)
+		assert.Equal(t, cpus, expected)
+	}
+	_, err := ParseCPU("foo")
+	assert.ErrorContains(t, err, "invalid CPU quantity")
+	assert.Equal(t, FormatCPU(0.25), "250m")
+
+	for q, expected := range map[string]int64{"128Mi": 128 << 20, "1G": 1e9, "1024": 1024, "1.5Ki": 1536} {
+		bytes, err := ParseMemory(q)
+		assert.NilError(t, err)
+		assert.Equal(t, bytes, expected)
+	}
+	_, err = ParseMemory("1X")
+	assert.ErrorContains(t, err, "invalid memory quantity")
+	assert.Equal(t, FormatMemory(128<<20), "128Mi")
+	assert.Equal(t, FormatMemory(1000), "1000")
+}
+
+func TestSanitizeName(t *testing.T) {
+	assert.Equal(t, SanitizeName("My_Container.1"), "my-container-1")
+	assert.Equal(t, SanitizeName("/data/dir/"), "data-dir")
+	assert.Equal(t, len(SanitizeName(strings.Repeat("a", 100))), 63)
+}
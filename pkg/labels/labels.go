@@ -35,6 +35,12 @@ const (
 	//Compose Service Name
 	ComposeService = "com.docker.compose.service"
 
+	// ComposeWorkingDir is the working directory of the compose project
+	ComposeWorkingDir = "com.docker.compose.project.working_dir"
+
+	// ComposeConfigFiles is the comma-separated list of the compose files of the project
+	ComposeConfigFiles = "com.docker.compose.project.config_files"
+
 	//Compose Network Name
 	ComposeNetwork = "com.docker.compose.network"
 
@@ -125,4 +131,18 @@ const (
 
 	// HealthState stores the current health state (status and failing streak).
 	HealthState = Prefix + "healthstate"
+
+	// Pod is the name of the pod (`nerdctl pod`) that the container belongs to.
+	Pod = Prefix + "pod"
+
+	// PodInfra is "true" for the infra container of a pod, which owns the namespaces shared by the pod.
+	PodInfra = Prefix + "pod-infra"
+
+	// PodShare is the comma-separated list of the namespaces shared by a pod, e.g. "net,ipc".
+	// It is set on the infra container.
+	PodShare = Prefix + "pod-share"
+
+	// BuildRef is the ref of the build that produced an image (see `nerdctl builder history`).
+	// It is set on the containerd image, not in the image config, so it does not change the image digest.
+	BuildRef = Prefix + "build-ref"
 )
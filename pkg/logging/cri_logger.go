@@ -78,6 +78,9 @@ func viewLogsCRI(lvopts LogViewOptions, stdout, stderr io.Writer, stopChannel ch
 	if lvopts.LogPath == "" {
 		return fmt.Errorf("logpath is nil ")
 	}
+	if lvopts.Filter != nil {
+		log.L.Warnf("unsupported Grep, Level and Format options for cri driver")
+	}
 
 	return ReadLogs(&lvopts, stdout, stderr, stopChannel)
 }
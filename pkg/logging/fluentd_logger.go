@@ -32,6 +32,7 @@ import (
 	"github.com/containerd/containerd/v2/core/runtime/v2/logging"
 	"github.com/containerd/log"
 
+	"github.com/containerd/nerdctl/v2/pkg/logging/message"
 	"github.com/containerd/nerdctl/v2/pkg/strutil"
 )
 
@@ -117,19 +118,26 @@ func (f *FluentdLogger) PreProcess(_ context.Context, _ string, config *logging.
 	f.config = config
 	return nil
 }
-func (f *FluentdLogger) Process(stdout <-chan string, stderr <-chan string) error {
+func (f *FluentdLogger) Process(stdout <-chan *message.Message, stderr <-chan *message.Message) error {
 	var wg sync.WaitGroup
 	wg.Add(2)
-	fun := func(wg *sync.WaitGroup, dataChan <-chan string, id, namespace, source string) {
+	fun := func(wg *sync.WaitGroup, dataChan <-chan *message.Message, id, namespace, source string) {
 		defer wg.Done()
-		metaData := map[string]string{
-			"container_id": id,
-			"namespace":    namespace,
-			"source":       source,
-		}
-		for log := range dataChan {
-			metaData["log"] = log
-			f.fluentClient.PostWithTime(f.Opts[Tag], time.Now(), metaData)
+		for m := range dataChan {
+			metaData := map[string]string{
+				"container_id": id,
+				"namespace":    namespace,
+				"source":       source,
+				"log":          m.Line,
+			}
+			// the field names correspond to Docker
+			if m.Partial != nil {
+				metaData["partial_message"] = "true"
+				metaData["partial_id"] = m.Partial.ID
+				metaData["partial_ordinal"] = strconv.Itoa(m.Partial.Ordinal)
+				metaData["partial_last"] = strconv.FormatBool(m.Partial.Last)
+			}
+			f.fluentClient.PostWithTime(f.Opts[Tag], m.Time, metaData)
 		}
 	}
 	go fun(&wg, stdout, f.config.ID, f.config.Namespace, "stdout")
@@ -0,0 +1,382 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package logging
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/containerd/containerd/v2/core/runtime/v2/logging"
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/logging/message"
+	"github.com/containerd/nerdctl/v2/pkg/strutil"
+)
+
+const (
+	gelfAddress           = "gelf-address"
+	gelfCompressionType   = "gelf-compression-type"
+	gelfCompressionLevel  = "gelf-compression-level"
+	gelfTCPMaxReconnect   = "gelf-tcp-max-reconnect"
+	gelfTCPReconnectDelay = "gelf-tcp-reconnect-delay"
+)
+
+var GelfLogOpts = []string{
+	gelfAddress,
+	gelfCompressionType,
+	gelfCompressionLevel,
+	gelfTCPMaxReconnect,
+	gelfTCPReconnectDelay,
+	Tag,
+	Labels,
+	Env,
+}
+
+const (
+	gelfCompressionGzip = "gzip"
+	gelfCompressionZlib = "zlib"
+	gelfCompressionNone = "none"
+
+	// gelfChunkSize is the maximum size of a UDP datagram, suitable for WAN
+	gelfChunkSize = 1420
+	// gelfChunkHeaderSize is the size of the magic bytes, message ID, sequence number and count
+	gelfChunkHeaderSize = 12
+	gelfMaxChunks       = 128
+
+	gelfDefaultCompressionLevel  = gzip.BestSpeed
+	gelfDefaultTCPMaxReconnect   = 3
+	gelfDefaultTCPReconnectDelay = time.Second
+
+	// syslog severities
+	gelfLevelError = 3
+	gelfLevelInfo  = 6
+)
+
+var gelfFieldNameRegexp = regexp.MustCompile(`[^\w.\-]`)
+
+func GelfLogOptsValidate(logOptMap map[string]string) error {
+	for key := range logOptMap {
+		if !strutil.InStringSlice(GelfLogOpts, key) {
+			log.L.Warnf("log-opt %s is ignored for gelf log driver", key)
+		}
+	}
+	_, err := parseGelfConfig(logOptMap)
+	return err
+}
+
+// gelfConfig is the parsed configuration of the gelf driver.
+type gelfConfig struct {
+	network          string
+	address          string
+	compressionType  string
+	compressionLevel int
+	maxReconnect     int
+	reconnectDelay   time.Duration
+}
+
+func parseGelfConfig(opts map[string]string) (*gelfConfig, error) {
+	address, ok := opts[gelfAddress]
+	if !ok {
+		return nil, fmt.Errorf("%s is required for gelf log driver", gelfAddress)
+	}
+	u, err := url.Parse(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s %q: %w", gelfAddress, address, err)
+	}
+	if u.Scheme != "udp" && u.Scheme != "tcp" {
+		return nil, fmt.Errorf("%s should be in the form proto://address, got %q (only udp and tcp are supported)", gelfAddress, address)
+	}
+	if _, _, err := net.SplitHostPort(u.Host); err != nil {
+		return nil, fmt.Errorf("%s should be in the form proto://address:port, got %q", gelfAddress, address)
+	}
+	cfg := &gelfConfig{
+		network:          u.Scheme,
+		address:          u.Host,
+		compressionType:  gelfCompressionGzip,
+		compressionLevel: gelfDefaultCompressionLevel,
+		maxReconnect:     gelfDefaultTCPMaxReconnect,
+		reconnectDelay:   gelfDefaultTCPReconnectDelay,
+	}
+	if v, ok := opts[gelfCompressionType]; ok {
+		if cfg.network == "tcp" {
+			return nil, fmt.Errorf("%s is only supported with udp", gelfCompressionType)
+		}
+		switch v {
+		case gelfCompressionGzip, gelfCompressionZlib, gelfCompressionNone:
+			cfg.compressionType = v
+		default:
+			return nil, fmt.Errorf("unknown %s %q (supported: gzip, zlib, none)", gelfCompressionType, v)
+		}
+	}
+	if v, ok := opts[gelfCompressionLevel]; ok {
+		if cfg.network == "tcp" {
+			return nil, fmt.Errorf("%s is only supported with udp", gelfCompressionLevel)
+		}
+		level, err := strconv.Atoi(v)
+		if err != nil || level < gzip.DefaultCompression || level > gzip.BestCompression {
+			return nil, fmt.Errorf("%s must be an integer between -1 and 9, got %q", gelfCompressionLevel, v)
+		}
+		cfg.compressionLevel = level
+	}
+	if v, ok := opts[gelfTCPMaxReconnect]; ok {
+		if cfg.network != "tcp" {
+			return nil, fmt.Errorf("%s is only supported with tcp", gelfTCPMaxReconnect)
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("%s must be a positive integer, got %q", gelfTCPMaxReconnect, v)
+		}
+		cfg.maxReconnect = n
+	}
+	if v, ok := opts[gelfTCPReconnectDelay]; ok {
+		if cfg.network != "tcp" {
+			return nil, fmt.Errorf("%s is only supported with tcp", gelfTCPReconnectDelay)
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("%s must be a positive number of seconds, got %q", gelfTCPReconnectDelay, v)
+		}
+		cfg.reconnectDelay = time.Duration(n) * time.Second
+	}
+	return cfg, nil
+}
+
+type GelfLogger struct {
+	Opts    map[string]string
+	Address string
+	writer  *gelfWriter
+	fields  map[string]any
+}
+
+func (g *GelfLogger) Init(dataStore, ns, id string) error {
+	return nil
+}
+
+func (g *GelfLogger) PreProcess(ctx context.Context, _ string, config *logging.Config) error {
+	cfg, err := parseGelfConfig(g.Opts)
+	if err != nil {
+		return err
+	}
+	info, err := loadContainerLogInfo(ctx, g.Address, config, g.Opts)
+	if err != nil {
+		return err
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		return err
+	}
+	writer, err := newGelfWriter(cfg)
+	if err != nil {
+		return err
+	}
+	g.writer = writer
+	g.fields = gelfFields(hostname, info)
+	return nil
+}
+
+func (g *GelfLogger) Process(stdout <-chan *message.Message, stderr <-chan *message.Message) error {
+	var wg sync.WaitGroup
+	wg.Add(2)
+	fn := func(dataChan <-chan *message.Message, level int) {
+		defer wg.Done()
+		for m := range dataChan {
+			if m.Line == "" {
+				// short_message must not be empty
+				continue
+			}
+			msg := make(map[string]any, len(g.fields)+3)
+			for k, v := range g.fields {
+				msg[k] = v
+			}
+			msg["short_message"] = m.Line
+			msg["level"] = level
+			msg["timestamp"] = float64(m.Time.UnixNano()) / float64(time.Second)
+			if m.Partial != nil {
+				msg["_partial_message"] = true
+				msg["_partial_id"] = m.Partial.ID
+				msg["_partial_ordinal"] = m.Partial.Ordinal
+				msg["_partial_last"] = m.Partial.Last
+			}
+			if err := g.writer.WriteMessage(msg); err != nil {
+				log.L.WithError(err).Error("failed to send GELF message")
+			}
+		}
+	}
+	go fn(stdout, gelfLevelInfo)
+	go fn(stderr, gelfLevelError)
+	wg.Wait()
+	return nil
+}
+
+func (g *GelfLogger) PostProcess() error {
+	return g.writer.Close()
+}
+
+// gelfFields returns the fields common to all the messages of the container.
+func gelfFields(hostname string, info *containerLogInfo) map[string]any {
+	fields := map[string]any{
+		"version":         "1.1",
+		"host":            hostname,
+		"_container_id":   info.FullID,
+		"_container_name": info.Name,
+		"_image_name":     info.ImageName,
+		"_tag":            info.Tag,
+	}
+	for k, v := range info.Extra {
+		name := "_" + gelfFieldNameRegexp.ReplaceAllString(k, "_")
+		if name == "_id" {
+			// reserved
+			continue
+		}
+		fields[name] = v
+	}
+	return fields
+}
+
+// gelfWriter sends GELF messages over UDP (compressed and chunked) or TCP (null-byte delimited).
+type gelfWriter struct {
+	cfg  *gelfConfig
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+func newGelfWriter(cfg *gelfConfig) (*gelfWriter, error) {
+	conn, err := net.Dial(cfg.network, cfg.address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to GELF endpoint %s://%s: %w", cfg.network, cfg.address, err)
+	}
+	return &gelfWriter{cfg: cfg, conn: conn}, nil
+}
+
+func (w *gelfWriter) WriteMessage(msg map[string]any) error {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.cfg.network == "tcp" {
+		return w.writeTCP(append(b, 0))
+	}
+	return w.writeUDP(b)
+}
+
+func (w *gelfWriter) writeTCP(b []byte) error {
+	_, err := w.conn.Write(b)
+	for i := 0; err != nil && i < w.cfg.maxReconnect; i++ {
+		log.L.WithError(err).Debugf("failed to write GELF message, reconnecting to %s", w.cfg.address)
+		time.Sleep(w.cfg.reconnectDelay)
+		w.conn.Close()
+		var conn net.Conn
+		if conn, err = net.Dial(w.cfg.network, w.cfg.address); err == nil {
+			w.conn = conn
+			_, err = w.conn.Write(b)
+		}
+	}
+	return err
+}
+
+func (w *gelfWriter) writeUDP(b []byte) error {
+	payload, err := gelfCompress(b, w.cfg.compressionType, w.cfg.compressionLevel)
+	if err != nil {
+		return err
+	}
+	if len(payload) <= gelfChunkSize {
+		_, err = w.conn.Write(payload)
+		return err
+	}
+	chunks, err := gelfChunks(payload)
+	if err != nil {
+		return err
+	}
+	for _, chunk := range chunks {
+		if _, err := w.conn.Write(chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *gelfWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.conn.Close()
+}
+
+func gelfCompress(b []byte, compressionType string, level int) ([]byte, error) {
+	var (
+		buf bytes.Buffer
+		zw  io.WriteCloser
+		err error
+	)
+	switch compressionType {
+	case gelfCompressionNone:
+		return b, nil
+	case gelfCompressionZlib:
+		zw, err = zlib.NewWriterLevel(&buf, level)
+	default:
+		zw, err = gzip.NewWriterLevel(&buf, level)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if _, err := zw.Write(b); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// gelfChunks splits a payload into GELF chunks sharing a random message ID.
+func gelfChunks(payload []byte) ([][]byte, error) {
+	const dataSize = gelfChunkSize - gelfChunkHeaderSize
+	count := (len(payload) + dataSize - 1) / dataSize
+	if count > gelfMaxChunks {
+		return nil, errors.New("GELF message is too large to be chunked")
+	}
+	var id [8]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return nil, err
+	}
+	chunks := make([][]byte, 0, count)
+	for i := 0; i < count; i++ {
+		data := payload[i*dataSize : min((i+1)*dataSize, len(payload))]
+		chunk := make([]byte, 0, gelfChunkHeaderSize+len(data))
+		chunk = append(chunk, 0x1e, 0x0f)
+		chunk = append(chunk, id[:]...)
+		chunk = append(chunk, byte(i), byte(count))
+		chunk = append(chunk, data...)
+		chunks = append(chunks, chunk)
+	}
+	return chunks, nil
+}
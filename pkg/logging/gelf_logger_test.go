@@ -0,0 +1,168 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package logging
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestParseGelfConfig(t *testing.T) {
+	cfg, err := parseGelfConfig(map[string]string{gelfAddress: "udp://127.0.0.1:12201"})
+	assert.NilError(t, err)
+	assert.Equal(t, cfg.network, "udp")
+	assert.Equal(t, cfg.address, "127.0.0.1:12201")
+	assert.Equal(t, cfg.compressionType, gelfCompressionGzip)
+
+	cfg, err = parseGelfConfig(map[string]string{
+		gelfAddress:           "tcp://localhost:12201",
+		gelfTCPMaxReconnect:   "5",
+		gelfTCPReconnectDelay: "2",
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, cfg.maxReconnect, 5)
+	assert.Equal(t, cfg.reconnectDelay.Seconds(), float64(2))
+
+	for _, opts := range []map[string]string{
+		{},
+		{gelfAddress: "http://127.0.0.1:12201"},
+		{gelfAddress: "udp://127.0.0.1"},
+		{gelfAddress: "udp://127.0.0.1:12201", gelfCompressionType: "lz4"},
+		{gelfAddress: "udp://127.0.0.1:12201", gelfCompressionLevel: "10"},
+		{gelfAddress: "udp://127.0.0.1:12201", gelfTCPMaxReconnect: "1"},
+		{gelfAddress: "tcp://127.0.0.1:12201", gelfCompressionType: "gzip"},
+		{gelfAddress: "tcp://127.0.0.1:12201", gelfTCPReconnectDelay: "-1"},
+	} {
+		_, err := parseGelfConfig(opts)
+		assert.Assert(t, err != nil, "expected an error for %v", opts)
+	}
+}
+
+func TestGelfFields(t *testing.T) {
+	fields := gelfFields("host1", &containerLogInfo{
+		ID:        "0123456789ab",
+		FullID:    "0123456789abcdef",
+		Name:      "web",
+		ImageName: "docker.io/library/nginx:latest",
+		Tag:       "web",
+		Extra:     map[string]string{"com.example/role": "frontend", "id": "reserved"},
+	})
+	assert.Equal(t, fields["host"], "host1")
+	assert.Equal(t, fields["_container_id"], "0123456789abcdef")
+	assert.Equal(t, fields["_container_name"], "web")
+	assert.Equal(t, fields["_com.example_role"], "frontend")
+	_, ok := fields["_id"]
+	assert.Assert(t, !ok)
+}
+
+func TestGelfWriterUDPChunked(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.NilError(t, err)
+	defer conn.Close()
+
+	w, err := newGelfWriter(&gelfConfig{
+		network:          "udp",
+		address:          conn.LocalAddr().String(),
+		compressionType:  gelfCompressionGzip,
+		compressionLevel: gzip.NoCompression,
+	})
+	assert.NilError(t, err)
+	defer w.Close()
+
+	// an uncompressed message larger than a datagram is chunked
+	message := strings.Repeat("x", 3*gelfChunkSize)
+	assert.NilError(t, w.WriteMessage(map[string]any{"version": "1.1", "short_message": message}))
+
+	var (
+		payload = make(map[byte][]byte)
+		id      []byte
+		count   int
+		buf     = make([]byte, 65536)
+	)
+	for count == 0 || len(payload) < count {
+		n, _, err := conn.ReadFrom(buf)
+		assert.NilError(t, err)
+		chunk := buf[:n]
+		assert.Assert(t, n <= gelfChunkSize)
+		assert.DeepEqual(t, chunk[:2], []byte{0x1e, 0x0f})
+		if id == nil {
+			id = append([]byte(nil), chunk[2:10]...)
+		}
+		assert.DeepEqual(t, chunk[2:10], id)
+		count = int(chunk[11])
+		payload[chunk[10]] = append([]byte(nil), chunk[12:]...)
+	}
+	assert.Assert(t, count > 1)
+
+	var joined bytes.Buffer
+	for i := 0; i < count; i++ {
+		joined.Write(payload[byte(i)])
+	}
+	zr, err := gzip.NewReader(&joined)
+	assert.NilError(t, err)
+	b, err := io.ReadAll(zr)
+	assert.NilError(t, err)
+	var msg map[string]any
+	assert.NilError(t, json.Unmarshal(b, &msg))
+	assert.Equal(t, msg["short_message"], message)
+}
+
+func TestGelfWriterTCP(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NilError(t, err)
+	defer l.Close()
+
+	received := make(chan []string)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			close(received)
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		var msgs []string
+		for len(msgs) < 2 {
+			b, err := r.ReadBytes(0)
+			if err != nil {
+				break
+			}
+			msgs = append(msgs, string(b[:len(b)-1]))
+		}
+		received <- msgs
+	}()
+
+	w, err := newGelfWriter(&gelfConfig{network: "tcp", address: l.Addr().String()})
+	assert.NilError(t, err)
+	defer w.Close()
+	assert.NilError(t, w.WriteMessage(map[string]any{"short_message": "foo", "level": gelfLevelInfo}))
+	assert.NilError(t, w.WriteMessage(map[string]any{"short_message": "bar", "level": gelfLevelError}))
+
+	msgs := <-received
+	assert.DeepEqual(t, msgs, []string{
+		`{"level":6,"short_message":"foo"}`,
+		`{"level":3,"short_message":"bar"}`,
+	})
+}
@@ -22,6 +22,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"os"
 	"os/exec"
 	"strconv"
@@ -38,6 +39,7 @@ import (
 
 	"github.com/containerd/nerdctl/v2/pkg/clientutil"
 	"github.com/containerd/nerdctl/v2/pkg/containerutil"
+	"github.com/containerd/nerdctl/v2/pkg/logging/message"
 	"github.com/containerd/nerdctl/v2/pkg/strutil"
 )
 
@@ -137,13 +139,25 @@ func (journaldLogger *JournaldLogger) PreProcess(ctx context.Context, dataStore
 	return nil
 }
 
-func (journaldLogger *JournaldLogger) Process(stdout <-chan string, stderr <-chan string) error {
+func (journaldLogger *JournaldLogger) Process(stdout <-chan *message.Message, stderr <-chan *message.Message) error {
 	var wg sync.WaitGroup
 	wg.Add(2)
-	f := func(wg *sync.WaitGroup, dataChan <-chan string, pri journal.Priority, vars map[string]string) {
+	f := func(wg *sync.WaitGroup, dataChan <-chan *message.Message, pri journal.Priority, vars map[string]string) {
 		defer wg.Done()
-		for log := range dataChan {
-			journal.Send(log, pri, vars)
+		for m := range dataChan {
+			if m.Partial == nil {
+				journal.Send(m.Line, pri, vars)
+				continue
+			}
+			// the field names correspond to Docker
+			partialVars := maps.Clone(vars)
+			partialVars["CONTAINER_PARTIAL_ID"] = m.Partial.ID
+			partialVars["CONTAINER_PARTIAL_ORDINAL"] = strconv.Itoa(m.Partial.Ordinal)
+			partialVars["CONTAINER_PARTIAL_LAST"] = strconv.FormatBool(m.Partial.Last)
+			if !m.Partial.Last {
+				partialVars["CONTAINER_PARTIAL_MESSAGE"] = "true"
+			}
+			journal.Send(m.Line, pri, partialVars)
 		}
 	}
 	// forward both stdout and stderr to the journal
@@ -219,6 +233,9 @@ func viewLogsJournald(lvopts LogViewOptions, stdout, stderr io.Writer, stopChann
 	if lvopts.Timestamps {
 		log.L.Warnf("unsupported Timestamps option for journald driver")
 	}
+	if lvopts.Filter != nil {
+		log.L.Warnf("unsupported Grep, Level and Format options for journald driver")
+	}
 	if lvopts.Until != "" {
 		// using GetTimestamp from moby to keep time format consistency
 		ts, err := timetypes.GetTimestamp(lvopts.Until, time.Now())
@@ -35,6 +35,7 @@ import (
 
 	"github.com/containerd/nerdctl/v2/pkg/internal/filesystem"
 	"github.com/containerd/nerdctl/v2/pkg/logging/jsonfile"
+	"github.com/containerd/nerdctl/v2/pkg/logging/message"
 	"github.com/containerd/nerdctl/v2/pkg/logging/tail"
 	"github.com/containerd/nerdctl/v2/pkg/strutil"
 )
@@ -122,7 +123,7 @@ func (jsonLogger *JSONLogger) PreProcess(ctx context.Context, dataStore string,
 	return nil
 }
 
-func (jsonLogger *JSONLogger) Process(stdout <-chan string, stderr <-chan string) error {
+func (jsonLogger *JSONLogger) Process(stdout <-chan *message.Message, stderr <-chan *message.Message) error {
 	return jsonfile.Encode(stdout, stderr, jsonLogger.logger)
 }
 
@@ -160,14 +161,20 @@ func viewLogsJSONFileDirect(lvopts LogViewOptions, jsonLogFilePath string, stdou
 	}
 	defer func() { fin.Close() }()
 
-	// Search start point based on tail line.
-	start, err := tail.FindTailLineStartIndex(fin, lvopts.Tail)
-	if err != nil {
-		return fmt.Errorf("failed to tail %d lines of JSON logfile %q: %w", lvopts.Tail, jsonLogFilePath, err)
-	}
+	// With --grep or --level, the last matching lines are only known once the whole file is decoded.
+	decodeTail := uint(0)
+	if lvopts.Filter.Selective() {
+		decodeTail = lvopts.Tail
+	} else {
+		// Search start point based on tail line.
+		start, err := tail.FindTailLineStartIndex(fin, lvopts.Tail)
+		if err != nil {
+			return fmt.Errorf("failed to tail %d lines of JSON logfile %q: %w", lvopts.Tail, jsonLogFilePath, err)
+		}
 
-	if _, err := fin.Seek(start, io.SeekStart); err != nil {
-		return fmt.Errorf("failed to seek in log file %q from %d position: %w", jsonLogFilePath, start, err)
+		if _, err := fin.Seek(start, io.SeekStart); err != nil {
+			return fmt.Errorf("failed to seek in log file %q from %d position: %w", jsonLogFilePath, start, err)
+		}
 	}
 
 	limitedMode := (lvopts.Tail > 0) && (!lvopts.Follow)
@@ -190,7 +197,10 @@ func viewLogsJSONFileDirect(lvopts LogViewOptions, jsonLogFilePath string, stdou
 				return nil
 			}
 
-			if line, err := jsonfile.Decode(stdout, stderr, fin, lvopts.Timestamps, lvopts.Since, lvopts.Until); err != nil {
+			line, err := jsonfile.Decode(stdout, stderr, fin, lvopts.Timestamps, lvopts.Since, lvopts.Until, lvopts.Filter, decodeTail)
+			// The tail only applies to the entries written before the logs are followed.
+			decodeTail = 0
+			if err != nil {
 				if len(line) > 0 {
 					time.Sleep(5 * time.Millisecond)
 					if retryTimes == 0 {
@@ -25,6 +25,8 @@ import (
 	"runtime"
 	"testing"
 	"time"
+
+	"github.com/containerd/nerdctl/v2/pkg/logging/logfilter"
 )
 
 func TestReadRotatedJSONLog(t *testing.T) {
@@ -119,6 +121,11 @@ func TestReadJSONLogs(t *testing.T) {
 	file.WriteString(`{"log":"line2\n","stream":"stdout","time":"2024-07-12T03:09:24.916296732Z"}` + "\n")
 	file.WriteString(`{"log":"line3\n","stream":"stdout","time":"2024-07-12T03:09:24.916296732Z"}` + "\n")
 
+	grepFilter, err := logfilter.New("line[12]", "", "")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
 	stopChan := make(chan os.Signal)
 	testCases := []struct {
 		name           string
@@ -157,6 +164,15 @@ func TestReadJSONLogs(t *testing.T) {
 			},
 			expected: "line1\nline2\nline3\n",
 		},
+		{
+			name: "using Tail 1 with a filter should output the last matching line",
+			logViewOptions: LogViewOptions{
+				LogPath: file.Name(),
+				Tail:    1,
+				Filter:  grepFilter,
+			},
+			expected: "line2\n",
+		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -29,6 +29,9 @@ import (
 	timetypes "github.com/docker/docker/api/types/time"
 
 	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/logging/logfilter"
+	"github.com/containerd/nerdctl/v2/pkg/logging/message"
 )
 
 // Entry is compatible with Docker "json-file" logs
@@ -43,19 +46,24 @@ func Path(dataStore, ns, id string) string {
 	return filepath.Join(dataStore, "containers", ns, id, id+"-json.log")
 }
 
-func Encode(stdout <-chan string, stderr <-chan string, writer io.Writer) error {
+// Encode writes the messages received from stdout and stderr as JSON lines.
+// The partial messages are written without a newline, like Docker.
+func Encode(stdout <-chan *message.Message, stderr <-chan *message.Message, writer io.Writer) error {
 	enc := json.NewEncoder(writer)
 	var encMu sync.Mutex
 	var wg sync.WaitGroup
 	wg.Add(2)
-	f := func(dataChan <-chan string, name string) {
+	f := func(dataChan <-chan *message.Message, name string) {
 		defer wg.Done()
 		e := &Entry{
 			Stream: name,
 		}
-		for logEntry := range dataChan {
-			e.Log = logEntry
-			e.Time = time.Now().UTC()
+		for m := range dataChan {
+			e.Log = m.Line
+			if m.EndsLine() {
+				e.Log += "\n"
+			}
+			e.Time = m.Time.UTC()
 			encMu.Lock()
 			encErr := enc.Encode(e)
 			encMu.Unlock()
@@ -71,45 +79,51 @@ func Encode(stdout <-chan string, stderr <-chan string, writer io.Writer) error
 	return nil
 }
 
-func writeEntry(e *Entry, stdout, stderr io.Writer, refTime time.Time, timestamps bool, since string, until string) error {
+// formatEntry returns the writer and the output of an entry, or a nil writer if the entry is filtered out.
+func formatEntry(e *Entry, stdout, stderr io.Writer, refTime time.Time, timestamps bool, since string, until string, filter *logfilter.Filter) (io.Writer, []byte, error) {
 	output := []byte{}
 
 	if since != "" {
 		ts, err := timetypes.GetTimestamp(since, refTime)
 		if err != nil {
-			return fmt.Errorf("invalid value for \"since\": %w", err)
+			return nil, nil, fmt.Errorf("invalid value for \"since\": %w", err)
 		}
 		v := strings.Split(ts, ".")
 		i, err := strconv.ParseInt(v[0], 10, 64)
 		if err != nil {
-			return err
+			return nil, nil, err
 		}
 		if e.Time.Before(time.Unix(i, 0)) {
-			return nil
+			return nil, nil, nil
 		}
 	}
 
 	if until != "" {
 		ts, err := timetypes.GetTimestamp(until, refTime)
 		if err != nil {
-			return fmt.Errorf("invalid value for \"until\": %w", err)
+			return nil, nil, fmt.Errorf("invalid value for \"until\": %w", err)
 		}
 		v := strings.Split(ts, ".")
 		i, err := strconv.ParseInt(v[0], 10, 64)
 		if err != nil {
-			return err
+			return nil, nil, err
 		}
 		if e.Time.After(time.Unix(i, 0)) {
-			return nil
+			return nil, nil, nil
 		}
 	}
 
+	text, ok := filter.Apply(e.Log)
+	if !ok {
+		return nil, nil, nil
+	}
+
 	if timestamps {
 		output = append(output, []byte(e.Time.Format(time.RFC3339Nano))...)
 		output = append(output, ' ')
 	}
 
-	output = append(output, []byte(e.Log)...)
+	output = append(output, []byte(text)...)
 
 	var writeTo io.Writer
 	switch e.Stream {
@@ -121,21 +135,33 @@ func writeEntry(e *Entry, stdout, stderr io.Writer, refTime time.Time, timestamp
 		log.L.Errorf("unknown stream name %q, entry=%+v", e.Stream, e)
 	}
 
-	if writeTo != nil {
-		_, err := writeTo.Write(output)
-		return err
-	}
-	return nil
+	return writeTo, output, nil
+}
+
+type tailOutput struct {
+	w      io.Writer
+	output []byte
 }
 
-func Decode(stdout, stderr io.Writer, r io.Reader, timestamps bool, since string, until string) ([]byte, error) {
+// Decode writes the entries of r that match the time range and the filter to stdout and stderr.
+// When tail is not 0, only the last tail matching entries are written, once r is read.
+func Decode(stdout, stderr io.Writer, r io.Reader, timestamps bool, since string, until string, filter *logfilter.Filter, tail uint) ([]byte, error) {
 	dec := json.NewDecoder(r)
 	now := time.Now()
+	var tailOutputs []tailOutput
+	flush := func() {
+		for _, o := range tailOutputs {
+			if _, err := o.w.Write(o.output); err != nil {
+				log.L.WithError(err).Errorf("error while writing log entry to output stream")
+			}
+		}
+	}
 	for {
 		var e Entry
 		if err := dec.Decode(&e); err == io.EOF {
 			break
 		} else if err != nil {
+			flush()
 			line, err := io.ReadAll(dec.Buffered())
 			if err != nil {
 				return nil, err
@@ -143,12 +169,27 @@ func Decode(stdout, stderr io.Writer, r io.Reader, timestamps bool, since string
 			return line, err
 		}
 
-		// Write out the entry directly
-		err := writeEntry(&e, stdout, stderr, now, timestamps, since, until)
+		w, output, err := formatEntry(&e, stdout, stderr, now, timestamps, since, until, filter)
 		if err != nil {
 			log.L.WithError(err).Errorf("error while writing log entry to output stream")
+			continue
+		}
+		if w == nil {
+			continue
+		}
+		if tail == 0 {
+			// Write out the entry directly
+			if _, err := w.Write(output); err != nil {
+				log.L.WithError(err).Errorf("error while writing log entry to output stream")
+			}
+			continue
+		}
+		tailOutputs = append(tailOutputs, tailOutput{w: w, output: output})
+		if uint(len(tailOutputs)) > tail {
+			tailOutputs = tailOutputs[1:]
 		}
 	}
+	flush()
 
 	return nil, nil
 }
@@ -0,0 +1,217 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package local
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"github.com/containerd/log"
+)
+
+const compressSuffix = ".gz"
+
+// File writes records to a log file that is rotated when it would exceed maxSize.
+// Up to maxFiles-1 rotated files are kept, from "<path>.1" (the most recent)
+// to "<path>.<maxFiles-1>", with a ".gz" suffix when they are compressed.
+type File struct {
+	path     string
+	maxSize  int64
+	maxFiles int
+	compress bool
+
+	mu   sync.Mutex
+	f    *os.File
+	size int64
+}
+
+// NewFile opens the log file at path for appending.
+// A maxSize of 0 disables the rotation.
+func NewFile(path string, maxSize int64, maxFiles int, compress bool) (*File, error) {
+	if maxFiles < 1 {
+		return nil, fmt.Errorf("max-file cannot be less than 1")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+	st, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &File{
+		path:     path,
+		maxSize:  maxSize,
+		maxFiles: maxFiles,
+		compress: compress,
+		f:        f,
+		size:     st.Size(),
+	}, nil
+}
+
+// Write writes a whole record, rotating the file beforehand if needed,
+// so that a record is never split across files.
+func (f *File) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.maxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.maxSize {
+		if err := f.rotate(); err != nil {
+			return 0, fmt.Errorf("failed to rotate log file %q: %w", f.path, err)
+		}
+	}
+	n, err := f.f.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Close closes the current log file.
+func (f *File) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.f.Close()
+}
+
+func (f *File) rotate() error {
+	if err := f.f.Close(); err != nil {
+		return err
+	}
+	if f.maxFiles > 1 {
+		for i := f.maxFiles - 1; i > 1; i-- {
+			for _, suffix := range []string{"", compressSuffix} {
+				err := os.Rename(rotatedName(f.path, i-1)+suffix, rotatedName(f.path, i)+suffix)
+				if err != nil && !errors.Is(err, os.ErrNotExist) {
+					return err
+				}
+			}
+		}
+		latest := rotatedName(f.path, 1)
+		if err := os.Rename(f.path, latest); err != nil {
+			return err
+		}
+		if f.compress {
+			if err := compressFile(latest); err != nil {
+				log.L.WithError(err).Warnf("failed to compress rotated log file %q", latest)
+			}
+		}
+	} else if err := os.Remove(f.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	nf, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	f.f = nf
+	f.size = 0
+	return nil
+}
+
+func rotatedName(path string, i int) string {
+	return fmt.Sprintf("%s.%d", path, i)
+}
+
+// compressFile replaces name by name.gz.
+func compressFile(name string) (retErr error) {
+	src, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	tmp := name + compressSuffix + ".tmp"
+	dst, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if retErr != nil {
+			dst.Close()
+			os.Remove(tmp)
+		}
+	}()
+	zw := gzip.NewWriter(dst)
+	if _, err := io.Copy(zw, src); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, name+compressSuffix); err != nil {
+		return err
+	}
+	return os.Remove(name)
+}
+
+// Segments returns the log files of the current log file at path,
+// from the oldest rotated one to the current one.
+func Segments(path string) []string {
+	var rotated []string
+	for i := 1; ; i++ {
+		name := rotatedName(path, i)
+		if _, err := os.Stat(name + compressSuffix); err == nil {
+			rotated = append(rotated, name+compressSuffix)
+		} else if _, err := os.Stat(name); err == nil {
+			rotated = append(rotated, name)
+		} else {
+			break
+		}
+	}
+	slices.Reverse(rotated)
+	return append(rotated, path)
+}
+
+// OpenSegment opens a rotated log file, decompressing it if needed.
+func OpenSegment(name string) (io.ReadCloser, error) {
+	f, err := os.Open(name)
+	if errors.Is(err, os.ErrNotExist) && filepath.Ext(name) != compressSuffix {
+		// compressed since it was listed
+		name += compressSuffix
+		f, err = os.Open(name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if filepath.Ext(name) != compressSuffix {
+		return f, nil
+	}
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &gzipReadCloser{Reader: zr, f: f}, nil
+}
+
+type gzipReadCloser struct {
+	*gzip.Reader
+	f *os.File
+}
+
+func (r *gzipReadCloser) Close() error {
+	return errors.Join(r.Reader.Close(), r.f.Close())
+}
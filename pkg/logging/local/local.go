@@ -0,0 +1,155 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package local implements the on-disk format of the "local" log driver,
+// which is compatible with the Docker "local" log driver.
+//
+// Each record is a protobuf-encoded LogEntry prefixed and suffixed by its
+// size as a big endian uint32. The current file is not compressed, the rotated
+// ones are compressed with gzip.
+package local
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types/plugins/logdriver"
+
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/logging/message"
+)
+
+const (
+	encodeBinaryLen = 4
+	// maxRecordSize protects against reading garbage as a huge size
+	maxRecordSize = 1 << 30
+)
+
+// Entry is a log entry of the "local" log driver.
+type Entry struct {
+	// Source is "stdout" or "stderr"
+	Source string
+	Time   time.Time
+	// Line does not include the trailing "\n"
+	Line []byte
+	// Partial is true when the line was not terminated by "\n"
+	Partial bool
+}
+
+// Path returns the path of the current log file.
+func Path(dataStore, ns, id string) string {
+	// the directory and file names correspond to Docker
+	return filepath.Join(dataStore, "containers", ns, id, "local-logs", "container.log")
+}
+
+// CachePath returns the path of the current file of the local cache
+// kept for the log drivers that cannot be read back.
+func CachePath(dataStore, ns, id string) string {
+	// the file name corresponds to Docker
+	return filepath.Join(dataStore, "containers", ns, id, "container-cached.log")
+}
+
+// Marshal encodes an entry as a record.
+func Marshal(e *Entry) ([]byte, error) {
+	pe := logdriver.LogEntry{
+		Source:   e.Source,
+		TimeNano: e.Time.UnixNano(),
+		Line:     e.Line,
+		Partial:  e.Partial,
+	}
+	size := pe.Size()
+	b := make([]byte, size+2*encodeBinaryLen)
+	binary.BigEndian.PutUint32(b, uint32(size))
+	if _, err := pe.MarshalTo(b[encodeBinaryLen : encodeBinaryLen+size]); err != nil {
+		return nil, err
+	}
+	binary.BigEndian.PutUint32(b[encodeBinaryLen+size:], uint32(size))
+	return b, nil
+}
+
+// ReadEntry reads the next record from r.
+// It returns io.EOF when there is no more record, and io.ErrUnexpectedEOF
+// when the last record is truncated (e.g., still being written).
+func ReadEntry(r io.Reader) (*Entry, error) {
+	var sizeBuf [encodeBinaryLen]byte
+	if _, err := io.ReadFull(r, sizeBuf[:]); err != nil {
+		return nil, err
+	}
+	size := binary.BigEndian.Uint32(sizeBuf[:])
+	if size > maxRecordSize {
+		return nil, fmt.Errorf("invalid log record size %d", size)
+	}
+	b := make([]byte, int(size)+encodeBinaryLen)
+	if _, err := io.ReadFull(r, b); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	if trailer := binary.BigEndian.Uint32(b[size:]); trailer != size {
+		return nil, fmt.Errorf("corrupted log record: size %d does not match trailer %d", size, trailer)
+	}
+	var pe logdriver.LogEntry
+	if err := pe.Unmarshal(b[:size]); err != nil {
+		return nil, err
+	}
+	return &Entry{
+		Source:  pe.Source,
+		Time:    time.Unix(0, pe.TimeNano).UTC(),
+		Line:    pe.Line,
+		Partial: pe.Partial,
+	}, nil
+}
+
+// Encode writes the messages received from stdout and stderr as records.
+func Encode(stdout <-chan *message.Message, stderr <-chan *message.Message, writer io.Writer) error {
+	var encMu sync.Mutex
+	var wg sync.WaitGroup
+	wg.Add(2)
+	f := func(dataChan <-chan *message.Message, name string) {
+		defer wg.Done()
+		for m := range dataChan {
+			e := &Entry{
+				Source:  name,
+				Time:    m.Time.UTC(),
+				Line:    []byte(m.Line),
+				Partial: !m.EndsLine(),
+			}
+			b, err := Marshal(e)
+			if err != nil {
+				log.L.WithError(err).Errorf("failed to encode log entry")
+				return
+			}
+			encMu.Lock()
+			_, err = writer.Write(b)
+			encMu.Unlock()
+			if err != nil {
+				log.L.WithError(err).Errorf("failed to write log entry")
+				return
+			}
+		}
+	}
+	go f(stdout, "stdout")
+	go f(stderr, "stderr")
+	wg.Wait()
+	return nil
+}
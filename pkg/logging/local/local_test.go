@@ -0,0 +1,112 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package local
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestMarshalReadEntry(t *testing.T) {
+	now := time.Now().UTC()
+	var buf bytes.Buffer
+	for _, e := range []*Entry{
+		{Source: "stdout", Time: now, Line: []byte("hello")},
+		{Source: "stderr", Time: now.Add(time.Second), Line: []byte("partial"), Partial: true},
+	} {
+		b, err := Marshal(e)
+		assert.NilError(t, err)
+		buf.Write(b)
+	}
+	// truncated record
+	b, err := Marshal(&Entry{Source: "stdout", Time: now, Line: []byte("truncated")})
+	assert.NilError(t, err)
+	buf.Write(b[:len(b)-2])
+
+	e, err := ReadEntry(&buf)
+	assert.NilError(t, err)
+	assert.Equal(t, e.Source, "stdout")
+	assert.Equal(t, string(e.Line), "hello")
+	assert.Assert(t, e.Time.Equal(now))
+	assert.Assert(t, !e.Partial)
+
+	e, err = ReadEntry(&buf)
+	assert.NilError(t, err)
+	assert.Equal(t, e.Source, "stderr")
+	assert.Assert(t, e.Partial)
+
+	_, err = ReadEntry(&buf)
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	_, err = ReadEntry(&buf)
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestFileRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "container.log")
+	record, err := Marshal(&Entry{Source: "stdout", Time: time.Now(), Line: []byte("0123456789")})
+	assert.NilError(t, err)
+
+	// Two records per file, three files
+	f, err := NewFile(path, int64(2*len(record)), 3, true)
+	assert.NilError(t, err)
+	for i := 0; i < 9; i++ {
+		b, err := Marshal(&Entry{Source: "stdout", Time: time.Now(), Line: []byte(fmt.Sprintf("line-%04d", i))})
+		assert.NilError(t, err)
+		_, err = f.Write(b)
+		assert.NilError(t, err)
+	}
+	assert.NilError(t, f.Close())
+
+	segments := Segments(path)
+	assert.DeepEqual(t, segments, []string{path + ".2.gz", path + ".1.gz", path})
+
+	var lines []string
+	for _, segment := range segments {
+		r, err := OpenSegment(segment)
+		assert.NilError(t, err)
+		for {
+			e, err := ReadEntry(r)
+			if err == io.EOF {
+				break
+			}
+			assert.NilError(t, err)
+			lines = append(lines, string(e.Line))
+		}
+		assert.NilError(t, r.Close())
+	}
+	assert.DeepEqual(t, lines, []string{"line-0004", "line-0005", "line-0006", "line-0007", "line-0008"})
+}
+
+func TestFileRotationSingleFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "container.log")
+	f, err := NewFile(path, 1, 1, true)
+	assert.NilError(t, err)
+	for i := 0; i < 3; i++ {
+		b, err := Marshal(&Entry{Source: "stdout", Time: time.Now(), Line: []byte("line")})
+		assert.NilError(t, err)
+		_, err = f.Write(b)
+		assert.NilError(t, err)
+	}
+	assert.NilError(t, f.Close())
+	assert.DeepEqual(t, Segments(path), []string{path})
+}
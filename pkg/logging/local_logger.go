@@ -0,0 +1,402 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	timetypes "github.com/docker/docker/api/types/time"
+	"github.com/docker/go-units"
+
+	"github.com/containerd/containerd/v2/core/runtime/v2/logging"
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/internal/filesystem"
+	"github.com/containerd/nerdctl/v2/pkg/logging/local"
+	"github.com/containerd/nerdctl/v2/pkg/logging/logfilter"
+	"github.com/containerd/nerdctl/v2/pkg/logging/message"
+	"github.com/containerd/nerdctl/v2/pkg/strutil"
+)
+
+const (
+	// Docker defaults for the "local" log driver
+	localDefaultMaxSize  = 20 * 1024 * 1024
+	localDefaultMaxFile  = 5
+	localDefaultCompress = true
+)
+
+var LocalDriverLogOpts = []string{
+	MaxSize,
+	MaxFile,
+	Compress,
+	Env,
+	Labels,
+}
+
+type LocalLogger struct {
+	Opts map[string]string
+	// pathFunc returns the path of the log file, local.Path is used when nil
+	pathFunc func(dataStore, ns, id string) string
+	file     *local.File
+}
+
+func (localLogger *LocalLogger) path(dataStore, ns, id string) string {
+	if localLogger.pathFunc != nil {
+		return localLogger.pathFunc(dataStore, ns, id)
+	}
+	return local.Path(dataStore, ns, id)
+}
+
+func LocalLogOptsValidate(logOptMap map[string]string) error {
+	for key := range logOptMap {
+		if !strutil.InStringSlice(LocalDriverLogOpts, key) {
+			log.L.Warnf("log-opt %s is ignored for local log driver", key)
+		}
+	}
+	if v, ok := logOptMap[Compress]; ok {
+		if _, err := strconv.ParseBool(v); err != nil {
+			return fmt.Errorf("invalid value for log-opt %s: %q", Compress, v)
+		}
+	}
+	return nil
+}
+
+func (localLogger *LocalLogger) Init(dataStore, ns, id string) error {
+	// Create the log file beforehand so that it can be viewed right after the container is started
+	logFilePath := localLogger.path(dataStore, ns, id)
+	if err := os.MkdirAll(filepath.Dir(logFilePath), 0700); err != nil {
+		return err
+	}
+	if _, err := os.Stat(logFilePath); errors.Is(err, os.ErrNotExist) {
+		if writeErr := filesystem.WriteFile(logFilePath, []byte{}, 0600); writeErr != nil {
+			return writeErr
+		}
+	}
+	return nil
+}
+
+func (localLogger *LocalLogger) PreProcess(ctx context.Context, dataStore string, config *logging.Config) error {
+	maxSize := int64(localDefaultMaxSize)
+	if capacity, ok := localLogger.Opts[MaxSize]; ok {
+		var err error
+		maxSize, err = units.FromHumanSize(capacity)
+		if err != nil {
+			return err
+		}
+		if maxSize <= 0 {
+			return fmt.Errorf("max-size must be a positive number")
+		}
+	}
+	maxFile := localDefaultMaxFile
+	if maxFileString, ok := localLogger.Opts[MaxFile]; ok {
+		var err error
+		maxFile, err = strconv.Atoi(maxFileString)
+		if err != nil {
+			return err
+		}
+		if maxFile < 1 {
+			return fmt.Errorf("max-file cannot be less than 1")
+		}
+	}
+	compress := localDefaultCompress
+	if compressString, ok := localLogger.Opts[Compress]; ok {
+		var err error
+		compress, err = strconv.ParseBool(compressString)
+		if err != nil {
+			return err
+		}
+	}
+	f, err := local.NewFile(localLogger.path(dataStore, config.Namespace, config.ID), maxSize, maxFile, compress)
+	if err != nil {
+		return err
+	}
+	localLogger.file = f
+	return nil
+}
+
+func (localLogger *LocalLogger) Process(stdout <-chan *message.Message, stderr <-chan *message.Message) error {
+	return local.Encode(stdout, stderr, localLogger.file)
+}
+
+func (localLogger *LocalLogger) PostProcess() error {
+	return localLogger.file.Close()
+}
+
+// localEntryWriter writes the entries of the "local" log driver
+// that match the time range of the log viewing options.
+type localEntryWriter struct {
+	stdout, stderr io.Writer
+	timestamps     bool
+	since, until   time.Time
+	filter         *logfilter.Filter
+}
+
+func newLocalEntryWriter(lvopts LogViewOptions, stdout, stderr io.Writer) (*localEntryWriter, error) {
+	w := &localEntryWriter{
+		stdout:     stdout,
+		stderr:     stderr,
+		timestamps: lvopts.Timestamps,
+		filter:     lvopts.Filter,
+	}
+	now := time.Now()
+	var err error
+	if lvopts.Since != "" {
+		if w.since, err = parseLogTimestamp(lvopts.Since, now); err != nil {
+			return nil, fmt.Errorf("invalid value for \"since\": %w", err)
+		}
+	}
+	if lvopts.Until != "" {
+		if w.until, err = parseLogTimestamp(lvopts.Until, now); err != nil {
+			return nil, fmt.Errorf("invalid value for \"until\": %w", err)
+		}
+	}
+	return w, nil
+}
+
+func (w *localEntryWriter) match(e *local.Entry) bool {
+	if !w.since.IsZero() && e.Time.Before(w.since) {
+		return false
+	}
+	if !w.until.IsZero() && e.Time.After(w.until) {
+		return false
+	}
+	return true
+}
+
+// format returns the writer and the output of an entry, or a nil writer if the entry is filtered out.
+func (w *localEntryWriter) format(e *local.Entry) (io.Writer, []byte) {
+	line := string(e.Line)
+	if !e.Partial {
+		line += "\n"
+	}
+	text, ok := w.filter.Apply(line)
+	if !ok {
+		return nil, nil
+	}
+	var output []byte
+	if w.timestamps {
+		output = append(output, e.Time.Format(time.RFC3339Nano)...)
+		output = append(output, ' ')
+	}
+	output = append(output, text...)
+	switch e.Source {
+	case "stdout":
+		return w.stdout, output
+	case "stderr":
+		return w.stderr, output
+	default:
+		log.L.Errorf("unknown stream name %q", e.Source)
+		return nil, nil
+	}
+}
+
+func (w *localEntryWriter) write(e *local.Entry) error {
+	writeTo, output := w.format(e)
+	if writeTo == nil {
+		return nil
+	}
+	_, err := writeTo.Write(output)
+	return err
+}
+
+// parseLogTimestamp parses a "since" or "until" value, relative to now.
+func parseLogTimestamp(value string, now time.Time) (time.Time, error) {
+	// using GetTimestamp from moby to keep time format consistency
+	ts, err := timetypes.GetTimestamp(value, now)
+	if err != nil {
+		return time.Time{}, err
+	}
+	sec, nsec, err := timetypes.ParseTimestamps(ts, 0)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(sec, nsec), nil
+}
+
+// readLocalEntries calls fn for each complete record of r.
+// It returns the number of bytes of the complete records.
+func readLocalEntries(r io.Reader, fn func(*local.Entry) error) (int64, error) {
+	cr := &countingReader{r: r}
+	var read int64
+	for {
+		e, err := local.ReadEntry(cr)
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return read, nil
+		}
+		if err != nil {
+			return read, err
+		}
+		read = cr.n
+		if err := fn(e); err != nil {
+			return read, err
+		}
+	}
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	cr.n += int64(n)
+	return n, err
+}
+
+// Loads log entries from the log files produced by the local driver and forwards
+// them to the provided io.Writers after applying the provided logging options.
+func viewLogsLocal(lvopts LogViewOptions, stdout, stderr io.Writer, stopChannel chan os.Signal) error {
+	logFilePath := local.Path(lvopts.DatastoreRootPath, lvopts.Namespace, lvopts.ContainerID)
+	return viewLogsLocalFile(lvopts, logFilePath, stdout, stderr, stopChannel)
+}
+
+// Loads log entries from a log file in the format of the local driver, including the
+// compressed rotated ones, and forwards them to the provided io.Writers.
+// If `LogViewOptions.Follow` is provided, it will keep reading the current log file
+// until it receives something through the stopChannel.
+func viewLogsLocalFile(lvopts LogViewOptions, logFilePath string, stdout, stderr io.Writer, stopChannel chan os.Signal) error {
+	w, err := newLocalEntryWriter(lvopts, stdout, stderr)
+	if err != nil {
+		return err
+	}
+
+	// With --tail, the last matching entries are only known once all the files are read.
+	// The entries are filtered with --grep and --level before they are kept.
+	type tailEntry struct {
+		w      io.Writer
+		output []byte
+	}
+	var tailEntries []tailEntry
+	emit := func(e *local.Entry) error {
+		if !w.match(e) {
+			return nil
+		}
+		if lvopts.Tail == 0 {
+			return w.write(e)
+		}
+		writeTo, output := w.format(e)
+		if writeTo == nil {
+			return nil
+		}
+		tailEntries = append(tailEntries, tailEntry{w: writeTo, output: output})
+		if uint(len(tailEntries)) > lvopts.Tail {
+			tailEntries = tailEntries[1:]
+		}
+		return nil
+	}
+
+	// Open the current file first, so that a concurrent rotation does not make us miss entries
+	fin, err := openFileShareDelete(logFilePath)
+	if err != nil {
+		return fmt.Errorf("failed to open local log file %q: %w", logFilePath, err)
+	}
+	defer func() { fin.Close() }()
+
+	for _, segment := range local.Segments(logFilePath) {
+		if segment == logFilePath {
+			continue
+		}
+		r, err := local.OpenSegment(segment)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				// removed by a rotation
+				continue
+			}
+			return err
+		}
+		_, err = readLocalEntries(r, emit)
+		r.Close()
+		if err != nil {
+			return fmt.Errorf("error occurred while reading local log file %q: %w", segment, err)
+		}
+	}
+
+	readCurrent := func(f *os.File, fn func(*local.Entry) error) error {
+		start, err := f.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return err
+		}
+		n, err := readLocalEntries(f, fn)
+		if err != nil {
+			return fmt.Errorf("error occurred while reading local log file %q: %w", logFilePath, err)
+		}
+		// Rewind to the beginning of a truncated record, to read it again once complete
+		_, err = f.Seek(start+n, io.SeekStart)
+		return err
+	}
+
+	if err := readCurrent(fin, emit); err != nil {
+		return err
+	}
+	for _, e := range tailEntries {
+		if _, err := e.w.Write(e.output); err != nil {
+			return err
+		}
+	}
+	if !lvopts.Follow {
+		return nil
+	}
+
+	write := func(e *local.Entry) error {
+		if !w.match(e) {
+			return nil
+		}
+		return w.write(e)
+	}
+	watcher, err := NewLogFileWatcher(filepath.Dir(logFilePath))
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+	baseName := filepath.Base(logFilePath)
+	for {
+		select {
+		case <-stopChannel:
+			log.L.Debug("received stop signal while re-reading local logfile, returning")
+			return nil
+		default:
+		}
+		// Read again in case entries were written before the watcher was set up
+		if err := readCurrent(fin, write); err != nil {
+			return err
+		}
+		recreated, err := startTail(context.Background(), baseName, watcher)
+		if err != nil {
+			return err
+		}
+		if recreated {
+			// Drain the rotated file before switching to the new one
+			if err := readCurrent(fin, write); err != nil {
+				return err
+			}
+			newF, err := openFileShareDelete(logFilePath)
+			if err != nil {
+				return fmt.Errorf("failed to open local log file %q: %w", logFilePath, err)
+			}
+			fin.Close()
+			fin = newF
+		}
+	}
+}
@@ -0,0 +1,101 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package logging
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/nerdctl/v2/pkg/logging/local"
+	"github.com/containerd/nerdctl/v2/pkg/logging/logfilter"
+)
+
+func TestViewLogsLocal(t *testing.T) {
+	dataStore := t.TempDir()
+	path := local.Path(dataStore, "default", "id")
+	f, err := local.NewFile(path, 256, 4, true)
+	assert.NilError(t, err)
+	start := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+	for i := 0; i < 20; i++ {
+		source := "stdout"
+		if i%5 == 0 {
+			source = "stderr"
+		}
+		b, err := local.Marshal(&local.Entry{
+			Source: source,
+			Time:   start.Add(time.Duration(i) * time.Minute),
+			Line:   []byte(fmt.Sprintf("line%d", i)),
+		})
+		assert.NilError(t, err)
+		_, err = f.Write(b)
+		assert.NilError(t, err)
+	}
+	assert.NilError(t, f.Close())
+	assert.Assert(t, len(local.Segments(path)) > 1)
+
+	lvopts := LogViewOptions{
+		ContainerID:       "id",
+		Namespace:         "default",
+		DatastoreRootPath: dataStore,
+	}
+	view := func(lvopts LogViewOptions) (string, string) {
+		var stdout, stderr bytes.Buffer
+		assert.NilError(t, viewLogsLocal(lvopts, &stdout, &stderr, make(chan os.Signal)))
+		return stdout.String(), stderr.String()
+	}
+
+	stdout, stderr := view(lvopts)
+	// the oldest segment was removed by the rotation
+	assert.Assert(t, strings.HasSuffix(stdout, "line18\nline19\n"), stdout)
+	assert.Assert(t, strings.HasSuffix(stderr, "line10\nline15\n"), stderr)
+
+	tailOpts := lvopts
+	tailOpts.Tail = 3
+	stdout, stderr = view(tailOpts)
+	assert.Equal(t, stdout, "line17\nline18\nline19\n")
+	assert.Equal(t, stderr, "")
+
+	rangeOpts := lvopts
+	rangeOpts.Since = start.Add(14 * time.Minute).Format(time.RFC3339)
+	rangeOpts.Until = start.Add(16 * time.Minute).Format(time.RFC3339)
+	rangeOpts.Timestamps = true
+	stdout, stderr = view(rangeOpts)
+	assert.Equal(t, stdout, start.Add(14*time.Minute).Format(time.RFC3339Nano)+" line14\n"+
+		start.Add(16*time.Minute).Format(time.RFC3339Nano)+" line16\n")
+	assert.Equal(t, stderr, start.Add(15*time.Minute).Format(time.RFC3339Nano)+" line15\n")
+
+	grepOpts := lvopts
+	grepOpts.Filter, err = logfilter.New("line1[58]", "", "")
+	assert.NilError(t, err)
+	stdout, stderr = view(grepOpts)
+	assert.Equal(t, stdout, "line18\n")
+	assert.Equal(t, stderr, "line15\n")
+
+	// the entries are filtered before the tail
+	grepOpts.Tail = 2
+	grepOpts.Filter, err = logfilter.New("line1[1-3]", "", "")
+	assert.NilError(t, err)
+	stdout, stderr = view(grepOpts)
+	assert.Equal(t, stdout, "line12\nline13\n")
+	assert.Equal(t, stderr, "")
+}
@@ -0,0 +1,240 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/docker/go-units"
+
+	"github.com/containerd/containerd/v2/core/runtime/v2/logging"
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/logging/local"
+	"github.com/containerd/nerdctl/v2/pkg/logging/message"
+)
+
+// Log options of the local cache kept for the drivers that cannot be read back
+// (dual logging). The names and the defaults correspond to Docker.
+const (
+	CacheDisabled = "cache-disabled"
+	CacheMaxSize  = "cache-max-size"
+	CacheMaxFile  = "cache-max-file"
+	CacheCompress = "cache-compress"
+)
+
+var cacheLogOpts = []string{
+	CacheDisabled,
+	CacheMaxSize,
+	CacheMaxFile,
+	CacheCompress,
+}
+
+// usesLogCache returns true when the driver cannot be read back by `nerdctl logs`,
+// so that its logs are also written to a local cache.
+func usesLogCache(driverName string) bool {
+	if _, ok := drivers[driverName]; !ok {
+		return false
+	}
+	_, ok := logViewers[driverName]
+	return !ok
+}
+
+// logCacheEnabled returns true unless the cache is disabled by the log options.
+// The options are expected to have been validated.
+func logCacheEnabled(driverName string, opts map[string]string) bool {
+	if !usesLogCache(driverName) {
+		return false
+	}
+	disabled, _ := strconv.ParseBool(opts[CacheDisabled])
+	return !disabled
+}
+
+// CacheLogOptsValidate validates the cache-* log options.
+func CacheLogOptsValidate(logOptMap map[string]string) error {
+	if v, ok := logOptMap[CacheDisabled]; ok {
+		if _, err := strconv.ParseBool(v); err != nil {
+			return fmt.Errorf("invalid value for log-opt %s: %q", CacheDisabled, v)
+		}
+	}
+	if v, ok := logOptMap[CacheMaxSize]; ok {
+		size, err := units.FromHumanSize(v)
+		if err != nil {
+			return fmt.Errorf("invalid value for log-opt %s: %w", CacheMaxSize, err)
+		}
+		if size <= 0 {
+			return fmt.Errorf("%s must be a positive number", CacheMaxSize)
+		}
+	}
+	if v, ok := logOptMap[CacheMaxFile]; ok {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("invalid value for log-opt %s: %w", CacheMaxFile, err)
+		}
+		if n < 1 {
+			return fmt.Errorf("%s cannot be less than 1", CacheMaxFile)
+		}
+	}
+	if v, ok := logOptMap[CacheCompress]; ok {
+		if _, err := strconv.ParseBool(v); err != nil {
+			return fmt.Errorf("invalid value for log-opt %s: %q", CacheCompress, v)
+		}
+	}
+	return nil
+}
+
+// splitCacheLogOpts separates the cache-* log options from the driver ones.
+func splitCacheLogOpts(opts map[string]string) (driverOpts, cacheOpts map[string]string) {
+	driverOpts = maps.Clone(opts)
+	cacheOpts = make(map[string]string)
+	for k, v := range opts {
+		if strings.HasPrefix(k, "cache-") {
+			cacheOpts[k] = v
+			delete(driverOpts, k)
+		}
+	}
+	return driverOpts, cacheOpts
+}
+
+// newCacheLogger returns a local logger writing to the cache file.
+func newCacheLogger(cacheOpts map[string]string) *LocalLogger {
+	opts := make(map[string]string)
+	for cacheKey, key := range map[string]string{
+		CacheMaxSize:  MaxSize,
+		CacheMaxFile:  MaxFile,
+		CacheCompress: Compress,
+	} {
+		if v, ok := cacheOpts[cacheKey]; ok {
+			opts[key] = v
+		}
+	}
+	return &LocalLogger{Opts: opts, pathFunc: local.CachePath}
+}
+
+// DualLogger writes the logs both to a driver that cannot be read back
+// and to a local cache that is read by `nerdctl logs`.
+type DualLogger struct {
+	Driver Driver
+	cache  *LocalLogger
+}
+
+func (d *DualLogger) Init(dataStore, ns, id string) error {
+	if err := d.Driver.Init(dataStore, ns, id); err != nil {
+		return err
+	}
+	return d.cache.Init(dataStore, ns, id)
+}
+
+func (d *DualLogger) PreProcess(ctx context.Context, dataStore string, config *logging.Config) error {
+	if err := d.Driver.PreProcess(ctx, dataStore, config); err != nil {
+		return err
+	}
+	if err := d.cache.PreProcess(ctx, dataStore, config); err != nil {
+		// The cache must not prevent the logs from being sent
+		log.G(ctx).WithError(err).Warn("failed to open the local log cache, `nerdctl logs` will not show the logs")
+		d.cache = nil
+	}
+	return nil
+}
+
+func (d *DualLogger) Process(stdout <-chan *message.Message, stderr <-chan *message.Message) error {
+	if d.cache == nil {
+		return d.Driver.Process(stdout, stderr)
+	}
+	driverStdout, cacheStdout := teeLogChannel(stdout)
+	driverStderr, cacheStderr := teeLogChannel(stderr)
+	var (
+		wg       sync.WaitGroup
+		cacheErr error
+	)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		cacheErr = d.cache.Process(cacheStdout, cacheStderr)
+	}()
+	err := d.Driver.Process(driverStdout, driverStderr)
+	// Keep consuming if the driver stopped early, so that the cache gets everything
+	go drainLogChannel(driverStdout)
+	go drainLogChannel(driverStderr)
+	wg.Wait()
+	return errors.Join(err, cacheErr)
+}
+
+func (d *DualLogger) PostProcess() error {
+	err := d.Driver.PostProcess()
+	if d.cache != nil {
+		err = errors.Join(err, d.cache.PostProcess())
+	}
+	return err
+}
+
+// logTeeBuffer is the number of messages buffered for each output of teeLogChannel.
+const logTeeBuffer = 10000
+
+// teeLogChannel duplicates the entries of in to two channels that are closed when in is.
+// The driver output is written in a blocking way, while the entries that do not fit
+// in the cache output are dropped, so that the local cache never blocks the driver.
+func teeLogChannel(in <-chan *message.Message) (driver <-chan *message.Message, cache <-chan *message.Message) {
+	out1 := make(chan *message.Message, logTeeBuffer)
+	out2 := make(chan *message.Message, logTeeBuffer)
+	go func() {
+		defer close(out1)
+		defer close(out2)
+		var dropped int
+		for s := range in {
+			out1 <- s
+			select {
+			case out2 <- s:
+				if dropped > 0 {
+					log.L.Warnf("dropped %d log lines from the local log cache, `nerdctl logs` will not show them", dropped)
+					dropped = 0
+				}
+			default:
+				dropped++
+			}
+		}
+		if dropped > 0 {
+			log.L.Warnf("dropped %d log lines from the local log cache, `nerdctl logs` will not show them", dropped)
+		}
+	}()
+	return out1, out2
+}
+
+func drainLogChannel(c <-chan *message.Message) {
+	for range c {
+	}
+}
+
+// Loads log entries from the local cache of the drivers that cannot be read back.
+func viewLogsCache(lvopts LogViewOptions, stdout, stderr io.Writer, stopChannel chan os.Signal) error {
+	logFilePath := local.CachePath(lvopts.DatastoreRootPath, lvopts.Namespace, lvopts.ContainerID)
+	if _, err := os.Stat(logFilePath); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return errors.New("the local log cache of the container does not exist (created by an older version of nerdctl, or disabled with --log-opt=cache-disabled=true)")
+		}
+		return err
+	}
+	return viewLogsLocalFile(lvopts, logFilePath, stdout, stderr, stopChannel)
+}
@@ -0,0 +1,126 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package logging
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/containerd/v2/core/runtime/v2/logging"
+
+	"github.com/containerd/nerdctl/v2/pkg/logging/message"
+)
+
+func TestCacheLogOptsValidate(t *testing.T) {
+	assert.NilError(t, ValidateLogOpts("syslog", map[string]string{
+		CacheDisabled: "false",
+		CacheMaxSize:  "10m",
+		CacheMaxFile:  "2",
+		CacheCompress: "false",
+	}))
+	assert.ErrorContains(t, ValidateLogOpts("fluentd", map[string]string{CacheMaxFile: "0"}), CacheMaxFile)
+	assert.ErrorContains(t, ValidateLogOpts("fluentd", map[string]string{CacheMaxSize: "big"}), CacheMaxSize)
+	assert.ErrorContains(t, ValidateLogOpts("fluentd", map[string]string{CacheDisabled: "maybe"}), CacheDisabled)
+}
+
+func TestGetDriverWithCache(t *testing.T) {
+	d, err := GetDriver("syslog", map[string]string{CacheMaxFile: "2"}, "")
+	assert.NilError(t, err)
+	dual, ok := d.(*DualLogger)
+	assert.Assert(t, ok)
+	assert.DeepEqual(t, dual.cache.Opts, map[string]string{MaxFile: "2"})
+	_, ok = dual.Driver.(*SyslogLogger)
+	assert.Assert(t, ok)
+
+	d, err = GetDriver("syslog", map[string]string{CacheDisabled: "true"}, "")
+	assert.NilError(t, err)
+	_, ok = d.(*SyslogLogger)
+	assert.Assert(t, ok)
+
+	d, err = GetDriver("json-file", nil, "")
+	assert.NilError(t, err)
+	_, ok = d.(*JSONLogger)
+	assert.Assert(t, ok)
+}
+
+type recordingDriver struct {
+	NoneLogger
+	lines []string
+}
+
+func (r *recordingDriver) Process(stdout <-chan *message.Message, stderr <-chan *message.Message) error {
+	for m := range stdout {
+		r.lines = append(r.lines, m.Line)
+	}
+	for m := range stderr {
+		r.lines = append(r.lines, m.Line)
+	}
+	return nil
+}
+
+func TestDualLogger(t *testing.T) {
+	dataStore := t.TempDir()
+	driver := &recordingDriver{}
+	d := &DualLogger{Driver: driver, cache: newCacheLogger(nil)}
+	assert.NilError(t, d.Init(dataStore, "default", "id"))
+	assert.NilError(t, d.PreProcess(context.Background(), dataStore, &logging.Config{Namespace: "default", ID: "id"}))
+
+	stdout := make(chan *message.Message, 2)
+	stderr := make(chan *message.Message, 1)
+	stdout <- &message.Message{Source: "stdout", Time: time.Now(), Line: "foo"}
+	stdout <- &message.Message{Source: "stdout", Time: time.Now(), Line: "bar"}
+	stderr <- &message.Message{Source: "stderr", Time: time.Now(), Line: "baz"}
+	close(stdout)
+	close(stderr)
+	assert.NilError(t, d.Process(stdout, stderr))
+	assert.NilError(t, d.PostProcess())
+	assert.DeepEqual(t, driver.lines, []string{"foo", "bar", "baz"})
+
+	var outBuf, errBuf bytes.Buffer
+	lvopts := LogViewOptions{ContainerID: "id", Namespace: "default", DatastoreRootPath: dataStore}
+	assert.NilError(t, viewLogsCache(lvopts, &outBuf, &errBuf, make(chan os.Signal)))
+	assert.Equal(t, outBuf.String(), "foo\nbar\n")
+	assert.Equal(t, errBuf.String(), "baz\n")
+}
+
+func TestTeeLogChannelDropsCacheOverflow(t *testing.T) {
+	const lines = 2 * logTeeBuffer
+	in := make(chan *message.Message)
+	driver, cache := teeLogChannel(in)
+	go func() {
+		defer close(in)
+		for i := 0; i < lines; i++ {
+			in <- &message.Message{Source: "stdout", Line: "foo"}
+		}
+	}()
+	// The cache output is not read until the driver output is closed
+	var driverLines int
+	for range driver {
+		driverLines++
+	}
+	var cacheLines int
+	for range cache {
+		cacheLines++
+	}
+	assert.Equal(t, driverLines, lines)
+	assert.Equal(t, cacheLines, logTeeBuffer)
+}
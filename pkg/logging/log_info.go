@@ -0,0 +1,130 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package logging
+
+import (
+	"bytes"
+	"context"
+	"strings"
+
+	"github.com/docker/cli/templates"
+
+	"github.com/containerd/containerd/v2/core/runtime/v2/logging"
+
+	"github.com/containerd/nerdctl/v2/pkg/clientutil"
+	"github.com/containerd/nerdctl/v2/pkg/containerutil"
+)
+
+// containerLogInfo holds the container metadata that the remote drivers attach to the logs.
+type containerLogInfo struct {
+	ID        string
+	FullID    string
+	Namespace string
+	Name      string
+	ImageName string
+	// Tag is the rendered "tag" log option, the short ID by default
+	Tag string
+	// Extra holds the labels and the environment variables selected
+	// with the "labels" and "env" log options
+	Extra map[string]string
+}
+
+// loadContainerLogInfo loads the metadata of the container being logged.
+func loadContainerLogInfo(ctx context.Context, address string, config *logging.Config, opts map[string]string) (*containerLogInfo, error) {
+	client, ctx, cancel, err := clientutil.NewClient(ctx, config.Namespace, address)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		cancel()
+		client.Close()
+	}()
+	container, err := client.LoadContainer(ctx, config.ID)
+	if err != nil {
+		return nil, err
+	}
+	containerLabels, err := container.Labels(ctx)
+	if err != nil {
+		return nil, err
+	}
+	containerInfo, err := container.Info(ctx)
+	if err != nil {
+		return nil, err
+	}
+	spec, err := container.Spec(ctx)
+	if err != nil {
+		return nil, err
+	}
+	env := make(map[string]string)
+	if spec.Process != nil {
+		for _, kv := range spec.Process.Env {
+			if k, v, ok := strings.Cut(kv, "="); ok {
+				env[k] = v
+			}
+		}
+	}
+
+	info := &containerLogInfo{
+		ID:        config.ID[:12],
+		FullID:    config.ID,
+		Namespace: config.Namespace,
+		Name:      containerutil.GetContainerName(containerLabels),
+		ImageName: containerInfo.Image,
+		Extra:     make(map[string]string),
+	}
+	if info.Tag, err = info.renderTag(opts[Tag]); err != nil {
+		return nil, err
+	}
+	for _, k := range splitLogOptList(opts[Labels]) {
+		if v, ok := containerLabels[k]; ok {
+			info.Extra[k] = v
+		}
+	}
+	for _, k := range splitLogOptList(opts[Env]) {
+		if v, ok := env[k]; ok {
+			info.Extra[k] = v
+		}
+	}
+	return info, nil
+}
+
+// renderTag renders the "tag" log option, e.g. "{{.Name}}/{{.ID}}".
+func (info *containerLogInfo) renderTag(tag string) (string, error) {
+	if tag == "" {
+		return info.ID, nil
+	}
+	tmpl, err := templates.Parse(tag)
+	if err != nil {
+		return "", err
+	}
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, info); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// splitLogOptList splits comma-separated log options such as "labels" and "env".
+func splitLogOptList(s string) []string {
+	var ss []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			ss = append(ss, v)
+		}
+	}
+	return ss
+}
@@ -23,9 +23,13 @@ import (
 	"os/exec"
 	"path/filepath"
 
+	"github.com/containerd/errdefs"
 	"github.com/containerd/log"
 
+	"github.com/containerd/nerdctl/v2/pkg/labels"
 	"github.com/containerd/nerdctl/v2/pkg/labels/k8slabels"
+	"github.com/containerd/nerdctl/v2/pkg/logging/logfilter"
+	"github.com/containerd/nerdctl/v2/pkg/logging/loguri"
 )
 
 // Type alias for functions which write out logs to the provided stdout/stderr Writers.
@@ -45,6 +49,7 @@ func RegisterLogViewer(driverName string, lvfn LogViewerFunc) {
 
 func init() {
 	RegisterLogViewer("json-file", viewLogsJSONFile)
+	RegisterLogViewer("local", viewLogsLocal)
 	RegisterLogViewer("journald", viewLogsJournald)
 	RegisterLogViewer("cri", viewLogsCRI)
 }
@@ -88,6 +93,10 @@ type LogViewOptions struct {
 
 	// DetailPrefix is the prefix added when Details is enabled.
 	DetailPrefix *string
+
+	// Filter filters and formats the structured log lines while they are decoded.
+	// It is only supported by the json-file and local drivers.
+	Filter *logfilter.Filter
 }
 
 func (lvo *LogViewOptions) Validate() error {
@@ -131,6 +140,13 @@ func InitContainerLogViewer(containerLabels map[string]string, lvopts LogViewOpt
 			return nil, fmt.Errorf("invalid LogViewOptions provided (%#v): %w", lvopts, err)
 		}
 
+		switch logURI := containerLabels[labels.LogURI]; {
+		case logURI == "none":
+			return nil, fmt.Errorf("log type `none` was selected, nothing to log")
+		case loguri.IsExternal(logURI):
+			return nil, fmt.Errorf("the logs of the container are sent to the log URI %q, the log viewer is unavailable: %w", logURI, errdefs.ErrNotImplemented)
+		}
+
 		lcfg, err = LoadLogConfig(lvopts.DatastoreRootPath, lvopts.Namespace, lvopts.ContainerID)
 		if err != nil {
 			return nil, fmt.Errorf("failed to load logging config: %w", err)
@@ -166,7 +182,10 @@ func (lv *ContainerLogViewer) PrintLogsTo(stdout, stderr io.Writer) error {
 	}
 	viewerFunc, err := getLogViewer(lv.loggingConfig.Driver)
 	if err != nil {
-		return err
+		if !logCacheEnabled(lv.loggingConfig.Driver, lv.loggingConfig.Opts) {
+			return err
+		}
+		viewerFunc = viewLogsCache
 	}
 
 	return viewerFunc(lv.logViewingOptions, stdout, stderr, lv.stopChannel)
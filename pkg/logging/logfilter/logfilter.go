@@ -0,0 +1,231 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package logfilter parses the structured (JSON or logfmt) log lines of the containers,
+// to filter them by pattern or level and to extract their fields.
+package logfilter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/docker/cli/templates"
+)
+
+// levelKeys are the field names of the log level, in order of preference.
+var levelKeys = []string{"level", "lvl", "severity", "loglevel", "log.level"}
+
+// Levels, from the least to the most severe.
+const (
+	levelUnknown = iota
+	levelTrace
+	levelDebug
+	levelInfo
+	levelWarn
+	levelError
+	levelFatal
+)
+
+// Filter filters the log lines by pattern and by level, and formats them with a template.
+// A nil *Filter keeps the lines unchanged.
+type Filter struct {
+	grep  *regexp.Regexp
+	level int
+	tmpl  *template.Template
+}
+
+// New returns a Filter for a pattern, a minimal level and a template of the fields,
+// or nil if all of them are empty.
+func New(grep, level, format string) (*Filter, error) {
+	if grep == "" && level == "" && format == "" {
+		return nil, nil
+	}
+	f := &Filter{}
+	var err error
+	if grep != "" {
+		if f.grep, err = regexp.Compile(grep); err != nil {
+			return nil, fmt.Errorf("invalid grep pattern %q: %w", grep, err)
+		}
+	}
+	if level != "" {
+		if f.level = parseLevel(level); f.level == levelUnknown {
+			return nil, fmt.Errorf("invalid level %q (supported: trace, debug, info, warn, error, fatal)", level)
+		}
+	}
+	if format != "" {
+		if f.tmpl, err = templates.Parse(format); err != nil {
+			return nil, fmt.Errorf("invalid format %q: %w", format, err)
+		}
+	}
+	return f, nil
+}
+
+// Selective returns whether the filter may filter out lines, i.e. it has a pattern or a level.
+func (f *Filter) Selective() bool {
+	return f != nil && (f.grep != nil || f.level != levelUnknown)
+}
+
+// Apply returns the text to print for a log line, that may end with a newline,
+// and false if the line is filtered out.
+//
+// The lines that are not structured, or have no level, are filtered out when a level is set.
+// The lines that are not structured are printed unchanged with a template.
+func (f *Filter) Apply(line string) (string, bool) {
+	if f == nil {
+		return line, true
+	}
+	text, newline := strings.CutSuffix(line, "\n")
+	if f.grep != nil && !f.grep.MatchString(text) {
+		return "", false
+	}
+	if f.level == levelUnknown && f.tmpl == nil {
+		return line, true
+	}
+	fields := ParseFields(text)
+	if f.level != levelUnknown && fieldsLevel(fields) < f.level {
+		return "", false
+	}
+	if f.tmpl == nil || fields == nil {
+		return line, true
+	}
+	var b bytes.Buffer
+	if err := f.tmpl.Execute(&b, fields); err != nil {
+		return line, true
+	}
+	if newline {
+		b.WriteByte('\n')
+	}
+	return b.String(), true
+}
+
+// ParseFields returns the fields of a JSON object or of a logfmt line,
+// or nil if the line is not structured.
+func ParseFields(line string) map[string]any {
+	line = strings.TrimSpace(line)
+	if strings.HasPrefix(line, "{") {
+		var fields map[string]any
+		if err := json.Unmarshal([]byte(line), &fields); err != nil {
+			return nil
+		}
+		return fields
+	}
+	return parseLogfmt(line)
+}
+
+// parseLogfmt parses a line of key=value pairs, where the values may be quoted.
+// It returns nil unless all the words of the line are pairs.
+func parseLogfmt(line string) map[string]any {
+	fields := make(map[string]any)
+	for line != "" {
+		key, rest, ok := strings.Cut(line, "=")
+		if !ok || !validLogfmtKey(key) {
+			return nil
+		}
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			quoted, err := strconv.QuotedPrefix(rest)
+			if err != nil {
+				return nil
+			}
+			if value, err = strconv.Unquote(quoted); err != nil {
+				return nil
+			}
+			rest = rest[len(quoted):]
+			if rest != "" && rest[0] != ' ' {
+				return nil
+			}
+		} else {
+			value, rest, _ = strings.Cut(rest, " ")
+		}
+		fields[key] = value
+		line = strings.TrimLeft(rest, " ")
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	return fields
+}
+
+func validLogfmtKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for _, r := range key {
+		if unicode.IsSpace(r) || r == '"' || r == '=' {
+			return false
+		}
+	}
+	return true
+}
+
+// fieldsLevel returns the level of the fields of a log line, or levelUnknown.
+func fieldsLevel(fields map[string]any) int {
+	for _, k := range levelKeys {
+		switch v := fields[k].(type) {
+		case string:
+			return parseLevel(v)
+		case float64:
+			// bunyan and pino levels
+			return numericLevel(v)
+		}
+	}
+	return levelUnknown
+}
+
+func parseLevel(s string) int {
+	switch strings.ToLower(s) {
+	case "trace", "trc":
+		return levelTrace
+	case "debug", "dbg":
+		return levelDebug
+	case "info", "inf", "information", "notice":
+		return levelInfo
+	case "warn", "warning", "wrn":
+		return levelWarn
+	case "error", "err", "erro":
+		return levelError
+	case "fatal", "critical", "crit", "panic", "alert", "emerg", "emergency":
+		return levelFatal
+	}
+	if n, err := strconv.ParseFloat(s, 64); err == nil {
+		return numericLevel(n)
+	}
+	return levelUnknown
+}
+
+func numericLevel(n float64) int {
+	switch {
+	case n >= 60:
+		return levelFatal
+	case n >= 50:
+		return levelError
+	case n >= 40:
+		return levelWarn
+	case n >= 30:
+		return levelInfo
+	case n >= 20:
+		return levelDebug
+	case n >= 10:
+		return levelTrace
+	}
+	return levelUnknown
+}
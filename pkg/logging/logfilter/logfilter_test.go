@@ -0,0 +1,155 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package logfilter
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestParseFields(t *testing.T) {
+	testCases := []struct {
+		line     string
+		expected map[string]any
+	}{
+		{
+			line:     `{"level":"info","msg":"started","port":8080}`,
+			expected: map[string]any{"level": "info", "msg": "started", "port": float64(8080)},
+		},
+		{
+			line:     `level=warn msg="disk almost full" used=95%`,
+			expected: map[string]any{"level": "warn", "msg": "disk almost full", "used": "95%"},
+		},
+		{
+			line:     `time=2024-01-01T00:00:00Z level=error msg=`,
+			expected: map[string]any{"time": "2024-01-01T00:00:00Z", "level": "error", "msg": ""},
+		},
+		{
+			line: "plain text line",
+		},
+		{
+			line: "result: a=b",
+		},
+		{
+			line: `{"not json`,
+		},
+		{
+			line: `msg="unterminated`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.line, func(t *testing.T) {
+			assert.DeepEqual(t, ParseFields(tc.line), tc.expected)
+		})
+	}
+}
+
+func TestFilterApply(t *testing.T) {
+	testCases := []struct {
+		name     string
+		grep     string
+		level    string
+		format   string
+		line     string
+		expected string
+		ok       bool
+	}{
+		{
+			name:     "grep",
+			grep:     "conn(ect|ection)",
+			line:     "connection refused\n",
+			expected: "connection refused\n",
+			ok:       true,
+		},
+		{
+			name: "grep no match",
+			grep: "^GET",
+			line: "POST /\n",
+		},
+		{
+			name:     "level JSON",
+			level:    "warn",
+			line:     `{"level":"ERROR","msg":"failed"}` + "\n",
+			expected: `{"level":"ERROR","msg":"failed"}` + "\n",
+			ok:       true,
+		},
+		{
+			name:  "level logfmt too low",
+			level: "warning",
+			line:  "lvl=info msg=ok\n",
+		},
+		{
+			name:     "level numeric",
+			level:    "error",
+			line:     `{"level":60,"msg":"crashed"}` + "\n",
+			expected: `{"level":60,"msg":"crashed"}` + "\n",
+			ok:       true,
+		},
+		{
+			name:  "level not structured",
+			level: "debug",
+			line:  "plain\n",
+		},
+		{
+			name:     "format",
+			format:   "{{.level}}: {{.msg}}",
+			line:     `{"level":"info","msg":"started"}` + "\n",
+			expected: "info: started\n",
+			ok:       true,
+		},
+		{
+			name:     "format not structured",
+			format:   "{{.msg}}",
+			line:     "plain\n",
+			expected: "plain\n",
+			ok:       true,
+		},
+		{
+			name:     "format partial line",
+			format:   "{{.msg}}",
+			line:     "msg=partial",
+			expected: "partial",
+			ok:       true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f, err := New(tc.grep, tc.level, tc.format)
+			assert.NilError(t, err)
+			text, ok := f.Apply(tc.line)
+			assert.Equal(t, ok, tc.ok)
+			assert.Equal(t, text, tc.expected)
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	f, err := New("", "", "")
+	assert.NilError(t, err)
+	assert.Assert(t, f == nil)
+	text, ok := f.Apply("line\n")
+	assert.Assert(t, ok)
+	assert.Equal(t, text, "line\n")
+
+	_, err = New("(", "", "")
+	assert.ErrorContains(t, err, "invalid grep pattern")
+	_, err = New("", "verbose", "")
+	assert.ErrorContains(t, err, "invalid level")
+	_, err = New("", "", "{{.msg")
+	assert.ErrorContains(t, err, "invalid format")
+}
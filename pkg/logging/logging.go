@@ -23,6 +23,8 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"maps"
+	"math"
 	"os"
 	"path/filepath"
 	"sort"
@@ -30,6 +32,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/docker/go-units"
 	"github.com/fsnotify/fsnotify"
 	"github.com/muesli/cancelreader"
 
@@ -38,24 +41,33 @@ import (
 	"github.com/containerd/errdefs"
 	"github.com/containerd/log"
 
+	"github.com/containerd/nerdctl/v2/pkg/idgen"
 	"github.com/containerd/nerdctl/v2/pkg/internal/filesystem"
+	"github.com/containerd/nerdctl/v2/pkg/logging/loguri"
+	"github.com/containerd/nerdctl/v2/pkg/logging/message"
 )
 
 const (
 	// MagicArgv1 is the magic argv1 for the containerd runtime v2 logging plugin mode.
-	MagicArgv1 = "_NERDCTL_INTERNAL_LOGGING"
+	MagicArgv1 = loguri.MagicArgv1
 	LogPath    = "log-path"
 	MaxSize    = "max-size"
 	MaxFile    = "max-file"
+	Compress   = "compress"
 	Tag        = "tag"
 	Env        = "env"
 	Labels     = "labels"
+	// MaxLineSize is the size above which the lines are split into partial messages
+	MaxLineSize = "max-line-size"
 )
 
+// DefaultMaxLineSize corresponds to the buffer size of Docker's log copier.
+const DefaultMaxLineSize = 16 * 1024
+
 type Driver interface {
 	Init(dataStore, ns, id string) error
 	PreProcess(ctx context.Context, dataStore string, config *logging.Config) error
-	Process(stdout <-chan string, stderr <-chan string) error
+	Process(stdout <-chan *message.Message, stderr <-chan *message.Message) error
 	PostProcess() error
 }
 
@@ -66,6 +78,16 @@ var drivers = make(map[string]DriverFactory)
 var driversLogOptsValidateFunctions = make(map[string]LogOptsValidateFunc)
 
 func ValidateLogOpts(logDriver string, logOpts map[string]string) error {
+	if _, err := parseMaxLineSize(logOpts); err != nil {
+		return err
+	}
+	logOpts = withoutMaxLineSize(logOpts)
+	if usesLogCache(logDriver) {
+		if err := CacheLogOptsValidate(logOpts); err != nil {
+			return err
+		}
+		logOpts, _ = splitCacheLogOpts(logOpts)
+	}
 	if value, ok := driversLogOptsValidateFunctions[logDriver]; ok && value != nil {
 		return value(logOpts)
 	}
@@ -91,7 +113,41 @@ func GetDriver(name string, opts map[string]string, address string) (Driver, err
 	if !ok {
 		return nil, fmt.Errorf("unknown logging driver %q: %w", name, errdefs.ErrNotFound)
 	}
-	return driverFactory(opts, address)
+	opts = withoutMaxLineSize(opts)
+	if !usesLogCache(name) {
+		return driverFactory(opts, address)
+	}
+	driverOpts, cacheOpts := splitCacheLogOpts(opts)
+	driver, err := driverFactory(driverOpts, address)
+	if err != nil || !logCacheEnabled(name, opts) {
+		return driver, err
+	}
+	return &DualLogger{Driver: driver, cache: newCacheLogger(cacheOpts)}, nil
+}
+
+// parseMaxLineSize parses the max-line-size log option that applies to all the drivers.
+func parseMaxLineSize(opts map[string]string) (int, error) {
+	v, ok := opts[MaxLineSize]
+	if !ok {
+		return DefaultMaxLineSize, nil
+	}
+	size, err := units.RAMInBytes(v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value for log-opt %s: %w", MaxLineSize, err)
+	}
+	if size <= 0 || size > math.MaxInt32 {
+		return 0, fmt.Errorf("%s must be a positive size, got %q", MaxLineSize, v)
+	}
+	return int(size), nil
+}
+
+func withoutMaxLineSize(opts map[string]string) map[string]string {
+	if _, ok := opts[MaxLineSize]; !ok {
+		return opts
+	}
+	opts = maps.Clone(opts)
+	delete(opts, MaxLineSize)
+	return opts
 }
 
 func init() {
@@ -101,6 +157,9 @@ func init() {
 	RegisterDriver("json-file", func(opts map[string]string, address string) (Driver, error) {
 		return &JSONLogger{Opts: opts}, nil
 	}, JSONFileLogOptsValidate)
+	RegisterDriver("local", func(opts map[string]string, address string) (Driver, error) {
+		return &LocalLogger{Opts: opts}, nil
+	}, LocalLogOptsValidate)
 	RegisterDriver("journald", func(opts map[string]string, address string) (Driver, error) {
 		return &JournaldLogger{Opts: opts, Address: address}, nil
 	}, JournalLogOptsValidate)
@@ -110,6 +169,12 @@ func init() {
 	RegisterDriver("syslog", func(opts map[string]string, address string) (Driver, error) {
 		return &SyslogLogger{Opts: opts}, nil
 	}, SyslogOptsValidate)
+	RegisterDriver("gelf", func(opts map[string]string, address string) (Driver, error) {
+		return &GelfLogger{Opts: opts, Address: address}, nil
+	}, GelfLogOptsValidate)
+	RegisterDriver("loki", func(opts map[string]string, address string) (Driver, error) {
+		return &LokiLogger{Opts: opts, Address: address}, nil
+	}, LokiLogOptsValidate)
 }
 
 // Main is the entrypoint for the containerd runtime v2 logging plugin mode.
@@ -207,7 +272,7 @@ func getContainerWait(ctx context.Context, address string, config *logging.Confi
 
 type ContainerWaitFunc func(ctx context.Context, address string, config *logging.Config) (<-chan containerd.ExitStatus, error)
 
-func loggingProcessAdapter(ctx context.Context, driver Driver, dataStore, address string, getContainerWait ContainerWaitFunc, config *logging.Config) error {
+func loggingProcessAdapter(ctx context.Context, driver Driver, dataStore, address string, getContainerWait ContainerWaitFunc, config *logging.Config, maxLineSize int) error {
 	if err := driver.PreProcess(ctx, dataStore, config); err != nil {
 		return err
 	}
@@ -242,29 +307,50 @@ func loggingProcessAdapter(ctx context.Context, driver Driver, dataStore, addres
 
 	var wg sync.WaitGroup
 	wg.Add(3)
-	stdout := make(chan string, 10000)
-	stderr := make(chan string, 10000)
-	processLogFunc := func(reader io.Reader, dataChan chan string) {
+	stdout := make(chan *message.Message, 10000)
+	stderr := make(chan *message.Message, 10000)
+	processLogFunc := func(reader io.Reader, dataChan chan *message.Message, source string) {
 		defer wg.Done()
 		defer close(dataChan)
-		r := bufio.NewReader(reader)
-
-		var err error
-
-		for err == nil {
-			var s string
-			s, err = r.ReadString('\n')
-			if len(s) > 0 {
-				dataChan <- s
+		// lines that do not fit in the buffer are split into partial messages
+		r := bufio.NewReaderSize(reader, maxLineSize)
+		var partial *message.PartialMetadata
+		for {
+			b, err := r.ReadSlice('\n')
+			if len(b) > 0 {
+				m := &message.Message{
+					Source: source,
+					Time:   time.Now().UTC(),
+				}
+				if b[len(b)-1] == '\n' {
+					b = b[:len(b)-1]
+					if partial != nil {
+						m.Partial = &message.PartialMetadata{ID: partial.ID, Ordinal: partial.Ordinal + 1, Last: true}
+						partial = nil
+					}
+				} else {
+					if partial == nil {
+						partial = &message.PartialMetadata{ID: idgen.GenerateID()}
+					}
+					partial.Ordinal++
+					m.Partial = &message.PartialMetadata{ID: partial.ID, Ordinal: partial.Ordinal}
+				}
+				m.Line = string(b)
+				dataChan <- m
 			}
-
-			if err != nil && err != io.EOF {
-				log.L.WithError(err).Error("failed to read log")
+			if errors.Is(err, bufio.ErrBufferFull) {
+				continue
+			}
+			if err != nil {
+				if err != io.EOF {
+					log.L.WithError(err).Error("failed to read log")
+				}
+				return
 			}
 		}
 	}
-	go processLogFunc(pipeStdoutR, stdout)
-	go processLogFunc(pipeStderrR, stderr)
+	go processLogFunc(pipeStdoutR, stdout, "stdout")
+	go processLogFunc(pipeStderrR, stderr, "stderr")
 	go func() {
 		defer wg.Done()
 		driver.Process(stdout, stderr)
@@ -303,6 +389,10 @@ func loggerFunc(dataStore string) (logging.LoggerFunc, error) {
 			if err != nil {
 				return err
 			}
+			maxLineSize, err := parseMaxLineSize(logConfig.Opts)
+			if err != nil {
+				return err
+			}
 
 			loggerLock := getLockPath(dataStore, config.Namespace, config.ID)
 
@@ -314,7 +404,7 @@ func loggerFunc(dataStore string) (logging.LoggerFunc, error) {
 					return err
 				}
 				// getContainerWait is extracted as parameter to allow mocking in tests.
-				return loggingProcessAdapter(ctx, driver, dataStore, logConfig.Address, getContainerWait, config)
+				return loggingProcessAdapter(ctx, driver, dataStore, logConfig.Address, getContainerWait, config, maxLineSize)
 			})
 		} else if !errors.Is(err, os.ErrNotExist) {
 			// the file does not exist if the container was created with nerdctl < 0.20
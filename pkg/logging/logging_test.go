@@ -25,14 +25,18 @@ import (
 	"testing"
 	"time"
 
+	"gotest.tools/v3/assert"
+
 	containerd "github.com/containerd/containerd/v2/client"
 	"github.com/containerd/containerd/v2/core/runtime/v2/logging"
+
+	"github.com/containerd/nerdctl/v2/pkg/logging/message"
 )
 
 type MockDriver struct {
 	processed      bool
-	receivedStdout []string
-	receivedStderr []string
+	receivedStdout []*message.Message
+	receivedStderr []*message.Message
 }
 
 func (m *MockDriver) Init(dataStore, ns, id string) error {
@@ -43,12 +47,12 @@ func (m *MockDriver) PreProcess(ctx context.Context, dataStore string, config *l
 	return nil
 }
 
-func (m *MockDriver) Process(stdout <-chan string, stderr <-chan string) error {
-	for line := range stdout {
-		m.receivedStdout = append(m.receivedStdout, line)
+func (m *MockDriver) Process(stdout <-chan *message.Message, stderr <-chan *message.Message) error {
+	for msg := range stdout {
+		m.receivedStdout = append(m.receivedStdout, msg)
 	}
-	for line := range stderr {
-		m.receivedStderr = append(m.receivedStderr, line)
+	for msg := range stderr {
+		m.receivedStderr = append(m.receivedStderr, msg)
 	}
 	m.processed = true
 	return nil
@@ -86,7 +90,7 @@ func TestLoggingProcessAdapter(t *testing.T) {
 		return exitChan, nil
 	}
 
-	err := loggingProcessAdapter(ctx, driver, "testDataStore", "", getContainerWaitMock, config)
+	err := loggingProcessAdapter(ctx, driver, "testDataStore", "", getContainerWaitMock, config, DefaultMaxLineSize)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -100,8 +104,8 @@ func TestLoggingProcessAdapter(t *testing.T) {
 	}
 
 	// Verify that the driver received the expected data
-	stdout := strings.Join(driver.receivedStdout, "\n")
-	stderr := strings.Join(driver.receivedStderr, "\n")
+	stdout := joinMessages(driver.receivedStdout)
+	stderr := joinMessages(driver.receivedStderr)
 
 	if stdout != normalString {
 		t.Fatalf("stdout is %s, expected %s", stdout, normalString)
@@ -112,6 +116,71 @@ func TestLoggingProcessAdapter(t *testing.T) {
 	}
 }
 
+func TestLoggingProcessAdapterPartialMessages(t *testing.T) {
+	longLine := generateRandomString(40)
+	driver := &MockDriver{}
+	config := &logging.Config{
+		Stdout: bytes.NewBufferString("short\n" + longLine + "\nno newline"),
+		Stderr: bytes.NewBufferString(""),
+	}
+	var getContainerWaitMock ContainerWaitFunc = func(ctx context.Context, address string, config *logging.Config) (<-chan containerd.ExitStatus, error) {
+		exitChan := make(chan containerd.ExitStatus, 1)
+		time.Sleep(50 * time.Millisecond)
+		exitChan <- containerd.ExitStatus{}
+		return exitChan, nil
+	}
+	// bufio does not use buffers smaller than 16 bytes
+	assert.NilError(t, loggingProcessAdapter(context.Background(), driver, "testDataStore", "", getContainerWaitMock, config, 16))
+
+	msgs := driver.receivedStdout
+	assert.Equal(t, len(msgs), 5)
+	assert.Equal(t, msgs[0].Line, "short")
+	assert.Assert(t, msgs[0].Partial == nil)
+	assert.Equal(t, msgs[0].Source, "stdout")
+
+	// the long line is split into 3 parts sharing the same ID
+	for i, m := range msgs[1:4] {
+		assert.Assert(t, m.Partial != nil)
+		assert.Equal(t, m.Partial.ID, msgs[1].Partial.ID)
+		assert.Equal(t, m.Partial.Ordinal, i+1)
+		assert.Equal(t, m.Partial.Last, i == 2)
+	}
+	assert.Equal(t, msgs[1].Line+msgs[2].Line+msgs[3].Line, longLine)
+
+	// the text without a newline is a partial message that does not end the line
+	assert.Equal(t, msgs[4].Line, "no newline")
+	assert.Assert(t, msgs[4].Partial != nil)
+	assert.Assert(t, msgs[4].Partial.ID != msgs[1].Partial.ID)
+	assert.Assert(t, !msgs[4].EndsLine())
+}
+
+func TestMaxLineSizeLogOpt(t *testing.T) {
+	size, err := parseMaxLineSize(map[string]string{})
+	assert.NilError(t, err)
+	assert.Equal(t, size, DefaultMaxLineSize)
+	size, err = parseMaxLineSize(map[string]string{MaxLineSize: "1m"})
+	assert.NilError(t, err)
+	assert.Equal(t, size, 1024*1024)
+	assert.ErrorContains(t, ValidateLogOpts("json-file", map[string]string{MaxLineSize: "0"}), MaxLineSize)
+	assert.ErrorContains(t, ValidateLogOpts("json-file", map[string]string{MaxLineSize: "huge"}), MaxLineSize)
+
+	d, err := GetDriver("json-file", map[string]string{MaxLineSize: "1m", MaxFile: "2"}, "")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, d.(*JSONLogger).Opts, map[string]string{MaxFile: "2"})
+}
+
+// joinMessages reassembles the text of the messages.
+func joinMessages(msgs []*message.Message) string {
+	var sb strings.Builder
+	for _, m := range msgs {
+		sb.WriteString(m.Line)
+		if m.EndsLine() {
+			sb.WriteByte('\n')
+		}
+	}
+	return sb.String()
+}
+
 // generateRandomString creates a random string of the given size.
 func generateRandomString(size int) string {
 	characters := "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
@@ -0,0 +1,99 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package loguri handles the log URIs of the containerd shims, that are used to forward
+// the container streams to nerdctl's logging drivers, to another logging binary, or to a file.
+package loguri
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+)
+
+const (
+	// MagicArgv1 is the magic argv1 for the containerd runtime v2 logging plugin mode.
+	MagicArgv1 = "_NERDCTL_INTERNAL_LOGGING"
+
+	// Schemes of the log URIs that containerd shims support.
+	SchemeBinary = "binary"
+	SchemeFile   = "file"
+)
+
+// IsLogURI returns true if the --log-driver value is a log URI such as
+// "binary:///usr/local/bin/logger?opt=val" or "file:///var/log/container.log",
+// rather than the name of a nerdctl logging driver.
+func IsLogURI(logDriver string) bool {
+	u, err := url.Parse(logDriver)
+	return err == nil && u.Scheme != ""
+}
+
+// Parse validates a log URI passed with --log-driver.
+// The log options are passed to the logging binary as arguments, in the URI query.
+func Parse(logDriver string, logOpts map[string]string) (*url.URL, error) {
+	u, err := url.Parse(logDriver)
+	if err != nil {
+		return nil, fmt.Errorf("invalid log URI %q: %w", logDriver, err)
+	}
+	if u.Host != "" {
+		return nil, fmt.Errorf("invalid log URI %q: the path must be absolute, e.g. %s:///path", logDriver, u.Scheme)
+	}
+	if !filepath.IsAbs(filepath.FromSlash(u.Path)) {
+		return nil, fmt.Errorf("invalid log URI %q: the path must be absolute", logDriver)
+	}
+	switch u.Scheme {
+	case SchemeBinary:
+		st, err := os.Stat(u.Path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid log URI %q: %w", logDriver, err)
+		}
+		if st.IsDir() {
+			return nil, fmt.Errorf("invalid log URI %q: %q is a directory", logDriver, u.Path)
+		}
+		if len(logOpts) > 0 {
+			q := u.Query()
+			for k, v := range logOpts {
+				q.Set(k, v)
+			}
+			u.RawQuery = q.Encode()
+		}
+	case SchemeFile:
+		if len(logOpts) > 0 {
+			return nil, errors.New("log options are not supported with file:// log URIs")
+		}
+		if u.RawQuery != "" {
+			return nil, fmt.Errorf("invalid log URI %q: file:// log URIs do not take a query", logDriver)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported log URI scheme %q (supported: %s://, %s://)", u.Scheme, SchemeBinary, SchemeFile)
+	}
+	return u, nil
+}
+
+// IsExternal returns true if the log URI of a container is not handled by nerdctl,
+// so that its logs cannot be read back.
+func IsExternal(logURI string) bool {
+	if logURI == "" || logURI == "none" {
+		return false
+	}
+	u, err := url.Parse(logURI)
+	if err != nil {
+		return true
+	}
+	return u.Scheme != SchemeBinary || !u.Query().Has(MagicArgv1)
+}
@@ -0,0 +1,65 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package loguri
+
+import (
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/nerdctl/v2/pkg/internal/filesystem"
+)
+
+func TestParse(t *testing.T) {
+	binary := filepath.Join(t.TempDir(), "logger")
+	assert.NilError(t, filesystem.WriteFile(binary, []byte("#!/bin/sh\n"), 0755))
+
+	u, err := Parse("binary://"+binary+"?foo=bar", map[string]string{"baz": "qux"})
+	assert.NilError(t, err)
+	assert.Equal(t, u.Path, binary)
+	assert.Equal(t, u.Query().Get("foo"), "bar")
+	assert.Equal(t, u.Query().Get("baz"), "qux")
+
+	u, err = Parse("file:///var/log/container.log", nil)
+	assert.NilError(t, err)
+	assert.Equal(t, u.String(), "file:///var/log/container.log")
+
+	for _, tc := range []struct {
+		logDriver string
+		logOpts   map[string]string
+	}{
+		{logDriver: "binary://" + filepath.Dir(binary)},
+		{logDriver: "binary://" + binary + ".missing"},
+		{logDriver: "binary://logger"},
+		{logDriver: "file://var/log/container.log"},
+		{logDriver: "file:///var/log/container.log?foo=bar"},
+		{logDriver: "file:///var/log/container.log", logOpts: map[string]string{"foo": "bar"}},
+		{logDriver: "fifo:///run/container.fifo"},
+	} {
+		_, err := Parse(tc.logDriver, tc.logOpts)
+		assert.Assert(t, err != nil, "expected an error for %q", tc.logDriver)
+	}
+}
+
+func TestIsExternal(t *testing.T) {
+	assert.Assert(t, !IsExternal(""))
+	assert.Assert(t, !IsExternal("none"))
+	assert.Assert(t, !IsExternal("binary:///usr/local/bin/nerdctl?"+MagicArgv1+"=%2Fvar%2Flib%2Fnerdctl%2F1935db59"))
+	assert.Assert(t, IsExternal("binary:///usr/local/bin/logger?foo=bar"))
+	assert.Assert(t, IsExternal("file:///var/log/container.log"))
+}
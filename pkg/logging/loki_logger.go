@@ -0,0 +1,475 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/docker/go-units"
+
+	"github.com/containerd/containerd/v2/core/runtime/v2/logging"
+	"github.com/containerd/log"
+
+	"github.com/containerd/nerdctl/v2/pkg/logging/message"
+	"github.com/containerd/nerdctl/v2/pkg/strutil"
+)
+
+const (
+	lokiURL            = "loki-url"
+	lokiTenantID       = "loki-tenant-id"
+	lokiBatchSize      = "loki-batch-size"
+	lokiBatchWait      = "loki-batch-wait"
+	lokiTimeout        = "loki-timeout"
+	lokiRetries        = "loki-retries"
+	lokiMinBackoff     = "loki-min-backoff"
+	lokiMaxBackoff     = "loki-max-backoff"
+	lokiExternalLabels = "loki-external-labels"
+)
+
+var LokiLogOpts = []string{
+	lokiURL,
+	lokiTenantID,
+	lokiBatchSize,
+	lokiBatchWait,
+	lokiTimeout,
+	lokiRetries,
+	lokiMinBackoff,
+	lokiMaxBackoff,
+	lokiExternalLabels,
+	Tag,
+	Labels,
+	Env,
+}
+
+// The defaults correspond to the Loki Docker driver.
+const (
+	lokiDefaultBatchSize  = 1024 * 1024
+	lokiDefaultBatchWait  = time.Second
+	lokiDefaultTimeout    = 10 * time.Second
+	lokiDefaultRetries    = 10
+	lokiDefaultMinBackoff = 500 * time.Millisecond
+	lokiDefaultMaxBackoff = 5 * time.Minute
+)
+
+// The bounds of the queues of the client, so that a slow or unreachable Loki never blocks the container.
+const (
+	lokiMaxPendingEntries = 1024
+	lokiMaxPendingBatches = 10
+)
+
+var lokiLabelNameRegexp = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+func LokiLogOptsValidate(logOptMap map[string]string) error {
+	for key := range logOptMap {
+		if !strutil.InStringSlice(LokiLogOpts, key) {
+			log.L.Warnf("log-opt %s is ignored for loki log driver", key)
+		}
+	}
+	_, err := parseLokiConfig(logOptMap)
+	return err
+}
+
+// lokiConfig is the parsed configuration of the loki driver.
+type lokiConfig struct {
+	url            string
+	tenantID       string
+	batchSize      int
+	batchWait      time.Duration
+	timeout        time.Duration
+	retries        int
+	minBackoff     time.Duration
+	maxBackoff     time.Duration
+	externalLabels map[string]string
+}
+
+func parseLokiConfig(opts map[string]string) (*lokiConfig, error) {
+	rawURL, ok := opts[lokiURL]
+	if !ok {
+		return nil, fmt.Errorf("%s is required for loki log driver", lokiURL)
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s %q: %w", lokiURL, rawURL, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("%s must be an http or https URL, got %q", lokiURL, rawURL)
+	}
+	cfg := &lokiConfig{
+		url:            rawURL,
+		tenantID:       opts[lokiTenantID],
+		batchSize:      lokiDefaultBatchSize,
+		batchWait:      lokiDefaultBatchWait,
+		timeout:        lokiDefaultTimeout,
+		retries:        lokiDefaultRetries,
+		minBackoff:     lokiDefaultMinBackoff,
+		maxBackoff:     lokiDefaultMaxBackoff,
+		externalLabels: make(map[string]string),
+	}
+	if v, ok := opts[lokiBatchSize]; ok {
+		size, err := units.FromHumanSize(v)
+		if err != nil || size <= 0 {
+			return nil, fmt.Errorf("%s must be a positive size, got %q", lokiBatchSize, v)
+		}
+		cfg.batchSize = int(size)
+	}
+	if v, ok := opts[lokiRetries]; ok {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("%s must be a positive integer, got %q", lokiRetries, v)
+		}
+		cfg.retries = n
+	}
+	for key, d := range map[string]*time.Duration{
+		lokiBatchWait:  &cfg.batchWait,
+		lokiTimeout:    &cfg.timeout,
+		lokiMinBackoff: &cfg.minBackoff,
+		lokiMaxBackoff: &cfg.maxBackoff,
+	} {
+		if v, ok := opts[key]; ok {
+			parsed, err := time.ParseDuration(v)
+			if err != nil || parsed <= 0 {
+				return nil, fmt.Errorf("%s must be a positive duration, got %q", key, v)
+			}
+			*d = parsed
+		}
+	}
+	if cfg.minBackoff > cfg.maxBackoff {
+		return nil, fmt.Errorf("%s cannot be greater than %s", lokiMinBackoff, lokiMaxBackoff)
+	}
+	for _, kv := range splitLogOptList(opts[lokiExternalLabels]) {
+		k, v, ok := strings.Cut(kv, "=")
+		if !ok || k == "" {
+			return nil, fmt.Errorf("%s should be in the form name=value,..., got %q", lokiExternalLabels, kv)
+		}
+		cfg.externalLabels[k] = v
+	}
+	return cfg, nil
+}
+
+type LokiLogger struct {
+	Opts    map[string]string
+	Address string
+	client  *lokiClient
+	labels  map[string]string
+}
+
+func (l *LokiLogger) Init(dataStore, ns, id string) error {
+	return nil
+}
+
+func (l *LokiLogger) PreProcess(ctx context.Context, _ string, config *logging.Config) error {
+	cfg, err := parseLokiConfig(l.Opts)
+	if err != nil {
+		return err
+	}
+	info, err := loadContainerLogInfo(ctx, l.Address, config, l.Opts)
+	if err != nil {
+		return err
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		return err
+	}
+	l.labels, err = lokiStreamLabels(cfg, hostname, info)
+	if err != nil {
+		return err
+	}
+	l.client = newLokiClient(cfg)
+	return nil
+}
+
+func (l *LokiLogger) Process(stdout <-chan *message.Message, stderr <-chan *message.Message) error {
+	var wg sync.WaitGroup
+	wg.Add(2)
+	fn := func(dataChan <-chan *message.Message, source string) {
+		defer wg.Done()
+		labels := make(map[string]string, len(l.labels)+1)
+		for k, v := range l.labels {
+			labels[k] = v
+		}
+		labels["source"] = source
+		l.client.pushMessages(labels, dataChan)
+	}
+	go fn(stdout, "stdout")
+	go fn(stderr, "stderr")
+	wg.Wait()
+	return nil
+}
+
+func (l *LokiLogger) PostProcess() error {
+	l.client.Close()
+	return nil
+}
+
+// lokiStreamLabels returns the labels of the streams of the container.
+// The values of the external labels are templates like the "tag" log option.
+func lokiStreamLabels(cfg *lokiConfig, hostname string, info *containerLogInfo) (map[string]string, error) {
+	labels := map[string]string{
+		"host":           hostname,
+		"container_name": info.Name,
+	}
+	if info.Tag != info.ID {
+		labels["tag"] = info.Tag
+	}
+	for k, v := range info.Extra {
+		labels[sanitizeLokiLabelName(k)] = v
+	}
+	for k, tmpl := range cfg.externalLabels {
+		v, err := info.renderTag(tmpl)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value %q: %w", lokiExternalLabels, tmpl, err)
+		}
+		labels[sanitizeLokiLabelName(k)] = v
+	}
+	return labels, nil
+}
+
+func sanitizeLokiLabelName(s string) string {
+	s = lokiLabelNameRegexp.ReplaceAllString(s, "_")
+	if s != "" && s[0] >= '0' && s[0] <= '9' {
+		s = "_" + s
+	}
+	return s
+}
+
+type lokiEntry struct {
+	labels map[string]string
+	time   time.Time
+	line   string
+}
+
+// lokiStream is a stream of the push API.
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+type lokiBatch struct {
+	streams map[string]*lokiStream
+	size    int
+	created time.Time
+}
+
+func newLokiBatch() *lokiBatch {
+	return &lokiBatch{streams: make(map[string]*lokiStream), created: time.Now()}
+}
+
+func (b *lokiBatch) add(e lokiEntry) {
+	keys := make([]string, 0, len(e.labels))
+	for k, v := range e.labels {
+		keys = append(keys, k+"="+strconv.Quote(v))
+	}
+	sort.Strings(keys)
+	key := strings.Join(keys, ",")
+	s, ok := b.streams[key]
+	if !ok {
+		s = &lokiStream{Stream: e.labels}
+		b.streams[key] = s
+	}
+	s.Values = append(s.Values, [2]string{strconv.FormatInt(e.time.UnixNano(), 10), e.line})
+	b.size += len(e.line)
+}
+
+func (b *lokiBatch) encode() ([]byte, error) {
+	req := struct {
+		Streams []*lokiStream `json:"streams"`
+	}{}
+	for _, s := range b.streams {
+		req.Streams = append(req.Streams, s)
+	}
+	return json.Marshal(req)
+}
+
+// lokiClient batches the entries and sends them to the push API of Loki,
+// retrying with an exponential back-off on network errors, 429 and 5xx responses.
+// The batches are sent by another goroutine than the one batching the entries,
+// and both queues are bounded: the entries and the batches that do not fit are dropped.
+type lokiClient struct {
+	cfg        *lokiConfig
+	httpClient *http.Client
+	entries    chan lokiEntry
+	batches    chan *lokiBatch
+	dropped    atomic.Int64
+	done       chan struct{}
+}
+
+func newLokiClient(cfg *lokiConfig) *lokiClient {
+	c := &lokiClient{
+		cfg:        cfg,
+		httpClient: &http.Client{Timeout: cfg.timeout},
+		entries:    make(chan lokiEntry, lokiMaxPendingEntries),
+		batches:    make(chan *lokiBatch, lokiMaxPendingBatches),
+		done:       make(chan struct{}),
+	}
+	go c.run()
+	go c.sendBatches()
+	return c
+}
+
+// Push queues an entry, or drops it if the queue is full.
+func (c *lokiClient) Push(labels map[string]string, t time.Time, line string) {
+	select {
+	case c.entries <- lokiEntry{labels: labels, time: t, line: line}:
+		if n := c.dropped.Swap(0); n > 0 {
+			log.L.Warnf("dropped %d log lines because the queue of the Loki log driver was full", n)
+		}
+	default:
+		c.dropped.Add(1)
+	}
+}
+
+// pushMessages pushes the messages until the channel is closed.
+// The parts of a line are joined into one entry, with the time of the first part,
+// up to the batch size.
+func (c *lokiClient) pushMessages(labels map[string]string, messages <-chan *message.Message) {
+	var (
+		partial     strings.Builder
+		partialTime time.Time
+	)
+	for m := range messages {
+		if m.Partial == nil {
+			c.Push(labels, m.Time, m.Line)
+			continue
+		}
+		if partial.Len() == 0 {
+			partialTime = m.Time
+		}
+		partial.WriteString(m.Line)
+		if m.Partial.Last || partial.Len() >= c.cfg.batchSize {
+			c.Push(labels, partialTime, partial.String())
+			partial.Reset()
+		}
+	}
+	if partial.Len() > 0 {
+		c.Push(labels, partialTime, partial.String())
+	}
+}
+
+// Close sends the pending entries and stops the client.
+func (c *lokiClient) Close() {
+	close(c.entries)
+	<-c.done
+	if n := c.dropped.Swap(0); n > 0 {
+		log.L.Warnf("dropped %d log lines because the queue of the Loki log driver was full", n)
+	}
+}
+
+// run batches the entries and queues the batches for sendBatches.
+func (c *lokiClient) run() {
+	defer close(c.batches)
+	batch := newLokiBatch()
+	ticker := time.NewTicker(c.cfg.batchWait / 2)
+	defer ticker.Stop()
+	flush := func() {
+		if len(batch.streams) > 0 {
+			select {
+			case c.batches <- batch:
+			default:
+				log.L.Warnf("dropping %d bytes of logs because %d batches are already waiting to be sent to Loki", batch.size, lokiMaxPendingBatches)
+			}
+		}
+		batch = newLokiBatch()
+	}
+	for {
+		select {
+		case e, ok := <-c.entries:
+			if !ok {
+				// The last batch is not dropped, Close waits for it to be sent.
+				if len(batch.streams) > 0 {
+					c.batches <- batch
+				}
+				return
+			}
+			if len(batch.streams) == 0 {
+				batch.created = time.Now()
+			}
+			batch.add(e)
+			if batch.size >= c.cfg.batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			if len(batch.streams) > 0 && time.Since(batch.created) >= c.cfg.batchWait {
+				flush()
+			}
+		}
+	}
+}
+
+func (c *lokiClient) sendBatches() {
+	defer close(c.done)
+	for batch := range c.batches {
+		c.send(batch)
+	}
+}
+
+func (c *lokiClient) send(batch *lokiBatch) {
+	body, err := batch.encode()
+	if err != nil {
+		log.L.WithError(err).Error("failed to encode Loki batch")
+		return
+	}
+	backoff := c.cfg.minBackoff
+	for attempt := 0; ; attempt++ {
+		status, err := c.push(body)
+		if err == nil && status/100 == 2 {
+			return
+		}
+		if err == nil && status/100 == 4 && status != http.StatusTooManyRequests {
+			log.L.Errorf("Loki rejected a batch of logs with status %d, dropping it", status)
+			return
+		}
+		if attempt >= c.cfg.retries {
+			log.L.WithError(err).Errorf("failed to send a batch of logs to Loki after %d attempts (last status %d), dropping it", attempt+1, status)
+			return
+		}
+		log.L.WithError(err).Debugf("failed to send a batch of logs to Loki (status %d), retrying in %s", status, backoff)
+		time.Sleep(backoff)
+		backoff = min(backoff*2, c.cfg.maxBackoff)
+	}
+}
+
+func (c *lokiClient) push(body []byte) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, c.cfg.url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.cfg.tenantID != "" {
+		req.Header.Set("X-Scope-OrgID", c.cfg.tenantID)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,243 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package logging
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/nerdctl/v2/pkg/logging/message"
+)
+
+func TestParseLokiConfig(t *testing.T) {
+	cfg, err := parseLokiConfig(map[string]string{
+		lokiURL:            "http://localhost:3100/loki/api/v1/push",
+		lokiBatchSize:      "10k",
+		lokiBatchWait:      "2s",
+		lokiRetries:        "0",
+		lokiExternalLabels: "job=nerdctl,name={{.Name}}",
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, cfg.batchSize, 10000)
+	assert.Equal(t, cfg.batchWait, 2*time.Second)
+	assert.Equal(t, cfg.retries, 0)
+	assert.DeepEqual(t, cfg.externalLabels, map[string]string{"job": "nerdctl", "name": "{{.Name}}"})
+
+	for _, opts := range []map[string]string{
+		{},
+		{lokiURL: "udp://localhost:3100"},
+		{lokiURL: "http://localhost:3100", lokiBatchSize: "0"},
+		{lokiURL: "http://localhost:3100", lokiTimeout: "forever"},
+		{lokiURL: "http://localhost:3100", lokiRetries: "-1"},
+		{lokiURL: "http://localhost:3100", lokiMinBackoff: "1m", lokiMaxBackoff: "1s"},
+		{lokiURL: "http://localhost:3100", lokiExternalLabels: "job"},
+	} {
+		_, err := parseLokiConfig(opts)
+		assert.Assert(t, err != nil, "expected an error for %v", opts)
+	}
+}
+
+func TestLokiStreamLabels(t *testing.T) {
+	cfg, err := parseLokiConfig(map[string]string{
+		lokiURL:            "http://localhost:3100",
+		lokiExternalLabels: "job=nerdctl,ns={{.Namespace}}",
+	})
+	assert.NilError(t, err)
+	labels, err := lokiStreamLabels(cfg, "host1", &containerLogInfo{
+		ID:        "0123456789ab",
+		Namespace: "default",
+		Name:      "web",
+		Tag:       "0123456789ab",
+		Extra:     map[string]string{"com.example/role": "frontend", "1st": "yes"},
+	})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, labels, map[string]string{
+		"host":             "host1",
+		"container_name":   "web",
+		"job":              "nerdctl",
+		"ns":               "default",
+		"com_example_role": "frontend",
+		"_1st":             "yes",
+	})
+}
+
+func TestLokiClientRetry(t *testing.T) {
+	type pushRequest struct {
+		Streams []lokiStream `json:"streams"`
+	}
+	var (
+		mu       sync.Mutex
+		attempts int
+		pushed   []pushRequest
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Check(t, r.Header.Get("X-Scope-OrgID") == "tenant1")
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		var req pushRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		pushed = append(pushed, req)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	cfg, err := parseLokiConfig(map[string]string{
+		lokiURL:        srv.URL,
+		lokiTenantID:   "tenant1",
+		lokiBatchWait:  "1h",
+		lokiMinBackoff: "10ms",
+		lokiMaxBackoff: "10ms",
+	})
+	assert.NilError(t, err)
+	c := newLokiClient(cfg)
+	now := time.Unix(0, 1000)
+	c.Push(map[string]string{"source": "stdout"}, now, "foo")
+	c.Push(map[string]string{"source": "stderr"}, now, "bar")
+	c.Push(map[string]string{"source": "stdout"}, now, "baz")
+	// Close flushes the batch even though the batch wait has not elapsed
+	c.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, attempts, 2)
+	assert.Equal(t, len(pushed), 1)
+	values := make(map[string][][2]string)
+	for _, s := range pushed[0].Streams {
+		values[s.Stream["source"]] = s.Values
+	}
+	assert.DeepEqual(t, values, map[string][][2]string{
+		"stdout": {{"1000", "foo"}, {"1000", "baz"}},
+		"stderr": {{"1000", "bar"}},
+	})
+}
+
+func TestLokiClientDropsRejectedBatch(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		attempts int
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		attempts++
+		mu.Unlock()
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	cfg, err := parseLokiConfig(map[string]string{lokiURL: srv.URL, lokiMinBackoff: "10ms"})
+	assert.NilError(t, err)
+	c := newLokiClient(cfg)
+	c.Push(map[string]string{"source": "stdout"}, time.Now(), "foo")
+	c.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, attempts, 1)
+}
+
+func TestLokiClientDoesNotBlock(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		pushed int
+	)
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		mu.Lock()
+		pushed++
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	cfg, err := parseLokiConfig(map[string]string{lokiURL: srv.URL, lokiBatchSize: "1"})
+	assert.NilError(t, err)
+	c := newLokiClient(cfg)
+	const lines = 10 * lokiMaxPendingEntries
+	pushedAll := make(chan struct{})
+	go func() {
+		defer close(pushedAll)
+		for i := 0; i < lines; i++ {
+			c.Push(map[string]string{"source": "stdout"}, time.Now(), "foo")
+		}
+	}()
+	select {
+	case <-pushedAll:
+	case <-time.After(10 * time.Second):
+		t.Fatal("Push blocked while Loki was not responding")
+	}
+	close(release)
+	c.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Assert(t, pushed > 0 && pushed < lines, "pushed %d batches", pushed)
+}
+
+func TestLokiClientJoinsPartialMessages(t *testing.T) {
+	type pushRequest struct {
+		Streams []lokiStream `json:"streams"`
+	}
+	var (
+		mu     sync.Mutex
+		values [][2]string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req pushRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		for _, s := range req.Streams {
+			values = append(values, s.Values...)
+		}
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	cfg, err := parseLokiConfig(map[string]string{lokiURL: srv.URL, lokiBatchWait: "1h"})
+	assert.NilError(t, err)
+	c := newLokiClient(cfg)
+	messages := make(chan *message.Message, 4)
+	messages <- &message.Message{Time: time.Unix(0, 1000), Line: "foo", Partial: &message.PartialMetadata{ID: "a", Ordinal: 1}}
+	messages <- &message.Message{Time: time.Unix(0, 2000), Line: "bar", Partial: &message.PartialMetadata{ID: "a", Ordinal: 2, Last: true}}
+	messages <- &message.Message{Time: time.Unix(0, 3000), Line: "baz"}
+	messages <- &message.Message{Time: time.Unix(0, 4000), Line: "qux", Partial: &message.PartialMetadata{ID: "b", Ordinal: 1}}
+	close(messages)
+	c.pushMessages(map[string]string{"source": "stdout"}, messages)
+	c.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.DeepEqual(t, values, [][2]string{{"1000", "foobar"}, {"3000", "baz"}, {"4000", "qux"}})
+}
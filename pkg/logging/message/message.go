@@ -0,0 +1,49 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package message defines the log messages passed from the logging plugin to the drivers.
+package message
+
+import "time"
+
+// PartialMetadata describes a message that is a part of a line, like Docker's PartialLogMetaData.
+// A line longer than the maximum line size is split into several messages sharing the same ID,
+// and the text written without a trailing newline before the stream is closed is a partial message too.
+type PartialMetadata struct {
+	// ID is shared by the parts of the same line
+	ID string
+	// Ordinal is the position of the part in the line, starting at 1
+	Ordinal int
+	// Last is true for the part that ends the line
+	Last bool
+}
+
+// Message is a line, or a part of a line, written by the container.
+type Message struct {
+	// Source is "stdout" or "stderr"
+	Source string
+	// Time is the time the message was read
+	Time time.Time
+	// Line is the text of the message, without the trailing newline
+	Line string
+	// Partial is nil unless the message is a part of a line
+	Partial *PartialMetadata
+}
+
+// EndsLine returns true if the message was terminated by a newline.
+func (m *Message) EndsLine() bool {
+	return m.Partial == nil || m.Partial.Last
+}
@@ -20,6 +20,8 @@ import (
 	"context"
 
 	"github.com/containerd/containerd/v2/core/runtime/v2/logging"
+
+	"github.com/containerd/nerdctl/v2/pkg/logging/message"
 )
 
 type NoneLogger struct {
@@ -34,7 +36,7 @@ func (n *NoneLogger) PreProcess(ctx context.Context, dataStore string, config *l
 	return nil
 }
 
-func (n *NoneLogger) Process(stdout <-chan string, stderr <-chan string) error {
+func (n *NoneLogger) Process(stdout <-chan *message.Message, stderr <-chan *message.Message) error {
 	return nil
 }
 
@@ -25,6 +25,8 @@ import (
 	"gotest.tools/v3/assert"
 
 	"github.com/containerd/containerd/v2/core/runtime/v2/logging"
+
+	"github.com/containerd/nerdctl/v2/pkg/logging/message"
 )
 
 func TestNoneLogger(t *testing.T) {
@@ -44,13 +46,13 @@ func TestNoneLogger(t *testing.T) {
 		logger.Init(tmpDir, "namespace", "id")
 		logger.PreProcess(ctx, tmpDir, &logging.Config{})
 
-		stdout := make(chan string)
-		stderr := make(chan string)
+		stdout := make(chan *message.Message)
+		stderr := make(chan *message.Message)
 
 		go func() {
 			for i := 0; i < 10; i++ {
-				stdout <- "test stdout"
-				stderr <- "test stderr"
+				stdout <- &message.Message{Source: "stdout", Time: time.Now(), Line: "test stdout"}
+				stderr <- &message.Message{Source: "stderr", Time: time.Now(), Line: "test stderr"}
 			}
 			close(stdout)
 			close(stderr)
@@ -34,6 +34,7 @@ import (
 	"github.com/containerd/containerd/v2/core/runtime/v2/logging"
 	"github.com/containerd/log"
 
+	"github.com/containerd/nerdctl/v2/pkg/logging/message"
 	"github.com/containerd/nerdctl/v2/pkg/strutil"
 )
 
@@ -132,13 +133,13 @@ func (sy *SyslogLogger) PreProcess(ctx context.Context, dataStore string, config
 	return nil
 }
 
-func (sy *SyslogLogger) Process(stdout <-chan string, stderr <-chan string) error {
+func (sy *SyslogLogger) Process(stdout <-chan *message.Message, stderr <-chan *message.Message) error {
 	var wg sync.WaitGroup
 	wg.Add(2)
-	fn := func(dataChan <-chan string, logFn func(msg string) error) {
+	fn := func(dataChan <-chan *message.Message, logFn func(msg string) error) {
 		defer wg.Done()
-		for log := range dataChan {
-			logFn(log)
+		for m := range dataChan {
+			logFn(m.Line)
 		}
 	}
 	go fn(stdout, sy.logger.Info)
@@ -0,0 +1,141 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package metricsutil writes metrics in the Prometheus text exposition format, and in the OpenMetrics format.
+package metricsutil
+
+import (
+	"bufio"
+	"io"
+	"maps"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Type is the type of a metric family.
+type Type string
+
+const (
+	Gauge   Type = "gauge"
+	Counter Type = "counter"
+)
+
+const (
+	// ContentTypeText is the content type of the Prometheus text format.
+	ContentTypeText = "text/plain; version=0.0.4; charset=utf-8"
+	// ContentTypeOpenMetrics is the content type of the OpenMetrics format.
+	ContentTypeOpenMetrics = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+)
+
+// Family is a family of metrics.
+type Family struct {
+	// Name is the name of the family. The samples of the counters are suffixed with "_total".
+	Name    string
+	Help    string
+	Type    Type
+	Samples []Sample
+}
+
+// Sample is a sample of a family.
+type Sample struct {
+	Labels map[string]string
+	Value  float64
+}
+
+// Add adds a sample to the family.
+func (f *Family) Add(value float64, labels map[string]string) {
+	f.Samples = append(f.Samples, Sample{Labels: labels, Value: value})
+}
+
+// Negotiate returns whether the OpenMetrics format is accepted by a request, and the content type of the response.
+func Negotiate(r *http.Request) (bool, string) {
+	if strings.Contains(r.Header.Get("Accept"), "application/openmetrics-text") {
+		return true, ContentTypeOpenMetrics
+	}
+	return false, ContentTypeText
+}
+
+// Write writes families in the Prometheus text format, or in the OpenMetrics format.
+// The families without samples are skipped.
+func Write(w io.Writer, families []*Family, openMetrics bool) error {
+	bw := bufio.NewWriter(w)
+	for _, f := range families {
+		if len(f.Samples) == 0 {
+			continue
+		}
+		name, sampleName := f.Name, f.Name
+		if f.Type == Counter {
+			sampleName += "_total"
+			// the name of the family is the name of the samples in the Prometheus text format
+			if !openMetrics {
+				name = sampleName
+			}
+		}
+		bw.WriteString("# HELP " + name + " " + escapeHelp(f.Help) + "\n")
+		bw.WriteString("# TYPE " + name + " " + string(f.Type) + "\n")
+		for _, s := range f.Samples {
+			bw.WriteString(sampleName)
+			writeLabels(bw, s.Labels)
+			bw.WriteString(" " + formatValue(s.Value) + "\n")
+		}
+	}
+	if openMetrics {
+		bw.WriteString("# EOF\n")
+	}
+	return bw.Flush()
+}
+
+func writeLabels(w *bufio.Writer, labels map[string]string) {
+	if len(labels) == 0 {
+		return
+	}
+	w.WriteByte('{')
+	for i, k := range slices.Sorted(maps.Keys(labels)) {
+		if i > 0 {
+			w.WriteByte(',')
+		}
+		w.WriteString(k + `="` + escapeLabelValue(labels[k]) + `"`)
+	}
+	w.WriteByte('}')
+}
+
+var (
+	helpEscaper       = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelValueEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string {
+	return helpEscaper.Replace(s)
+}
+
+func escapeLabelValue(s string) string {
+	return labelValueEscaper.Replace(s)
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
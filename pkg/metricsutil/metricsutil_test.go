@@ -0,0 +1,80 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package metricsutil
+
+import (
+	"bytes"
+	"math"
+	"net/http/httptest"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestWrite(t *testing.T) {
+	families := []*Family{
+		{
+			Name: "nerdctl_container_network_receive_bytes",
+			Help: "Bytes received by the container",
+			Type: Counter,
+		},
+		{
+			Name: "nerdctl_container_memory_usage_bytes",
+			Help: "Memory used by the container,\nwithout the inactive file cache",
+			Type: Gauge,
+		},
+		{
+			Name: "nerdctl_images",
+			Help: "Number of images",
+			Type: Gauge,
+		},
+	}
+	families[0].Add(1024, map[string]string{"name": "web", "id": "abc"})
+	families[1].Add(1.5e9, map[string]string{"name": `we"b\`})
+	families[1].Add(math.Inf(1), nil)
+
+	var text bytes.Buffer
+	assert.NilError(t, Write(&text, families, false))
+	assert.Equal(t, text.String(), `# HELP nerdctl_container_network_receive_bytes_total Bytes received by the container
+# TYPE nerdctl_container_network_receive_bytes_total counter
+nerdctl_container_network_receive_bytes_total{id="abc",name="web"} 1024
+# HELP nerdctl_container_memory_usage_bytes Memory used by the container,\nwithout the inactive file cache
+# TYPE nerdctl_container_memory_usage_bytes gauge
+nerdctl_container_memory_usage_bytes{name="we\"b\\"} 1.5e+09
+nerdctl_container_memory_usage_bytes +Inf
+`)
+
+	var openMetrics bytes.Buffer
+	assert.NilError(t, Write(&openMetrics, families[:1], true))
+	assert.Equal(t, openMetrics.String(), `# HELP nerdctl_container_network_receive_bytes Bytes received by the container
+# TYPE nerdctl_container_network_receive_bytes counter
+nerdctl_container_network_receive_bytes_total{id="abc",name="web"} 1024
+# EOF
+`)
+}
+
+func TestNegotiate(t *testing.T) {
+	r := httptest.NewRequest("GET", "/metrics", nil)
+	openMetrics, contentType := Negotiate(r)
+	assert.Assert(t, !openMetrics)
+	assert.Equal(t, contentType, ContentTypeText)
+
+	r.Header.Set("Accept", "application/openmetrics-text;version=1.0.0,text/plain;q=0.5")
+	openMetrics, contentType = Negotiate(r)
+	assert.Assert(t, openMetrics)
+	assert.Equal(t, contentType, ContentTypeOpenMetrics)
+}
@@ -23,6 +23,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"os"
 	"path/filepath"
 
 	"github.com/containerd/log"
@@ -332,6 +333,15 @@ func (vs *volumeStore) rawGet(name string, size bool) (vol *native.Volume, err e
 		return nil, err
 	}
 
+	// volume.json is written once on creation, so its modification time is the creation time of the volume
+	jsonPath, err := vs.manager.Location(name, volumeJSONFileName)
+	if err != nil {
+		return nil, err
+	}
+	if st, err := os.Stat(jsonPath); err == nil {
+		vol.CreatedAt = st.ModTime()
+	}
+
 	if size {
 		vol.Size, err = vs.manager.GroupSize(name, dataDirName)
 		if err != nil {
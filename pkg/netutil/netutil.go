@@ -21,6 +21,7 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net"
 	"os"
@@ -37,6 +38,7 @@ import (
 	"github.com/containerd/log"
 
 	"github.com/containerd/nerdctl/v2/pkg/api/types"
+	"github.com/containerd/nerdctl/v2/pkg/config"
 	"github.com/containerd/nerdctl/v2/pkg/internal/filesystem"
 	"github.com/containerd/nerdctl/v2/pkg/labels"
 	"github.com/containerd/nerdctl/v2/pkg/netutil/nettype"
@@ -45,9 +47,10 @@ import (
 )
 
 type CNIEnv struct {
-	Path        string
-	NetconfPath string
-	Namespace   string
+	Path         string
+	NetconfPath  string
+	Namespace    string
+	AddressPools []config.AddressPool
 }
 
 type CNIEnvOpt func(e *CNIEnv) error
@@ -190,6 +193,25 @@ func WithNamespace(namespace string) CNIEnvOpt {
 	}
 }
 
+// WithDefaultAddressPools sets the address pools used for allocating subnets
+// to networks created without an explicit subnet.
+func WithDefaultAddressPools(pools []config.AddressPool) CNIEnvOpt {
+	return func(e *CNIEnv) error {
+		for _, p := range pools {
+			_, base, err := net.ParseCIDR(p.Base)
+			if err != nil {
+				return fmt.Errorf("failed to parse address pool base %q: %w", p.Base, err)
+			}
+			ones, bits := base.Mask.Size()
+			if p.Size < ones || p.Size > bits {
+				return fmt.Errorf("invalid size %d for address pool %q", p.Size, p.Base)
+			}
+		}
+		e.AddressPools = pools
+		return nil
+	}
+}
+
 func NewCNIEnv(cniPath, cniConfPath string, opts ...CNIEnvOpt) (*CNIEnv, error) {
 	e := CNIEnv{
 		Path:        cniPath,
@@ -526,6 +548,28 @@ func (e *CNIEnv) parseSubnet(subnetStr string) (*net.IPNet, error) {
 	if err != nil {
 		return nil, err
 	}
+	if subnetStr == "" {
+		// The allocated subnets must not shadow the routes of the host, while an explicit subnet may
+		routeSubnets, err := subnetutil.GetHostRouteSubnets()
+		if err != nil {
+			return nil, err
+		}
+		usedSubnets = append(usedSubnets, routeSubnets...)
+	}
+	if subnetStr == "" && len(e.AddressPools) > 0 {
+		for _, p := range e.AddressPools {
+			_, pool, err := net.ParseCIDR(p.Base)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse address pool base %q: %w", p.Base, err)
+			}
+			subnet, err := subnetutil.GetFreeSubnetInPool(pool, p.Size, usedSubnets)
+			if err == nil {
+				return subnet, nil
+			}
+			log.L.WithError(err).Debugf("no free subnet in address pool %s", p)
+		}
+		return nil, errors.New("could not find free subnet in the default address pools")
+	}
 	if subnetStr == "" {
 		_, defaultSubnet, _ := net.ParseCIDR(StartingCIDR)
 		subnet, err := subnetutil.GetFreeSubnet(defaultSubnet, usedSubnets)
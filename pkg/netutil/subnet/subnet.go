@@ -58,6 +58,29 @@ func GetFreeSubnet(n *net.IPNet, usedNetworks []*net.IPNet) (*net.IPNet, error)
 	return nil, fmt.Errorf("could not find free subnet")
 }
 
+// GetFreeSubnetInPool tries to find a free subnet with the given prefix size inside the pool
+func GetFreeSubnetInPool(pool *net.IPNet, size int, usedNetworks []*net.IPNet) (*net.IPNet, error) {
+	ones, bits := pool.Mask.Size()
+	if size < ones || size > bits {
+		return nil, fmt.Errorf("invalid subnet size %d for address pool %s", size, pool.String())
+	}
+	n := &net.IPNet{
+		IP:   append(net.IP(nil), pool.IP...),
+		Mask: net.CIDRMask(size, bits),
+	}
+	for pool.Contains(n.IP) {
+		if !IntersectsWithNetworks(n, usedNetworks) {
+			return n, nil
+		}
+		next, err := nextSubnet(n)
+		if err != nil {
+			break
+		}
+		n = next
+	}
+	return nil, fmt.Errorf("could not find free subnet in address pool %s", pool.String())
+}
+
 func nextSubnet(subnet *net.IPNet) (*net.IPNet, error) {
 	newSubnet := &net.IPNet{
 		IP:   subnet.IP,
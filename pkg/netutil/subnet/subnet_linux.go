@@ -0,0 +1,49 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package subnet
+
+import (
+	"net"
+
+	"github.com/vishvananda/netlink"
+
+	"github.com/containerd/nerdctl/v2/pkg/rootlessutil"
+)
+
+// GetHostRouteSubnets returns the destinations of the routes on the host (e.g., VPN routes),
+// excluding the default routes.
+func GetHostRouteSubnets() ([]*net.IPNet, error) {
+	var routes []netlink.Route
+	if err := rootlessutil.WithDetachedNetNSIfAny(func() error {
+		var err2 error
+		routes, err2 = netlink.RouteList(nil, netlink.FAMILY_ALL)
+		return err2
+	}); err != nil {
+		return nil, err
+	}
+	nets := make([]*net.IPNet, 0, len(routes))
+	for _, r := range routes {
+		if r.Dst == nil {
+			continue
+		}
+		if ones, _ := r.Dst.Mask.Size(); ones == 0 {
+			continue
+		}
+		nets = append(nets, r.Dst)
+	}
+	return nets, nil
+}
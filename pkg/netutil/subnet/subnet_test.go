@@ -48,3 +48,20 @@ func TestNextSubnet(t *testing.T) {
 		assert.Equal(t, nextSubnet.String(), tc.expect)
 	}
 }
+
+func TestGetFreeSubnetInPool(t *testing.T) {
+	_, pool, _ := net.ParseCIDR("172.30.0.0/16")
+	_, used1, _ := net.ParseCIDR("172.30.0.0/24")
+	_, used2, _ := net.ParseCIDR("172.30.1.0/25")
+
+	subnet, err := GetFreeSubnetInPool(pool, 24, []*net.IPNet{used1, used2})
+	assert.NilError(t, err)
+	assert.Equal(t, subnet.String(), "172.30.2.0/24")
+
+	_, full, _ := net.ParseCIDR("172.30.0.0/16")
+	_, err = GetFreeSubnetInPool(pool, 24, []*net.IPNet{full})
+	assert.ErrorContains(t, err, "could not find free subnet")
+
+	_, err = GetFreeSubnetInPool(pool, 8, nil)
+	assert.ErrorContains(t, err, "invalid subnet size")
+}
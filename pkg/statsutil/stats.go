@@ -45,6 +45,62 @@ type StatsEntry struct {
 	BlockWrite       float64
 	PidsCurrent      uint64
 	IsInvalid        bool
+
+	// Read is the time of the sample.
+	Read time.Time
+	// Networks are the statistics of the network interfaces, without the loopback one.
+	Networks []NetworkStats
+	// OOMKills is the number of processes killed by the OOM killer.
+	OOMKills uint64
+	// CPUUsage is the cumulative CPU time of the container, in nanoseconds.
+	CPUUsage uint64
+	// SystemCPUUsage is the cumulative CPU time of the host, in nanoseconds, and OnlineCPUs its number of CPUs.
+	// With cgroup v1, CPUPercentage is computed from their deltas and the one of CPUUsage, like Docker does.
+	// With cgroup v2, it is computed from the delta of CPUUsage and the time between the samples.
+	SystemCPUUsage uint64
+	OnlineCPUs     uint32
+
+	// The memory breakdown and the pressure stall information are only available with cgroup v2.
+	MemoryAnon float64
+	MemoryFile float64
+	// MemoryKernelStack, MemorySlab and MemorySock are the main kernel allocations, not the whole "kernel" entry of memory.stat.
+	MemoryKernelStack float64
+	MemorySlab        float64
+	MemorySock        float64
+	CPUPressure       *Pressure
+	MemoryPressure    *Pressure
+	IOPressure        *Pressure
+}
+
+// NetworkStats represents the statistics of a network interface of a container.
+type NetworkStats struct {
+	Name      string
+	RxBytes   uint64
+	RxPackets uint64
+	RxErrors  uint64
+	RxDropped uint64
+	TxBytes   uint64
+	TxPackets uint64
+	TxErrors  uint64
+	TxDropped uint64
+}
+
+// Pressure represents the pressure stall information (PSI) of a resource.
+type Pressure struct {
+	// Some is the share of time in which at least some tasks are stalled on the resource.
+	Some PressureData
+	// Full is the share of time in which all the tasks are stalled on the resource.
+	Full PressureData
+}
+
+// PressureData represents the "some" or the "full" line of the pressure stall information.
+type PressureData struct {
+	// Avg10, Avg60 and Avg300 are the percentages of stalled time in the last 10, 60 and 300 seconds.
+	Avg10  float64
+	Avg60  float64
+	Avg300 float64
+	// Total is the total stalled time, in microseconds.
+	Total uint64
 }
 
 // FormattedStatsEntry represents a formatted StatsEntry
@@ -59,6 +115,35 @@ type FormattedStatsEntry struct {
 	PIDs     string
 }
 
+// JSONStatsEntry represents the statistics printed by `nerdctl stats --format json`.
+// Along with the fields of FormattedStatsEntry, it has the raw counters, so that the rates can be computed from them.
+type JSONStatsEntry struct {
+	FormattedStatsEntry
+	Read              time.Time
+	CPUPercentage     float64
+	CPUUsage          uint64
+	SystemCPUUsage    uint64
+	OnlineCPUs        uint32
+	Memory            float64
+	MemoryLimit       float64
+	MemoryPercentage  float64
+	MemoryAnon        float64 `json:",omitempty"`
+	MemoryFile        float64 `json:",omitempty"`
+	MemoryKernelStack float64 `json:",omitempty"`
+	MemorySlab        float64 `json:",omitempty"`
+	MemorySock        float64 `json:",omitempty"`
+	OOMKills          uint64
+	NetworkRx         float64
+	NetworkTx         float64
+	Networks          []NetworkStats
+	BlockRead         float64
+	BlockWrite        float64
+	PidsCurrent       uint64
+	CPUPressure       *Pressure `json:",omitempty"`
+	MemoryPressure    *Pressure `json:",omitempty"`
+	IOPressure        *Pressure `json:",omitempty"`
+}
+
 // Stats represents an entity to store containers statistics synchronously
 type Stats struct {
 	mutex sync.RWMutex
@@ -117,6 +202,19 @@ func (cs *Stats) SetErrorAndReset(err error) {
 	cs.BlockRead = 0
 	cs.BlockWrite = 0
 	cs.PidsCurrent = 0
+	cs.Networks = nil
+	cs.OOMKills = 0
+	cs.CPUUsage = 0
+	cs.SystemCPUUsage = 0
+	cs.OnlineCPUs = 0
+	cs.MemoryAnon = 0
+	cs.MemoryFile = 0
+	cs.MemoryKernelStack = 0
+	cs.MemorySlab = 0
+	cs.MemorySock = 0
+	cs.CPUPressure = nil
+	cs.MemoryPressure = nil
+	cs.IOPressure = nil
 	cs.err = err
 	cs.IsInvalid = true
 }
@@ -145,6 +243,40 @@ func RenderEntry(in *StatsEntry, noTrunc bool) FormattedStatsEntry {
 	}
 }
 
+// RenderJSONEntry renders a JSONStatsEntry from StatsEntry
+func RenderJSONEntry(in *StatsEntry, noTrunc bool) JSONStatsEntry {
+	networks := in.Networks
+	if networks == nil {
+		networks = []NetworkStats{}
+	}
+	return JSONStatsEntry{
+		FormattedStatsEntry: RenderEntry(in, noTrunc),
+		Read:                in.Read,
+		CPUPercentage:       in.CPUPercentage,
+		CPUUsage:            in.CPUUsage,
+		SystemCPUUsage:      in.SystemCPUUsage,
+		OnlineCPUs:          in.OnlineCPUs,
+		Memory:              in.Memory,
+		MemoryLimit:         in.MemoryLimit,
+		MemoryPercentage:    in.MemoryPercentage,
+		MemoryAnon:          in.MemoryAnon,
+		MemoryFile:          in.MemoryFile,
+		MemoryKernelStack:   in.MemoryKernelStack,
+		MemorySlab:          in.MemorySlab,
+		MemorySock:          in.MemorySock,
+		OOMKills:            in.OOMKills,
+		NetworkRx:           in.NetworkRx,
+		NetworkTx:           in.NetworkTx,
+		Networks:            networks,
+		BlockRead:           in.BlockRead,
+		BlockWrite:          in.BlockWrite,
+		PidsCurrent:         in.PidsCurrent,
+		CPUPressure:         in.CPUPressure,
+		MemoryPressure:      in.MemoryPressure,
+		IOPressure:          in.IOPressure,
+	}
+}
+
 /*
 a set of functions to format container stats
 */
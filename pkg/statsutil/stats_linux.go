@@ -40,6 +40,10 @@ func calculateMemPercent(limit float64, usedNo float64) float64 {
 
 func SetCgroupStatsFields(previousStats *ContainerStats, data *v1.Metrics, links []netlink.Link, systemInfo SystemInfo) (StatsEntry, error) {
 	cpuPercent := calculateCgroupCPUPercent(previousStats, data, systemInfo)
+	onlineCPUs := systemInfo.OnlineCPUs
+	if onlineCPUs == 0 {
+		onlineCPUs = uint32(len(data.CPU.Usage.PerCPU))
+	}
 	blkRead, blkWrite := calculateCgroupBlockIO(data)
 	mem := calculateCgroupMemUsage(data)
 	memLimit := getCgroupMemLimit(float64(data.Memory.Usage.Limit))
@@ -47,6 +51,11 @@ func SetCgroupStatsFields(previousStats *ContainerStats, data *v1.Metrics, links
 	pidsStatsCurrent := data.Pids.Current
 	netRx, netTx := calculateCgroupNetwork(links)
 
+	var oomKills uint64
+	if data.MemoryOomControl != nil {
+		oomKills = data.MemoryOomControl.OomKill
+	}
+
 	return StatsEntry{
 		CPUPercentage:    cpuPercent,
 		Memory:           mem,
@@ -57,11 +66,17 @@ func SetCgroupStatsFields(previousStats *ContainerStats, data *v1.Metrics, links
 		BlockRead:        float64(blkRead),
 		BlockWrite:       float64(blkWrite),
 		PidsCurrent:      pidsStatsCurrent,
+		Read:             time.Now(),
+		Networks:         calculateNetworkStats(links),
+		OOMKills:         oomKills,
+		CPUUsage:         data.CPU.Usage.Total,
+		SystemCPUUsage:   systemInfo.SystemUsage,
+		OnlineCPUs:       onlineCPUs,
 	}, nil
 
 }
 
-func SetCgroup2StatsFields(previousStats *ContainerStats, metrics *v2.Metrics, links []netlink.Link) (StatsEntry, error) {
+func SetCgroup2StatsFields(previousStats *ContainerStats, metrics *v2.Metrics, links []netlink.Link, systemInfo SystemInfo) (StatsEntry, error) {
 	cpuPercent := calculateCgroup2CPUPercent(previousStats, metrics)
 	blkRead, blkWrite := calculateCgroup2IO(metrics)
 	mem := calculateCgroup2MemUsage(metrics)
@@ -70,18 +85,54 @@ func SetCgroup2StatsFields(previousStats *ContainerStats, metrics *v2.Metrics, l
 	pidsStatsCurrent := metrics.Pids.Current
 	netRx, netTx := calculateCgroupNetwork(links)
 
-	return StatsEntry{
-		CPUPercentage:    cpuPercent,
-		Memory:           mem,
-		MemoryPercentage: memPercent,
-		MemoryLimit:      memLimit,
-		NetworkRx:        netRx,
-		NetworkTx:        netTx,
-		BlockRead:        float64(blkRead),
-		BlockWrite:       float64(blkWrite),
-		PidsCurrent:      pidsStatsCurrent,
-	}, nil
+	entry := StatsEntry{
+		CPUPercentage:     cpuPercent,
+		Memory:            mem,
+		MemoryPercentage:  memPercent,
+		MemoryLimit:       memLimit,
+		NetworkRx:         netRx,
+		NetworkTx:         netTx,
+		BlockRead:         float64(blkRead),
+		BlockWrite:        float64(blkWrite),
+		PidsCurrent:       pidsStatsCurrent,
+		Read:              time.Now(),
+		Networks:          calculateNetworkStats(links),
+		CPUUsage:          metrics.CPU.UsageUsec * 1000,
+		SystemCPUUsage:    systemInfo.SystemUsage,
+		OnlineCPUs:        systemInfo.OnlineCPUs,
+		MemoryAnon:        float64(metrics.Memory.Anon),
+		MemoryFile:        float64(metrics.Memory.File),
+		MemoryKernelStack: float64(metrics.Memory.KernelStack),
+		MemorySlab:        float64(metrics.Memory.Slab),
+		MemorySock:        float64(metrics.Memory.Sock),
+		CPUPressure:       cgroup2Pressure(metrics.CPU.GetPSI()),
+		MemoryPressure:    cgroup2Pressure(metrics.Memory.GetPSI()),
+		IOPressure:        cgroup2Pressure(metrics.Io.GetPSI()),
+	}
+	if metrics.MemoryEvents != nil {
+		entry.OOMKills = metrics.MemoryEvents.OomKill
+	}
+	return entry, nil
+
+}
 
+// cgroup2Pressure returns the pressure stall information of a resource, if the kernel reports it.
+func cgroup2Pressure(psi *v2.PSIStats) *Pressure {
+	if psi == nil {
+		return nil
+	}
+	data := func(d *v2.PSIData) PressureData {
+		return PressureData{
+			Avg10:  d.GetAvg10(),
+			Avg60:  d.GetAvg60(),
+			Avg300: d.GetAvg300(),
+			Total:  d.GetTotal(),
+		}
+	}
+	return &Pressure{
+		Some: data(psi.GetSome()),
+		Full: data(psi.GetFull()),
+	}
 }
 
 func getCgroupMemLimit(memLimit float64) float64 {
@@ -212,3 +263,25 @@ func calculateCgroupNetwork(links []netlink.Link) (float64, float64) {
 	}
 	return rx, tx
 }
+
+func calculateNetworkStats(links []netlink.Link) []NetworkStats {
+	var res []NetworkStats
+	for _, l := range links {
+		stats := l.Attrs().Statistics
+		if stats == nil {
+			continue
+		}
+		res = append(res, NetworkStats{
+			Name:      l.Attrs().Name,
+			RxBytes:   stats.RxBytes,
+			RxPackets: stats.RxPackets,
+			RxErrors:  stats.RxErrors,
+			RxDropped: stats.RxDropped,
+			TxBytes:   stats.TxBytes,
+			TxPackets: stats.TxPackets,
+			TxErrors:  stats.TxErrors,
+			TxDropped: stats.TxDropped,
+		})
+	}
+	return res
+}
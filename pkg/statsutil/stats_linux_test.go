@@ -0,0 +1,84 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package statsutil
+
+import (
+	"testing"
+	"time"
+
+	"github.com/vishvananda/netlink"
+	"gotest.tools/v3/assert"
+
+	v2 "github.com/containerd/cgroups/v3/cgroup2/stats"
+)
+
+func TestSetCgroup2StatsFields(t *testing.T) {
+	metrics := &v2.Metrics{
+		Pids: &v2.PidsStat{Current: 3},
+		CPU: &v2.CPUStat{
+			UsageUsec: 1000,
+			PSI: &v2.PSIStats{
+				Some: &v2.PSIData{Avg10: 1.5, Avg60: 0.5, Avg300: 0.1, Total: 4242},
+				Full: &v2.PSIData{},
+			},
+		},
+		Memory: &v2.MemoryStat{
+			Usage:        4096,
+			UsageLimit:   8192,
+			InactiveFile: 1024,
+			Anon:         2048,
+			File:         1024,
+			KernelStack:  100,
+			Slab:         200,
+			Sock:         10,
+		},
+		Io:           &v2.IOStat{},
+		MemoryEvents: &v2.MemoryEvents{OomKill: 2},
+	}
+	eth0 := &netlink.Dummy{LinkAttrs: netlink.LinkAttrs{
+		Name:       "eth0",
+		Statistics: &netlink.LinkStatistics{RxBytes: 10, TxBytes: 20, RxPackets: 1, TxPackets: 2, RxDropped: 3},
+	}}
+
+	entry, err := SetCgroup2StatsFields(&ContainerStats{Time: time.Now()}, metrics, []netlink.Link{eth0}, SystemInfo{OnlineCPUs: 4, SystemUsage: 5000000})
+	assert.NilError(t, err)
+	assert.Equal(t, entry.Memory, float64(3072))
+	assert.Equal(t, entry.MemoryLimit, float64(8192))
+	assert.Equal(t, entry.MemoryAnon, float64(2048))
+	assert.Equal(t, entry.CPUUsage, uint64(1000000))
+	assert.Equal(t, entry.SystemCPUUsage, uint64(5000000))
+	assert.Equal(t, entry.OnlineCPUs, uint32(4))
+	assert.Equal(t, entry.MemoryFile, float64(1024))
+	assert.Equal(t, entry.MemoryKernelStack, float64(100))
+	assert.Equal(t, entry.MemorySlab, float64(200))
+	assert.Equal(t, entry.MemorySock, float64(10))
+	assert.Equal(t, entry.OOMKills, uint64(2))
+	assert.Equal(t, entry.NetworkRx, float64(10))
+	assert.DeepEqual(t, entry.Networks, []NetworkStats{
+		{Name: "eth0", RxBytes: 10, TxBytes: 20, RxPackets: 1, TxPackets: 2, RxDropped: 3},
+	})
+	assert.DeepEqual(t, entry.CPUPressure, &Pressure{Some: PressureData{Avg10: 1.5, Avg60: 0.5, Avg300: 0.1, Total: 4242}})
+	// the pressure is not reported by the kernel
+	assert.Assert(t, entry.MemoryPressure == nil)
+	assert.Assert(t, entry.IOPressure == nil)
+	assert.Assert(t, !entry.Read.IsZero())
+
+	jsonEntry := RenderJSONEntry(&entry, false)
+	assert.Equal(t, jsonEntry.MemUsage, "3KiB / 8KiB")
+	assert.Equal(t, jsonEntry.Memory, float64(3072))
+	assert.Equal(t, jsonEntry.OOMKills, uint64(2))
+}
@@ -0,0 +1,191 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package tarutil
+
+import (
+	"archive/tar"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"syscall"
+)
+
+// FileError is the error of a single file of an archive.
+// Archive and Extract skip the files they fail to process, and report them as FileErrors.
+type FileError struct {
+	Path string
+	Err  error
+}
+
+func (e *FileError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Path, e.Err)
+}
+
+func (e *FileError) Unwrap() error {
+	return e.Err
+}
+
+// ArchiveOptions specifies options for Archive.
+type ArchiveOptions struct {
+	// Name is the name of the top-level entry in the archive. Defaults to the base name of the archived path.
+	// When Name is ".", the content of the directory is archived without the directory itself.
+	Name string
+	// FollowSymlinks archives the targets of symbolic links instead of the links (`tar -h`).
+	FollowSymlinks bool
+}
+
+// Archive writes a tar archive of the file or directory at p to w.
+// The numeric owners of the files are recorded, their user and group names are not.
+// Files that cannot be read are left out of the archive, which is still completed;
+// their errors are then returned joined as *FileError.
+func Archive(w io.Writer, p string, opts ArchiveOptions) error {
+	name := opts.Name
+	if name == "" {
+		name = filepath.Base(p)
+	}
+	a := &archiver{
+		tw:             tar.NewWriter(w),
+		followSymlinks: opts.FollowSymlinks,
+		hardlinks:      map[fileID]string{},
+	}
+	if err := a.add(p, path.Clean(name), nil); err != nil {
+		return err
+	}
+	if err := a.tw.Close(); err != nil {
+		return err
+	}
+	return errors.Join(a.errs...)
+}
+
+type fileID struct {
+	dev uint64
+	ino uint64
+}
+
+type archiver struct {
+	tw             *tar.Writer
+	followSymlinks bool
+	// hardlinks maps the files with several links to the first name they were archived as
+	hardlinks map[fileID]string
+	errs      []error
+}
+
+// add archives p as name. ancestors are the directories being archived above p, to detect symlink loops.
+// Only the errors of the archive writer are returned, the errors of the files are recorded in a.errs.
+func (a *archiver) add(p, name string, ancestors []fileID) error {
+	var (
+		fi  os.FileInfo
+		err error
+	)
+	if a.followSymlinks {
+		fi, err = os.Stat(p)
+	} else {
+		fi, err = os.Lstat(p)
+	}
+	if err != nil {
+		a.errs = append(a.errs, &FileError{Path: p, Err: err})
+		return nil
+	}
+	if fi.Mode()&os.ModeSocket != 0 {
+		// tar cannot represent sockets, like tar(1) they are skipped
+		return nil
+	}
+	var link string
+	if fi.Mode()&os.ModeSymlink != 0 {
+		if link, err = os.Readlink(p); err != nil {
+			a.errs = append(a.errs, &FileError{Path: p, Err: err})
+			return nil
+		}
+	}
+	hdr, err := tar.FileInfoHeader(fi, link)
+	if err != nil {
+		a.errs = append(a.errs, &FileError{Path: p, Err: err})
+		return nil
+	}
+	// The names are resolved against the databases of the host, which may not be those of the container.
+	hdr.Uname, hdr.Gname = "", ""
+	hdr.Name = name
+
+	var id fileID
+	st, _ := fi.Sys().(*syscall.Stat_t)
+	if st != nil {
+		id = fileID{dev: uint64(st.Dev), ino: st.Ino} //nolint:unconvert // Dev is not uint64 on all architectures
+	}
+
+	switch {
+	case fi.IsDir():
+		if slices.Contains(ancestors, id) {
+			a.errs = append(a.errs, &FileError{Path: p, Err: errors.New("symbolic link loop")})
+			return nil
+		}
+		entries, err := os.ReadDir(p)
+		if err != nil {
+			a.errs = append(a.errs, &FileError{Path: p, Err: err})
+			return nil
+		}
+		if name != "." {
+			hdr.Name = name + "/"
+			if err := a.tw.WriteHeader(hdr); err != nil {
+				return err
+			}
+		}
+		ancestors = append(ancestors, id)
+		for _, e := range entries {
+			childName := e.Name()
+			if name != "." {
+				childName = path.Join(name, childName)
+			}
+			if err := a.add(filepath.Join(p, e.Name()), childName, ancestors); err != nil {
+				return err
+			}
+		}
+		return nil
+	case fi.Mode().IsRegular():
+		if st != nil && st.Nlink > 1 {
+			if first, ok := a.hardlinks[id]; ok {
+				hdr.Typeflag = tar.TypeLink
+				hdr.Linkname = first
+				hdr.Size = 0
+				return a.tw.WriteHeader(hdr)
+			}
+		}
+		// The file is opened before writing its header, so that an unreadable file can be skipped.
+		f, err := os.Open(p)
+		if err != nil {
+			a.errs = append(a.errs, &FileError{Path: p, Err: err})
+			return nil
+		}
+		defer f.Close()
+		if st != nil && st.Nlink > 1 {
+			a.hardlinks[id] = name
+		}
+		if err := a.tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		// Past its header, a file cannot be skipped anymore without corrupting the archive.
+		if _, err := io.CopyN(a.tw, f, hdr.Size); err != nil {
+			return &FileError{Path: p, Err: err}
+		}
+		return nil
+	default:
+		return a.tw.WriteHeader(hdr)
+	}
+}
@@ -0,0 +1,160 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package tarutil
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/containerd/nerdctl/v2/pkg/internal/filesystem"
+)
+
+func archiveNames(t *testing.T, b []byte) []string {
+	t.Helper()
+	var names []string
+	tr := tar.NewReader(bytes.NewReader(b))
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return names
+		}
+		assert.NilError(t, err)
+		names = append(names, hdr.Name)
+	}
+}
+
+func TestArchiveExtract(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "src")
+	assert.NilError(t, os.MkdirAll(filepath.Join(src, "sub"), 0o755))
+	assert.NilError(t, filesystem.WriteFile(filepath.Join(src, "sub", "file"), []byte("content"), 0o640))
+	assert.NilError(t, os.Link(filepath.Join(src, "sub", "file"), filepath.Join(src, "hardlink")))
+	assert.NilError(t, os.Symlink("sub/file", filepath.Join(src, "symlink")))
+	assert.NilError(t, os.Chmod(filepath.Join(src, "sub"), 0o750))
+
+	var b bytes.Buffer
+	assert.NilError(t, Archive(&b, src, ArchiveOptions{}))
+	assert.DeepEqual(t, archiveNames(t, b.Bytes()), []string{"src/", "src/hardlink", "src/sub/", "src/sub/file", "src/symlink"})
+
+	dst := filepath.Join(t.TempDir(), "dst")
+	assert.NilError(t, Extract(bytes.NewReader(b.Bytes()), dst, ExtractOptions{}))
+	dt, err := filesystem.ReadFile(filepath.Join(dst, "src", "sub", "file"))
+	assert.NilError(t, err)
+	assert.Equal(t, string(dt), "content")
+	link, err := os.Readlink(filepath.Join(dst, "src", "symlink"))
+	assert.NilError(t, err)
+	assert.Equal(t, link, "sub/file")
+	fi, err := os.Stat(filepath.Join(dst, "src", "sub"))
+	assert.NilError(t, err)
+	assert.Equal(t, fi.Mode().Perm(), os.FileMode(0o750))
+	fi1, err := os.Stat(filepath.Join(dst, "src", "hardlink"))
+	assert.NilError(t, err)
+	fi2, err := os.Stat(filepath.Join(dst, "src", "sub", "file"))
+	assert.NilError(t, err)
+	assert.Assert(t, os.SameFile(fi1, fi2))
+	assert.Equal(t, fi2.Mode().Perm(), os.FileMode(0o640))
+}
+
+func TestArchiveName(t *testing.T) {
+	src := t.TempDir()
+	assert.NilError(t, filesystem.WriteFile(filepath.Join(src, "file"), []byte("content"), 0o644))
+	assert.NilError(t, os.Symlink("file", filepath.Join(src, "symlink")))
+
+	var b bytes.Buffer
+	assert.NilError(t, Archive(&b, src, ArchiveOptions{Name: "."}))
+	assert.DeepEqual(t, archiveNames(t, b.Bytes()), []string{"file", "symlink"})
+
+	b.Reset()
+	assert.NilError(t, Archive(&b, filepath.Join(src, "symlink"), ArchiveOptions{Name: "renamed", FollowSymlinks: true}))
+	dst := t.TempDir()
+	assert.NilError(t, Extract(&b, dst, ExtractOptions{}))
+	fi, err := os.Lstat(filepath.Join(dst, "renamed"))
+	assert.NilError(t, err)
+	assert.Assert(t, fi.Mode().IsRegular())
+}
+
+func TestArchiveFileErrors(t *testing.T) {
+	src := t.TempDir()
+	assert.NilError(t, filesystem.WriteFile(filepath.Join(src, "file"), []byte("content"), 0o644))
+	assert.NilError(t, os.Symlink("nonexistent", filepath.Join(src, "dangling")))
+
+	var b bytes.Buffer
+	err := Archive(&b, src, ArchiveOptions{Name: ".", FollowSymlinks: true})
+	var fileErr *FileError
+	assert.Assert(t, errors.As(err, &fileErr))
+	assert.Equal(t, fileErr.Path, filepath.Join(src, "dangling"))
+	assert.Assert(t, errors.Is(err, os.ErrNotExist))
+	// the other files are still archived
+	assert.DeepEqual(t, archiveNames(t, b.Bytes()), []string{"file"})
+}
+
+func TestExtractConfined(t *testing.T) {
+	outside := t.TempDir()
+	dst := t.TempDir()
+	assert.NilError(t, os.Symlink(outside, filepath.Join(dst, "escape")))
+
+	var b bytes.Buffer
+	tw := tar.NewWriter(&b)
+	for _, name := range []string{"../evil", "escape/evil"} {
+		assert.NilError(t, tw.WriteHeader(&tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0o644, Size: 4}))
+		_, err := tw.Write([]byte("evil"))
+		assert.NilError(t, err)
+	}
+	assert.NilError(t, tw.Close())
+
+	assert.NilError(t, Extract(&b, dst, ExtractOptions{}))
+	entries, err := os.ReadDir(outside)
+	assert.NilError(t, err)
+	assert.Equal(t, len(entries), 0)
+	_, err = os.Stat(filepath.Join(dst, "evil"))
+	assert.NilError(t, err)
+}
+
+func TestExtractOwner(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("requires root")
+	}
+	var b bytes.Buffer
+	tw := tar.NewWriter(&b)
+	assert.NilError(t, tw.WriteHeader(&tar.Header{Name: "file", Typeflag: tar.TypeReg, Mode: 0o644, Uid: 1234, Gid: 5678}))
+	assert.NilError(t, tw.Close())
+	archive := b.Bytes()
+
+	for _, tc := range []struct {
+		opts     ExtractOptions
+		uid, gid uint32
+	}{
+		{ExtractOptions{}, 0, 0},
+		{ExtractOptions{SameOwner: true}, 1234, 5678},
+		{ExtractOptions{SameOwner: true, Chown: &Owner{UID: 42, GID: 43}}, 42, 43},
+	} {
+		dst := t.TempDir()
+		assert.NilError(t, Extract(bytes.NewReader(archive), dst, tc.opts))
+		fi, err := os.Lstat(filepath.Join(dst, "file"))
+		assert.NilError(t, err)
+		st := fi.Sys().(*syscall.Stat_t)
+		assert.Equal(t, st.Uid, tc.uid)
+		assert.Equal(t, st.Gid, tc.gid)
+	}
+}
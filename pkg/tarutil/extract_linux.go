@@ -0,0 +1,227 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package tarutil
+
+import (
+	"archive/tar"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"syscall"
+
+	securejoin "github.com/cyphar/filepath-securejoin"
+	"golang.org/x/sys/unix"
+)
+
+// Owner is a numeric file owner.
+type Owner struct {
+	UID int
+	GID int
+}
+
+// ExtractOptions specifies options for Extract.
+// By default, the extracted files are owned by the calling user (`tar --no-same-owner`).
+type ExtractOptions struct {
+	// SameOwner restores the owners recorded in the archive, which requires privileges (`tar --same-owner`).
+	SameOwner bool
+	// Chown is the owner given to all the extracted files. It takes precedence over SameOwner.
+	Chown *Owner
+}
+
+// Extract extracts the tar archive read from r into dir. dir is created if it does not exist, but its parent must.
+// Entries cannot escape dir: ".." components and symbolic links are resolved within dir.
+// Entries that cannot be extracted are skipped and their errors are returned joined as *FileError,
+// except for the errors affecting the whole destination (e.g., a read-only file system), which abort the extraction.
+func Extract(r io.Reader, dir string, opts ExtractOptions) error {
+	if err := os.Mkdir(dir, 0o755); err == nil {
+		if err := opts.chown(dir, nil); err != nil {
+			return err
+		}
+	} else if !errors.Is(err, os.ErrExist) {
+		return err
+	}
+
+	var (
+		tr   = tar.NewReader(r)
+		errs []error
+		// directories get their mode and times restored last, as extracting their content modifies them
+		dirs []extractedDir
+	)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return errors.Join(append(errs, err)...)
+		}
+		target, err := extractEntry(tr, hdr, dir, opts)
+		if err != nil {
+			err = &FileError{Path: hdr.Name, Err: err}
+			if isFatal(err) {
+				return errors.Join(append(errs, err)...)
+			}
+			errs = append(errs, err)
+			continue
+		}
+		if hdr.Typeflag == tar.TypeDir {
+			dirs = append(dirs, extractedDir{path: target, hdr: hdr})
+		}
+	}
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if err := restoreModeAndTimes(dirs[i].path, dirs[i].hdr); err != nil {
+			errs = append(errs, &FileError{Path: dirs[i].hdr.Name, Err: err})
+		}
+	}
+	return errors.Join(errs...)
+}
+
+type extractedDir struct {
+	path string
+	hdr  *tar.Header
+}
+
+// isFatal returns whether err prevents extracting any other entry.
+func isFatal(err error) bool {
+	return errors.Is(err, syscall.EROFS) || errors.Is(err, syscall.ENOSPC) || errors.Is(err, syscall.EDQUOT)
+}
+
+// resolve returns the path of name within dir, without following a symbolic link in the last component.
+func resolve(dir, name string) (string, error) {
+	name = path.Clean("/" + name)
+	if name == "/" {
+		return dir, nil
+	}
+	parent, err := securejoin.SecureJoin(dir, path.Dir(name))
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(parent, path.Base(name)), nil
+}
+
+func extractEntry(tr *tar.Reader, hdr *tar.Header, dir string, opts ExtractOptions) (string, error) {
+	target, err := resolve(dir, hdr.Name)
+	if err != nil {
+		return "", err
+	}
+	if hdr.Typeflag != tar.TypeDir {
+		// the archive may not contain entries for the parent directories
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return "", err
+		}
+		if err := removeNonDir(target); err != nil {
+			return "", err
+		}
+	}
+
+	mode := uint32(hdr.Mode) & 0o7777
+	switch hdr.Typeflag {
+	case tar.TypeDir:
+		if fi, err := os.Lstat(target); err == nil && !fi.IsDir() {
+			if err := os.Remove(target); err != nil {
+				return "", err
+			}
+		}
+		if err := os.Mkdir(target, 0o755); err != nil && !errors.Is(err, os.ErrExist) {
+			return "", err
+		}
+		return target, opts.chown(target, hdr)
+	case tar.TypeReg:
+		f, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+		if err != nil {
+			return "", err
+		}
+		_, err = io.Copy(f, tr)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return "", err
+		}
+	case tar.TypeSymlink:
+		if err := os.Symlink(hdr.Linkname, target); err != nil {
+			return "", err
+		}
+	case tar.TypeLink:
+		source, err := securejoin.SecureJoin(dir, path.Clean("/"+hdr.Linkname))
+		if err != nil {
+			return "", err
+		}
+		if err := os.Link(source, target); err != nil {
+			return "", err
+		}
+		// the link shares the owner, mode and times of the file extracted earlier
+		return target, nil
+	case tar.TypeChar, tar.TypeBlock, tar.TypeFifo:
+		typ := map[byte]uint32{tar.TypeChar: unix.S_IFCHR, tar.TypeBlock: unix.S_IFBLK, tar.TypeFifo: unix.S_IFIFO}[hdr.Typeflag]
+		if err := unix.Mknod(target, typ|mode, int(unix.Mkdev(uint32(hdr.Devmajor), uint32(hdr.Devminor)))); err != nil {
+			return "", err
+		}
+	default:
+		return "", fmt.Errorf("unsupported file type %q", hdr.Typeflag)
+	}
+
+	if err := opts.chown(target, hdr); err != nil {
+		return "", err
+	}
+	return target, restoreModeAndTimes(target, hdr)
+}
+
+// removeNonDir removes the file at p so that it can be replaced, unless it is a directory.
+func removeNonDir(p string) error {
+	fi, err := os.Lstat(p)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	if fi.IsDir() {
+		return errors.New("cannot overwrite a directory with a non-directory")
+	}
+	return os.Remove(p)
+}
+
+// chown sets the owner of p. hdr is nil for the directories that are not in the archive.
+func (opts ExtractOptions) chown(p string, hdr *tar.Header) error {
+	switch {
+	case opts.Chown != nil:
+		return os.Lchown(p, opts.Chown.UID, opts.Chown.GID)
+	case opts.SameOwner && hdr != nil:
+		return os.Lchown(p, hdr.Uid, hdr.Gid)
+	default:
+		return nil
+	}
+}
+
+func restoreModeAndTimes(p string, hdr *tar.Header) error {
+	if hdr.Typeflag != tar.TypeSymlink {
+		// chmod comes after chown, which clears the setuid and setgid bits
+		if err := unix.Chmod(p, uint32(hdr.Mode)&0o7777); err != nil {
+			return err
+		}
+	}
+	atime := hdr.AccessTime
+	if atime.IsZero() {
+		atime = hdr.ModTime
+	}
+	ts := []unix.Timespec{unix.NsecToTimespec(atime.UnixNano()), unix.NsecToTimespec(hdr.ModTime.UnixNano())}
+	return unix.UtimesNanoAt(unix.AT_FDCWD, p, ts, unix.AT_SYMLINK_NOFOLLOW)
+}
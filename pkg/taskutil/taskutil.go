@@ -39,6 +39,7 @@ import (
 	"github.com/containerd/nerdctl/v2/pkg/cioutil"
 	"github.com/containerd/nerdctl/v2/pkg/consoleutil"
 	"github.com/containerd/nerdctl/v2/pkg/infoutil"
+	"github.com/containerd/nerdctl/v2/pkg/logging/loguri"
 )
 
 // NewTask is from https://github.com/containerd/containerd/blob/v1.4.3/cmd/ctr/commands/tasks/tasks_unix.go#L70-L108
@@ -86,6 +87,13 @@ func NewTask(ctx context.Context, client *containerd.Client, container container
 			ioCreator = cioutil.NewContainerIO(namespace, logURI, false, streams.stdIn, streams.stdOut, streams.stdErr)
 		}
 
+	} else if isTerminal && isDetach && loguri.IsExternal(logURI) {
+		// binary:// and file:// log URIs passed with --log-driver are handled by the shim as is
+		u, err := url.Parse(logURI)
+		if err != nil {
+			return nil, err
+		}
+		ioCreator = cio.TerminalLogURI(u)
 	} else if isTerminal && isDetach {
 		u, err := url.Parse(logURI)
 		if err != nil {